@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
 
@@ -8,8 +9,10 @@ import (
 	"github.com/joho/godotenv"
 	"github.com/warui/event-ticketing-api/internal/config"
 	"github.com/warui/event-ticketing-api/internal/database"
+	"github.com/warui/event-ticketing-api/internal/jobs"
 	"github.com/warui/event-ticketing-api/internal/middleware"
 	"github.com/warui/event-ticketing-api/internal/routes"
+	"github.com/warui/event-ticketing-api/internal/services"
 )
 
 func main() {
@@ -32,6 +35,15 @@ func main() {
 		log.Fatalf("Failed to run migrations: %v", err)
 	}
 
+	// Build the services once so the routes and background jobs share them
+	svc, err := services.NewServices(db, cfg)
+	if err != nil {
+		log.Fatalf("Failed to initialize services: %v", err)
+	}
+
+	// Start background jobs
+	jobs.Start(context.Background(), cfg, svc)
+
 	// Set Gin mode
 	gin.SetMode(cfg.GinMode)
 
@@ -53,7 +65,7 @@ func main() {
 	})
 
 	// Initialize routes
-	routes.SetupRoutes(router, db, cfg)
+	routes.SetupRoutes(router, db, cfg, svc)
 
 	// Start server
 	port := os.Getenv("PORT")
@@ -24,6 +24,10 @@ type Config struct {
 	JWTSecret      string
 	JWTExpiryHours int
 
+	// Ticket QR signing
+	TicketSigningKeys  string
+	TicketSigningKeyID string
+
 	// Authboss
 	CookieSecret  string
 	SessionSecret string
@@ -33,6 +37,15 @@ type Config struct {
 	PaystackPublicKey   string
 	PaystackCallbackURL string
 
+	// Payment providers
+	PaymentProvider            string
+	PaymentProvidersByCurrency string
+	PaymentCallbackURL         string
+	FlutterwaveSecretKey       string
+	FlutterwaveWebhookHash     string
+	PaymentFakeEnabled         bool   // Registers the fake provider outside production
+	PaymentFakeWebhookSecret   string // Signs fake provider webhooks
+
 	// Resend
 	ResendAPIKey string
 	FromEmail    string
@@ -55,6 +68,9 @@ type Config struct {
 	DefaultPlatformFeePercentage   float64
 	DefaultWithdrawalFeePercentage float64
 	Currency                       string
+	CheckoutHoldDuration           time.Duration
+	PaymentAbandonTimeout          time.Duration // Pending payments older than this are marked failed
+	WaitlistOfferDuration          time.Duration // How long a waitlisted buyer has to use their purchase link
 
 	// Frontend
 	FrontendURL string
@@ -66,6 +82,10 @@ func LoadConfig() *Config {
 	rateLimitReq, _ := strconv.Atoi(getEnv("RATE_LIMIT_REQUESTS", "100"))
 	platformFee, _ := strconv.ParseFloat(getEnv("DEFAULT_PLATFORM_FEE_PERCENTAGE", "5.0"), 64)
 	withdrawalFee, _ := strconv.ParseFloat(getEnv("DEFAULT_WITHDRAWAL_FEE_PERCENTAGE", "2.5"), 64)
+	checkoutHold, _ := time.ParseDuration(getEnv("CHECKOUT_HOLD_DURATION", "15m"))
+	paymentAbandon, _ := time.ParseDuration(getEnv("PAYMENT_ABANDON_TIMEOUT", "24h"))
+	waitlistOffer, _ := time.ParseDuration(getEnv("WAITLIST_OFFER_DURATION", "2h"))
+	fakePayments, _ := strconv.ParseBool(getEnv("PAYMENT_FAKE_ENABLED", "false"))
 
 	return &Config{
 		Port:        getEnv("PORT", "8080"),
@@ -82,6 +102,9 @@ func LoadConfig() *Config {
 		JWTSecret:      getEnv("JWT_SECRET", "your-secret-key-change-in-production"),
 		JWTExpiryHours: jwtExpiry,
 
+		TicketSigningKeys:  getEnv("TICKET_SIGNING_KEYS", ""),
+		TicketSigningKeyID: getEnv("TICKET_SIGNING_KEY_ID", ""),
+
 		CookieSecret:  getEnv("AUTHBOSS_COOKIE_SECRET", ""),
 		SessionSecret: getEnv("AUTHBOSS_SESSION_SECRET", ""),
 
@@ -89,6 +112,14 @@ func LoadConfig() *Config {
 		PaystackPublicKey:   getEnv("PAYSTACK_PUBLIC_KEY", ""),
 		PaystackCallbackURL: getEnv("PAYSTACK_CALLBACK_URL", "http://localhost:8080/api/v1/payments/callback"),
 
+		PaymentProvider:            getEnv("PAYMENT_PROVIDER", "paystack"),
+		PaymentProvidersByCurrency: getEnv("PAYMENT_PROVIDERS_BY_CURRENCY", ""),
+		PaymentCallbackURL:         getEnv("PAYMENT_CALLBACK_URL", getEnv("PAYSTACK_CALLBACK_URL", "http://localhost:8080/api/v1/payments/callback")),
+		FlutterwaveSecretKey:       getEnv("FLUTTERWAVE_SECRET_KEY", ""),
+		FlutterwaveWebhookHash:     getEnv("FLUTTERWAVE_WEBHOOK_HASH", ""),
+		PaymentFakeEnabled:         fakePayments,
+		PaymentFakeWebhookSecret:   getEnv("PAYMENT_FAKE_WEBHOOK_SECRET", ""),
+
 		ResendAPIKey: getEnv("RESEND_API_KEY", ""),
 		FromEmail:    getEnv("FROM_EMAIL", "noreply@example.com"),
 		FromName:     getEnv("FROM_NAME", "Event Ticketing"),
@@ -107,6 +138,9 @@ func LoadConfig() *Config {
 		DefaultPlatformFeePercentage:   platformFee,
 		DefaultWithdrawalFeePercentage: withdrawalFee,
 		Currency:                       getEnv("CURRENCY", "NGN"),
+		CheckoutHoldDuration:           checkoutHold,
+		PaymentAbandonTimeout:          paymentAbandon,
+		WaitlistOfferDuration:          waitlistOffer,
 
 		FrontendURL: getEnv("FRONTEND_URL", "http://localhost:3000"),
 	}
@@ -36,17 +36,42 @@ func RunMigrations(db *gorm.DB) error {
 		return fmt.Errorf("failed to create uuid extension: %w", err)
 	}
 
+	// Convert money columns before AutoMigrate changes their type
+	if err := migrateMoneyToMinorUnits(db); err != nil {
+		return fmt.Errorf("failed to convert money columns: %w", err)
+	}
+
 	// Auto migrate all models
 	err := db.AutoMigrate(
 		&models.User{},
 		&models.Category{},
 		&models.Event{},
+		&models.EventSession{},
 		&models.TicketType{},
+		&models.TicketPriceChange{},
+		&models.Seat{},
+		&models.PromoCode{},
+		&models.AccessCode{},
 		&models.Ticket{},
+		&models.TicketTransfer{},
+		&models.RegistrationQuestion{},
+		&models.TicketAnswer{},
+		&models.SessionCheckIn{},
+		&models.EventTemplate{},
 		&models.Transaction{},
+		&models.InventoryHold{},
+		&models.WaitlistEntry{},
+		&models.EventCancellation{},
+		&models.EventChangeRequest{},
+		&models.EventStatusChange{},
 		&models.PlatformSettings{},
 		&models.WithdrawalRequest{},
 		&models.OrganizerBalance{},
+		&models.PayoutAccount{},
+		&models.JournalEntry{},
+		&models.LedgerLine{},
+		&models.ReconciliationReport{},
+		&models.ReconciliationMismatch{},
 	)
 	if err != nil {
 		return fmt.Errorf("failed to run migrations: %w", err)
@@ -59,7 +84,7 @@ func RunMigrations(db *gorm.DB) error {
 		defaultSettings := &models.PlatformSettings{
 			PlatformFeePercentage:   5.0,
 			WithdrawalFeePercentage: 2.5,
-			MinWithdrawalAmount:     1000.0,
+			MinWithdrawalAmount:     100000,
 			Currency:                "NGN",
 		}
 		if err := db.Create(defaultSettings).Error; err != nil {
@@ -102,6 +127,53 @@ func RunMigrations(db *gorm.DB) error {
 	return nil
 }
 
+// moneyColumns were stored as floating point main currency units before amounts
+// moved to integer minor units
+var moneyColumns = []struct {
+	table  string
+	column string
+}{
+	{"ticket_types", "price"},
+	{"tickets", "price"},
+	{"transactions", "amount"},
+	{"transactions", "platform_fee"},
+	{"transactions", "net_amount"},
+	{"withdrawal_requests", "amount"},
+	{"withdrawal_requests", "withdrawal_fee"},
+	{"withdrawal_requests", "net_amount"},
+	{"organizer_balances", "total_earnings"},
+	{"organizer_balances", "available_balance"},
+	{"organizer_balances", "pending_balance"},
+	{"organizer_balances", "withdrawn_amount"},
+	{"platform_settings", "min_withdrawal_amount"},
+}
+
+// migrateMoneyToMinorUnits converts float money columns to bigint minor units,
+// rounding each value to the nearest minor unit. Columns that are already integers
+// are left alone, so it is safe to run on every start.
+func migrateMoneyToMinorUnits(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, money := range moneyColumns {
+			var dataType string
+			if err := tx.Raw(`SELECT data_type FROM information_schema.columns WHERE table_schema = current_schema() AND table_name = ? AND column_name = ?`,
+				money.table, money.column).Scan(&dataType).Error; err != nil {
+				return err
+			}
+			if dataType != "double precision" && dataType != "real" && dataType != "numeric" {
+				continue
+			}
+
+			// Defaults are in main units; AutoMigrate sets the new ones
+			if err := tx.Exec(fmt.Sprintf(`ALTER TABLE %s ALTER COLUMN %s DROP DEFAULT, ALTER COLUMN %s TYPE bigint USING ROUND(%s * 100)::bigint`,
+				money.table, money.column, money.column, money.column)).Error; err != nil {
+				return fmt.Errorf("failed to convert %s.%s: %w", money.table, money.column, err)
+			}
+			log.Printf("Converted %s.%s to minor units", money.table, money.column)
+		}
+		return nil
+	})
+}
+
 // runDataMigrations handles data cleanup and transformations
 func runDataMigrations(db *gorm.DB) error {
 	log.Println("Running data migrations...")
@@ -133,6 +205,50 @@ func runDataMigrations(db *gorm.DB) error {
 	db.Exec(`ALTER TABLE transactions ALTER COLUMN payment_metadata DROP DEFAULT`)
 	// Ignore errors as the column might not have a default
 
+	// Migration 3: Open ledgers for balances that existed before the ledger
+	if err := openOrganizerLedgers(db); err != nil {
+		return err
+	}
+
 	log.Println("Data migrations completed")
 	return nil
 }
+
+// openOrganizerLedgers records an opening balance entry for each organizer whose
+// balance predates the ledger. Their stored balance already includes these amounts.
+func openOrganizerLedgers(db *gorm.DB) error {
+	var balances []models.OrganizerBalance
+	if err := db.Where("available_balance + pending_balance > 0").
+		Where("NOT EXISTS (SELECT 1 FROM journal_entries e WHERE e.organizer_id = organizer_balances.organizer_id)").
+		Find(&balances).Error; err != nil {
+		return fmt.Errorf("failed to load organizer balances: %w", err)
+	}
+
+	for _, balance := range balances {
+		organizerID := balance.OrganizerID
+		lines := []models.LedgerLine{
+			models.Debit(models.LedgerAccountGatewayClearing, balance.AvailableBalance+balance.PendingBalance),
+		}
+		if balance.AvailableBalance != 0 {
+			lines = append(lines, models.Credit(models.LedgerAccountOrganizerAvailable, balance.AvailableBalance))
+		}
+		if balance.PendingBalance != 0 {
+			lines = append(lines, models.Credit(models.LedgerAccountOrganizerPending, balance.PendingBalance))
+		}
+
+		entry := &models.JournalEntry{
+			Type:        models.JournalEntryOpeningBalance,
+			OrganizerID: &organizerID,
+			Description: "Opening balance",
+			Lines:       lines,
+		}
+		if err := db.Create(entry).Error; err != nil {
+			return fmt.Errorf("failed to open ledger for organizer %s: %w", organizerID, err)
+		}
+	}
+
+	if len(balances) > 0 {
+		log.Printf("Opened ledgers for %d organizers", len(balances))
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/warui/event-ticketing-api/internal/config"
+	"github.com/warui/event-ticketing-api/internal/middleware"
+	"github.com/warui/event-ticketing-api/internal/models"
+	"github.com/warui/event-ticketing-api/internal/services"
+	"gorm.io/gorm"
+)
+
+type AccessCodeHandler struct {
+	db                *gorm.DB
+	cfg               *config.Config
+	accessCodeService *services.AccessCodeService
+}
+
+func NewAccessCodeHandler(db *gorm.DB, cfg *config.Config, accessCodeService *services.AccessCodeService) *AccessCodeHandler {
+	return &AccessCodeHandler{
+		db:                db,
+		cfg:               cfg,
+		accessCodeService: accessCodeService,
+	}
+}
+
+// accessCodeResponse is an access code with the purchases made with it
+type accessCodeResponse struct {
+	models.AccessCode
+	services.AccessCodeUsage
+}
+
+// CreateAccessCode creates a code that unlocks hidden ticket types of an event.
+// A code is generated when none is given.
+func (h *AccessCodeHandler) CreateAccessCode(c *gin.Context) {
+	organizerID, _ := middleware.GetUserID(c)
+
+	var event models.Event
+	if err := h.db.First(&event, "id = ? AND organizer_id = ?", c.Param("id"), organizerID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		return
+	}
+
+	var req struct {
+		Code          string      `json:"code"`
+		Label         string      `json:"label"`
+		TicketTypeIDs []uuid.UUID `json:"ticket_type_ids" binding:"required,min=1"`
+		MaxUses       int         `json:"max_uses" binding:"min=0"`
+		ExpiresAt     *time.Time  `json:"expires_at"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	code := models.NormalizeCode(req.Code)
+	if code == "" {
+		generated, err := h.accessCodeService.GenerateCode()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate access code"})
+			return
+		}
+		code = generated
+	}
+	if !codePattern.MatchString(code) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Code must be 3 to 32 letters, digits, dashes or underscores"})
+		return
+	}
+
+	var ticketTypes []models.TicketType
+	if err := h.db.Where("id IN ? AND event_id = ?", req.TicketTypeIDs, event.ID).Find(&ticketTypes).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch ticket types"})
+		return
+	}
+	if len(ticketTypes) != len(req.TicketTypeIDs) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Ticket types must belong to the event"})
+		return
+	}
+	for _, ticketType := range ticketTypes {
+		if !ticketType.IsHidden {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Access codes can only unlock hidden ticket types"})
+			return
+		}
+	}
+
+	var existing int64
+	h.db.Model(&models.AccessCode{}).Where("event_id = ? AND code = ?", event.ID, code).Count(&existing)
+	if existing > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Access code already exists for this event"})
+		return
+	}
+
+	accessCode := &models.AccessCode{
+		EventID:     event.ID,
+		Code:        code,
+		Label:       req.Label,
+		MaxUses:     req.MaxUses,
+		ExpiresAt:   req.ExpiresAt,
+		CreatedBy:   organizerID,
+		TicketTypes: ticketTypes,
+	}
+	if err := h.db.Omit("TicketTypes.*").Create(accessCode).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create access code"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, accessCode)
+}
+
+// GetAccessCodes lists an event's access codes with their usage, optionally for one ticket type
+func (h *AccessCodeHandler) GetAccessCodes(c *gin.Context) {
+	organizerID, _ := middleware.GetUserID(c)
+
+	var event models.Event
+	if err := h.db.First(&event, "id = ? AND organizer_id = ?", c.Param("id"), organizerID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		return
+	}
+
+	query := h.db.Preload("TicketTypes").Where("event_id = ?", event.ID)
+	if ticketTypeID := c.Query("ticket_type_id"); ticketTypeID != "" {
+		query = query.Where("id IN (?)", h.db.Table("access_code_ticket_types").Select("access_code_id").Where("ticket_type_id = ?", ticketTypeID))
+	}
+
+	var accessCodes []models.AccessCode
+	if err := query.Order("created_at DESC").Find(&accessCodes).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch access codes"})
+		return
+	}
+
+	usage, err := h.accessCodeService.Usage(event.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch access code usage"})
+		return
+	}
+
+	response := make([]accessCodeResponse, len(accessCodes))
+	for i, accessCode := range accessCodes {
+		response[i] = accessCodeResponse{AccessCode: accessCode, AccessCodeUsage: usage[accessCode.ID]}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// RevokeAccessCode stops a code from unlocking ticket types. Orders already placed with it are kept.
+func (h *AccessCodeHandler) RevokeAccessCode(c *gin.Context) {
+	organizerID, _ := middleware.GetUserID(c)
+
+	var accessCode models.AccessCode
+	if err := h.db.Joins("JOIN events ON events.id = access_codes.event_id").
+		Where("access_codes.id = ? AND events.organizer_id = ?", c.Param("id"), organizerID).
+		First(&accessCode).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Access code not found"})
+		return
+	}
+
+	now := time.Now()
+	result := h.db.Model(&models.AccessCode{}).
+		Where("id = ? AND revoked_at IS NULL", accessCode.ID).
+		Update("revoked_at", now)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke access code"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Access code already revoked"})
+		return
+	}
+	accessCode.RevokedAt = &now
+
+	c.JSON(http.StatusOK, accessCode)
+}
@@ -1,11 +1,14 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/warui/event-ticketing-api/internal/config"
 	"github.com/warui/event-ticketing-api/internal/middleware"
 	"github.com/warui/event-ticketing-api/internal/models"
@@ -14,16 +17,22 @@ import (
 )
 
 type AdminHandler struct {
-	db           *gorm.DB
-	cfg          *config.Config
-	emailService *services.EmailService
+	db              *gorm.DB
+	cfg             *config.Config
+	emailService    *services.EmailService
+	ticketDocuments *services.TicketDocumentService
+	ledgerService   *services.LedgerService
+	payoutService   *services.PayoutService
 }
 
-func NewAdminHandler(db *gorm.DB, cfg *config.Config, emailService *services.EmailService) *AdminHandler {
+func NewAdminHandler(db *gorm.DB, cfg *config.Config, emailService *services.EmailService, ticketDocuments *services.TicketDocumentService, ledgerService *services.LedgerService, payoutService *services.PayoutService) *AdminHandler {
 	return &AdminHandler{
-		db:           db,
-		cfg:          cfg,
-		emailService: emailService,
+		db:              db,
+		cfg:             cfg,
+		emailService:    emailService,
+		ticketDocuments: ticketDocuments,
+		ledgerService:   ledgerService,
+		payoutService:   payoutService,
 	}
 }
 
@@ -45,7 +54,7 @@ func (h *AdminHandler) UpdatePlatformSettings(c *gin.Context) {
 	 	var req struct {
 			PlatformFeePercentage   *float64 `json:"platform_fee_percentage"`
 			WithdrawalFeePercentage *float64 `json:"withdrawal_fee_percentage"`
-			MinWithdrawalAmount     *float64 `json:"min_withdrawal_amount"`
+			MinWithdrawalAmount     *int64   `json:"min_withdrawal_amount"`
 			Currency                *string  `json:"currency"`
 		}
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -147,21 +156,39 @@ func (h *AdminHandler) ReviewWithdrawalRequest(c *gin.Context) {
 	withdrawal.ReviewedAt = &now
 	withdrawal.ReviewComment = req.Comment
 
-	if req.Action == "approve" {
-		withdrawal.Status = models.WithdrawalStatusApproved
-	} else {
+	withdrawal.Status = models.WithdrawalStatusApproved
+	if req.Action == "reject" {
 		withdrawal.Status = models.WithdrawalStatusRejected
+	}
 
-		// Return amount to organizer's available balance
-		var balance models.OrganizerBalance
-		if err := h.db.Where("organizer_id = ?", withdrawal.OrganizerID).First(&balance).Error; err == nil {
-			balance.AvailableBalance += withdrawal.Amount
-			balance.PendingBalance -= withdrawal.Amount
-			h.db.Save(&balance)
+	// Only one review may win; a rejection returns the amount to the available balance
+	errAlreadyReviewed := errors.New("withdrawal request already reviewed")
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.WithdrawalRequest{}).
+			Where("id = ? AND status = ?", withdrawal.ID, models.WithdrawalStatusPending).
+			Updates(map[string]interface{}{
+				"status":         withdrawal.Status,
+				"reviewed_by":    withdrawal.ReviewedBy,
+				"reviewed_at":    withdrawal.ReviewedAt,
+				"review_comment": withdrawal.ReviewComment,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errAlreadyReviewed
 		}
-	}
 
-	if err := h.db.Save(&withdrawal).Error; err != nil {
+		if withdrawal.Status == models.WithdrawalStatusRejected {
+			return h.ledgerService.RecordWithdrawalRejectedTx(tx, &withdrawal, h.cfg.Currency)
+		}
+		return nil
+	})
+	if errors.Is(err, errAlreadyReviewed) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Withdrawal request already reviewed"})
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update withdrawal request"})
 		return
 	}
@@ -172,7 +199,7 @@ func (h *AdminHandler) ReviewWithdrawalRequest(c *gin.Context) {
 	c.JSON(http.StatusOK, withdrawal)
 }
 
-// ProcessWithdrawal marks a withdrawal as processed
+// ProcessWithdrawal marks a withdrawal paid outside the payment gateway as processed
 func (h *AdminHandler) ProcessWithdrawal(c *gin.Context) {
 	requestID := c.Param("id")
 
@@ -196,22 +223,14 @@ func (h *AdminHandler) ProcessWithdrawal(c *gin.Context) {
 		return
 	}
 
-	now := time.Now()
-	withdrawal.Status = models.WithdrawalStatusProcessed
-	withdrawal.ProcessedAt = &now
-	withdrawal.TransactionRef = req.TransactionRef
-
-	if err := h.db.Save(&withdrawal).Error; err != nil {
+	completed, err := h.payoutService.CompleteWithdrawal(&withdrawal, req.TransactionRef)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process withdrawal"})
 		return
 	}
-
-	// Update organizer balance
-	var balance models.OrganizerBalance
-	if err := h.db.Where("organizer_id = ?", withdrawal.OrganizerID).First(&balance).Error; err == nil {
-		balance.WithdrawnAmount += withdrawal.NetAmount
-		balance.PendingBalance -= withdrawal.Amount
-		h.db.Save(&balance)
+	if !completed {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Withdrawal already processed"})
+		return
 	}
 
 	// Send email notification
@@ -220,6 +239,59 @@ func (h *AdminHandler) ProcessWithdrawal(c *gin.Context) {
 	c.JSON(http.StatusOK, withdrawal)
 }
 
+// SendWithdrawalPayout pays an approved withdrawal out through the payment gateway
+func (h *AdminHandler) SendWithdrawalPayout(c *gin.Context) {
+	withdrawalID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid withdrawal ID"})
+		return
+	}
+
+	withdrawal, err := h.payoutService.SendPayout(withdrawalID)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Withdrawal request not found"})
+		case errors.Is(err, services.ErrPayoutNotAllowed):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, withdrawal)
+}
+
+// SendWithdrawalPayouts pays out a batch of approved withdrawals. Without
+// withdrawal_ids the oldest approved withdrawals are sent, up to limit.
+func (h *AdminHandler) SendWithdrawalPayouts(c *gin.Context) {
+	var req struct {
+		WithdrawalIDs []uuid.UUID `json:"withdrawal_ids"`
+		Limit         int         `json:"limit" binding:"omitempty,min=1,max=100"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Limit == 0 {
+		req.Limit = 50
+	}
+
+	sent, failed, err := h.payoutService.SendPayouts(req.WithdrawalIDs, req.Limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send payouts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"sent":   sent,
+		"failed": failed,
+	})
+}
+
 // GetPlatformStats returns platform statistics
 func (h *AdminHandler) GetPlatformStats(c *gin.Context) {
 	startDateStr := c.Query("start")
@@ -253,12 +325,12 @@ func (h *AdminHandler) GetPlatformStats(c *gin.Context) {
 	}
 
 	var stats struct {
-		TotalUsers       int64   `json:"total_users"`
-		TotalOrganizers  int64   `json:"total_organizers"`
-		TotalEvents      int64   `json:"total_events"`
-		TotalTicketsSold int64   `json:"total_tickets_sold"`
-		TotalRevenue     float64 `json:"total_revenue"`
-		PlatformRevenue  float64 `json:"platform_revenue"`
+		TotalUsers       int64 `json:"total_users"`
+		TotalOrganizers  int64 `json:"total_organizers"`
+		TotalEvents      int64 `json:"total_events"`
+		TotalTicketsSold int64 `json:"total_tickets_sold"`
+		TotalRevenue     int64 `json:"total_revenue"`
+		PlatformRevenue  int64 `json:"platform_revenue"`
 	}
 
 	h.db.Model(&models.User{}).Where("created_at BETWEEN ? AND ?", startDate, endDate).Count(&stats.TotalUsers)
@@ -267,13 +339,29 @@ func (h *AdminHandler) GetPlatformStats(c *gin.Context) {
 	h.db.Model(&models.Ticket{}).Where("status = ? AND created_at BETWEEN ? AND ?", models.TicketStatusConfirmed, startDate, endDate).Count(&stats.TotalTicketsSold)
 
 	var transactions []models.Transaction
-	h.db.Where("status = ? AND type = ? AND created_at BETWEEN ? AND ?", models.TransactionStatusCompleted, models.TransactionTypeTicketPurchase, startDate, endDate).Find(&transactions)
+	h.db.Where("status IN ? AND type = ? AND created_at BETWEEN ? AND ?", []models.TransactionStatus{
+		models.TransactionStatusCompleted,
+		models.TransactionStatusPartiallyRefunded,
+		models.TransactionStatusRefunded,
+	}, models.TransactionTypeTicketPurchase, startDate, endDate).Find(&transactions)
 
 	for _, t := range transactions {
 		stats.TotalRevenue += t.Amount
 		stats.PlatformRevenue += t.PlatformFee
 	}
 
+	// Net out refunds that have not failed
+	var refunds []models.Transaction
+	h.db.Where("status IN ? AND type = ? AND created_at BETWEEN ? AND ?", []models.TransactionStatus{
+		models.TransactionStatusPending,
+		models.TransactionStatusCompleted,
+	}, models.TransactionTypeRefund, startDate, endDate).Find(&refunds)
+
+	for _, r := range refunds {
+		stats.TotalRevenue -= r.Amount
+		stats.PlatformRevenue -= r.PlatformFee
+	}
+
 	c.JSON(http.StatusOK, stats)
 }
 
@@ -552,7 +640,9 @@ func (h *AdminHandler) GetFeaturedEvents(c *gin.Context) {
 	var events []models.Event
 	if err := h.db.Where("is_featured = ? AND status = ?", true, models.EventStatusPublished).
 		Preload("Organizer").
-		Preload("TicketTypes").
+		Preload("TicketTypes", func(db *gorm.DB) *gorm.DB {
+			return db.Where("is_hidden = ?", false).Order(services.TicketTypeOrder)
+		}).
 		Order("start_date ASC").
 		Find(&events).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch featured events"})
@@ -561,3 +651,88 @@ func (h *AdminHandler) GetFeaturedEvents(c *gin.Context) {
 
 	c.JSON(http.StatusOK, events)
 }
+
+// ==================== Ticket Signing ====================
+
+// ReissueTicketQRCodes regenerates the QR code and PDF of confirmed tickets that
+// were not signed with the active key. It works in batches ordered by ticket ID so
+// it can be called repeatedly after a key rotation, passing back next_after_id, until
+// nothing remains. Tickets that fail are reported and skipped by the next batch.
+func (h *AdminHandler) ReissueTicketQRCodes(c *gin.Context) {
+	var req struct {
+		KeyID   string     `json:"key_id"`
+		AfterID *uuid.UUID `json:"after_id"`
+		Limit   int        `json:"limit" binding:"omitempty,min=1,max=500"`
+		Notify  bool       `json:"notify"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	activeKeyID := h.ticketDocuments.ActiveKeyID()
+	if req.KeyID != "" && req.KeyID == activeKeyID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Tickets signed with the active key do not need reissuing"})
+		return
+	}
+
+	if req.Limit == 0 {
+		req.Limit = 100
+	}
+
+	scope := func(afterID *uuid.UUID) *gorm.DB {
+		query := h.db.Model(&models.Ticket{}).Where("status = ?", models.TicketStatusConfirmed)
+		if afterID != nil {
+			query = query.Where("id > ?", *afterID)
+		}
+		if req.KeyID != "" {
+			return query.Where("qr_key_id = ?", req.KeyID)
+		}
+		return query.Where("qr_key_id IS NULL OR qr_key_id <> ?", activeKeyID)
+	}
+
+	var tickets []models.Ticket
+	if err := scope(req.AfterID).Preload("Event").Preload("TicketType").Preload("Attendee").Preload("Session").Preload("Answers.Question").Order("id ASC").Limit(req.Limit).Find(&tickets).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tickets"})
+		return
+	}
+
+	reissued := 0
+	var failed []string
+	for i := range tickets {
+		ticket := &tickets[i]
+
+		pdfData, err := h.ticketDocuments.GenerateTicketDocuments(ticket, &ticket.Event, &ticket.Attendee)
+		if err != nil {
+			failed = append(failed, ticket.TicketNumber)
+			continue
+		}
+
+		if err := h.db.Model(ticket).Select("qr_code_url", "pdf_url", "qr_key_id").Updates(ticket).Error; err != nil {
+			failed = append(failed, ticket.TicketNumber)
+			continue
+		}
+		reissued++
+
+		if req.Notify {
+			go h.emailService.SendTicketEmail(ticket, &ticket.Event, &ticket.Attendee, pdfData)
+		}
+	}
+
+	nextAfterID := req.AfterID
+	if len(tickets) > 0 {
+		nextAfterID = &tickets[len(tickets)-1].ID
+	}
+
+	var remaining int64
+	scope(nextAfterID).Count(&remaining)
+
+	c.JSON(http.StatusOK, gin.H{
+		"active_key_id": activeKeyID,
+		"reissued":      reissued,
+		"failed":        failed,
+		"remaining":     remaining,
+		"next_after_id": nextAfterID,
+	})
+}
@@ -1,9 +1,10 @@
 package handlers
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
-	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -16,32 +17,38 @@ import (
 )
 
 type AttendeeHandler struct {
-	db              *gorm.DB
-	cfg             *config.Config
-	paystackService *services.PaystackService
-	storageService  *services.StorageService
-	qrcodeService   *services.QRCodeService
-	pdfService      *services.PDFService
-	emailService    *services.EmailService
+	db                *gorm.DB
+	cfg               *config.Config
+	paymentGateways   *services.PaymentGateways
+	storageService    *services.StorageService
+	inventoryService  *services.InventoryService
+	orderService      *services.OrderService
+	promoCodeService  *services.PromoCodeService
+	accessCodeService *services.AccessCodeService
+	waitlistService   *services.WaitlistService
 }
 
 func NewAttendeeHandler(
 	db *gorm.DB,
 	cfg *config.Config,
-	paystackService *services.PaystackService,
+	paymentGateways *services.PaymentGateways,
 	storageService *services.StorageService,
-	qrcodeService *services.QRCodeService,
-	pdfService *services.PDFService,
-	emailService *services.EmailService,
+	inventoryService *services.InventoryService,
+	orderService *services.OrderService,
+	promoCodeService *services.PromoCodeService,
+	accessCodeService *services.AccessCodeService,
+	waitlistService *services.WaitlistService,
 ) *AttendeeHandler {
 	return &AttendeeHandler{
-		db:              db,
-		cfg:             cfg,
-		paystackService: paystackService,
-		storageService:  storageService,
-		qrcodeService:   qrcodeService,
-		pdfService:      pdfService,
-		emailService:    emailService,
+		db:                db,
+		cfg:               cfg,
+		paymentGateways:   paymentGateways,
+		storageService:    storageService,
+		inventoryService:  inventoryService,
+		orderService:      orderService,
+		promoCodeService:  promoCodeService,
+		accessCodeService: accessCodeService,
+		waitlistService:   waitlistService,
 	}
 }
 
@@ -51,7 +58,9 @@ func (h *AttendeeHandler) GetPublishedEvents(c *gin.Context) {
 	city := c.Query("city")
 	search := c.Query("search")
 
-	query := h.db.Preload("Organizer").Preload("TicketTypes").Where("status = ?", models.EventStatusPublished)
+	query := h.db.Preload("Organizer").Preload("TicketTypes", func(db *gorm.DB) *gorm.DB {
+		return db.Where("is_hidden = ?", false).Order(services.TicketTypeOrder)
+	}).Where("status = ?", models.EventStatusPublished)
 
 	if category != "" {
 		query = query.Where("category = ?", category)
@@ -77,16 +86,45 @@ func (h *AttendeeHandler) GetPublishedEvents(c *gin.Context) {
 	c.JSON(http.StatusOK, events)
 }
 
-// GetEventDetails retrieves details of a specific event
+// GetEventDetails retrieves details of a specific event. Hidden ticket types are
+// included only when ?access_code= unlocks them.
 func (h *AttendeeHandler) GetEventDetails(c *gin.Context) {
 	eventID := c.Param("id")
 
 	var event models.Event
-	if err := h.db.Preload("Organizer").Preload("TicketTypes").First(&event, "id = ? AND status = ?", eventID, models.EventStatusPublished).Error; err != nil {
+	if err := h.db.Preload("Organizer").Preload("TicketTypes", func(db *gorm.DB) *gorm.DB {
+		return db.Order(services.TicketTypeOrder)
+	}).Preload("Questions", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position ASC, created_at ASC")
+	}).Preload("Sessions", func(db *gorm.DB) *gorm.DB {
+		return db.Order("start_date ASC")
+	}).First(&event, "id = ? AND status = ?", eventID, models.EventStatusPublished).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
 		return
 	}
 
+	var accessCode *models.AccessCode
+	if code := c.Query("access_code"); code != "" {
+		var err error
+		accessCode, err = h.accessCodeService.Find(event.ID, code, time.Now())
+		if errors.Is(err, services.ErrAccessCodeInvalid) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Access code is not valid"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check access code"})
+			return
+		}
+	}
+
+	visible := event.TicketTypes[:0]
+	for _, ticketType := range event.TicketTypes {
+		if !ticketType.IsHidden || (accessCode != nil && accessCode.Unlocks(ticketType.ID)) {
+			visible = append(visible, ticketType)
+		}
+	}
+	event.TicketTypes = visible
+
 	c.JSON(http.StatusOK, event)
 }
 
@@ -97,9 +135,14 @@ func (h *AttendeeHandler) InitiateTicketPurchase(c *gin.Context) {
 	var req struct {
 		EventID string `json:"event_id" binding:"required"`
 		Items   []struct {
-			TicketTypeID string `json:"ticket_type_id" binding:"required"`
-			Quantity     int    `json:"quantity" binding:"required,min=1"`
+			TicketTypeID string                        `json:"ticket_type_id" binding:"required"`
+			Quantity     int                           `json:"quantity" binding:"required,min=1"`
+			SeatIDs      []uuid.UUID                   `json:"seat_ids"`  // One per ticket for reserved seating
+			Attendees    []services.TicketRegistration `json:"attendees"` // Named holders of the first tickets, in order
 		} `json:"items" binding:"required,min=1,dive"`
+		PromoCode     string `json:"promo_code"`
+		AccessCode    string `json:"access_code"`    // Unlocks hidden ticket types
+		WaitlistToken string `json:"waitlist_token"` // From the purchase link sent to a waitlisted buyer
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -107,16 +150,53 @@ func (h *AttendeeHandler) InitiateTicketPurchase(c *gin.Context) {
 		return
 	}
 
-	// Get event
+	// Only published events are on sale; drafts, events in review and approved
+	// events waiting for their publish time are not
 	var event models.Event
-	if err := h.db.First(&event, "id = ?", req.EventID).Error; err != nil {
+	if err := h.db.Preload("Questions").First(&event, "id = ? AND status = ?", req.EventID, models.EventStatusPublished).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
 		return
 	}
 
+	// Check the access code up front; checkout locks it again below
+	var accessCode *models.AccessCode
+	if req.AccessCode != "" {
+		var err error
+		accessCode, err = h.accessCodeService.Find(event.ID, req.AccessCode, time.Now())
+		if errors.Is(err, services.ErrAccessCodeInvalid) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Access code is not valid"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check access code"})
+			return
+		}
+	}
+
+	// A waitlist offer lets its holder buy the tickets set aside for them
+	var waitlistOffer *models.WaitlistEntry
+	if req.WaitlistToken != "" {
+		var err error
+		waitlistOffer, err = h.waitlistService.FindOffer(req.WaitlistToken, attendeeID, time.Now())
+		if errors.Is(err, services.ErrWaitlistOfferInvalid) || (err == nil && waitlistOffer.EventID != event.ID) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Waitlist offer has expired or is not valid"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check waitlist offer"})
+			return
+		}
+	}
+
 	// Validate all ticket types and calculate total
-	var totalAmount float64
+	unlocksHidden := false
+	var subtotal int64
+	var lines []models.OrderLine
 	var ticketItems []map[string]interface{}
+	var stockRequests []services.StockRequest
+	var seatRequests []services.SeatRequest
+	requestIndex := make(map[uuid.UUID]int)
+	pickedSeats := make(map[uuid.UUID]bool)
 
 	for _, item := range req.Items {
 		// Get ticket type
@@ -126,97 +206,277 @@ func (h *AttendeeHandler) InitiateTicketPurchase(c *gin.Context) {
 			return
 		}
 
+		// Hidden ticket types do not exist for buyers without their access code
+		if ticketType.IsHidden {
+			if accessCode == nil || !accessCode.Unlocks(ticketType.ID) {
+				c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("Ticket type %s not found", item.TicketTypeID)})
+				return
+			}
+			unlocksHidden = true
+		}
+
 		// Check availability
-		if !ticketType.IsAvailable() {
+		if !ticketType.IsOnSale() {
 			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Tickets for %s not available for sale", ticketType.Name)})
 			return
 		}
 
-		// Check quantity
-		if item.Quantity > ticketType.RemainingTickets() {
-			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Not enough tickets available for %s", ticketType.Name)})
+		// Reserved seating tickets each need a seat picked from the seat map
+		if ticketType.ReservedSeating && len(item.SeatIDs) != item.Quantity {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Pick %d seats for %s", item.Quantity, ticketType.Name)})
+			return
+		}
+		if !ticketType.ReservedSeating && len(item.SeatIDs) > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("%s is general admission and has no seats", ticketType.Name)})
+			return
+		}
+		for _, seatID := range item.SeatIDs {
+			if pickedSeats[seatID] {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Each seat can only be picked once"})
+				return
+			}
+			pickedSeats[seatID] = true
+		}
+		if len(item.SeatIDs) > 0 {
+			seatRequests = append(seatRequests, services.SeatRequest{TicketTypeID: ticketType.ID, SeatIDs: item.SeatIDs})
+		}
+
+		// Holders can be named now or later from the ticket
+		if len(item.Attendees) > item.Quantity {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("At most %d attendees for %s", item.Quantity, ticketType.Name)})
+			return
+		}
+		for i := range item.Attendees {
+			if err := item.Attendees[i].Validate(event.Questions); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
+
+		// Total quantities per ticket type so repeated lines share one hold
+		if i, ok := requestIndex[ticketType.ID]; ok {
+			stockRequests[i].Quantity += item.Quantity
+		} else {
+			requestIndex[ticketType.ID] = len(stockRequests)
+			stockRequests = append(stockRequests, services.StockRequest{TicketTypeID: ticketType.ID, Quantity: item.Quantity})
+		}
+		quantity := stockRequests[requestIndex[ticketType.ID]].Quantity
+
+		// Check quantity. Tickets owed to the waitlist are not on general sale; a
+		// waitlist offer adds the tickets set aside for its holder.
+		stock, err := h.waitlistService.GeneralStockTx(h.db, &ticketType)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check ticket availability"})
+			return
+		}
+		if waitlistOffer != nil && waitlistOffer.TicketTypeID == ticketType.ID {
+			stock += waitlistOffer.Quantity
+		}
+		if quantity > stock {
+			c.JSON(http.StatusConflict, gin.H{
+				"error":          fmt.Sprintf("Not enough tickets available for %s", ticketType.Name),
+				"ticket_type_id": ticketType.ID,
+				"waitlist":       true, // The buyer can join the ticket type's waitlist
+			})
 			return
 		}
 
-		if item.Quantity > ticketType.MaxPerOrder {
+		if quantity > ticketType.MaxPerOrder {
 			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Maximum %d tickets per order for %s", ticketType.MaxPerOrder, ticketType.Name)})
 			return
 		}
 
 		// Add to total
-		itemTotal := ticketType.Price * float64(item.Quantity)
-		totalAmount += itemTotal
+		line := models.OrderLine{TicketTypeID: ticketType.ID, UnitPrice: ticketType.Price, Quantity: item.Quantity}
+		lines = append(lines, line)
+		subtotal += line.Subtotal()
 
 		// Store item info for metadata
-		ticketItems = append(ticketItems, map[string]interface{}{
+		ticketItem := map[string]interface{}{
 			"ticket_type_id": item.TicketTypeID,
 			"quantity":       item.Quantity,
 			"price":          ticketType.Price,
 			"name":           ticketType.Name,
-		})
+		}
+		if len(item.SeatIDs) > 0 {
+			ticketItem["seat_ids"] = item.SeatIDs
+		}
+		if len(item.Attendees) > 0 {
+			ticketItem["attendees"] = item.Attendees
+		}
+		ticketItems = append(ticketItems, ticketItem)
+	}
+
+	// An offer only holds tickets of its own type
+	if waitlistOffer != nil {
+		if _, ok := requestIndex[waitlistOffer.TicketTypeID]; !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Waitlist offer is for a ticket type not in the order"})
+			return
+		}
 	}
 
 	// Get platform settings for fee calculation
 	var settings models.PlatformSettings
 	h.db.First(&settings)
-	platformFee := totalAmount * (settings.PlatformFeePercentage / 100)
 
 	// Get user
 	var user models.User
 	h.db.First(&user, attendeeID)
 
-	// Create transaction
+	provider := h.paymentGateways.ForCurrency(h.cfg.Currency)
+	metadata := map[string]interface{}{
+		"event_id":    event.ID.String(),
+		"attendee_id": attendeeID.String(),
+	}
 	transaction := &models.Transaction{
 		UserID:           attendeeID,
 		EventID:          &event.ID,
 		Type:             models.TransactionTypeTicketPurchase,
 		Status:           models.TransactionStatusPending,
-		Amount:           totalAmount,
 		Currency:         h.cfg.Currency,
-		PlatformFee:      platformFee,
-		NetAmount:        totalAmount - platformFee,
-		PaymentGateway:   "paystack",
+		PaymentGateway:   provider.Name(),
 		PaymentReference: fmt.Sprintf("TXN-%s-%d", uuid.New().String()[:8], time.Now().Unix()),
 		Description:      fmt.Sprintf("Purchase of tickets for %s", event.Title),
 	}
+	var promoCode *models.PromoCode
+
+	// Apply the promo code, create the transaction and hold its tickets together
+	// so neither stock nor promo code uses are promised to more buyers than allowed
+	holdExpiresAt := time.Now().Add(h.cfg.CheckoutHoldDuration)
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if unlocksHidden {
+			code, err := h.accessCodeService.UnlockTx(tx, event.ID, req.AccessCode, time.Now())
+			if err != nil {
+				return err
+			}
+			transaction.AccessCodeID = &code.ID
+		}
+
+		if req.PromoCode != "" {
+			code, discounts, err := h.promoCodeService.ApplyTx(tx, event.ID, attendeeID, req.PromoCode, lines, time.Now())
+			if err != nil {
+				return err
+			}
+			promoCode = code
+			transaction.PromoCodeID = &code.ID
+			for i, discount := range discounts {
+				ticketItems[i]["discount"] = discount
+				transaction.DiscountAmount += discount
+			}
+		}
+
+		transaction.Amount = subtotal - transaction.DiscountAmount
+		transaction.PlatformFee = models.PercentageOf(transaction.Amount, settings.PlatformFeePercentage)
+		transaction.NetAmount = transaction.Amount - transaction.PlatformFee
+
+		// The cart is kept on the transaction so fulfilment does not depend on the
+		// provider echoing metadata back
+		metadata["items"] = ticketItems // Store all cart items
+		metadataJSON, _ := json.Marshal(metadata)
+		paymentMetadata := string(metadataJSON)
+		transaction.PaymentMetadata = &paymentMetadata
 
-	if err := h.db.Create(transaction).Error; err != nil {
+		if err := tx.Create(transaction).Error; err != nil {
+			return err
+		}
+		if waitlistOffer != nil {
+			if _, err := h.waitlistService.RedeemOfferTx(tx, req.WaitlistToken, attendeeID, transaction.ID, time.Now()); err != nil {
+				return err
+			}
+		}
+		// Checked again under lock: the count above may be stale by now
+		if err := h.waitlistService.CheckGeneralStockTx(tx, stockRequests); err != nil {
+			return err
+		}
+		if err := h.inventoryService.ReserveTx(tx, transaction.ID, stockRequests, holdExpiresAt); err != nil {
+			return err
+		}
+		for _, request := range seatRequests {
+			if err := h.inventoryService.HoldSeatsTx(tx, transaction.ID, request); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, services.ErrPromoCodeInvalid) || errors.Is(err, services.ErrAccessCodeInvalid) || errors.Is(err, services.ErrWaitlistOfferInvalid) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, services.ErrSeatUnavailable) {
+		c.JSON(http.StatusConflict, gin.H{"error": "One or more of the chosen seats are no longer available"})
+		return
+	}
+	if errors.Is(err, services.ErrSessionFull) {
+		c.JSON(http.StatusConflict, gin.H{"error": "The session is full"})
+		return
+	}
+	if errors.Is(err, services.ErrInsufficientStock) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Not enough tickets available"})
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create transaction"})
 		return
 	}
 
-	// Initialize payment with Paystack
-	metadata := map[string]interface{}{
-		"transaction_id": transaction.ID.String(),
-		"event_id":       event.ID.String(),
-		"attendee_id":    attendeeID.String(),
-		"items":          ticketItems, // Store all cart items
+	response := gin.H{
+		"transaction_id":    transaction.ID,
+		"payment_reference": transaction.PaymentReference,
+		"payment_gateway":   transaction.PaymentGateway,
+		"subtotal":          subtotal,
+		"discount":          transaction.DiscountAmount,
+		"amount":            transaction.Amount,
+		"currency":          h.cfg.Currency,
+	}
+	if promoCode != nil {
+		response["promo_code"] = promoCode.Code
 	}
 
-	paymentInit, err := h.paystackService.InitializeTransaction(
-		user.Email,
-		totalAmount,
-		transaction.PaymentReference,
-		metadata,
-	)
+	// Free orders, from free tickets or a full discount, need no payment
+	if transaction.Amount == 0 {
+		tickets, err := h.orderService.FulfillPayment(transaction, &services.PaymentResult{
+			Reference:  transaction.PaymentReference,
+			Successful: true,
+			Currency:   transaction.Currency,
+			PaidAt:     time.Now(),
+		})
+		if err != nil {
+			c.JSON(http.StatusConflict, gin.H{"error": "Order could not be completed: " + err.Error()})
+			return
+		}
+		response["status"] = "success"
+		response["tickets"] = tickets
+		c.JSON(http.StatusOK, response)
+		return
+	}
 
+	// Initialize payment with the provider
+	metadata["transaction_id"] = transaction.ID.String()
+	session, err := provider.InitializePayment(services.PaymentRequest{
+		Email:     user.Email,
+		Amount:    transaction.Amount,
+		Currency:  transaction.Currency,
+		Reference: transaction.PaymentReference,
+		Metadata:  metadata,
+	})
 	if err != nil {
+		h.orderService.FailPayment(transaction, "Payment initialization failed")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to initialize payment: " + err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"transaction_id":    transaction.ID,
-		"payment_reference": transaction.PaymentReference,
-		"authorization_url": paymentInit.Data.AuthorizationURL,
-		"amount":            totalAmount,
-		"currency":          h.cfg.Currency,
-	})
+	response["authorization_url"] = session.AuthorizationURL
+	response["hold_expires_at"] = holdExpiresAt
+	c.JSON(http.StatusOK, response)
 }
 
 // VerifyPayment verifies a payment and creates tickets
 func (h *AttendeeHandler) VerifyPayment(c *gin.Context) {
+	// Flutterwave redirects back with tx_ref
 	reference := c.Query("reference")
+	if reference == "" {
+		reference = c.Query("tx_ref")
+	}
 	if reference == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Payment reference required"})
 		return
@@ -229,11 +489,15 @@ func (h *AttendeeHandler) VerifyPayment(c *gin.Context) {
 		return
 	}
 
-	// Check if already processed
-	if transaction.Status == models.TransactionStatusCompleted {
+	// Check if already processed (refunds only happen after tickets were issued)
+	if transaction.Status == models.TransactionStatusCompleted ||
+		transaction.Status == models.TransactionStatusPartiallyRefunded ||
+		transaction.Status == models.TransactionStatusRefunded {
+		// Finish any ticket delivery an earlier call could not complete
+		h.orderService.DeliverTickets(&transaction)
+
 		// Get existing tickets for this transaction
-		var existingTickets []models.Ticket
-		h.db.Preload("Event").Preload("TicketType").Where("transaction_id = ?", transaction.ID).Find(&existingTickets)
+		existingTickets, _ := h.orderService.IssuedTickets(&transaction)
 
 		c.JSON(http.StatusOK, gin.H{
 			"message": "Payment already verified",
@@ -243,124 +507,39 @@ func (h *AttendeeHandler) VerifyPayment(c *gin.Context) {
 		return
 	}
 
-	// Verify with Paystack
-	verification, err := h.paystackService.VerifyTransaction(reference)
+	// Verify with the provider that took the payment
+	provider, err := h.paymentGateways.Get(transaction.PaymentGateway)
 	if err != nil {
-		transaction.Status = models.TransactionStatusFailed
-		transaction.FailureReason = err.Error()
-		h.db.Save(&transaction)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Payment verification failed"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	if !h.paystackService.IsTransactionSuccessful(verification) {
-		transaction.Status = models.TransactionStatusFailed
-		transaction.FailureReason = "Payment not successful"
-		h.db.Save(&transaction)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Payment was not successful"})
+	// The provider could not be reached; the webhook or the reconciler settles the payment
+	payment, err := provider.VerifyPayment(reference)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Payment could not be verified yet, please try again shortly"})
 		return
 	}
 
-	// Extract metadata with safe type assertions
-	metadata := verification.Data.Metadata
-	eventID, _ := metadata["event_id"].(string)
-
-	// Get event
-	var event models.Event
-	h.db.First(&event, "id = ?", eventID)
-
-	// Extract cart items from metadata
-	var cartItems []map[string]interface{}
-	if items, ok := metadata["items"].([]interface{}); ok {
-		for _, item := range items {
-			if itemMap, ok := item.(map[string]interface{}); ok {
-				cartItems = append(cartItems, itemMap)
-			}
-		}
+	if payment.Failed {
+		h.orderService.FailPayment(&transaction, "Payment "+payment.Status)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Payment was not successful"})
+		return
 	}
 
-	// Create tickets for all items in cart
-	var tickets []models.Ticket
-	var user models.User
-	h.db.First(&user, transaction.UserID)
-
-	for _, item := range cartItems {
-		ticketTypeID, _ := item["ticket_type_id"].(string)
-
-		// Handle quantity - could be float64 or string
-		var quantity int
-		switch v := item["quantity"].(type) {
-		case float64:
-			quantity = int(v)
-		case string:
-			if parsed, err := strconv.Atoi(v); err == nil {
-				quantity = parsed
-			} else {
-				quantity = 1
-			}
-		default:
-			quantity = 1
-		}
-
-		// Get ticket type
-		var ticketType models.TicketType
-		h.db.First(&ticketType, "id = ?", ticketTypeID)
-
-		// Create tickets for this item
-		for i := 0; i < quantity; i++ {
-			ticket := models.Ticket{
-				EventID:       event.ID,
-				TicketTypeID:  ticketType.ID,
-				AttendeeID:    transaction.UserID,
-				TransactionID: transaction.ID,
-				Status:        models.TicketStatusConfirmed,
-				Price:         ticketType.Price,
-			}
-
-			if err := h.db.Create(&ticket).Error; err != nil {
-				continue
-			}
-
-			// Generate QR code
-			qrData, err := h.qrcodeService.GenerateTicketQRCode(ticket.TicketNumber, ticket.ID.String())
-			if err == nil {
-				qrFilename := services.GenerateUniqueFilename(fmt.Sprintf("qr-%s", ticket.TicketNumber), "png")
-				qrURL, _ := h.storageService.UploadFile(qrData, "tickets/qrcodes", qrFilename)
-				ticket.QRCodeURL = qrURL
-			}
-
-			// Generate PDF
-			ticket.Event = event
-			ticket.TicketType = ticketType
-			pdfData, err := h.pdfService.GenerateTicketPDF(&ticket, &event, &user, qrData)
-			if err == nil {
-				pdfFilename := services.GenerateUniqueFilename(fmt.Sprintf("ticket-%s", ticket.TicketNumber), "pdf")
-				pdfURL, _ := h.storageService.UploadFile(pdfData, "tickets/pdfs", pdfFilename)
-				ticket.PDFURL = pdfURL
-			}
-
-			h.db.Save(&ticket)
-			tickets = append(tickets, ticket)
-
-			// Send email with PDF attachment
-			go h.emailService.SendTicketEmail(&ticket, &event, &user, pdfData)
-		}
-
-		// Update ticket type sold count
-		ticketType.Sold += quantity
-		h.db.Save(&ticketType)
+	// Still processing at the provider; the held tickets stay held until it settles
+	if !payment.Successful {
+		c.JSON(http.StatusAccepted, gin.H{
+			"message": "Payment is still being processed",
+			"status":  "pending",
+		})
+		return
 	}
 
-	// Update transaction status
-	transaction.Status = models.TransactionStatusCompleted
-	h.db.Save(&transaction)
-
-	// Update organizer balance
-	var balance models.OrganizerBalance
-	if err := h.db.Where("organizer_id = ?", event.OrganizerID).First(&balance).Error; err == nil {
-		balance.TotalEarnings += transaction.NetAmount
-		balance.AvailableBalance += transaction.NetAmount
-		h.db.Save(&balance)
+	tickets, err := h.orderService.FulfillPayment(&transaction, payment)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Payment could not be fulfilled: " + err.Error()})
+		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -389,7 +568,7 @@ func (h *AttendeeHandler) GetTicketDetails(c *gin.Context) {
 	attendeeID, _ := middleware.GetUserID(c)
 
 	var ticket models.Ticket
-	if err := h.db.Preload("Event").Preload("TicketType").Preload("Transaction").First(&ticket, "id = ? AND attendee_id = ?", ticketID, attendeeID).Error; err != nil {
+	if err := h.db.Preload("Event").Preload("TicketType").Preload("Transaction").Preload("Session").Preload("Answers.Question").First(&ticket, "id = ? AND attendee_id = ?", ticketID, attendeeID).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Ticket not found"})
 		return
 	}
@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/warui/event-ticketing-api/internal/config"
+	"github.com/warui/event-ticketing-api/internal/middleware"
+	"github.com/warui/event-ticketing-api/internal/models"
+	"github.com/warui/event-ticketing-api/internal/services"
+	"gorm.io/gorm"
+)
+
+type CompTicketHandler struct {
+	db                *gorm.DB
+	cfg               *config.Config
+	compTicketService *services.CompTicketService
+}
+
+func NewCompTicketHandler(db *gorm.DB, cfg *config.Config, compTicketService *services.CompTicketService) *CompTicketHandler {
+	return &CompTicketHandler{
+		db:                db,
+		cfg:               cfg,
+		compTicketService: compTicketService,
+	}
+}
+
+// IssueCompTickets issues free tickets to a guest list sent as JSON or as a CSV
+// file upload with ticket_type_id, quantity and note form fields
+func (h *CompTicketHandler) IssueCompTickets(c *gin.Context) {
+	organizerID, _ := middleware.GetUserID(c)
+
+	var event models.Event
+	if err := h.db.First(&event, "id = ? AND organizer_id = ?", c.Param("id"), organizerID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		return
+	}
+
+	req := services.CompTicketRequest{EventID: event.ID, IssuedBy: organizerID}
+
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		ticketTypeID, err := uuid.Parse(c.PostForm("ticket_type_id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ticket type ID"})
+			return
+		}
+		quantity := 1
+		if value := c.PostForm("quantity"); value != "" {
+			quantity, err = strconv.Atoi(value)
+			if err != nil || quantity < 1 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Quantity must be a positive number"})
+				return
+			}
+		}
+
+		file, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "CSV file required"})
+			return
+		}
+		fileContent, err := file.Open()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read file"})
+			return
+		}
+		defer fileContent.Close()
+
+		recipients, err := services.ParseCompRecipientsCSV(fileContent)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		// Rows without a quantity column get the quantity from the form
+		for i := range recipients {
+			if recipients[i].Quantity == 0 {
+				recipients[i].Quantity = quantity
+			}
+		}
+
+		req.TicketTypeID = ticketTypeID
+		req.Note = c.PostForm("note")
+		req.Recipients = recipients
+	} else {
+		var body struct {
+			TicketTypeID uuid.UUID                `json:"ticket_type_id" binding:"required"`
+			Note         string                   `json:"note"`
+			Recipients   []services.CompRecipient `json:"recipients" binding:"required,min=1,dive"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		req.TicketTypeID = body.TicketTypeID
+		req.Note = body.Note
+		req.Recipients = body.Recipients
+	}
+
+	results, err := h.compTicketService.IssueTickets(req)
+	if errors.Is(err, services.ErrInvalidGuestList) || errors.Is(err, services.ErrEventCancelled) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Ticket type not found"})
+		return
+	}
+	if errors.Is(err, services.ErrInsufficientStock) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Not enough tickets available"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to issue complimentary tickets"})
+		return
+	}
+
+	var tickets int
+	for _, result := range results {
+		tickets += result.Quantity
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":    "Complimentary tickets issued",
+		"recipients": results,
+		"tickets":    tickets,
+	})
+}
+
+// GetCompTickets lists the complimentary tickets issued for an event
+func (h *CompTicketHandler) GetCompTickets(c *gin.Context) {
+	organizerID, _ := middleware.GetUserID(c)
+
+	var event models.Event
+	if err := h.db.First(&event, "id = ? AND organizer_id = ?", c.Param("id"), organizerID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		return
+	}
+
+	var transactions []models.Transaction
+	if err := h.db.Preload("User").Preload("Tickets").
+		Where("event_id = ? AND type = ?", event.ID, models.TransactionTypeComplimentary).
+		Order("created_at DESC").
+		Find(&transactions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch complimentary tickets"})
+		return
+	}
+
+	c.JSON(http.StatusOK, transactions)
+}
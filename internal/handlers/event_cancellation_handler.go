@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/warui/event-ticketing-api/internal/config"
+	"github.com/warui/event-ticketing-api/internal/middleware"
+	"github.com/warui/event-ticketing-api/internal/models"
+	"github.com/warui/event-ticketing-api/internal/services"
+	"gorm.io/gorm"
+)
+
+type EventCancellationHandler struct {
+	db                  *gorm.DB
+	cfg                 *config.Config
+	cancellationService *services.EventCancellationService
+}
+
+func NewEventCancellationHandler(db *gorm.DB, cfg *config.Config, cancellationService *services.EventCancellationService) *EventCancellationHandler {
+	return &EventCancellationHandler{
+		db:                  db,
+		cfg:                 cfg,
+		cancellationService: cancellationService,
+	}
+}
+
+// CancelEvent cancels one of the organizer's events
+func (h *EventCancellationHandler) CancelEvent(c *gin.Context) {
+	organizerID, _ := middleware.GetUserID(c)
+
+	var event models.Event
+	if err := h.db.First(&event, "id = ? AND organizer_id = ?", c.Param("id"), organizerID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		return
+	}
+
+	h.cancel(c, &event)
+}
+
+// GetCancellation reports refund and notification progress for one of the organizer's events
+func (h *EventCancellationHandler) GetCancellation(c *gin.Context) {
+	organizerID, _ := middleware.GetUserID(c)
+
+	var event models.Event
+	if err := h.db.First(&event, "id = ? AND organizer_id = ?", c.Param("id"), organizerID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		return
+	}
+
+	h.progress(c, &event)
+}
+
+// AdminCancelEvent cancels any event
+func (h *EventCancellationHandler) AdminCancelEvent(c *gin.Context) {
+	var event models.Event
+	if err := h.db.First(&event, "id = ?", c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		return
+	}
+
+	h.cancel(c, &event)
+}
+
+// AdminGetCancellation reports refund and notification progress for any event
+func (h *EventCancellationHandler) AdminGetCancellation(c *gin.Context) {
+	var event models.Event
+	if err := h.db.First(&event, "id = ?", c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		return
+	}
+
+	h.progress(c, &event)
+}
+
+// cancel cancels the event and responds with the queued cancellation
+func (h *EventCancellationHandler) cancel(c *gin.Context, event *models.Event) {
+	userID, _ := middleware.GetUserID(c)
+
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cancellation, err := h.cancellationService.CancelEvent(event.ID, userID, req.Reason)
+	if err != nil {
+		if errors.Is(err, services.ErrEventNotCancellable) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel event"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message":      "Event cancelled. Refunds and notifications are being processed",
+		"cancellation": cancellation,
+	})
+}
+
+// progress responds with the event's cancellation
+func (h *EventCancellationHandler) progress(c *gin.Context, event *models.Event) {
+	var cancellation models.EventCancellation
+	if err := h.db.First(&cancellation, "event_id = ?", event.ID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event has not been cancelled"})
+		return
+	}
+
+	c.JSON(http.StatusOK, cancellation)
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/warui/event-ticketing-api/internal/config"
+	"github.com/warui/event-ticketing-api/internal/models"
+	"github.com/warui/event-ticketing-api/internal/services"
+	"gorm.io/gorm"
+)
+
+type LedgerHandler struct {
+	db            *gorm.DB
+	cfg           *config.Config
+	ledgerService *services.LedgerService
+}
+
+func NewLedgerHandler(db *gorm.DB, cfg *config.Config, ledgerService *services.LedgerService) *LedgerHandler {
+	return &LedgerHandler{
+		db:            db,
+		cfg:           cfg,
+		ledgerService: ledgerService,
+	}
+}
+
+// GetOrganizerStatement lists an organizer's ledger entries line by line with running balances
+func (h *LedgerHandler) GetOrganizerStatement(c *gin.Context) {
+	organizerID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organizer ID"})
+		return
+	}
+
+	var organizer models.User
+	if err := h.db.First(&organizer, "id = ? AND role = ?", organizerID, models.RoleOrganizer).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Organizer not found"})
+		return
+	}
+
+	var from, to *time.Time
+	if start := c.Query("start"); start != "" {
+		date, err := time.Parse("2006-01-02", start)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start date format. Use YYYY-MM-DD"})
+			return
+		}
+		from = &date
+	}
+	if end := c.Query("end"); end != "" {
+		date, err := time.Parse("2006-01-02", end)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end date format. Use YYYY-MM-DD"})
+			return
+		}
+		// Include the whole end day
+		date = date.AddDate(0, 0, 1)
+		to = &date
+	}
+
+	statement, err := h.ledgerService.OrganizerStatement(organizer.ID, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build statement"})
+		return
+	}
+
+	c.JSON(http.StatusOK, statement)
+}
+
+// CheckBalances lists organizers whose stored balance does not match their ledger
+func (h *LedgerHandler) CheckBalances(c *gin.Context) {
+	mismatches, err := h.ledgerService.CheckBalances()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check balances"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"in_balance": len(mismatches) == 0,
+		"mismatches": mismatches,
+	})
+}
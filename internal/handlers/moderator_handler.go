@@ -1,10 +1,11 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
-	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/warui/event-ticketing-api/internal/config"
 	"github.com/warui/event-ticketing-api/internal/middleware"
 	"github.com/warui/event-ticketing-api/internal/models"
@@ -13,16 +14,20 @@ import (
 )
 
 type ModeratorHandler struct {
-	db           *gorm.DB
-	cfg          *config.Config
-	emailService *services.EmailService
+	db            *gorm.DB
+	cfg           *config.Config
+	emailService  *services.EmailService
+	changeService *services.EventChangeService
+	lifecycle     *services.EventLifecycleService
 }
 
-func NewModeratorHandler(db *gorm.DB, cfg *config.Config, emailService *services.EmailService) *ModeratorHandler {
+func NewModeratorHandler(db *gorm.DB, cfg *config.Config, emailService *services.EmailService, changeService *services.EventChangeService, lifecycle *services.EventLifecycleService) *ModeratorHandler {
 	return &ModeratorHandler{
-		db:           db,
-		cfg:          cfg,
-		emailService: emailService,
+		db:            db,
+		cfg:           cfg,
+		emailService:  emailService,
+		changeService: changeService,
+		lifecycle:     lifecycle,
 	}
 }
 
@@ -42,7 +47,12 @@ func (h *ModeratorHandler) GetEventForReview(c *gin.Context) {
 	eventID := c.Param("id")
 
 	var event models.Event
-	if err := h.db.Preload("Organizer").Preload("TicketTypes").First(&event, "id = ?", eventID).Error; err != nil {
+	// A series is reviewed once, with all of its sessions
+	if err := h.db.Preload("Organizer").Preload("TicketTypes", func(db *gorm.DB) *gorm.DB {
+		return db.Order(services.TicketTypeOrder)
+	}).Preload("Sessions", func(db *gorm.DB) *gorm.DB {
+		return db.Order("start_date ASC")
+	}).First(&event, "id = ?", eventID).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
 		return
 	}
@@ -50,11 +60,17 @@ func (h *ModeratorHandler) GetEventForReview(c *gin.Context) {
 	c.JSON(http.StatusOK, event)
 }
 
-// ReviewEvent approves or rejects an event
+// ReviewEvent approves or rejects an event. Approved events go live straight away
+// unless the organizer scheduled them for later.
 func (h *ModeratorHandler) ReviewEvent(c *gin.Context) {
-	eventID := c.Param("id")
 	moderatorID, _ := middleware.GetUserID(c)
 
+	eventID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		return
+	}
+
 	var req struct {
 		Action  string `json:"action" binding:"required,oneof=approve reject"`
 		Comment string `json:"comment"`
@@ -65,43 +81,107 @@ func (h *ModeratorHandler) ReviewEvent(c *gin.Context) {
 		return
 	}
 
+	event, err := h.lifecycle.Review(eventID, moderatorID, req.Action == "approve", req.Comment)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		case errors.Is(err, services.ErrInvalidEventTransition):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Event is not pending review"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update event"})
+		}
+		return
+	}
+
+	// Send email notification to organizer
+	var organizer models.User
+	if err := h.db.First(&organizer, "id = ?", event.OrganizerID).Error; err == nil {
+		event.Organizer = organizer
+		go h.emailService.SendEventApprovalEmail(event, &organizer, req.Action == "approve")
+	}
+
+	c.JSON(http.StatusOK, event)
+}
+
+// GetEventHistory lists every status change of an event, oldest first
+func (h *ModeratorHandler) GetEventHistory(c *gin.Context) {
 	var event models.Event
-	if err := h.db.Preload("Organizer").First(&event, "id = ?", eventID).Error; err != nil {
+	if err := h.db.First(&event, "id = ?", c.Param("id")).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
 		return
 	}
 
-	if event.Status != models.EventStatusPending {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Event is not pending review"})
+	var history []models.EventStatusChange
+	if err := h.db.Where("event_id = ?", event.ID).Order("created_at ASC").Find(&history).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch event history"})
 		return
 	}
 
-	if req.Action == "approve" {
-		event.Status = models.EventStatusPublished // Auto-publish on approval
-		event.ModeratorID = &moderatorID
-		event.ModerationComment = req.Comment
-		now := time.Now()
-		event.ModeratedAt = &now
-	} else if req.Action == "reject" {
-		event.Status = models.EventStatusRejected
-		event.ModeratorID = &moderatorID
-		event.ModerationComment = req.Comment
-		now := time.Now()
-		event.ModeratedAt = &now
-	} else {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid action. Must be 'approve' or 'reject'"})
+	c.JSON(http.StatusOK, history)
+}
+
+// GetPendingChanges lists changes to published events waiting for review, oldest first
+func (h *ModeratorHandler) GetPendingChanges(c *gin.Context) {
+	var changes []models.EventChangeRequest
+	if err := h.db.Preload("Event").Where("status = ?", models.EventChangeStatusPending).
+		Order("created_at ASC").Find(&changes).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch pending changes"})
 		return
 	}
 
-	if err := h.db.Save(&event).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update event"})
+	c.JSON(http.StatusOK, changes)
+}
+
+// GetChangeForReview retrieves a change with what it changes
+func (h *ModeratorHandler) GetChangeForReview(c *gin.Context) {
+	var change models.EventChangeRequest
+	if err := h.db.Preload("Event").First(&change, "id = ?", c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Change not found"})
 		return
 	}
 
-	// Send email notification to organizer
-	go h.emailService.SendEventApprovalEmail(&event, &event.Organizer, req.Action == "approve")
+	c.JSON(http.StatusOK, gin.H{"change": change, "changes": change.Changes()})
+}
 
-	c.JSON(http.StatusOK, event)
+// ReviewChange approves or rejects a change to a published event
+func (h *ModeratorHandler) ReviewChange(c *gin.Context) {
+	moderatorID, _ := middleware.GetUserID(c)
+
+	changeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Change not found"})
+		return
+	}
+
+	var req struct {
+		Action  string `json:"action" binding:"required,oneof=approve reject"`
+		Comment string `json:"comment"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	change, err := h.changeService.ReviewChange(changeID, moderatorID, req.Action == "approve", req.Comment)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Change not found"})
+		case errors.Is(err, services.ErrChangeNotPending), errors.Is(err, services.ErrEventNotEditable):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to review change"})
+		}
+		return
+	}
+
+	var organizer models.User
+	if err := h.db.First(&organizer, "id = ?", change.Event.OrganizerID).Error; err == nil {
+		go h.emailService.SendEventChangeReviewEmail(change, change.Event, &organizer)
+	}
+
+	c.JSON(http.StatusOK, change)
 }
 
 // GetModerationStats returns moderation statistics
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
@@ -12,6 +13,7 @@ import (
 	"github.com/warui/event-ticketing-api/internal/models"
 	"github.com/warui/event-ticketing-api/internal/services"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type OrganizerHandler struct {
@@ -19,14 +21,22 @@ type OrganizerHandler struct {
 	cfg            *config.Config
 	storageService *services.StorageService
 	imageService   *services.ImageService
+	ticketSigner   *services.TicketSigner
+	ledgerService  *services.LedgerService
+	changeService  *services.EventChangeService
+	lifecycle      *services.EventLifecycleService
 }
 
-func NewOrganizerHandler(db *gorm.DB, cfg *config.Config, storageService *services.StorageService, imageService *services.ImageService) *OrganizerHandler {
+func NewOrganizerHandler(db *gorm.DB, cfg *config.Config, storageService *services.StorageService, imageService *services.ImageService, ticketSigner *services.TicketSigner, ledgerService *services.LedgerService, changeService *services.EventChangeService, lifecycle *services.EventLifecycleService) *OrganizerHandler {
 	return &OrganizerHandler{
 		db:             db,
 		cfg:            cfg,
 		storageService: storageService,
 		imageService:   imageService,
+		ticketSigner:   ticketSigner,
+		ledgerService:  ledgerService,
+		changeService:  changeService,
+		lifecycle:      lifecycle,
 	}
 }
 
@@ -40,16 +50,30 @@ type CreateEventRequest struct {
 	Country     string    `json:"country"`
 	StartDate   time.Time `json:"start_date" binding:"required"`
 	EndDate     time.Time `json:"end_date" binding:"required"`
+
+	// When the event goes live once approved; only read on creation, see SetPublishSchedule
+	PublishMode models.PublishMode `json:"publish_mode" binding:"omitempty,oneof=on_approval scheduled"`
+	PublishAt   *time.Time         `json:"publish_at"`
+}
+
+type PublishScheduleRequest struct {
+	PublishMode models.PublishMode `json:"publish_mode" binding:"required,oneof=on_approval scheduled"`
+	PublishAt   *time.Time         `json:"publish_at"`
 }
 
 type CreateTicketTypeRequest struct {
 	Name        string    `json:"name" binding:"required"`
 	Description string    `json:"description"`
-	Price       float64   `json:"price" binding:"required,min=0"`
+	Price       int64     `json:"price" binding:"required,min=0"` // Minor units
 	Quantity    int       `json:"quantity" binding:"required,min=1"`
 	MaxPerOrder int       `json:"max_per_order" binding:"required,min=1"`
 	SaleStart   time.Time `json:"sale_start" binding:"required"`
 	SaleEnd     time.Time `json:"sale_end" binding:"required"`
+	IsHidden    bool      `json:"is_hidden"` // Sold only with an access code
+
+	DisplayOrder int `json:"display_order"` // Lower comes first in listings
+
+	SessionID *uuid.UUID `json:"session_id"` // Multi-session events: one session, or leave out for a series pass
 }
 
 // CreateEvent creates a new event
@@ -78,12 +102,13 @@ func (h *OrganizerHandler) CreateEvent(c *gin.Context) {
 		Country:     req.Country,
 		StartDate:   req.StartDate,
 		EndDate:     req.EndDate,
-		OrganizerID: organizerID,
-		Status:      models.EventStatusPending, // Auto-submit for approval
+		PublishMode: req.PublishMode,
+		PublishAt:   req.PublishAt,
 	}
 
-	if err := h.db.Create(event).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create event"})
+	// Events start as drafts; the organizer submits them once ticket types are added
+	if err := h.lifecycle.Create(event, organizerID); err != nil {
+		h.lifecycleError(c, err, "Failed to create event")
 		return
 	}
 
@@ -166,18 +191,24 @@ func (h *OrganizerHandler) UpdateEvent(c *gin.Context) {
 		return
 	}
 
-	// Only allow updates for draft or rejected events
-	if event.Status != models.EventStatusDraft && event.Status != models.EventStatusRejected {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot update event in current status"})
-		return
-	}
-
 	var req CreateEventRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
+	// Published events change through moderation; see updatePublishedEvent
+	if event.Status == models.EventStatusPublished {
+		h.updatePublishedEvent(c, &event, req)
+		return
+	}
+
+	// Only allow updates for draft or rejected events
+	if event.Status != models.EventStatusDraft && event.Status != models.EventStatusRejected {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot update event in current status"})
+		return
+	}
+
 	event.Title = req.Title
 	event.Description = req.Description
 	event.Category = req.Category
@@ -196,68 +227,185 @@ func (h *OrganizerHandler) UpdateEvent(c *gin.Context) {
 	c.JSON(http.StatusOK, event)
 }
 
-// SubmitEventForReview submits an event for moderation
-func (h *OrganizerHandler) SubmitEventForReview(c *gin.Context) {
-	eventID := c.Param("id")
+// updatePublishedEvent applies small edits to a published event straight away and
+// queues changes to its title, dates or venue for moderation
+func (h *OrganizerHandler) updatePublishedEvent(c *gin.Context, event *models.Event, req CreateEventRequest) {
+	updated, change, err := h.changeService.EditPublishedEvent(event.ID, event.OrganizerID, services.EventEdit{
+		Description: req.Description,
+		Category:    req.Category,
+		Details: models.EventDetails{
+			Title:     req.Title,
+			Venue:     req.Venue,
+			Address:   req.Address,
+			City:      req.City,
+			Country:   req.Country,
+			StartDate: req.StartDate,
+			EndDate:   req.EndDate,
+		},
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidEventChange):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrEventNotEditable):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot update event in current status"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update event"})
+		}
+		return
+	}
+
+	if change == nil {
+		c.JSON(http.StatusOK, updated)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "Changes to the title, dates or venue are waiting for review",
+		"event":   updated,
+		"change":  change,
+		"changes": change.Changes(),
+	})
+}
+
+// GetEventChanges lists the changes requested to one of the organizer's published events
+func (h *OrganizerHandler) GetEventChanges(c *gin.Context) {
 	organizerID, _ := middleware.GetUserID(c)
 
 	var event models.Event
-	if err := h.db.Preload("TicketTypes").First(&event, "id = ? AND organizer_id = ?", eventID, organizerID).Error; err != nil {
+	if err := h.db.First(&event, "id = ? AND organizer_id = ?", c.Param("id"), organizerID).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
 		return
 	}
 
-	if event.Status != models.EventStatusDraft && event.Status != models.EventStatusRejected {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Event cannot be submitted in current status"})
+	var changes []models.EventChangeRequest
+	if err := h.db.Where("event_id = ?", event.ID).Order("created_at DESC").Find(&changes).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch event changes"})
 		return
 	}
 
-	// Validate event has at least one ticket type
-	if len(event.TicketTypes) == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Event must have at least one ticket type"})
+	c.JSON(http.StatusOK, changes)
+}
+
+// SubmitEventForReview submits an event for moderation
+func (h *OrganizerHandler) SubmitEventForReview(c *gin.Context) {
+	organizerID, _ := middleware.GetUserID(c)
+	eventID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
 		return
 	}
 
-	event.Status = models.EventStatusPending
-	if err := h.db.Save(&event).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to submit event"})
+	event, err := h.lifecycle.Submit(eventID, organizerID)
+	if err != nil {
+		h.lifecycleError(c, err, "Failed to submit event")
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Event submitted for review", "event": event})
 }
 
-// PublishEvent publishes an approved event
+// WithdrawEvent takes an event out of review and back to draft
+func (h *OrganizerHandler) WithdrawEvent(c *gin.Context) {
+	organizerID, _ := middleware.GetUserID(c)
+	eventID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+
+	event, err := h.lifecycle.Withdraw(eventID, organizerID)
+	if err != nil {
+		h.lifecycleError(c, err, "Failed to withdraw event")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Event withdrawn from review", "event": event})
+}
+
+// PublishEvent publishes an approved event, ahead of its schedule if it has one
 func (h *OrganizerHandler) PublishEvent(c *gin.Context) {
-	eventID := c.Param("id")
 	organizerID, _ := middleware.GetUserID(c)
+	eventID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
 
-	var event models.Event
-	if err := h.db.First(&event, "id = ? AND organizer_id = ?", eventID, organizerID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+	event, err := h.lifecycle.Publish(eventID, organizerID)
+	if err != nil {
+		h.lifecycleError(c, err, "Failed to publish event")
 		return
 	}
 
-	if event.Status != models.EventStatusApproved {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Only approved events can be published"})
+	c.JSON(http.StatusOK, event)
+}
+
+// SetPublishSchedule chooses whether an event goes live on approval or at a set time
+func (h *OrganizerHandler) SetPublishSchedule(c *gin.Context) {
+	organizerID, _ := middleware.GetUserID(c)
+	eventID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
 		return
 	}
 
-	event.Status = models.EventStatusPublished
-	if err := h.db.Save(&event).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to publish event"})
+	var req PublishScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	event, err := h.lifecycle.SetPublishSchedule(eventID, organizerID, req.PublishMode, req.PublishAt)
+	if err != nil {
+		h.lifecycleError(c, err, "Failed to update publish schedule")
 		return
 	}
 
 	c.JSON(http.StatusOK, event)
 }
 
+// GetEventHistory lists every status change of one of the organizer's events, oldest first
+func (h *OrganizerHandler) GetEventHistory(c *gin.Context) {
+	organizerID, _ := middleware.GetUserID(c)
+
+	var event models.Event
+	if err := h.db.First(&event, "id = ? AND organizer_id = ?", c.Param("id"), organizerID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		return
+	}
+
+	var history []models.EventStatusChange
+	if err := h.db.Where("event_id = ?", event.ID).Order("created_at ASC").Find(&history).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch event history"})
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// lifecycleError maps event lifecycle errors to responses
+func (h *OrganizerHandler) lifecycleError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+	case errors.Is(err, services.ErrInvalidEventTransition),
+		errors.Is(err, services.ErrEventIncomplete),
+		errors.Is(err, services.ErrInvalidPublishSchedule):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
+
 // GetMyEvents retrieves organizer's events
 func (h *OrganizerHandler) GetMyEvents(c *gin.Context) {
 	organizerID, _ := middleware.GetUserID(c)
 	status := c.Query("status")
 
-	query := h.db.Preload("TicketTypes").Where("organizer_id = ?", organizerID)
+	query := h.db.Preload("TicketTypes", func(db *gorm.DB) *gorm.DB {
+		return db.Order(services.TicketTypeOrder)
+	}).Where("organizer_id = ?", organizerID)
 
 	if status != "" {
 		query = query.Where("status = ?", status)
@@ -278,7 +426,11 @@ func (h *OrganizerHandler) GetMyEvent(c *gin.Context) {
 	eventID := c.Param("id")
 
 	var event models.Event
-	if err := h.db.Preload("TicketTypes").First(&event, "id = ? AND organizer_id = ?", eventID, organizerID).Error; err != nil {
+	if err := h.db.Preload("TicketTypes", func(db *gorm.DB) *gorm.DB {
+		return db.Order(services.TicketTypeOrder)
+	}).Preload("Sessions", func(db *gorm.DB) *gorm.DB {
+		return db.Order("start_date ASC")
+	}).First(&event, "id = ? AND organizer_id = ?", eventID, organizerID).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
 		return
 	}
@@ -309,6 +461,14 @@ func (h *OrganizerHandler) CreateTicketType(c *gin.Context) {
 		return
 	}
 
+	if req.SessionID != nil {
+		var session models.EventSession
+		if err := h.db.First(&session, "id = ? AND event_id = ?", *req.SessionID, event.ID).Error; err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Session must belong to the event"})
+			return
+		}
+	}
+
 	eventIDUUID, _ := uuid.Parse(eventID)
 	ticketType := &models.TicketType{
 		EventID:     eventIDUUID,
@@ -320,6 +480,10 @@ func (h *OrganizerHandler) CreateTicketType(c *gin.Context) {
 		SaleStart:   req.SaleStart,
 		SaleEnd:     req.SaleEnd,
 		IsActive:    true,
+		IsHidden:    req.IsHidden,
+		SessionID:   req.SessionID,
+
+		DisplayOrder: req.DisplayOrder,
 	}
 
 	if err := h.db.Create(ticketType).Error; err != nil {
@@ -351,10 +515,8 @@ func (h *OrganizerHandler) RequestWithdrawal(c *gin.Context) {
 	organizerID, _ := middleware.GetUserID(c)
 
 	var req struct {
-		Amount        float64 `json:"amount" binding:"required,min=0"`
-		BankName      string  `json:"bank_name" binding:"required"`
-		AccountNumber string  `json:"account_number" binding:"required"`
-		AccountName   string  `json:"account_name" binding:"required"`
+		Amount          int64     `json:"amount" binding:"required,min=1"` // Minor units
+		PayoutAccountID uuid.UUID `json:"payout_account_id" binding:"required"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -362,6 +524,16 @@ func (h *OrganizerHandler) RequestWithdrawal(c *gin.Context) {
 		return
 	}
 
+	var account models.PayoutAccount
+	if err := h.db.First(&account, "id = ? AND organizer_id = ?", req.PayoutAccountID, organizerID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Payout account not found"})
+		return
+	}
+	if account.Status != models.PayoutAccountStatusApproved {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Payout account has not been approved"})
+		return
+	}
+
 	// Get platform settings
 	var settings models.PlatformSettings
 	if err := h.db.First(&settings).Error; err != nil {
@@ -372,50 +544,59 @@ func (h *OrganizerHandler) RequestWithdrawal(c *gin.Context) {
 	// Check minimum withdrawal amount
 	if req.Amount < settings.MinWithdrawalAmount {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": fmt.Sprintf("Minimum withdrawal amount is %.2f", settings.MinWithdrawalAmount),
+			"error": fmt.Sprintf("Minimum withdrawal amount is %s", models.FormatAmount(settings.MinWithdrawalAmount)),
 		})
 		return
 	}
 
-	// Get organizer balance
-	var balance models.OrganizerBalance
-	if err := h.db.Where("organizer_id = ?", organizerID).First(&balance).Error; err != nil {
+	// Calculate withdrawal fee
+	withdrawalFee := models.PercentageOf(req.Amount, settings.WithdrawalFeePercentage)
+	netAmount := req.Amount - withdrawalFee
+
+	withdrawal := &models.WithdrawalRequest{
+		OrganizerID:     organizerID,
+		Amount:          req.Amount,
+		WithdrawalFee:   withdrawalFee,
+		NetAmount:       netAmount,
+		PayoutAccountID: &account.ID,
+		RecipientCode:   account.RecipientCode,
+		PaymentGateway:  account.PaymentGateway,
+		BankName:        account.BankName,
+		AccountNumber:   account.AccountNumber,
+		AccountName:     account.AccountName,
+		BankCode:        account.BankCode,
+		Status:          models.WithdrawalStatusPending,
+	}
+
+	// Lock the balance so concurrent requests cannot both spend it
+	errInsufficientBalance := errors.New("insufficient balance")
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		var balance models.OrganizerBalance
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("organizer_id = ?", organizerID).First(&balance).Error; err != nil {
+			return err
+		}
+		if balance.AvailableBalance < req.Amount {
+			return errInsufficientBalance
+		}
+
+		if err := tx.Create(withdrawal).Error; err != nil {
+			return err
+		}
+		return h.ledgerService.RecordWithdrawalRequestTx(tx, withdrawal, h.cfg.Currency)
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Balance not found"})
 		return
 	}
-
-	// Check available balance
-	if balance.AvailableBalance < req.Amount {
+	if errors.Is(err, errInsufficientBalance) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Insufficient balance"})
 		return
 	}
-
-	// Calculate withdrawal fee
-	withdrawalFee := req.Amount * (settings.WithdrawalFeePercentage / 100)
-	netAmount := req.Amount - withdrawalFee
-
-	// Create withdrawal request
-	withdrawal := &models.WithdrawalRequest{
-		OrganizerID:   organizerID,
-		Amount:        req.Amount,
-		WithdrawalFee: withdrawalFee,
-		NetAmount:     netAmount,
-		BankName:      req.BankName,
-		AccountNumber: req.AccountNumber,
-		AccountName:   req.AccountName,
-		Status:        models.WithdrawalStatusPending,
-	}
-
-	if err := h.db.Create(withdrawal).Error; err != nil {
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create withdrawal request"})
 		return
 	}
 
-	// Update balance
-	balance.AvailableBalance -= req.Amount
-	balance.PendingBalance += req.Amount
-	h.db.Save(&balance)
-
 	c.JSON(http.StatusCreated, withdrawal)
 }
 
@@ -443,18 +624,38 @@ func (h *OrganizerHandler) GetEventStats(c *gin.Context) {
 		return
 	}
 
-	var stats struct {
-		TotalTicketsSold int64   `json:"total_tickets_sold"`
-		TotalRevenue     float64 `json:"total_revenue"`
-		NetRevenue       float64 `json:"net_revenue"`
-		CheckedInTickets int64   `json:"checked_in_tickets"`
+	type promoCodeStats struct {
+		PromoCodeID   uuid.UUID `json:"promo_code_id"`
+		Code          string    `json:"code"`
+		Uses          int64     `json:"uses"`
+		DiscountTotal int64     `json:"discount_total"`
+		Revenue       int64     `json:"revenue"`
 	}
 
-	h.db.Model(&models.Ticket{}).Where("event_id = ? AND status = ?", eventID, models.TicketStatusConfirmed).Count(&stats.TotalTicketsSold)
+	var stats struct {
+		TotalTicketsSold     int64            `json:"total_tickets_sold"`
+		ComplimentaryTickets int64            `json:"complimentary_tickets"`
+		TotalRevenue         int64            `json:"total_revenue"`
+		NetRevenue           int64            `json:"net_revenue"`
+		CheckedInTickets     int64            `json:"checked_in_tickets"`
+		TotalDiscount        int64            `json:"total_discount"`
+		PromoCodes           []promoCodeStats `json:"promo_codes"`
+	}
+
+	// A checked-in ticket stays sold; check-ins are reported on their own.
+	// Complimentary tickets are counted apart from sold ones.
+	validStatuses := []models.TicketStatus{models.TicketStatusConfirmed, models.TicketStatusUsed}
+	validTickets := func(transactionType models.TransactionType) *gorm.DB {
+		return h.db.Model(&models.Ticket{}).
+			Joins("JOIN transactions ON transactions.id = tickets.transaction_id").
+			Where("tickets.event_id = ? AND tickets.status IN ? AND transactions.type = ?", eventID, validStatuses, transactionType)
+	}
+	validTickets(models.TransactionTypeTicketPurchase).Count(&stats.TotalTicketsSold)
+	validTickets(models.TransactionTypeComplimentary).Count(&stats.ComplimentaryTickets)
 	h.db.Model(&models.Ticket{}).Where("event_id = ? AND checked_in_at IS NOT NULL", eventID).Count(&stats.CheckedInTickets)
 
 	var tickets []models.Ticket
-	h.db.Where("event_id = ? AND status = ?", eventID, models.TicketStatusConfirmed).Find(&tickets)
+	h.db.Where("event_id = ? AND status IN ?", eventID, validStatuses).Find(&tickets)
 
 	for _, ticket := range tickets {
 		stats.TotalRevenue += ticket.Price
@@ -463,8 +664,217 @@ func (h *OrganizerHandler) GetEventStats(c *gin.Context) {
 	// Get platform settings to calculate net revenue
 	var settings models.PlatformSettings
 	h.db.First(&settings)
-	platformFee := stats.TotalRevenue * (settings.PlatformFeePercentage / 100)
+	platformFee := models.PercentageOf(stats.TotalRevenue, settings.PlatformFeePercentage)
 	stats.NetRevenue = stats.TotalRevenue - platformFee
 
+	// Paid purchases per promo code, including codes not used yet
+	stats.PromoCodes = []promoCodeStats{}
+	h.db.Model(&models.PromoCode{}).
+		Select("promo_codes.id AS promo_code_id, promo_codes.code, COUNT(transactions.id) AS uses, "+
+			"COALESCE(SUM(transactions.discount_amount), 0) AS discount_total, COALESCE(SUM(transactions.amount), 0) AS revenue").
+		Joins("LEFT JOIN transactions ON transactions.promo_code_id = promo_codes.id AND transactions.deleted_at IS NULL AND transactions.status IN ?",
+			[]models.TransactionStatus{models.TransactionStatusCompleted, models.TransactionStatusPartiallyRefunded, models.TransactionStatusRefunded}).
+		Where("promo_codes.event_id = ?", event.ID).
+		Group("promo_codes.id, promo_codes.code").
+		Order("promo_codes.code").
+		Scan(&stats.PromoCodes)
+	for _, promoCode := range stats.PromoCodes {
+		stats.TotalDiscount += promoCode.DiscountTotal
+	}
+
 	c.JSON(http.StatusOK, stats)
 }
+
+// CheckInTicket validates a scanned ticket QR code at the gate and marks the ticket as used.
+// At multi-session events a series pass is admitted once per session instead.
+func (h *OrganizerHandler) CheckInTicket(c *gin.Context) {
+	eventID := c.Param("id")
+	userID, _ := middleware.GetUserID(c)
+	role, _ := middleware.GetUserRole(c)
+
+	var req struct {
+		QRData    string     `json:"qr_data" binding:"required"`
+		Section   string     `json:"section"`    // Gates for one section of a seated venue name it
+		SessionID *uuid.UUID `json:"session_id"` // Required at multi-session events
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Admins can check in at any event, organizers only at their own
+	query := h.db.Where("id = ?", eventID)
+	if role != models.RoleAdmin {
+		query = query.Where("organizer_id = ?", userID)
+	}
+
+	var event models.Event
+	if err := query.First(&event).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		return
+	}
+
+	// Multi-session events admit to one session at a time
+	var sessions int64
+	h.db.Model(&models.EventSession{}).Where("event_id = ?", event.ID).Count(&sessions)
+	if sessions > 0 && req.SessionID == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "session_id is required at multi-session events"})
+		return
+	}
+	if req.SessionID != nil {
+		var session models.EventSession
+		if err := h.db.First(&session, "id = ? AND event_id = ?", *req.SessionID, event.ID).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+			return
+		}
+	}
+	gate := models.CheckInGate{EventID: event.ID, Section: req.Section, SessionID: req.SessionID}
+
+	// Reject unsigned and forged codes before touching the database
+	claims, err := h.ticketSigner.VerifyTicketPayload(req.QRData)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"result": models.CheckInResultForged, "error": "Ticket code could not be verified: " + err.Error()})
+		return
+	}
+
+	ticketUUID, err := uuid.Parse(claims.TicketID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"result": models.CheckInResultUnknown, "error": "Unrecognised ticket code"})
+		return
+	}
+	ticketNumber := claims.TicketNumber
+
+	// Mark the ticket used in a single conditional update so two gates
+	// scanning the same code at once cannot both admit it
+	now := time.Now()
+	update := h.db.Model(&models.Ticket{}).
+		Where("id = ? AND ticket_number = ? AND event_id = ? AND status = ?", ticketUUID, ticketNumber, event.ID, models.TicketStatusConfirmed)
+	if req.Section != "" {
+		update = update.Where("(seat_id IS NULL OR seat_id IN (?))",
+			h.db.Model(&models.Seat{}).Select("id").Where("event_id = ? AND LOWER(section) = LOWER(?)", event.ID, req.Section))
+	}
+	if req.SessionID != nil {
+		// Series passes are admitted per session below and stay confirmed
+		update = update.Where("session_id = ?", *req.SessionID)
+	}
+	result := update.
+		Updates(map[string]interface{}{
+			"status":        models.TicketStatusUsed,
+			"checked_in_at": now,
+			"checked_in_by": userID,
+		})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check in ticket"})
+		return
+	}
+
+	var ticket models.Ticket
+	if err := h.db.Preload("Attendee").Preload("TicketType").Preload("Seat").Preload("Session").First(&ticket, "id = ? AND ticket_number = ?", ticketUUID, ticketNumber).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"result": models.CheckInResultUnknown, "error": "Ticket not found"})
+		return
+	}
+	ticket.Attendee.Password = ""
+
+	if result.RowsAffected == 1 {
+		c.JSON(http.StatusOK, gin.H{
+			"result":  models.CheckInResultValid,
+			"message": "Ticket checked in",
+			"ticket":  ticket,
+		})
+		return
+	}
+
+	checkIn := ticket.CheckInResultFor(gate)
+	if checkIn == models.CheckInResultValid && req.SessionID != nil && ticket.SessionID == nil {
+		h.checkInSeriesPass(c, &ticket, *req.SessionID, userID)
+		return
+	}
+
+	switch checkIn {
+	case models.CheckInResultAlreadyUsed:
+		response := gin.H{
+			"result":        checkIn,
+			"error":         "Ticket has already been used",
+			"checked_in_at": ticket.CheckedInAt,
+			"ticket":        ticket,
+		}
+		if ticket.CheckedInBy != nil {
+			if staff := h.checkInStaff(*ticket.CheckedInBy); staff != nil {
+				response["checked_in_by"] = staff
+			}
+		}
+		c.JSON(http.StatusConflict, response)
+	case models.CheckInResultWrongEvent:
+		c.JSON(http.StatusBadRequest, gin.H{"result": checkIn, "error": "Ticket is for a different event"})
+	case models.CheckInResultCancelled:
+		c.JSON(http.StatusBadRequest, gin.H{"result": checkIn, "error": "Ticket has been cancelled", "ticket": ticket})
+	case models.CheckInResultWrongSection:
+		c.JSON(http.StatusBadRequest, gin.H{"result": checkIn, "error": "Ticket is for another section", "seat_label": ticket.SeatLabel, "ticket": ticket})
+	case models.CheckInResultWrongSession:
+		c.JSON(http.StatusBadRequest, gin.H{"result": checkIn, "error": "Ticket is for another session", "session": ticket.Session, "ticket": ticket})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"result": models.CheckInResultNotConfirmed, "error": "Ticket is not confirmed", "ticket": ticket})
+	}
+}
+
+// checkInSeriesPass admits a series pass to one session. The unique check-in per
+// ticket and session keeps two gates from admitting the same pass twice.
+func (h *OrganizerHandler) checkInSeriesPass(c *gin.Context, ticket *models.Ticket, sessionID, userID uuid.UUID) {
+	now := time.Now()
+	admission := models.SessionCheckIn{TicketID: ticket.ID, SessionID: sessionID, CheckedInAt: now, CheckedInBy: userID}
+	result := h.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&admission)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check in ticket"})
+		return
+	}
+
+	if result.RowsAffected == 1 {
+		h.db.Model(ticket).Updates(map[string]interface{}{"checked_in_at": now, "checked_in_by": userID})
+		ticket.CheckedInAt = &now
+		ticket.CheckedInBy = &userID
+		c.JSON(http.StatusOK, gin.H{
+			"result":  models.CheckInResultValid,
+			"message": "Series pass checked in for this session",
+			"ticket":  ticket,
+		})
+		return
+	}
+
+	var existing models.SessionCheckIn
+	if err := h.db.First(&existing, "ticket_id = ? AND session_id = ?", ticket.ID, sessionID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check in ticket"})
+		return
+	}
+	response := gin.H{
+		"result":        models.CheckInResultAlreadyUsed,
+		"error":         "Series pass has already been used for this session",
+		"checked_in_at": existing.CheckedInAt,
+		"ticket":        ticket,
+	}
+	if staff := h.checkInStaff(existing.CheckedInBy); staff != nil {
+		response["checked_in_by"] = staff
+	}
+	c.JSON(http.StatusConflict, response)
+}
+
+// checkInStaff describes the staff member who checked a ticket in, or nil if they are gone
+func (h *OrganizerHandler) checkInStaff(userID uuid.UUID) gin.H {
+	var staff models.User
+	if err := h.db.Select("id", "first_name", "last_name", "email").First(&staff, "id = ?", userID).Error; err != nil {
+		return nil
+	}
+	return gin.H{
+		"id":    staff.ID,
+		"name":  fmt.Sprintf("%s %s", staff.FirstName, staff.LastName),
+		"email": staff.Email,
+	}
+}
+
+// GetTicketSigningKeys lists the public keys scanners use to verify ticket QR codes offline
+func (h *OrganizerHandler) GetTicketSigningKeys(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"active_key_id": h.ticketSigner.ActiveKeyID(),
+		"keys":          h.ticketSigner.PublicKeys(),
+	})
+}
@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/warui/event-ticketing-api/internal/config"
+	"github.com/warui/event-ticketing-api/internal/middleware"
+	"github.com/warui/event-ticketing-api/internal/models"
+	"github.com/warui/event-ticketing-api/internal/services"
+	"gorm.io/gorm"
+)
+
+type PayoutAccountHandler struct {
+	db                   *gorm.DB
+	cfg                  *config.Config
+	payoutAccountService *services.PayoutAccountService
+}
+
+func NewPayoutAccountHandler(db *gorm.DB, cfg *config.Config, payoutAccountService *services.PayoutAccountService) *PayoutAccountHandler {
+	return &PayoutAccountHandler{
+		db:                   db,
+		cfg:                  cfg,
+		payoutAccountService: payoutAccountService,
+	}
+}
+
+// ListBanks returns the banks organizers can add payout accounts for
+func (h *PayoutAccountHandler) ListBanks(c *gin.Context) {
+	banks, err := h.payoutAccountService.ListBanks()
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to fetch banks"})
+		return
+	}
+
+	c.JSON(http.StatusOK, banks)
+}
+
+// CreatePayoutAccount verifies a bank account and saves it for admin approval
+func (h *PayoutAccountHandler) CreatePayoutAccount(c *gin.Context) {
+	organizerID, _ := middleware.GetUserID(c)
+
+	var req struct {
+		BankCode      string `json:"bank_code" binding:"required"`
+		AccountNumber string `json:"account_number" binding:"required,numeric"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	account, err := h.payoutAccountService.AddAccount(organizerID, req.BankCode, req.AccountNumber)
+	if err != nil {
+		if errors.Is(err, services.ErrAccountNotVerified) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to save payout account"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, account)
+}
+
+// GetMyPayoutAccounts lists the organizer's payout accounts
+func (h *PayoutAccountHandler) GetMyPayoutAccounts(c *gin.Context) {
+	organizerID, _ := middleware.GetUserID(c)
+
+	var accounts []models.PayoutAccount
+	if err := h.db.Where("organizer_id = ?", organizerID).Order("created_at DESC").Find(&accounts).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch payout accounts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, accounts)
+}
+
+// DeletePayoutAccount removes a payout account. Withdrawals already requested
+// keep the bank details they were made with.
+func (h *PayoutAccountHandler) DeletePayoutAccount(c *gin.Context) {
+	organizerID, _ := middleware.GetUserID(c)
+
+	result := h.db.Where("id = ? AND organizer_id = ?", c.Param("id"), organizerID).Delete(&models.PayoutAccount{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete payout account"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Payout account not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Payout account deleted"})
+}
+
+// GetPayoutAccounts lists payout accounts for admins, optionally by status
+func (h *PayoutAccountHandler) GetPayoutAccounts(c *gin.Context) {
+	query := h.db.Preload("Organizer")
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var accounts []models.PayoutAccount
+	if err := query.Order("created_at DESC").Find(&accounts).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch payout accounts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, accounts)
+}
+
+// ReviewPayoutAccount approves or rejects a new payout account
+func (h *PayoutAccountHandler) ReviewPayoutAccount(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	var req struct {
+		Action  string `json:"action" binding:"required,oneof=approve reject"`
+		Comment string `json:"comment"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var account models.PayoutAccount
+	if err := h.db.First(&account, "id = ?", c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Payout account not found"})
+		return
+	}
+
+	now := time.Now()
+	account.Status = models.PayoutAccountStatusApproved
+	if req.Action == "reject" {
+		account.Status = models.PayoutAccountStatusRejected
+	}
+	account.ReviewedBy = &userID
+	account.ReviewedAt = &now
+	account.ReviewComment = req.Comment
+
+	result := h.db.Model(&models.PayoutAccount{}).
+		Where("id = ? AND status = ?", account.ID, models.PayoutAccountStatusPending).
+		Updates(map[string]interface{}{
+			"status":         account.Status,
+			"reviewed_by":    account.ReviewedBy,
+			"reviewed_at":    account.ReviewedAt,
+			"review_comment": account.ReviewComment,
+		})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update payout account"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Payout account already reviewed"})
+		return
+	}
+
+	c.JSON(http.StatusOK, account)
+}
@@ -0,0 +1,222 @@
+package handlers
+
+import (
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/warui/event-ticketing-api/internal/config"
+	"github.com/warui/event-ticketing-api/internal/middleware"
+	"github.com/warui/event-ticketing-api/internal/models"
+	"gorm.io/gorm"
+)
+
+// codePattern is the shape promo and access codes must have once upper-cased
+var codePattern = regexp.MustCompile(`^[A-Z0-9_-]{3,32}$`)
+
+type PromoCodeHandler struct {
+	db  *gorm.DB
+	cfg *config.Config
+}
+
+func NewPromoCodeHandler(db *gorm.DB, cfg *config.Config) *PromoCodeHandler {
+	return &PromoCodeHandler{
+		db:  db,
+		cfg: cfg,
+	}
+}
+
+type PromoCodeRequest struct {
+	Code           string              `json:"code" binding:"required"`
+	DiscountType   models.DiscountType `json:"discount_type" binding:"required,oneof=percentage fixed"`
+	PercentOff     float64             `json:"percent_off"`
+	AmountOff      int64               `json:"amount_off"` // Minor units
+	TicketTypeIDs  []uuid.UUID         `json:"ticket_type_ids"`
+	MaxUses        int                 `json:"max_uses" binding:"min=0"`
+	MaxUsesPerUser int                 `json:"max_uses_per_user" binding:"min=0"`
+	MinQuantity    int                 `json:"min_quantity" binding:"min=0"`
+	StartsAt       *time.Time          `json:"starts_at"`
+	EndsAt         *time.Time          `json:"ends_at"`
+	IsActive       *bool               `json:"is_active"`
+}
+
+// validate checks the request and returns the ticket types the code is limited to,
+// or a message saying what is wrong with it
+func (h *PromoCodeHandler) validate(req *PromoCodeRequest, eventID uuid.UUID) ([]models.TicketType, string) {
+	req.Code = models.NormalizeCode(req.Code)
+	if !codePattern.MatchString(req.Code) {
+		return nil, "Code must be 3 to 32 letters, digits, dashes or underscores"
+	}
+
+	switch req.DiscountType {
+	case models.DiscountTypePercentage:
+		if req.PercentOff <= 0 || req.PercentOff > 100 {
+			return nil, "Percent off must be greater than 0 and at most 100"
+		}
+		req.AmountOff = 0
+	case models.DiscountTypeFixed:
+		if req.AmountOff <= 0 {
+			return nil, "Amount off must be greater than 0"
+		}
+		req.PercentOff = 0
+	}
+
+	if req.StartsAt != nil && req.EndsAt != nil && !req.EndsAt.After(*req.StartsAt) {
+		return nil, "End date must be after start date"
+	}
+
+	var ticketTypes []models.TicketType
+	if len(req.TicketTypeIDs) > 0 {
+		if err := h.db.Where("id IN ? AND event_id = ?", req.TicketTypeIDs, eventID).Find(&ticketTypes).Error; err != nil {
+			return nil, "Failed to check ticket types"
+		}
+		if len(ticketTypes) != len(req.TicketTypeIDs) {
+			return nil, "Ticket types must belong to the event"
+		}
+	}
+
+	return ticketTypes, ""
+}
+
+// CreatePromoCode adds a discount code to one of the organizer's events
+func (h *PromoCodeHandler) CreatePromoCode(c *gin.Context) {
+	organizerID, _ := middleware.GetUserID(c)
+
+	var event models.Event
+	if err := h.db.First(&event, "id = ? AND organizer_id = ?", c.Param("id"), organizerID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		return
+	}
+
+	var req PromoCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ticketTypes, problem := h.validate(&req, event.ID)
+	if problem != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": problem})
+		return
+	}
+
+	var existing int64
+	h.db.Model(&models.PromoCode{}).Where("event_id = ? AND code = ?", event.ID, req.Code).Count(&existing)
+	if existing > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Promo code already exists for this event"})
+		return
+	}
+
+	promoCode := &models.PromoCode{
+		EventID:        event.ID,
+		Code:           req.Code,
+		DiscountType:   req.DiscountType,
+		PercentOff:     req.PercentOff,
+		AmountOff:      req.AmountOff,
+		MaxUses:        req.MaxUses,
+		MaxUsesPerUser: req.MaxUsesPerUser,
+		MinQuantity:    req.MinQuantity,
+		StartsAt:       req.StartsAt,
+		EndsAt:         req.EndsAt,
+		IsActive:       req.IsActive == nil || *req.IsActive,
+		TicketTypes:    ticketTypes,
+	}
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("TicketTypes.*").Create(promoCode).Error; err != nil {
+			return err
+		}
+		// A false is_active is a zero value, so Create stored the column default
+		if req.IsActive != nil && !*req.IsActive {
+			promoCode.IsActive = false
+			return tx.Model(promoCode).Update("is_active", false).Error
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create promo code"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, promoCode)
+}
+
+// GetPromoCodes lists the promo codes of one of the organizer's events
+func (h *PromoCodeHandler) GetPromoCodes(c *gin.Context) {
+	organizerID, _ := middleware.GetUserID(c)
+
+	var event models.Event
+	if err := h.db.First(&event, "id = ? AND organizer_id = ?", c.Param("id"), organizerID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		return
+	}
+
+	var promoCodes []models.PromoCode
+	if err := h.db.Preload("TicketTypes").Where("event_id = ?", event.ID).Order("created_at DESC").Find(&promoCodes).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch promo codes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, promoCodes)
+}
+
+// UpdatePromoCode changes a promo code. Purchases that already used it keep their discount.
+func (h *PromoCodeHandler) UpdatePromoCode(c *gin.Context) {
+	organizerID, _ := middleware.GetUserID(c)
+
+	var promoCode models.PromoCode
+	if err := h.db.Joins("JOIN events ON events.id = promo_codes.event_id").
+		Where("promo_codes.id = ? AND events.organizer_id = ?", c.Param("id"), organizerID).
+		First(&promoCode).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Promo code not found"})
+		return
+	}
+
+	var req PromoCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ticketTypes, problem := h.validate(&req, promoCode.EventID)
+	if problem != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": problem})
+		return
+	}
+
+	var existing int64
+	h.db.Model(&models.PromoCode{}).Where("event_id = ? AND code = ? AND id <> ?", promoCode.EventID, req.Code, promoCode.ID).Count(&existing)
+	if existing > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Promo code already exists for this event"})
+		return
+	}
+
+	promoCode.Code = req.Code
+	promoCode.DiscountType = req.DiscountType
+	promoCode.PercentOff = req.PercentOff
+	promoCode.AmountOff = req.AmountOff
+	promoCode.MaxUses = req.MaxUses
+	promoCode.MaxUsesPerUser = req.MaxUsesPerUser
+	promoCode.MinQuantity = req.MinQuantity
+	promoCode.StartsAt = req.StartsAt
+	promoCode.EndsAt = req.EndsAt
+	if req.IsActive != nil {
+		promoCode.IsActive = *req.IsActive
+	}
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("TicketTypes").Save(&promoCode).Error; err != nil {
+			return err
+		}
+		return tx.Model(&promoCode).Association("TicketTypes").Replace(ticketTypes)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update promo code"})
+		return
+	}
+	promoCode.TicketTypes = ticketTypes
+
+	c.JSON(http.StatusOK, promoCode)
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/warui/event-ticketing-api/internal/config"
+	"github.com/warui/event-ticketing-api/internal/models"
+	"github.com/warui/event-ticketing-api/internal/services"
+	"gorm.io/gorm"
+)
+
+type ReconciliationHandler struct {
+	db                    *gorm.DB
+	cfg                   *config.Config
+	reconciliationService *services.ReconciliationService
+}
+
+func NewReconciliationHandler(db *gorm.DB, cfg *config.Config, reconciliationService *services.ReconciliationService) *ReconciliationHandler {
+	return &ReconciliationHandler{
+		db:                    db,
+		cfg:                   cfg,
+		reconciliationService: reconciliationService,
+	}
+}
+
+// GetReports lists daily reconciliation reports, newest first
+func (h *ReconciliationHandler) GetReports(c *gin.Context) {
+	query := h.db.Model(&models.ReconciliationReport{})
+	if gateway := c.Query("gateway"); gateway != "" {
+		query = query.Where("payment_gateway = ?", gateway)
+	}
+	if c.Query("mismatched") == "true" {
+		query = query.Where("mismatch_count > 0")
+	}
+
+	var reports []models.ReconciliationReport
+	if err := query.Order("date DESC, payment_gateway ASC").Limit(90).Find(&reports).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch reports"})
+		return
+	}
+
+	c.JSON(http.StatusOK, reports)
+}
+
+// GetReport returns a reconciliation report with its mismatches
+func (h *ReconciliationHandler) GetReport(c *gin.Context) {
+	var report models.ReconciliationReport
+	if err := h.db.Preload("Mismatches").First(&report, "id = ?", c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Report not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// RunReport builds the report for a day and gateway now instead of waiting for
+// the daily job. Defaults to yesterday and the default gateway.
+func (h *ReconciliationHandler) RunReport(c *gin.Context) {
+	var req struct {
+		Date    string `json:"date"`
+		Gateway string `json:"gateway"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	day := time.Now().AddDate(0, 0, -1)
+	if req.Date != "" {
+		date, err := time.Parse("2006-01-02", req.Date)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format. Use YYYY-MM-DD"})
+			return
+		}
+		day = date
+	}
+	if !day.Before(time.Now().UTC().Truncate(24 * time.Hour)) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only days that have ended can be reconciled"})
+		return
+	}
+
+	gateway := req.Gateway
+	if gateway == "" {
+		gateway = h.cfg.PaymentProvider
+	}
+
+	report, err := h.reconciliationService.BuildDailyReport(gateway, day)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/warui/event-ticketing-api/internal/config"
+	"github.com/warui/event-ticketing-api/internal/middleware"
+	"github.com/warui/event-ticketing-api/internal/models"
+	"github.com/warui/event-ticketing-api/internal/services"
+	"gorm.io/gorm"
+)
+
+type RefundHandler struct {
+	db            *gorm.DB
+	cfg           *config.Config
+	refundService *services.RefundService
+}
+
+func NewRefundHandler(db *gorm.DB, cfg *config.Config, refundService *services.RefundService) *RefundHandler {
+	return &RefundHandler{
+		db:            db,
+		cfg:           cfg,
+		refundService: refundService,
+	}
+}
+
+type RefundTicketsRequest struct {
+	TicketIDs []uuid.UUID `json:"ticket_ids"`
+	Reason    string      `json:"reason"`
+}
+
+// RequestRefund lets an attendee refund their own tickets under the event's refund policy
+func (h *RefundHandler) RequestRefund(c *gin.Context) {
+	transactionID := c.Param("id")
+	attendeeID, _ := middleware.GetUserID(c)
+
+	var req RefundTicketsRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var transaction models.Transaction
+	if err := h.db.Preload("Event").First(&transaction, "id = ? AND user_id = ? AND type = ?", transactionID, attendeeID, models.TransactionTypeTicketPurchase).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Transaction not found"})
+		return
+	}
+
+	if transaction.Event == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Transaction is not for an event"})
+		return
+	}
+
+	percentage := transaction.Event.RefundPercentageAt(time.Now())
+	if percentage == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Refunds are not available for this event"})
+		return
+	}
+
+	h.refund(c, services.RefundRequest{
+		TransactionID: transaction.ID,
+		TicketIDs:     req.TicketIDs,
+		Percentage:    percentage,
+		Reason:        req.Reason,
+		HolderID:      attendeeID,
+	})
+}
+
+// SetRefundPolicy sets the percentage attendees get back and the deadline for claiming it
+func (h *RefundHandler) SetRefundPolicy(c *gin.Context) {
+	eventID := c.Param("id")
+	organizerID, _ := middleware.GetUserID(c)
+
+	var req struct {
+		RefundPercentage *float64   `json:"refund_percentage" binding:"required,min=0,max=100"`
+		RefundDeadline   *time.Time `json:"refund_deadline"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var event models.Event
+	if err := h.db.First(&event, "id = ? AND organizer_id = ?", eventID, organizerID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		return
+	}
+
+	if req.RefundDeadline != nil && req.RefundDeadline.After(event.StartDate) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Refund deadline must be before the event starts"})
+		return
+	}
+
+	if err := h.db.Model(&event).Updates(map[string]interface{}{
+		"refund_percentage": *req.RefundPercentage,
+		"refund_deadline":   req.RefundDeadline,
+	}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update refund policy"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"refund_percentage": *req.RefundPercentage,
+		"refund_deadline":   req.RefundDeadline,
+	})
+}
+
+// RefundOrder lets an organizer refund a purchase for their event in full, regardless of policy
+func (h *RefundHandler) RefundOrder(c *gin.Context) {
+	eventID := c.Param("id")
+	organizerID, _ := middleware.GetUserID(c)
+
+	var req struct {
+		TransactionID uuid.UUID   `json:"transaction_id" binding:"required"`
+		TicketIDs     []uuid.UUID `json:"ticket_ids"`
+		Reason        string      `json:"reason"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var event models.Event
+	if err := h.db.First(&event, "id = ? AND organizer_id = ?", eventID, organizerID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		return
+	}
+
+	var transaction models.Transaction
+	if err := h.db.First(&transaction, "id = ? AND event_id = ? AND type = ?", req.TransactionID, event.ID, models.TransactionTypeTicketPurchase).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Transaction not found"})
+		return
+	}
+
+	h.refund(c, services.RefundRequest{
+		TransactionID: transaction.ID,
+		TicketIDs:     req.TicketIDs,
+		Percentage:    100,
+		Reason:        req.Reason,
+	})
+}
+
+// GetEventRefunds lists refunds issued for an organizer's event
+func (h *RefundHandler) GetEventRefunds(c *gin.Context) {
+	eventID := c.Param("id")
+	organizerID, _ := middleware.GetUserID(c)
+
+	var event models.Event
+	if err := h.db.First(&event, "id = ? AND organizer_id = ?", eventID, organizerID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		return
+	}
+
+	var refunds []models.Transaction
+	if err := h.db.Preload("User").Where("event_id = ? AND type = ?", event.ID, models.TransactionTypeRefund).Order("created_at DESC").Find(&refunds).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch refunds"})
+		return
+	}
+
+	c.JSON(http.StatusOK, refunds)
+}
+
+// AdminRefund refunds any purchase, overriding the event's refund policy
+func (h *RefundHandler) AdminRefund(c *gin.Context) {
+	transactionID := c.Param("id")
+
+	var req struct {
+		RefundTicketsRequest
+		Percentage *float64 `json:"percentage" binding:"omitempty,gt=0,max=100"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var transaction models.Transaction
+	if err := h.db.First(&transaction, "id = ? AND type = ?", transactionID, models.TransactionTypeTicketPurchase).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Transaction not found"})
+		return
+	}
+
+	percentage := 100.0
+	if req.Percentage != nil {
+		percentage = *req.Percentage
+	}
+
+	h.refund(c, services.RefundRequest{
+		TransactionID: transaction.ID,
+		TicketIDs:     req.TicketIDs,
+		Percentage:    percentage,
+		Reason:        req.Reason,
+	})
+}
+
+// refund runs a refund and writes the response
+func (h *RefundHandler) refund(c *gin.Context, req services.RefundRequest) {
+	refund, err := h.refundService.RefundTickets(req)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrRefundNotAllowed), errors.Is(err, services.ErrNothingToRefund):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process refund: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Refund initiated",
+		"refund":  refund,
+	})
+}
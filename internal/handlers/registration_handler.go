@@ -0,0 +1,231 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/warui/event-ticketing-api/internal/config"
+	"github.com/warui/event-ticketing-api/internal/middleware"
+	"github.com/warui/event-ticketing-api/internal/models"
+	"github.com/warui/event-ticketing-api/internal/services"
+	"gorm.io/gorm"
+)
+
+type RegistrationHandler struct {
+	db                  *gorm.DB
+	cfg                 *config.Config
+	registrationService *services.RegistrationService
+}
+
+func NewRegistrationHandler(db *gorm.DB, cfg *config.Config, registrationService *services.RegistrationService) *RegistrationHandler {
+	return &RegistrationHandler{
+		db:                  db,
+		cfg:                 cfg,
+		registrationService: registrationService,
+	}
+}
+
+type RegistrationQuestionRequest struct {
+	Label      string              `json:"label" binding:"required"`
+	Type       models.QuestionType `json:"type" binding:"required,oneof=text select"`
+	Options    []string            `json:"options"`
+	IsRequired bool                `json:"is_required"`
+	Position   int                 `json:"position"`
+}
+
+// apply copies the request onto the question and checks the result
+func (r *RegistrationQuestionRequest) apply(question *models.RegistrationQuestion) error {
+	question.Label = strings.TrimSpace(r.Label)
+	question.Type = r.Type
+	question.Options = nil
+	for _, option := range r.Options {
+		question.Options = append(question.Options, strings.TrimSpace(option))
+	}
+	question.IsRequired = r.IsRequired
+	question.Position = r.Position
+	return question.Validate()
+}
+
+// CreateQuestion adds a registration question to one of the organizer's events
+func (h *RegistrationHandler) CreateQuestion(c *gin.Context) {
+	organizerID, _ := middleware.GetUserID(c)
+
+	var event models.Event
+	if err := h.db.First(&event, "id = ? AND organizer_id = ?", c.Param("id"), organizerID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		return
+	}
+
+	var req RegistrationQuestionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	question := models.RegistrationQuestion{EventID: event.ID}
+	if err := req.apply(&question); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.db.Create(&question).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create question"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, question)
+}
+
+// GetQuestions lists the registration questions of one of the organizer's events
+func (h *RegistrationHandler) GetQuestions(c *gin.Context) {
+	organizerID, _ := middleware.GetUserID(c)
+
+	var event models.Event
+	if err := h.db.First(&event, "id = ? AND organizer_id = ?", c.Param("id"), organizerID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		return
+	}
+
+	var questions []models.RegistrationQuestion
+	if err := h.db.Where("event_id = ?", event.ID).Order("position ASC, created_at ASC").Find(&questions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch questions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, questions)
+}
+
+// UpdateQuestion changes a registration question. Answers already given are kept.
+func (h *RegistrationHandler) UpdateQuestion(c *gin.Context) {
+	question, ok := h.organizerQuestion(c)
+	if !ok {
+		return
+	}
+
+	var req RegistrationQuestionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := req.apply(&question); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.db.Model(&question).Select("label", "type", "options", "is_required", "position").
+		Updates(&question).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update question"})
+		return
+	}
+
+	c.JSON(http.StatusOK, question)
+}
+
+// DeleteQuestion removes a registration question and the answers given to it
+func (h *RegistrationHandler) DeleteQuestion(c *gin.Context) {
+	question, ok := h.organizerQuestion(c)
+	if !ok {
+		return
+	}
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("question_id = ?", question.ID).Delete(&models.TicketAnswer{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&question).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete question"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Question deleted"})
+}
+
+// organizerQuestion loads the question in the path if it belongs to one of the
+// organizer's events, answering 404 otherwise
+func (h *RegistrationHandler) organizerQuestion(c *gin.Context) (models.RegistrationQuestion, bool) {
+	organizerID, _ := middleware.GetUserID(c)
+
+	var question models.RegistrationQuestion
+	if err := h.db.Joins("JOIN events ON events.id = registration_questions.event_id").
+		Where("registration_questions.id = ? AND events.organizer_id = ?", c.Param("id"), organizerID).
+		First(&question).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Question not found"})
+		return question, false
+	}
+	return question, true
+}
+
+// RegisterTicket names the holder of one of the attendee's tickets and records
+// their answers to the event's questions
+func (h *RegistrationHandler) RegisterTicket(c *gin.Context) {
+	attendeeID, _ := middleware.GetUserID(c)
+
+	ticketID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Ticket not found"})
+		return
+	}
+
+	var req services.TicketRegistration
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ticket, err := h.registrationService.RegisterTicket(ticketID, attendeeID, req)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Ticket not found"})
+		return
+	}
+	if errors.Is(err, services.ErrInvalidRegistration) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, services.ErrRegistrationClosed) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save registration"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Registration saved", "ticket": ticket})
+}
+
+// GetEventAttendees exports the holders of an event's tickets with their answers,
+// as JSON or with ?format=csv as a spreadsheet
+func (h *RegistrationHandler) GetEventAttendees(c *gin.Context) {
+	organizerID, _ := middleware.GetUserID(c)
+
+	var event models.Event
+	if err := h.db.First(&event, "id = ? AND organizer_id = ?", c.Param("id"), organizerID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		return
+	}
+
+	rows, questions, err := h.registrationService.Attendees(event.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch attendees"})
+		return
+	}
+
+	if c.Query("format") == "csv" {
+		filename := fmt.Sprintf("attendees-%s-%s.csv", event.ID.String()[:8], time.Now().Format("20060102"))
+		c.Header("Content-Type", "text/csv")
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
+		if err := services.WriteAttendeesCSV(c.Writer, questions, rows); err != nil {
+			c.Error(err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"questions": questions, "attendees": rows})
+}
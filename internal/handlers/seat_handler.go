@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/warui/event-ticketing-api/internal/config"
+	"github.com/warui/event-ticketing-api/internal/middleware"
+	"github.com/warui/event-ticketing-api/internal/models"
+	"github.com/warui/event-ticketing-api/internal/services"
+	"gorm.io/gorm"
+)
+
+type SeatHandler struct {
+	db                *gorm.DB
+	cfg               *config.Config
+	seatService       *services.SeatService
+	accessCodeService *services.AccessCodeService
+}
+
+func NewSeatHandler(db *gorm.DB, cfg *config.Config, seatService *services.SeatService, accessCodeService *services.AccessCodeService) *SeatHandler {
+	return &SeatHandler{
+		db:                db,
+		cfg:               cfg,
+		seatService:       seatService,
+		accessCodeService: accessCodeService,
+	}
+}
+
+// publicSeat is a seat as shown to buyers, without who holds it
+type publicSeat struct {
+	ID           uuid.UUID `json:"id"`
+	TicketTypeID uuid.UUID `json:"ticket_type_id"`
+	Section      string    `json:"section"`
+	Row          string    `json:"row"`
+	Number       string    `json:"number"`
+	Position     int       `json:"position"`
+	Available    bool      `json:"available"`
+}
+
+// UploadSeatMap replaces the seat map of an organizer's event
+func (h *SeatHandler) UploadSeatMap(c *gin.Context) {
+	organizerID, _ := middleware.GetUserID(c)
+
+	var event models.Event
+	if err := h.db.First(&event, "id = ? AND organizer_id = ?", c.Param("id"), organizerID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		return
+	}
+
+	var req struct {
+		Sections []services.SeatMapSection `json:"sections" binding:"dive"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	seats, err := h.seatService.ReplaceSeatMap(event.ID, req.Sections)
+	if errors.Is(err, services.ErrInvalidSeatMap) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, services.ErrSeatMapLocked) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save seat map"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Seat map saved", "seats": seats})
+}
+
+// GetSeatMap lists every seat of an organizer's event with its status and ticket
+func (h *SeatHandler) GetSeatMap(c *gin.Context) {
+	organizerID, _ := middleware.GetUserID(c)
+
+	var event models.Event
+	if err := h.db.First(&event, "id = ? AND organizer_id = ?", c.Param("id"), organizerID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		return
+	}
+
+	query := h.db.Where("event_id = ?", event.ID)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var seats []models.Seat
+	if err := query.Order("position ASC").Find(&seats).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch seats"})
+		return
+	}
+
+	c.JSON(http.StatusOK, seats)
+}
+
+// GetEventSeats shows buyers the seat map of a published event. Seats of hidden
+// ticket types are included only when ?access_code= unlocks them.
+func (h *SeatHandler) GetEventSeats(c *gin.Context) {
+	var event models.Event
+	if err := h.db.Preload("TicketTypes").First(&event, "id = ? AND status = ?", c.Param("id"), models.EventStatusPublished).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		return
+	}
+
+	var accessCode *models.AccessCode
+	if code := c.Query("access_code"); code != "" {
+		var err error
+		accessCode, err = h.accessCodeService.Find(event.ID, code, time.Now())
+		if errors.Is(err, services.ErrAccessCodeInvalid) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Access code is not valid"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check access code"})
+			return
+		}
+	}
+
+	var visible []uuid.UUID
+	for _, ticketType := range event.TicketTypes {
+		if !ticketType.IsHidden || (accessCode != nil && accessCode.Unlocks(ticketType.ID)) {
+			visible = append(visible, ticketType.ID)
+		}
+	}
+
+	var seats []models.Seat
+	if err := h.db.Where("event_id = ? AND ticket_type_id IN ?", event.ID, visible).
+		Order("position ASC").Find(&seats).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch seats"})
+		return
+	}
+
+	response := make([]publicSeat, len(seats))
+	for i, seat := range seats {
+		response[i] = publicSeat{
+			ID:           seat.ID,
+			TicketTypeID: seat.TicketTypeID,
+			Section:      seat.Section,
+			Row:          seat.Row,
+			Number:       seat.Number,
+			Position:     seat.Position,
+			Available:    seat.Status == models.SeatStatusAvailable,
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/warui/event-ticketing-api/internal/config"
+	"github.com/warui/event-ticketing-api/internal/middleware"
+	"github.com/warui/event-ticketing-api/internal/models"
+	"github.com/warui/event-ticketing-api/internal/services"
+	"gorm.io/gorm"
+)
+
+type SessionHandler struct {
+	db             *gorm.DB
+	cfg            *config.Config
+	sessionService *services.SessionService
+}
+
+func NewSessionHandler(db *gorm.DB, cfg *config.Config, sessionService *services.SessionService) *SessionHandler {
+	return &SessionHandler{
+		db:             db,
+		cfg:            cfg,
+		sessionService: sessionService,
+	}
+}
+
+// AddSessions adds sessions to one of the organizer's events, either listed one by
+// one or generated from a recurrence rule
+func (h *SessionHandler) AddSessions(c *gin.Context) {
+	organizerID, _ := middleware.GetUserID(c)
+
+	var event models.Event
+	if err := h.db.First(&event, "id = ? AND organizer_id = ?", c.Param("id"), organizerID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		return
+	}
+
+	var req struct {
+		Sessions   []services.SessionInput  `json:"sessions" binding:"dive"`
+		Recurrence *services.RecurrenceRule `json:"recurrence"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if (len(req.Sessions) > 0) == (req.Recurrence != nil) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Give either sessions or a recurrence rule"})
+		return
+	}
+
+	inputs := req.Sessions
+	if req.Recurrence != nil {
+		var err error
+		if inputs, err = req.Recurrence.Sessions(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	sessions, err := h.sessionService.AddSessions(event.ID, inputs)
+	if err != nil {
+		h.sessionError(c, err, "Failed to add sessions")
+		return
+	}
+
+	c.JSON(http.StatusCreated, sessions)
+}
+
+// GetSessions lists the sessions of one of the organizer's events with their ticket types
+func (h *SessionHandler) GetSessions(c *gin.Context) {
+	organizerID, _ := middleware.GetUserID(c)
+
+	var event models.Event
+	if err := h.db.First(&event, "id = ? AND organizer_id = ?", c.Param("id"), organizerID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		return
+	}
+
+	var sessions []models.EventSession
+	if err := h.db.Preload("TicketTypes", func(db *gorm.DB) *gorm.DB {
+		return db.Order(services.TicketTypeOrder)
+	}).Where("event_id = ?", event.ID).
+		Order("start_date ASC").Find(&sessions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch sessions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, sessions)
+}
+
+// UpdateSession changes one session of a series
+func (h *SessionHandler) UpdateSession(c *gin.Context) {
+	session, ok := h.organizerSession(c)
+	if !ok {
+		return
+	}
+
+	var req services.SessionInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updated, err := h.sessionService.UpdateSession(session.ID, req)
+	if err != nil {
+		h.sessionError(c, err, "Failed to update session")
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// DeleteSession removes a session that has no sales
+func (h *SessionHandler) DeleteSession(c *gin.Context) {
+	session, ok := h.organizerSession(c)
+	if !ok {
+		return
+	}
+
+	if err := h.sessionService.DeleteSession(session.ID); err != nil {
+		h.sessionError(c, err, "Failed to delete session")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Session deleted"})
+}
+
+// organizerSession loads the session in the path if it belongs to one of the
+// organizer's events, answering 404 otherwise
+func (h *SessionHandler) organizerSession(c *gin.Context) (models.EventSession, bool) {
+	organizerID, _ := middleware.GetUserID(c)
+
+	var session models.EventSession
+	if err := h.db.Joins("JOIN events ON events.id = event_sessions.event_id").
+		Where("event_sessions.id = ? AND events.organizer_id = ?", c.Param("id"), organizerID).
+		First(&session).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return session, false
+	}
+	return session, true
+}
+
+// sessionError writes the response for a failed session change
+func (h *SessionHandler) sessionError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrInvalidSession):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrSessionLocked):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
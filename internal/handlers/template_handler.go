@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/warui/event-ticketing-api/internal/config"
+	"github.com/warui/event-ticketing-api/internal/middleware"
+	"github.com/warui/event-ticketing-api/internal/models"
+	"github.com/warui/event-ticketing-api/internal/services"
+	"gorm.io/gorm"
+)
+
+type EventTemplateHandler struct {
+	db              *gorm.DB
+	cfg             *config.Config
+	templateService *services.EventTemplateService
+}
+
+func NewEventTemplateHandler(db *gorm.DB, cfg *config.Config, templateService *services.EventTemplateService) *EventTemplateHandler {
+	return &EventTemplateHandler{
+		db:              db,
+		cfg:             cfg,
+		templateService: templateService,
+	}
+}
+
+// CloneEvent copies one of the organizer's events into a new draft, moved by a
+// number of days or to a new start date
+func (h *EventTemplateHandler) CloneEvent(c *gin.Context) {
+	organizerID, _ := middleware.GetUserID(c)
+
+	var req struct {
+		OffsetDays *int       `json:"offset_days"`
+		StartDate  *time.Time `json:"start_date"`
+		Title      string     `json:"title"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if (req.OffsetDays != nil) == (req.StartDate != nil) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Give either offset_days or start_date"})
+		return
+	}
+
+	var event models.Event
+	if err := h.db.First(&event, "id = ? AND organizer_id = ?", c.Param("id"), organizerID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		return
+	}
+
+	start := event.StartDate
+	if req.OffsetDays != nil {
+		start = start.AddDate(0, 0, *req.OffsetDays)
+	} else {
+		start = *req.StartDate
+	}
+
+	clone, err := h.templateService.CloneEvent(event.ID, organizerID, start, req.Title)
+	if err != nil {
+		h.templateError(c, err, "Failed to clone event")
+		return
+	}
+
+	c.JSON(http.StatusCreated, clone)
+}
+
+// SaveTemplate saves one of the organizer's events as a reusable template
+func (h *EventTemplateHandler) SaveTemplate(c *gin.Context) {
+	organizerID, _ := middleware.GetUserID(c)
+
+	eventID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		return
+	}
+
+	var req struct {
+		Name string `json:"name" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	template, err := h.templateService.SaveTemplate(eventID, organizerID, req.Name)
+	if err != nil {
+		h.templateError(c, err, "Failed to save template")
+		return
+	}
+
+	c.JSON(http.StatusCreated, template)
+}
+
+// GetTemplates lists the organizer's templates
+func (h *EventTemplateHandler) GetTemplates(c *gin.Context) {
+	organizerID, _ := middleware.GetUserID(c)
+
+	var templates []models.EventTemplate
+	if err := h.db.Where("organizer_id = ?", organizerID).Order("created_at DESC").Find(&templates).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch templates"})
+		return
+	}
+
+	c.JSON(http.StatusOK, templates)
+}
+
+// DeleteTemplate removes one of the organizer's templates. Events created from it are kept.
+func (h *EventTemplateHandler) DeleteTemplate(c *gin.Context) {
+	organizerID, _ := middleware.GetUserID(c)
+
+	result := h.db.Where("id = ? AND organizer_id = ?", c.Param("id"), organizerID).Delete(&models.EventTemplate{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete template"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Template not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Template deleted"})
+}
+
+// CreateFromTemplate creates a draft event from one of the organizer's templates
+func (h *EventTemplateHandler) CreateFromTemplate(c *gin.Context) {
+	organizerID, _ := middleware.GetUserID(c)
+
+	templateID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Template not found"})
+		return
+	}
+
+	var req struct {
+		StartDate time.Time `json:"start_date" binding:"required"`
+		Title     string    `json:"title"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	event, err := h.templateService.CreateFromTemplate(templateID, organizerID, req.StartDate, req.Title)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Template not found"})
+			return
+		}
+		h.templateError(c, err, "Failed to create event")
+		return
+	}
+
+	c.JSON(http.StatusCreated, event)
+}
+
+// templateError writes the response for a failed copy
+func (h *EventTemplateHandler) templateError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrInvalidEventCopy):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
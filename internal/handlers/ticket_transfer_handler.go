@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/warui/event-ticketing-api/internal/config"
+	"github.com/warui/event-ticketing-api/internal/middleware"
+	"github.com/warui/event-ticketing-api/internal/models"
+	"github.com/warui/event-ticketing-api/internal/services"
+	"gorm.io/gorm"
+)
+
+type TicketTransferHandler struct {
+	db              *gorm.DB
+	cfg             *config.Config
+	transferService *services.TicketTransferService
+}
+
+func NewTicketTransferHandler(db *gorm.DB, cfg *config.Config, transferService *services.TicketTransferService) *TicketTransferHandler {
+	return &TicketTransferHandler{
+		db:              db,
+		cfg:             cfg,
+		transferService: transferService,
+	}
+}
+
+// RequestTransfer offers one of the attendee's tickets to someone else by email
+func (h *TicketTransferHandler) RequestTransfer(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	ticketID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Ticket not found"})
+		return
+	}
+
+	var req struct {
+		Email   string `json:"email" binding:"required,email"`
+		Message string `json:"message" binding:"max=500"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	transfer, err := h.transferService.RequestTransfer(ticketID, userID, req.Email, req.Message)
+	if err != nil {
+		h.transferError(c, err, "Ticket not found")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":  "Transfer sent",
+		"transfer": transfer,
+	})
+}
+
+// GetMyTransfers lists the transfers the user has sent and those offered to their email
+func (h *TicketTransferHandler) GetMyTransfers(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	var user models.User
+	if err := h.db.First(&user, "id = ?", userID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	// The ticket itself is left out: once accepted it carries the recipient's QR code
+	var sent []models.TicketTransfer
+	if err := h.db.Preload("Event").
+		Where("from_user_id = ?", userID).Order("created_at DESC").Find(&sent).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch transfers"})
+		return
+	}
+
+	var received []models.TicketTransfer
+	if err := h.db.Preload("Event").Preload("FromUser").
+		Where("to_email = ?", strings.ToLower(user.Email)).Order("created_at DESC").Find(&received).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch transfers"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"sent":     sent,
+		"received": received,
+	})
+}
+
+// AcceptTransfer moves an offered ticket to the signed-in recipient
+func (h *TicketTransferHandler) AcceptTransfer(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	transferID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Transfer not found"})
+		return
+	}
+
+	transfer, err := h.transferService.AcceptTransfer(transferID, userID)
+	if err != nil {
+		h.transferError(c, err, "Transfer not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Ticket transferred to you. Your ticket is being sent to your email.",
+		"transfer": transfer,
+	})
+}
+
+// DeclineTransfer turns down a ticket offered to the signed-in user
+func (h *TicketTransferHandler) DeclineTransfer(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	transferID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Transfer not found"})
+		return
+	}
+
+	transfer, err := h.transferService.DeclineTransfer(transferID, userID)
+	if err != nil {
+		h.transferError(c, err, "Transfer not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Transfer declined",
+		"transfer": transfer,
+	})
+}
+
+// CancelTransfer withdraws a transfer the user sent before it is accepted
+func (h *TicketTransferHandler) CancelTransfer(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	transferID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Transfer not found"})
+		return
+	}
+
+	transfer, err := h.transferService.CancelTransfer(transferID, userID)
+	if err != nil {
+		h.transferError(c, err, "Transfer not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Transfer cancelled",
+		"transfer": transfer,
+	})
+}
+
+// SetTransferPolicy turns ticket transfers on or off for an event and sets the cutoff
+func (h *TicketTransferHandler) SetTransferPolicy(c *gin.Context) {
+	eventID := c.Param("id")
+	organizerID, _ := middleware.GetUserID(c)
+
+	var req struct {
+		AllowTransfers   *bool      `json:"allow_transfers" binding:"required"`
+		TransferDeadline *time.Time `json:"transfer_deadline"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var event models.Event
+	if err := h.db.First(&event, "id = ? AND organizer_id = ?", eventID, organizerID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		return
+	}
+
+	if req.TransferDeadline != nil && req.TransferDeadline.After(event.StartDate) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Transfer deadline must be before the event starts"})
+		return
+	}
+
+	if err := h.db.Model(&event).Updates(map[string]interface{}{
+		"allow_transfers":   *req.AllowTransfers,
+		"transfer_deadline": req.TransferDeadline,
+	}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update transfer policy"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"allow_transfers":   *req.AllowTransfers,
+		"transfer_deadline": req.TransferDeadline,
+	})
+}
+
+// transferError writes the response for a failed transfer action
+func (h *TicketTransferHandler) transferError(c *gin.Context, err error, notFound string) {
+	switch {
+	case errors.Is(err, services.ErrTransferNotAllowed):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": notFound})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process transfer"})
+	}
+}
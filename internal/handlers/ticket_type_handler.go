@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/warui/event-ticketing-api/internal/config"
+	"github.com/warui/event-ticketing-api/internal/middleware"
+	"github.com/warui/event-ticketing-api/internal/models"
+	"github.com/warui/event-ticketing-api/internal/services"
+	"gorm.io/gorm"
+)
+
+type TicketTypeHandler struct {
+	db                *gorm.DB
+	cfg               *config.Config
+	ticketTypeService *services.TicketTypeService
+}
+
+func NewTicketTypeHandler(db *gorm.DB, cfg *config.Config, ticketTypeService *services.TicketTypeService) *TicketTypeHandler {
+	return &TicketTypeHandler{
+		db:                db,
+		cfg:               cfg,
+		ticketTypeService: ticketTypeService,
+	}
+}
+
+// UpdateTicketType changes a ticket type of one of the organizer's events
+func (h *TicketTypeHandler) UpdateTicketType(c *gin.Context) {
+	ticketType, ok := h.organizerTicketType(c)
+	if !ok {
+		return
+	}
+
+	var req services.TicketTypeUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	organizerID, _ := middleware.GetUserID(c)
+	updated, err := h.ticketTypeService.UpdateTicketType(ticketType.ID, organizerID, req)
+	if err != nil {
+		h.ticketTypeError(c, err, "Failed to update ticket type")
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// DeactivateTicketType stops sales of a ticket type
+func (h *TicketTypeHandler) DeactivateTicketType(c *gin.Context) {
+	h.setActive(c, false)
+}
+
+// ActivateTicketType resumes sales of a deactivated ticket type
+func (h *TicketTypeHandler) ActivateTicketType(c *gin.Context) {
+	h.setActive(c, true)
+}
+
+func (h *TicketTypeHandler) setActive(c *gin.Context, active bool) {
+	ticketType, ok := h.organizerTicketType(c)
+	if !ok {
+		return
+	}
+
+	updated, err := h.ticketTypeService.SetActive(ticketType.ID, active)
+	if err != nil {
+		h.ticketTypeError(c, err, "Failed to update ticket type")
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// DeleteTicketType removes a ticket type that has no sales
+func (h *TicketTypeHandler) DeleteTicketType(c *gin.Context) {
+	ticketType, ok := h.organizerTicketType(c)
+	if !ok {
+		return
+	}
+
+	if err := h.ticketTypeService.DeleteTicketType(ticketType.ID); err != nil {
+		h.ticketTypeError(c, err, "Failed to delete ticket type")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Ticket type deleted"})
+}
+
+// ReorderTicketTypes sets the order an event's ticket types are listed in
+func (h *TicketTypeHandler) ReorderTicketTypes(c *gin.Context) {
+	organizerID, _ := middleware.GetUserID(c)
+
+	var event models.Event
+	if err := h.db.First(&event, "id = ? AND organizer_id = ?", c.Param("id"), organizerID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		return
+	}
+
+	var req struct {
+		TicketTypeIDs []uuid.UUID `json:"ticket_type_ids" binding:"required,min=1"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ticketTypes, err := h.ticketTypeService.ReorderTicketTypes(event.ID, req.TicketTypeIDs)
+	if err != nil {
+		h.ticketTypeError(c, err, "Failed to reorder ticket types")
+		return
+	}
+
+	c.JSON(http.StatusOK, ticketTypes)
+}
+
+// GetPriceChanges lists the recorded price changes of one of the organizer's events
+func (h *TicketTypeHandler) GetPriceChanges(c *gin.Context) {
+	organizerID, _ := middleware.GetUserID(c)
+
+	var event models.Event
+	if err := h.db.First(&event, "id = ? AND organizer_id = ?", c.Param("id"), organizerID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		return
+	}
+
+	var changes []models.TicketPriceChange
+	if err := h.db.Where("event_id = ?", event.ID).Order("created_at DESC").Find(&changes).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch price changes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, changes)
+}
+
+// organizerTicketType loads the ticket type in the path if it belongs to one of the
+// organizer's events, answering 404 otherwise
+func (h *TicketTypeHandler) organizerTicketType(c *gin.Context) (models.TicketType, bool) {
+	organizerID, _ := middleware.GetUserID(c)
+
+	var ticketType models.TicketType
+	if err := h.db.Joins("JOIN events ON events.id = ticket_types.event_id").
+		Where("ticket_types.id = ? AND events.organizer_id = ?", c.Param("id"), organizerID).
+		First(&ticketType).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Ticket type not found"})
+		return ticketType, false
+	}
+	return ticketType, true
+}
+
+// ticketTypeError writes the response for a failed ticket type change
+func (h *TicketTypeHandler) ticketTypeError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrInvalidTicketType):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrTicketTypeLocked):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Ticket type not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
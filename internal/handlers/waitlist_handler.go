@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/warui/event-ticketing-api/internal/config"
+	"github.com/warui/event-ticketing-api/internal/middleware"
+	"github.com/warui/event-ticketing-api/internal/models"
+	"github.com/warui/event-ticketing-api/internal/services"
+	"gorm.io/gorm"
+)
+
+type WaitlistHandler struct {
+	db              *gorm.DB
+	cfg             *config.Config
+	waitlistService *services.WaitlistService
+}
+
+func NewWaitlistHandler(db *gorm.DB, cfg *config.Config, waitlistService *services.WaitlistService) *WaitlistHandler {
+	return &WaitlistHandler{
+		db:              db,
+		cfg:             cfg,
+		waitlistService: waitlistService,
+	}
+}
+
+// waitlistEntryResponse is a waitlist entry with its place in the queue
+type waitlistEntryResponse struct {
+	models.WaitlistEntry
+	Position int64 `json:"position,omitempty"` // 1 is next in line; only set while waiting
+}
+
+// JoinWaitlist queues the attendee for a sold-out ticket type
+func (h *WaitlistHandler) JoinWaitlist(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	var req struct {
+		TicketTypeID uuid.UUID `json:"ticket_type_id" binding:"required"`
+		Quantity     int       `json:"quantity" binding:"required,min=1"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	entry, err := h.waitlistService.Join(req.TicketTypeID, userID, req.Quantity)
+	if errors.Is(err, services.ErrWaitlistUnavailable) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Ticket type not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to join waitlist"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, waitlistEntryResponse{WaitlistEntry: *entry, Position: h.position(entry)})
+}
+
+// GetMyWaitlist lists the attendee's waitlist entries with their place in each queue
+func (h *WaitlistHandler) GetMyWaitlist(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	var entries []models.WaitlistEntry
+	if err := h.db.Preload("Event").Preload("TicketType").
+		Where("user_id = ?", userID).Order("created_at DESC").Find(&entries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch waitlist"})
+		return
+	}
+
+	response := make([]waitlistEntryResponse, len(entries))
+	for i := range entries {
+		response[i] = waitlistEntryResponse{WaitlistEntry: entries[i], Position: h.position(&entries[i])}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// LeaveWaitlist takes the attendee off a waitlist, giving up any offer they hold
+func (h *WaitlistHandler) LeaveWaitlist(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	entryID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Waitlist entry not found"})
+		return
+	}
+
+	err = h.waitlistService.Leave(entryID, userID)
+	if errors.Is(err, services.ErrWaitlistUnavailable) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Waitlist entry not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to leave waitlist"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Removed from waitlist"})
+}
+
+// GetEventWaitlist lists the waitlist of an organizer's event, optionally for one ticket type
+func (h *WaitlistHandler) GetEventWaitlist(c *gin.Context) {
+	organizerID, _ := middleware.GetUserID(c)
+
+	var event models.Event
+	if err := h.db.First(&event, "id = ? AND organizer_id = ?", c.Param("id"), organizerID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		return
+	}
+
+	query := h.db.Preload("User").Preload("TicketType").Where("event_id = ?", event.ID)
+	if ticketTypeID := c.Query("ticket_type_id"); ticketTypeID != "" {
+		query = query.Where("ticket_type_id = ?", ticketTypeID)
+	}
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var entries []models.WaitlistEntry
+	if err := query.Order("created_at ASC").Find(&entries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch waitlist"})
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+// position counts the waiting entries queued ahead of a waiting entry, plus itself
+func (h *WaitlistHandler) position(entry *models.WaitlistEntry) int64 {
+	if entry.Status != models.WaitlistStatusWaiting {
+		return 0
+	}
+
+	var ahead int64
+	h.db.Model(&models.WaitlistEntry{}).
+		Where("ticket_type_id = ? AND status = ? AND (created_at < ? OR (created_at = ? AND id < ?))",
+			entry.TicketTypeID, models.WaitlistStatusWaiting, entry.CreatedAt, entry.CreatedAt, entry.ID).
+		Count(&ahead)
+	return ahead + 1
+}
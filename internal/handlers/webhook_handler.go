@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/warui/event-ticketing-api/internal/config"
+	"github.com/warui/event-ticketing-api/internal/models"
+	"github.com/warui/event-ticketing-api/internal/services"
+	"gorm.io/gorm"
+)
+
+type WebhookHandler struct {
+	db              *gorm.DB
+	cfg             *config.Config
+	paymentGateways *services.PaymentGateways
+	orderService    *services.OrderService
+	refundService   *services.RefundService
+	payoutService   *services.PayoutService
+	emailService    *services.EmailService
+}
+
+func NewWebhookHandler(db *gorm.DB, cfg *config.Config, paymentGateways *services.PaymentGateways, orderService *services.OrderService, refundService *services.RefundService, payoutService *services.PayoutService, emailService *services.EmailService) *WebhookHandler {
+	return &WebhookHandler{
+		db:              db,
+		cfg:             cfg,
+		paymentGateways: paymentGateways,
+		orderService:    orderService,
+		refundService:   refundService,
+		payoutService:   payoutService,
+		emailService:    emailService,
+	}
+}
+
+// PaystackWebhook receives event notifications from Paystack on the original webhook URL
+func (h *WebhookHandler) PaystackWebhook(c *gin.Context) {
+	h.handleWebhook(c, "paystack")
+}
+
+// ProviderWebhook receives event notifications from the provider named in the URL
+func (h *WebhookHandler) ProviderWebhook(c *gin.Context) {
+	h.handleWebhook(c, c.Param("provider"))
+}
+
+// handleWebhook authenticates and processes one webhook delivery. Unauthenticated
+// requests are rejected. Handled events are acknowledged with 200; a 500 makes the
+// provider retry the delivery later.
+func (h *WebhookHandler) handleWebhook(c *gin.Context, providerName string) {
+	provider, err := h.paymentGateways.Get(providerName)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unknown payment provider"})
+		return
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+
+	event, err := provider.ParseWebhook(body, c.Request.Header)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidWebhookSignature) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid signature"})
+			return
+		}
+		if errors.Is(err, services.ErrWebhookVerificationFailed) {
+			c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to verify event with payment provider"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	switch event.Type {
+	case services.WebhookPaymentSucceeded:
+		err = h.handlePaymentSucceeded(provider, event.Payment)
+	case services.WebhookRefundProcessed, services.WebhookRefundFailed:
+		err = h.handleRefundEvent(provider, event)
+	case services.WebhookTransferSucceeded, services.WebhookTransferFailed:
+		err = h.handleTransferEvent(provider, event)
+	default:
+		log.Printf("Ignoring %s webhook event %s", provider.Name(), event.ProviderType)
+	}
+
+	if err != nil {
+		log.Printf("Failed to handle %s webhook %s: %v", provider.Name(), event.ProviderType, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process webhook"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// handlePaymentSucceeded fulfils the order through the same path as the payment redirect.
+// Only purchases made through the provider are matched; other providers' webhooks
+// cannot settle them.
+func (h *WebhookHandler) handlePaymentSucceeded(provider services.PaymentProvider, payment *services.PaymentResult) error {
+	var transaction models.Transaction
+	if err := h.db.First(&transaction, "payment_reference = ? AND type = ? AND payment_gateway IN ?", payment.Reference,
+		models.TransactionTypeTicketPurchase, services.StoredGatewayNames(provider.Name())).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Printf("%s payment %s does not match any transaction", provider.Name(), payment.Reference)
+			return nil
+		}
+		return err
+	}
+
+	if _, err := h.orderService.FulfillPayment(&transaction, payment); err != nil {
+		if errors.Is(err, services.ErrPaymentMismatch) || errors.Is(err, services.ErrInsufficientStock) || errors.Is(err, services.ErrSeatUnavailable) ||
+			errors.Is(err, services.ErrEventCancelled) || errors.Is(err, services.ErrPaymentRefunded) {
+			log.Printf("%s payment %s rejected: %v", provider.Name(), payment.Reference, err)
+			return nil
+		}
+		return err
+	}
+
+	return nil
+}
+
+// handleRefundEvent settles refunds created through the refund workflow and paid through the provider
+func (h *WebhookHandler) handleRefundEvent(provider services.PaymentProvider, event *services.WebhookEvent) error {
+	refund := event.Refund
+
+	var err error
+	if event.Type == services.WebhookRefundFailed {
+		err = h.refundService.FailRefund(provider.Name(), refund.ID, fmt.Sprintf("Refund failed at %s", provider.Name()))
+	} else {
+		err = h.refundService.CompleteRefund(provider.Name(), refund.ID)
+	}
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Printf("%s refund %s for %s does not match any refund", provider.Name(), refund.ID, refund.PaymentReference)
+		return nil
+	}
+	return err
+}
+
+// handleTransferEvent settles withdrawals paid out through the provider's transfers.
+// A failed or reversed transfer returns the amount to the organizer.
+func (h *WebhookHandler) handleTransferEvent(provider services.PaymentProvider, event *services.WebhookEvent) error {
+	transfer := event.Transfer
+
+	var withdrawal models.WithdrawalRequest
+	if err := h.db.Preload("Organizer").First(&withdrawal, "transaction_ref = ? AND payment_gateway IN ?", transfer.Reference,
+		services.StoredGatewayNames(provider.Name())).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Printf("%s transfer %s does not match any withdrawal", provider.Name(), transfer.Reference)
+			return nil
+		}
+		return err
+	}
+
+	var changed bool
+	var err error
+	if event.Type == services.WebhookTransferFailed {
+		changed, err = h.payoutService.FailWithdrawal(&withdrawal, fmt.Sprintf("Transfer %s at %s", transfer.Status, provider.Name()))
+	} else {
+		changed, err = h.payoutService.CompleteWithdrawal(&withdrawal, transfer.Reference)
+	}
+	if err != nil {
+		return err
+	}
+	if changed {
+		go h.emailService.SendWithdrawalStatusEmail(&withdrawal, &withdrawal.Organizer)
+	}
+
+	return nil
+}
@@ -0,0 +1,106 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/warui/event-ticketing-api/internal/config"
+	"github.com/warui/event-ticketing-api/internal/services"
+)
+
+// Start launches the background jobs on the service graph the routes use. They
+// stop when ctx is cancelled.
+func Start(ctx context.Context, cfg *config.Config, svc *services.Services) {
+	go every(ctx, time.Minute, "release expired inventory holds", func() error {
+		released, err := svc.Inventory.ReleaseExpiredHolds(100)
+		if released > 0 {
+			log.Printf("Released %d expired inventory holds", released)
+		}
+		return err
+	})
+
+	// Stock freed by refunds, cancellations, expired holds or new quantity goes to
+	// waitlists first
+	go every(ctx, time.Minute, "offer tickets to waitlists", func() error {
+		offered, err := svc.Waitlist.ProcessOffers(100)
+		if offered > 0 {
+			log.Printf("Offered tickets to %d waitlisted buyers", offered)
+		}
+		return err
+	})
+
+	// Approved events scheduled for later go live once their publish time comes
+	go every(ctx, time.Minute, "publish scheduled events", func() error {
+		published, err := svc.Lifecycle.PublishScheduled(100)
+		if published > 0 {
+			log.Printf("Published %d scheduled events", published)
+		}
+		return err
+	})
+
+	go every(ctx, 5*time.Minute, "retry ticket delivery", func() error {
+		delivered, err := svc.Order.RetryPendingDeliveries(50)
+		if delivered > 0 {
+			log.Printf("Delivered tickets for %d transactions", delivered)
+		}
+		return err
+	})
+
+	// Payments that went through after the event was cancelled or sold out are returned
+	go every(ctx, time.Minute, "refund unfulfilled payments", func() error {
+		sent, err := svc.Refund.ProcessUnfulfilledRefunds(50)
+		if sent > 0 {
+			log.Printf("Sent %d refunds of unfulfilled payments", sent)
+		}
+		return err
+	})
+
+	go every(ctx, 30*time.Second, "process event cancellations", func() error {
+		return svc.Cancellation.ProcessPending(25)
+	})
+
+	// Holders of events whose details changed get new tickets
+	go every(ctx, 30*time.Second, "notify holders of event changes", func() error {
+		return svc.Change.ProcessPending(25)
+	})
+
+	// Purchases still pending after their checkout hold expired are checked with the gateway
+	go every(ctx, 5*time.Minute, "reconcile pending payments", func() error {
+		result, err := svc.Reconciliation.ReconcilePending(cfg.CheckoutHoldDuration, cfg.PaymentAbandonTimeout, 50)
+		if result != nil && result.Fulfilled+result.Failed > 0 {
+			log.Printf("Reconciled pending payments: %d fulfilled, %d failed", result.Fulfilled, result.Failed)
+		}
+		return err
+	})
+
+	// Yesterday's report is built once; later runs skip it
+	go every(ctx, time.Hour, "daily reconciliation report", func() error {
+		reports, err := svc.Reconciliation.BuildDailyReports(time.Now().AddDate(0, 0, -1))
+		for _, report := range reports {
+			if report.MismatchCount > 0 {
+				log.Printf("Reconciliation report %s for %s on %s has %d mismatches",
+					report.ID, report.PaymentGateway, report.Date.Format("2006-01-02"), report.MismatchCount)
+			}
+		}
+		return err
+	})
+}
+
+// every runs fn on a fixed interval until ctx is cancelled, logging failures
+func every(ctx context.Context, interval time.Duration, name string, fn func() error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := fn(); err != nil {
+			log.Printf("Job %q failed: %v", name, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AccessCode unlocks hidden ticket types of an event: they are shown and sold only
+// to buyers who enter the code. A zero MaxUses means no limit.
+type AccessCode struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	EventID   uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_access_code_event" json:"event_id"`
+	Code      string     `gorm:"not null;uniqueIndex:idx_access_code_event" json:"code"` // Stored upper case
+	Label     string     `json:"label,omitempty"`                                        // Who the code was given to, e.g. "Press"
+	MaxUses   int        `gorm:"default:0" json:"max_uses"`                              // Orders, not tickets
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	CreatedBy uuid.UUID  `gorm:"type:uuid;not null" json:"created_by"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+
+	// Relationships
+	TicketTypes []TicketType `gorm:"many2many:access_code_ticket_types" json:"ticket_types,omitempty"`
+}
+
+// IsValidAt reports whether the code is neither revoked nor expired
+func (a *AccessCode) IsValidAt(now time.Time) bool {
+	if a.RevokedAt != nil {
+		return false
+	}
+	return a.ExpiresAt == nil || now.Before(*a.ExpiresAt)
+}
+
+// Unlocks reports whether the code unlocks the given ticket type
+func (a *AccessCode) Unlocks(ticketTypeID uuid.UUID) bool {
+	for _, ticketType := range a.TicketTypes {
+		if ticketType.ID == ticketTypeID {
+			return true
+		}
+	}
+	return false
+}
@@ -34,6 +34,10 @@ type Event struct {
 	Status      EventStatus `gorm:"type:varchar(20);not null;default:'draft'" json:"status"`
 	IsFeatured  bool        `gorm:"default:false" json:"is_featured"`
 
+	// Publishing: on approval, or at PublishAt once approved
+	PublishMode PublishMode `gorm:"type:varchar(20);not null;default:'on_approval'" json:"publish_mode"`
+	PublishAt   *time.Time  `json:"publish_at,omitempty"`
+
 	OrganizerID uuid.UUID `gorm:"type:uuid;not null" json:"organizer_id"`
 	Organizer   User      `gorm:"foreignKey:OrganizerID" json:"organizer,omitempty"`
 
@@ -43,6 +47,16 @@ type Event struct {
 	ModerationComment string     `gorm:"type:text" json:"moderation_comment,omitempty"`
 	ModeratedAt       *time.Time `json:"moderated_at,omitempty"`
 
+	// Refund policy: attendees may request RefundPercentage of the ticket price
+	// back until RefundDeadline. A zero percentage means no self-service refunds.
+	RefundPercentage float64    `gorm:"default:0" json:"refund_percentage"`
+	RefundDeadline   *time.Time `json:"refund_deadline,omitempty"`
+
+	// Transfer policy: attendees may pass tickets on to someone else until
+	// TransferDeadline, or until the event starts when there is no deadline
+	AllowTransfers   bool       `gorm:"default:true" json:"allow_transfers"`
+	TransferDeadline *time.Time `json:"transfer_deadline,omitempty"`
+
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
@@ -50,6 +64,13 @@ type Event struct {
 	// Relationships
 	TicketTypes []TicketType `gorm:"foreignKey:EventID" json:"ticket_types,omitempty"`
 	Tickets     []Ticket     `gorm:"foreignKey:EventID" json:"tickets,omitempty"`
+
+	// Asked of every ticket holder, at checkout or afterwards
+	Questions []RegistrationQuestion `gorm:"foreignKey:EventID" json:"questions,omitempty"`
+
+	// Occurrences of a recurring or multi-session event; StartDate and EndDate
+	// then span the whole series
+	Sessions []EventSession `gorm:"foreignKey:EventID" json:"sessions,omitempty"`
 }
 
 func (e *Event) BeforeCreate(tx *gorm.DB) error {
@@ -59,20 +80,49 @@ func (e *Event) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
+// RefundPercentageAt returns the share of the ticket price the refund policy
+// allows attendees to claim at the given time, or 0 if refunds are closed
+func (e *Event) RefundPercentageAt(now time.Time) float64 {
+	if e.RefundPercentage <= 0 || !now.Before(e.StartDate) {
+		return 0
+	}
+	if e.RefundDeadline != nil && now.After(*e.RefundDeadline) {
+		return 0
+	}
+	return e.RefundPercentage
+}
+
+// TransfersOpenAt reports whether tickets for the event can change hands at the given time
+func (e *Event) TransfersOpenAt(now time.Time) bool {
+	if !e.AllowTransfers || e.Status == EventStatusCancelled || !now.Before(e.StartDate) {
+		return false
+	}
+	return e.TransferDeadline == nil || now.Before(*e.TransferDeadline)
+}
+
 type TicketType struct {
 	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
 	EventID     uuid.UUID `gorm:"type:uuid;not null" json:"event_id"`
 	Name        string    `gorm:"not null" json:"name"`
 	Description string    `gorm:"type:text" json:"description"`
-	Price       float64   `gorm:"not null" json:"price"`
+	Price       int64     `gorm:"not null" json:"price"` // Minor units
 	Quantity    int       `gorm:"not null" json:"quantity"`
 	Sold        int       `gorm:"default:0" json:"sold"`
+	Reserved    int       `gorm:"default:0" json:"reserved"` // Held by checkouts awaiting payment
 	MaxPerOrder int       `gorm:"default:10" json:"max_per_order"`
 	SaleStart   time.Time `json:"sale_start"`
 	SaleEnd     time.Time `json:"sale_end"`
 	IsActive    bool      `gorm:"default:true" json:"is_active"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	IsHidden    bool      `gorm:"default:false" json:"is_hidden"` // Shown and sold only with an access code
+
+	ReservedSeating bool `gorm:"default:false" json:"reserved_seating"` // Buyers pick seats from the event seat map
+
+	SessionID *uuid.UUID `gorm:"type:uuid;index" json:"session_id,omitempty"` // Valid for one session; nil covers every session
+
+	DisplayOrder int `gorm:"default:0" json:"display_order"` // Lower comes first in listings
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
 
 	// Relationships
 	Event   Event    `gorm:"foreignKey:EventID" json:"event,omitempty"`
@@ -87,13 +137,19 @@ func (t *TicketType) BeforeCreate(tx *gorm.DB) error {
 }
 
 func (t *TicketType) IsAvailable() bool {
+	return t.IsOnSale() && t.Sold+t.Reserved < t.Quantity
+}
+
+// IsOnSale reports whether the ticket type is active and inside its sale window,
+// whether or not any tickets are left
+func (t *TicketType) IsOnSale() bool {
 	now := time.Now()
 	return t.IsActive &&
-		t.Sold < t.Quantity &&
 		now.After(t.SaleStart) &&
 		now.Before(t.SaleEnd)
 }
 
+// RemainingTickets returns the tickets that are neither sold nor held by a checkout
 func (t *TicketType) RemainingTickets() int {
-	return t.Quantity - t.Sold
+	return t.Quantity - t.Sold - t.Reserved
 }
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type CancellationStatus string
+
+const (
+	CancellationStatusRefunding CancellationStatus = "refunding"
+	CancellationStatusNotifying CancellationStatus = "notifying"
+	CancellationStatusCompleted CancellationStatus = "completed"
+)
+
+// EventCancellation tracks the background refunds and notifications that follow
+// cancelling an event. The cursors let a restarted worker resume where it stopped.
+type EventCancellation struct {
+	ID          uuid.UUID          `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	EventID     uuid.UUID          `gorm:"type:uuid;uniqueIndex;not null" json:"event_id"`
+	RequestedBy uuid.UUID          `gorm:"type:uuid;not null" json:"requested_by"`
+	Reason      string             `gorm:"type:text" json:"reason"`
+	Status      CancellationStatus `gorm:"type:varchar(20);not null;default:'refunding';index" json:"status"`
+
+	// Progress
+	TotalPurchases    int    `gorm:"default:0" json:"total_purchases"`
+	RefundedPurchases int    `gorm:"default:0" json:"refunded_purchases"`
+	FailedPurchases   int    `gorm:"default:0" json:"failed_purchases"`
+	NotifiedAttendees int    `gorm:"default:0" json:"notified_attendees"`
+	LastError         string `gorm:"type:text" json:"last_error,omitempty"`
+
+	// Resume state
+	LastTransactionID *uuid.UUID `gorm:"type:uuid" json:"-"`
+	LastAttendeeID    *uuid.UUID `gorm:"type:uuid" json:"-"`
+	LockedUntil       *time.Time `json:"-"`
+
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+
+	// Relationships
+	Event Event `gorm:"foreignKey:EventID" json:"-"`
+}
+
+func (c *EventCancellation) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// EventDetails are the parts of an event ticket holders plan around. Changing them
+// on a published event needs a moderator's approval and a notice to every holder.
+type EventDetails struct {
+	Title     string    `json:"title"`
+	Venue     string    `json:"venue"`
+	Address   string    `json:"address"`
+	City      string    `json:"city"`
+	Country   string    `json:"country"`
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"`
+}
+
+// EventDetailChange is one changed detail, formatted for ticket holders
+type EventDetailChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// Details returns the event's material details
+func (e *Event) Details() EventDetails {
+	return EventDetails{
+		Title:     e.Title,
+		Venue:     e.Venue,
+		Address:   e.Address,
+		City:      e.City,
+		Country:   e.Country,
+		StartDate: e.StartDate,
+		EndDate:   e.EndDate,
+	}
+}
+
+// Changes lists the details that differ in to, in the order holders read them
+func (d EventDetails) Changes(to EventDetails) []EventDetailChange {
+	const dateFormat = "Monday, January 2, 2006 at 3:04 PM"
+
+	var changes []EventDetailChange
+	add := func(field, old, new string) {
+		if old != new {
+			changes = append(changes, EventDetailChange{Field: field, Old: old, New: new})
+		}
+	}
+	add("Title", d.Title, to.Title)
+	if !d.StartDate.Equal(to.StartDate) {
+		add("Starts", d.StartDate.Format(dateFormat), to.StartDate.Format(dateFormat))
+	}
+	if !d.EndDate.Equal(to.EndDate) {
+		add("Ends", d.EndDate.Format(dateFormat), to.EndDate.Format(dateFormat))
+	}
+	add("Venue", d.Venue, to.Venue)
+	add("Address", d.Address, to.Address)
+	add("City", d.City, to.City)
+	add("Country", d.Country, to.Country)
+	return changes
+}
+
+// ApplyChanges sets the details that differ between from and d on the event and
+// leaves the rest alone, so fields changed since from was taken are kept
+func (d EventDetails) ApplyChanges(event *Event, from EventDetails) {
+	if d.Title != from.Title {
+		event.Title = d.Title
+	}
+	if d.Venue != from.Venue {
+		event.Venue = d.Venue
+	}
+	if d.Address != from.Address {
+		event.Address = d.Address
+	}
+	if d.City != from.City {
+		event.City = d.City
+	}
+	if d.Country != from.Country {
+		event.Country = d.Country
+	}
+	if !d.StartDate.Equal(from.StartDate) {
+		event.StartDate = d.StartDate
+	}
+	if !d.EndDate.Equal(from.EndDate) {
+		event.EndDate = d.EndDate
+	}
+}
+
+type EventChangeStatus string
+
+const (
+	EventChangeStatusPending   EventChangeStatus = "pending"
+	EventChangeStatusRejected  EventChangeStatus = "rejected"
+	EventChangeStatusNotifying EventChangeStatus = "notifying" // Approved; holders are being sent new tickets
+	EventChangeStatusCompleted EventChangeStatus = "completed"
+)
+
+// EventChangeRequest is an organizer's change to the material details of a published
+// event, waiting for moderation. Once approved, every ticket holder is sent the
+// changes with regenerated tickets in the background; the cursor lets a restarted
+// worker resume where it stopped.
+type EventChangeRequest struct {
+	ID          uuid.UUID         `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	EventID     uuid.UUID         `gorm:"type:uuid;not null;index" json:"event_id"`
+	RequestedBy uuid.UUID         `gorm:"type:uuid;not null" json:"requested_by"`
+	Previous    EventDetails      `gorm:"type:jsonb;serializer:json" json:"previous"`
+	Proposed    EventDetails      `gorm:"type:jsonb;serializer:json" json:"proposed"`
+	Status      EventChangeStatus `gorm:"type:varchar(20);not null;default:'pending';index" json:"status"`
+
+	// Moderation
+	ModeratorID       *uuid.UUID `gorm:"type:uuid" json:"moderator_id,omitempty"`
+	ModerationComment string     `gorm:"type:text" json:"moderation_comment,omitempty"`
+	ModeratedAt       *time.Time `json:"moderated_at,omitempty"`
+
+	// Notification progress
+	NotifiedAttendees int        `gorm:"default:0" json:"notified_attendees"`
+	LastAttendeeID    *uuid.UUID `gorm:"type:uuid" json:"-"`
+	LockedUntil       *time.Time `json:"-"`
+	LastError         string     `gorm:"type:text" json:"last_error,omitempty"`
+
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+
+	// Relationships
+	Event *Event `gorm:"foreignKey:EventID" json:"event,omitempty"`
+}
+
+func (c *EventChangeRequest) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
+
+// Changes lists what the request changes
+func (c *EventChangeRequest) Changes() []EventDetailChange {
+	return c.Previous.Changes(c.Proposed)
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEventDetailsChanges(t *testing.T) {
+	start := time.Date(2026, 7, 15, 18, 0, 0, 0, time.UTC)
+	before := EventDetails{Title: "Summer Fest", Venue: "Park", City: "Lagos", StartDate: start, EndDate: start.Add(6 * time.Hour)}
+
+	after := before
+	after.Venue = "Stadium"
+	after.StartDate = start.Add(24 * time.Hour)
+
+	changes := before.Changes(after)
+	if len(changes) != 2 {
+		t.Fatalf("Expected 2 changes, got %+v", changes)
+	}
+	if changes[0].Field != "Starts" || changes[0].New != "Thursday, July 16, 2026 at 6:00 PM" {
+		t.Errorf("Expected the new start date first, got %+v", changes[0])
+	}
+	if changes[1].Field != "Venue" || changes[1].Old != "Park" || changes[1].New != "Stadium" {
+		t.Errorf("Expected the venue change, got %+v", changes[1])
+	}
+
+	if len(before.Changes(before)) != 0 {
+		t.Errorf("Expected no changes between equal details")
+	}
+}
+
+func TestEventDetailsApplyChangesKeepsLaterEdits(t *testing.T) {
+	start := time.Date(2026, 7, 15, 18, 0, 0, 0, time.UTC)
+	event := &Event{Title: "Summer Fest", Venue: "Park", StartDate: start, EndDate: start.Add(6 * time.Hour)}
+	previous := event.Details()
+
+	proposed := previous
+	proposed.Venue = "Stadium"
+
+	// The series dates moved after the change was requested
+	event.EndDate = start.Add(30 * time.Hour)
+
+	proposed.ApplyChanges(event, previous)
+	if event.Venue != "Stadium" {
+		t.Errorf("Expected the venue to change, got %s", event.Venue)
+	}
+	if !event.EndDate.Equal(start.Add(30 * time.Hour)) {
+		t.Errorf("Expected the later end date to be kept, got %s", event.EndDate)
+	}
+}
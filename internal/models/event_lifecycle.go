@@ -0,0 +1,59 @@
+package models
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// eventTransitions lists the statuses an event may move to from each status.
+// Cancelled and completed events are final.
+var eventTransitions = map[EventStatus][]EventStatus{
+	EventStatusDraft:     {EventStatusPending, EventStatusCancelled},
+	EventStatusPending:   {EventStatusApproved, EventStatusRejected, EventStatusDraft, EventStatusCancelled},
+	EventStatusRejected:  {EventStatusPending, EventStatusCancelled},
+	EventStatusApproved:  {EventStatusPublished, EventStatusCancelled},
+	EventStatusPublished: {EventStatusCompleted, EventStatusCancelled},
+}
+
+// CanTransitionTo reports whether an event in this status may move to the given one
+func (s EventStatus) CanTransitionTo(to EventStatus) bool {
+	return slices.Contains(eventTransitions[s], to)
+}
+
+// PublishMode decides when an approved event goes live
+type PublishMode string
+
+const (
+	PublishModeOnApproval PublishMode = "on_approval" // As soon as a moderator approves it
+	PublishModeScheduled  PublishMode = "scheduled"   // At PublishAt, or on approval if that has passed
+)
+
+// PublishesAt reports whether an approved event should be published at the given time
+func (e *Event) PublishesAt(now time.Time) bool {
+	if e.PublishMode != PublishModeScheduled || e.PublishAt == nil {
+		return true
+	}
+	return !now.Before(*e.PublishAt)
+}
+
+// EventStatusChange records one move of an event through its lifecycle. ChangedBy
+// is nil for moves made by the platform, such as a scheduled publish.
+type EventStatusChange struct {
+	ID         uuid.UUID   `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	EventID    uuid.UUID   `gorm:"type:uuid;not null;index" json:"event_id"`
+	FromStatus EventStatus `gorm:"type:varchar(20)" json:"from_status"` // Empty when the event was created
+	ToStatus   EventStatus `gorm:"type:varchar(20);not null" json:"to_status"`
+	ChangedBy  *uuid.UUID  `gorm:"type:uuid" json:"changed_by,omitempty"`
+	Comment    string      `gorm:"type:text" json:"comment,omitempty"`
+	CreatedAt  time.Time   `json:"created_at"`
+}
+
+func (c *EventStatusChange) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
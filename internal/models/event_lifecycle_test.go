@@ -0,0 +1,52 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEventStatusCanTransitionTo(t *testing.T) {
+	tests := []struct {
+		from EventStatus
+		to   EventStatus
+		want bool
+	}{
+		{EventStatusDraft, EventStatusPending, true},
+		{EventStatusDraft, EventStatusPublished, false},
+		{EventStatusPending, EventStatusApproved, true},
+		{EventStatusPending, EventStatusDraft, true},
+		{EventStatusPending, EventStatusPublished, false},
+		{EventStatusRejected, EventStatusPending, true},
+		{EventStatusRejected, EventStatusApproved, false},
+		{EventStatusApproved, EventStatusPublished, true},
+		{EventStatusApproved, EventStatusDraft, false},
+		{EventStatusPublished, EventStatusCancelled, true},
+		{EventStatusPublished, EventStatusDraft, false},
+		{EventStatusCancelled, EventStatusPublished, false},
+		{EventStatusCompleted, EventStatusCancelled, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.from.CanTransitionTo(tt.to); got != tt.want {
+			t.Errorf("%s -> %s: expected %v, got %v", tt.from, tt.to, tt.want, got)
+		}
+	}
+}
+
+func TestEventPublishesAt(t *testing.T) {
+	now := time.Now()
+	later := now.Add(time.Hour)
+
+	onApproval := &Event{PublishMode: PublishModeOnApproval}
+	if !onApproval.PublishesAt(now) {
+		t.Errorf("Expected an on-approval event to publish straight away")
+	}
+
+	scheduled := &Event{PublishMode: PublishModeScheduled, PublishAt: &later}
+	if scheduled.PublishesAt(now) {
+		t.Errorf("Expected a scheduled event to wait for its publish time")
+	}
+	if !scheduled.PublishesAt(later) {
+		t.Errorf("Expected a scheduled event to publish at its publish time")
+	}
+}
@@ -0,0 +1,288 @@
+package models
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// EventBlueprint is everything needed to set an event up again: its details, ticket
+// types, sessions, registration questions and seat map. Dates are kept as offsets
+// in seconds from the event start so the copy can be placed on any date.
+type EventBlueprint struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Category    string `json:"category"`
+	Venue       string `json:"venue"`
+	Address     string `json:"address"`
+	City        string `json:"city"`
+	Country     string `json:"country"`
+	ImageURL    string `json:"image_url"`
+	Duration    int64  `json:"duration"` // Seconds from start to end
+
+	RefundPercentage     float64 `json:"refund_percentage"`
+	RefundDeadlineOffset *int64  `json:"refund_deadline_offset,omitempty"`
+	AllowTransfers       bool    `json:"allow_transfers"`
+	TransferDeadline     *int64  `json:"transfer_deadline_offset,omitempty"`
+
+	TicketTypes []TicketTypeBlueprint `json:"ticket_types"`
+	Sessions    []SessionBlueprint    `json:"sessions,omitempty"`
+	Questions   []QuestionBlueprint   `json:"questions,omitempty"`
+	SeatRows    []SeatRowBlueprint    `json:"seat_rows,omitempty"`
+}
+
+// TicketTypeBlueprint is a ticket type without its sales. Session is the index of
+// its session in the blueprint, or nil for a series pass.
+type TicketTypeBlueprint struct {
+	Name            string `json:"name"`
+	Description     string `json:"description"`
+	Price           int64  `json:"price"`
+	Quantity        int    `json:"quantity"`
+	MaxPerOrder     int    `json:"max_per_order"`
+	SaleStartOffset int64  `json:"sale_start_offset"`
+	SaleEndOffset   int64  `json:"sale_end_offset"`
+	IsActive        bool   `json:"is_active"`
+	IsHidden        bool   `json:"is_hidden"`
+	ReservedSeating bool   `json:"reserved_seating"`
+	DisplayOrder    int    `json:"display_order"`
+	Session         *int   `json:"session,omitempty"`
+}
+
+type SessionBlueprint struct {
+	Title       string `json:"title"`
+	StartOffset int64  `json:"start_offset"`
+	Duration    int64  `json:"duration"`
+	Capacity    int    `json:"capacity"`
+}
+
+type QuestionBlueprint struct {
+	Label      string       `json:"label"`
+	Type       QuestionType `json:"type"`
+	Options    []string     `json:"options,omitempty"`
+	IsRequired bool         `json:"is_required"`
+	Position   int          `json:"position"`
+}
+
+// SeatRowBlueprint is a row of seats numbered from 1, sold as the ticket type at
+// index TicketType of the blueprint
+type SeatRowBlueprint struct {
+	Section    string `json:"section"`
+	Row        string `json:"row"`
+	Seats      int    `json:"seats"`
+	TicketType int    `json:"ticket_type"`
+}
+
+// NewEventBlueprint captures an event with its TicketTypes, Sessions and Questions
+// loaded, and its seats in map order
+func NewEventBlueprint(event *Event, seats []Seat) EventBlueprint {
+	offset := func(t time.Time) int64 { return int64(t.Sub(event.StartDate).Seconds()) }
+	optionalOffset := func(t *time.Time) *int64 {
+		if t == nil {
+			return nil
+		}
+		o := offset(*t)
+		return &o
+	}
+
+	blueprint := EventBlueprint{
+		Title:                event.Title,
+		Description:          event.Description,
+		Category:             event.Category,
+		Venue:                event.Venue,
+		Address:              event.Address,
+		City:                 event.City,
+		Country:              event.Country,
+		ImageURL:             event.ImageURL,
+		Duration:             offset(event.EndDate),
+		RefundPercentage:     event.RefundPercentage,
+		RefundDeadlineOffset: optionalOffset(event.RefundDeadline),
+		AllowTransfers:       event.AllowTransfers,
+		TransferDeadline:     optionalOffset(event.TransferDeadline),
+	}
+
+	sessions := append([]EventSession(nil), event.Sessions...)
+	sort.SliceStable(sessions, func(i, j int) bool { return sessions[i].StartDate.Before(sessions[j].StartDate) })
+	sessionIndex := make(map[uuid.UUID]int, len(sessions))
+	for i, session := range sessions {
+		sessionIndex[session.ID] = i
+		blueprint.Sessions = append(blueprint.Sessions, SessionBlueprint{
+			Title:       session.Title,
+			StartOffset: offset(session.StartDate),
+			Duration:    int64(session.EndDate.Sub(session.StartDate).Seconds()),
+			Capacity:    session.Capacity,
+		})
+	}
+
+	ticketTypeIndex := make(map[uuid.UUID]int, len(event.TicketTypes))
+	for i, ticketType := range event.TicketTypes {
+		ticketTypeIndex[ticketType.ID] = i
+		typeBlueprint := TicketTypeBlueprint{
+			Name:            ticketType.Name,
+			Description:     ticketType.Description,
+			Price:           ticketType.Price,
+			Quantity:        ticketType.Quantity,
+			MaxPerOrder:     ticketType.MaxPerOrder,
+			SaleStartOffset: offset(ticketType.SaleStart),
+			SaleEndOffset:   offset(ticketType.SaleEnd),
+			IsActive:        ticketType.IsActive,
+			IsHidden:        ticketType.IsHidden,
+			ReservedSeating: ticketType.ReservedSeating,
+			DisplayOrder:    ticketType.DisplayOrder,
+		}
+		if ticketType.SessionID != nil {
+			if index, ok := sessionIndex[*ticketType.SessionID]; ok {
+				typeBlueprint.Session = &index
+			}
+		}
+		blueprint.TicketTypes = append(blueprint.TicketTypes, typeBlueprint)
+	}
+
+	for _, question := range event.Questions {
+		blueprint.Questions = append(blueprint.Questions, QuestionBlueprint{
+			Label:      question.Label,
+			Type:       question.Type,
+			Options:    question.Options,
+			IsRequired: question.IsRequired,
+			Position:   question.Position,
+		})
+	}
+
+	// Seats are laid out in rows numbered from 1, so a row is its length
+	for _, seat := range seats {
+		index, ok := ticketTypeIndex[seat.TicketTypeID]
+		if !ok {
+			continue
+		}
+		last := len(blueprint.SeatRows) - 1
+		if last >= 0 && blueprint.SeatRows[last].Section == seat.Section && blueprint.SeatRows[last].Row == seat.Row &&
+			blueprint.SeatRows[last].TicketType == index {
+			blueprint.SeatRows[last].Seats++
+			continue
+		}
+		blueprint.SeatRows = append(blueprint.SeatRows, SeatRowBlueprint{Section: seat.Section, Row: seat.Row, Seats: 1, TicketType: index})
+	}
+
+	return blueprint
+}
+
+// Build turns the blueprint into a new draft event starting at start, with its
+// ticket types, sessions and questions attached and its seats returned separately.
+// IDs are assigned up front so the parts already point at each other.
+func (b *EventBlueprint) Build(organizerID uuid.UUID, start time.Time) (*Event, []Seat) {
+	at := func(offset int64) time.Time { return start.Add(time.Duration(offset) * time.Second) }
+	optionalAt := func(offset *int64) *time.Time {
+		if offset == nil {
+			return nil
+		}
+		t := at(*offset)
+		return &t
+	}
+
+	event := &Event{
+		ID:               uuid.New(),
+		Title:            b.Title,
+		Description:      b.Description,
+		Category:         b.Category,
+		Venue:            b.Venue,
+		Address:          b.Address,
+		City:             b.City,
+		Country:          b.Country,
+		ImageURL:         b.ImageURL,
+		StartDate:        start,
+		EndDate:          at(b.Duration),
+		Status:           EventStatusDraft,
+		PublishMode:      PublishModeOnApproval,
+		OrganizerID:      organizerID,
+		RefundPercentage: b.RefundPercentage,
+		RefundDeadline:   optionalAt(b.RefundDeadlineOffset),
+		AllowTransfers:   b.AllowTransfers,
+		TransferDeadline: optionalAt(b.TransferDeadline),
+	}
+
+	for _, session := range b.Sessions {
+		sessionStart := at(session.StartOffset)
+		event.Sessions = append(event.Sessions, EventSession{
+			ID:        uuid.New(),
+			EventID:   event.ID,
+			Title:     session.Title,
+			StartDate: sessionStart,
+			EndDate:   sessionStart.Add(time.Duration(session.Duration) * time.Second),
+			Capacity:  session.Capacity,
+		})
+	}
+
+	for _, ticketType := range b.TicketTypes {
+		built := TicketType{
+			ID:              uuid.New(),
+			EventID:         event.ID,
+			Name:            ticketType.Name,
+			Description:     ticketType.Description,
+			Price:           ticketType.Price,
+			Quantity:        ticketType.Quantity,
+			MaxPerOrder:     ticketType.MaxPerOrder,
+			SaleStart:       at(ticketType.SaleStartOffset),
+			SaleEnd:         at(ticketType.SaleEndOffset),
+			IsActive:        ticketType.IsActive,
+			IsHidden:        ticketType.IsHidden,
+			ReservedSeating: ticketType.ReservedSeating,
+			DisplayOrder:    ticketType.DisplayOrder,
+		}
+		if ticketType.Session != nil && *ticketType.Session < len(event.Sessions) {
+			built.SessionID = &event.Sessions[*ticketType.Session].ID
+		}
+		event.TicketTypes = append(event.TicketTypes, built)
+	}
+
+	for _, question := range b.Questions {
+		event.Questions = append(event.Questions, RegistrationQuestion{
+			ID:         uuid.New(),
+			EventID:    event.ID,
+			Label:      question.Label,
+			Type:       question.Type,
+			Options:    question.Options,
+			IsRequired: question.IsRequired,
+			Position:   question.Position,
+		})
+	}
+
+	var seats []Seat
+	for _, row := range b.SeatRows {
+		if row.TicketType < 0 || row.TicketType >= len(event.TicketTypes) {
+			continue
+		}
+		for number := 1; number <= row.Seats; number++ {
+			seats = append(seats, Seat{
+				ID:           uuid.New(),
+				EventID:      event.ID,
+				TicketTypeID: event.TicketTypes[row.TicketType].ID,
+				Section:      row.Section,
+				Row:          row.Row,
+				Number:       strconv.Itoa(number),
+				Position:     len(seats) + 1,
+				Status:       SeatStatusAvailable,
+			})
+		}
+	}
+
+	return event, seats
+}
+
+// EventTemplate is an organizer's saved event set-up, used to create new events
+type EventTemplate struct {
+	ID          uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	OrganizerID uuid.UUID      `gorm:"type:uuid;not null;index" json:"organizer_id"`
+	Name        string         `gorm:"not null" json:"name"`
+	Blueprint   EventBlueprint `gorm:"type:jsonb;serializer:json" json:"blueprint"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+}
+
+func (t *EventTemplate) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
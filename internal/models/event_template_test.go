@@ -0,0 +1,88 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestEventBlueprintShiftsDatesAndKeepsLinks(t *testing.T) {
+	start := time.Date(2026, 3, 6, 19, 0, 0, 0, time.UTC)
+	refundDeadline := start.Add(-48 * time.Hour)
+	sessionID := uuid.New()
+	passID, nightID := uuid.New(), uuid.New()
+
+	event := &Event{
+		Title:            "Jazz Nights",
+		Venue:            "Hall",
+		StartDate:        start,
+		EndDate:          start.Add(26 * time.Hour),
+		Status:           EventStatusPublished,
+		IsFeatured:       true,
+		RefundPercentage: 50,
+		RefundDeadline:   &refundDeadline,
+		Sessions: []EventSession{
+			{ID: sessionID, Title: "Night 1", StartDate: start, EndDate: start.Add(3 * time.Hour), Capacity: 80},
+		},
+		TicketTypes: []TicketType{
+			{ID: passID, Name: "Pass", Price: 5000, Quantity: 50, Sold: 20, SaleStart: start.Add(-30 * 24 * time.Hour), SaleEnd: start, IsActive: true},
+			{ID: nightID, Name: "Night 1", Price: 2000, Quantity: 4, Sold: 4, SaleStart: start.Add(-7 * 24 * time.Hour), SaleEnd: start, SessionID: &sessionID, ReservedSeating: true},
+		},
+		Questions: []RegistrationQuestion{
+			{Label: "Meal", Type: QuestionTypeSelect, Options: []string{"Meat", "Veg"}, IsRequired: true, Position: 1},
+		},
+	}
+	seats := []Seat{
+		{TicketTypeID: nightID, Section: "Stalls", Row: "A", Number: "1"},
+		{TicketTypeID: nightID, Section: "Stalls", Row: "A", Number: "2"},
+		{TicketTypeID: nightID, Section: "Stalls", Row: "B", Number: "1"},
+		{TicketTypeID: nightID, Section: "Balcony", Row: "A", Number: "1"},
+	}
+
+	blueprint := NewEventBlueprint(event, seats)
+	if len(blueprint.SeatRows) != 3 || blueprint.SeatRows[0].Seats != 2 {
+		t.Fatalf("Expected 3 seat rows with 2 seats in the first, got %+v", blueprint.SeatRows)
+	}
+
+	organizerID := uuid.New()
+	newStart := start.AddDate(0, 0, 7)
+	clone, clonedSeats := blueprint.Build(organizerID, newStart)
+
+	if clone.ID == uuid.Nil || clone.Status != EventStatusDraft || clone.IsFeatured || clone.OrganizerID != organizerID {
+		t.Errorf("Expected an unfeatured draft for the organizer, got %+v", clone)
+	}
+	if !clone.EndDate.Equal(newStart.Add(26*time.Hour)) || !clone.RefundDeadline.Equal(refundDeadline.AddDate(0, 0, 7)) {
+		t.Errorf("Expected dates moved by a week, got end %s and refund deadline %s", clone.EndDate, clone.RefundDeadline)
+	}
+
+	if len(clone.Sessions) != 1 || !clone.Sessions[0].StartDate.Equal(newStart) || clone.Sessions[0].Capacity != 80 {
+		t.Fatalf("Expected the session moved with its capacity, got %+v", clone.Sessions)
+	}
+	if len(clone.TicketTypes) != 2 {
+		t.Fatalf("Expected 2 ticket types, got %d", len(clone.TicketTypes))
+	}
+	pass, night := clone.TicketTypes[0], clone.TicketTypes[1]
+	if pass.Sold != 0 || pass.SessionID != nil || !pass.SaleStart.Equal(newStart.Add(-30*24*time.Hour)) {
+		t.Errorf("Expected an unsold series pass with a moved sale window, got %+v", pass)
+	}
+	if night.IsActive || night.SessionID == nil || *night.SessionID != clone.Sessions[0].ID {
+		t.Errorf("Expected the inactive night ticket on the new session, got %+v", night)
+	}
+
+	if len(clone.Questions) != 1 || clone.Questions[0].EventID != clone.ID || len(clone.Questions[0].Options) != 2 {
+		t.Errorf("Expected the question copied to the new event, got %+v", clone.Questions)
+	}
+
+	if len(clonedSeats) != 4 {
+		t.Fatalf("Expected 4 seats, got %d", len(clonedSeats))
+	}
+	for i, seat := range clonedSeats {
+		if seat.TicketTypeID != night.ID || seat.EventID != clone.ID || seat.Position != i+1 || seat.Status != SeatStatusAvailable {
+			t.Errorf("Expected seat %d free on the new night ticket, got %+v", i, seat)
+		}
+	}
+	if clonedSeats[3].Section != "Balcony" || clonedSeats[1].Number != "2" {
+		t.Errorf("Expected the seat map layout kept, got %+v", clonedSeats)
+	}
+}
@@ -0,0 +1,104 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEventRefundPercentageAt(t *testing.T) {
+	now := time.Now()
+	deadline := now.Add(24 * time.Hour)
+	passedDeadline := now.Add(-1 * time.Hour)
+
+	tests := []struct {
+		name     string
+		event    Event
+		expected float64
+	}{
+		{
+			name:     "No refund policy",
+			event:    Event{StartDate: now.Add(48 * time.Hour)},
+			expected: 0,
+		},
+		{
+			name:     "Before deadline",
+			event:    Event{StartDate: now.Add(48 * time.Hour), RefundPercentage: 80, RefundDeadline: &deadline},
+			expected: 80,
+		},
+		{
+			name:     "After deadline",
+			event:    Event{StartDate: now.Add(48 * time.Hour), RefundPercentage: 80, RefundDeadline: &passedDeadline},
+			expected: 0,
+		},
+		{
+			name:     "No deadline before start",
+			event:    Event{StartDate: now.Add(48 * time.Hour), RefundPercentage: 100},
+			expected: 100,
+		},
+		{
+			name:     "Event already started",
+			event:    Event{StartDate: now.Add(-1 * time.Hour), RefundPercentage: 100},
+			expected: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.event.RefundPercentageAt(now)
+			if result != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestEventTransfersOpenAt(t *testing.T) {
+	now := time.Now()
+	deadline := now.Add(24 * time.Hour)
+	passedDeadline := now.Add(-1 * time.Hour)
+
+	tests := []struct {
+		name     string
+		event    Event
+		expected bool
+	}{
+		{
+			name:     "Transfers allowed without deadline",
+			event:    Event{StartDate: now.Add(48 * time.Hour), AllowTransfers: true},
+			expected: true,
+		},
+		{
+			name:     "Transfers turned off",
+			event:    Event{StartDate: now.Add(48 * time.Hour)},
+			expected: false,
+		},
+		{
+			name:     "Before deadline",
+			event:    Event{StartDate: now.Add(48 * time.Hour), AllowTransfers: true, TransferDeadline: &deadline},
+			expected: true,
+		},
+		{
+			name:     "After deadline",
+			event:    Event{StartDate: now.Add(48 * time.Hour), AllowTransfers: true, TransferDeadline: &passedDeadline},
+			expected: false,
+		},
+		{
+			name:     "Event already started",
+			event:    Event{StartDate: now.Add(-1 * time.Hour), AllowTransfers: true},
+			expected: false,
+		},
+		{
+			name:     "Event cancelled",
+			event:    Event{StartDate: now.Add(48 * time.Hour), AllowTransfers: true, Status: EventStatusCancelled},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := tt.event.TransfersOpenAt(now); result != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
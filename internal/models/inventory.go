@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type HoldStatus string
+
+const (
+	HoldStatusActive    HoldStatus = "active"
+	HoldStatusConverted HoldStatus = "converted"
+	HoldStatusReleased  HoldStatus = "released"
+)
+
+// InventoryHold reserves ticket stock for a checkout until payment succeeds or the hold expires
+type InventoryHold struct {
+	ID            uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	TransactionID uuid.UUID  `gorm:"type:uuid;not null;index" json:"transaction_id"`
+	TicketTypeID  uuid.UUID  `gorm:"type:uuid;not null;index" json:"ticket_type_id"`
+	Quantity      int        `gorm:"not null" json:"quantity"`
+	Status        HoldStatus `gorm:"type:varchar(20);not null;default:'active';index" json:"status"`
+	ExpiresAt     time.Time  `gorm:"not null;index" json:"expires_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+
+	// Relationships
+	TicketType TicketType `gorm:"foreignKey:TicketTypeID" json:"ticket_type,omitempty"`
+}
+
+func (h *InventoryHold) BeforeCreate(tx *gorm.DB) error {
+	if h.ID == uuid.Nil {
+		h.ID = uuid.New()
+	}
+	return nil
+}
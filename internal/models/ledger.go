@@ -0,0 +1,80 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LedgerAccount is an account money moves between. Organizer accounts are kept
+// per organizer through the journal entry's OrganizerID.
+type LedgerAccount string
+
+const (
+	LedgerAccountGatewayClearing    LedgerAccount = "gateway_clearing"    // Money collected and held by the payment gateway
+	LedgerAccountOrganizerAvailable LedgerAccount = "organizer_available" // Earnings the organizer can withdraw
+	LedgerAccountOrganizerPending   LedgerAccount = "organizer_pending"   // Earnings locked in a withdrawal request
+	LedgerAccountPlatformFees       LedgerAccount = "platform_fees"       // Ticket and withdrawal fees earned by the platform
+	LedgerAccountPayouts            LedgerAccount = "payouts"             // Money paid out to organizers' bank accounts
+)
+
+type JournalEntryType string
+
+const (
+	JournalEntryTicketSale         JournalEntryType = "ticket_sale"
+	JournalEntryRefund             JournalEntryType = "refund"
+	JournalEntryRefundReversal     JournalEntryType = "refund_reversal"
+	JournalEntryWithdrawalRequest  JournalEntryType = "withdrawal_request"
+	JournalEntryWithdrawalRejected JournalEntryType = "withdrawal_rejected"
+	JournalEntryWithdrawalPaid     JournalEntryType = "withdrawal_paid"
+	JournalEntryWithdrawalFailed   JournalEntryType = "withdrawal_failed"
+	JournalEntryOpeningBalance     JournalEntryType = "opening_balance"
+)
+
+// JournalEntry records one money movement as ledger lines that sum to zero.
+// Entries are append-only: mistakes are corrected with a new entry.
+type JournalEntry struct {
+	ID            uuid.UUID        `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Type          JournalEntryType `gorm:"type:varchar(30);not null;index" json:"type"`
+	OrganizerID   *uuid.UUID       `gorm:"type:uuid;index" json:"organizer_id,omitempty"`
+	TransactionID *uuid.UUID       `gorm:"type:uuid;index" json:"transaction_id,omitempty"`
+	WithdrawalID  *uuid.UUID       `gorm:"type:uuid;index" json:"withdrawal_id,omitempty"`
+	Currency      string           `gorm:"default:'NGN'" json:"currency"`
+	Description   string           `json:"description"`
+	CreatedAt     time.Time        `gorm:"index" json:"created_at"`
+
+	Lines []LedgerLine `gorm:"foreignKey:JournalEntryID" json:"lines,omitempty"`
+}
+
+// LedgerLine moves Amount minor units into or out of an account. Debits are
+// positive and credits negative, so the lines of an entry always sum to zero.
+type LedgerLine struct {
+	ID             uuid.UUID     `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	JournalEntryID uuid.UUID     `gorm:"type:uuid;not null;index" json:"journal_entry_id"`
+	Account        LedgerAccount `gorm:"type:varchar(30);not null;index" json:"account"`
+	Amount         int64         `gorm:"not null" json:"amount"`
+	CreatedAt      time.Time     `json:"created_at"`
+}
+
+// Debit returns a line taking amount into the account
+func Debit(account LedgerAccount, amount int64) LedgerLine {
+	return LedgerLine{Account: account, Amount: amount}
+}
+
+// Credit returns a line taking amount out of the account
+func Credit(account LedgerAccount, amount int64) LedgerLine {
+	return LedgerLine{Account: account, Amount: -amount}
+}
+
+// IsBalanced reports whether the entry has lines and they sum to zero
+func (e *JournalEntry) IsBalanced() bool {
+	if len(e.Lines) == 0 {
+		return false
+	}
+
+	var total int64
+	for _, line := range e.Lines {
+		total += line.Amount
+	}
+	return total == 0
+}
@@ -0,0 +1,40 @@
+package models
+
+import "testing"
+
+func TestJournalEntryIsBalanced(t *testing.T) {
+	tests := []struct {
+		name     string
+		lines    []LedgerLine
+		expected bool
+	}{
+		{"Sale", []LedgerLine{
+			Debit(LedgerAccountGatewayClearing, 200000),
+			Credit(LedgerAccountOrganizerAvailable, 190000),
+			Credit(LedgerAccountPlatformFees, 10000),
+		}, true},
+		{"Fee missing", []LedgerLine{
+			Debit(LedgerAccountGatewayClearing, 200000),
+			Credit(LedgerAccountOrganizerAvailable, 190000),
+		}, false},
+		{"No lines", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := &JournalEntry{Lines: tt.lines}
+			if result := entry.IsBalanced(); result != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestDebitAndCreditSigns(t *testing.T) {
+	if line := Debit(LedgerAccountPayouts, 500); line.Amount != 500 {
+		t.Errorf("Expected debit of 500, got %d", line.Amount)
+	}
+	if line := Credit(LedgerAccountPayouts, 500); line.Amount != -500 {
+		t.Errorf("Expected credit of -500, got %d", line.Amount)
+	}
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// Money is stored as int64 minor units of the currency (kobo for NGN, cents for
+// USD), the same unit the payment gateways use, so totals add up exactly.
+//
+// Percentage fees are calculated by PercentageOf: the rate is taken to the nearest
+// basis point (0.01%) and the fee is rounded half up to the nearest minor unit.
+
+// ToMinorUnits converts an amount in the main currency unit to minor units, rounding to the nearest unit
+func ToMinorUnits(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
+// FormatAmount renders minor units in the main currency unit, e.g. 199950 as "1999.50"
+func FormatAmount(amount int64) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, amount/100, amount%100)
+}
+
+// PercentageOf returns percentage (0-100) of amount, rounded half up to the nearest minor unit
+func PercentageOf(amount int64, percentage float64) int64 {
+	basisPoints := int64(math.Round(percentage * 100))
+	return MulDivRound(amount, basisPoints, 10000)
+}
+
+// MulDivRound returns amount * numerator / denominator rounded half away from
+// zero, without overflowing on large intermediate products
+func MulDivRound(amount, numerator, denominator int64) int64 {
+	if denominator == 0 {
+		return 0
+	}
+
+	product := new(big.Int).Mul(big.NewInt(amount), big.NewInt(numerator))
+	divisor := big.NewInt(denominator)
+	negative := product.Sign()*divisor.Sign() < 0
+	product.Abs(product)
+	divisor.Abs(divisor)
+
+	quotient, remainder := new(big.Int).QuoRem(product, divisor, new(big.Int))
+	if remainder.Lsh(remainder, 1).Cmp(divisor) >= 0 {
+		quotient.Add(quotient, big.NewInt(1))
+	}
+	if negative {
+		quotient.Neg(quotient)
+	}
+	return quotient.Int64()
+}
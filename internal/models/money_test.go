@@ -0,0 +1,89 @@
+package models
+
+import (
+	"math"
+	"testing"
+)
+
+func TestToMinorUnits(t *testing.T) {
+	tests := []struct {
+		amount   float64
+		expected int64
+	}{
+		{19.99, 1999},
+		{0.1 + 0.2, 30},
+		{1000, 100000},
+		{0, 0},
+	}
+
+	for _, tt := range tests {
+		if result := ToMinorUnits(tt.amount); result != tt.expected {
+			t.Errorf("ToMinorUnits(%v) = %d, expected %d", tt.amount, result, tt.expected)
+		}
+	}
+}
+
+func TestFormatAmount(t *testing.T) {
+	tests := []struct {
+		amount   int64
+		expected string
+	}{
+		{199950, "1999.50"},
+		{5, "0.05"},
+		{0, "0.00"},
+		{-1999, "-19.99"},
+	}
+
+	for _, tt := range tests {
+		if result := FormatAmount(tt.amount); result != tt.expected {
+			t.Errorf("FormatAmount(%d) = %s, expected %s", tt.amount, result, tt.expected)
+		}
+	}
+}
+
+func TestPercentageOf(t *testing.T) {
+	tests := []struct {
+		name       string
+		amount     int64
+		percentage float64
+		expected   int64
+	}{
+		{"Exact", 200000, 5, 10000},
+		{"Rounds half up", 1999, 5, 100}, // 99.95
+		{"Above half", 1990, 2.5, 50},    // 49.75
+		{"Below half", 1001, 5, 50},      // 50.05
+		{"Fractional percentage", 10000, 2.25, 225},
+		{"Zero percent", 5000, 0, 0},
+		{"Full amount", 4321, 100, 4321},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := PercentageOf(tt.amount, tt.percentage); result != tt.expected {
+				t.Errorf("Expected %d, got %d", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestMulDivRound(t *testing.T) {
+	tests := []struct {
+		name                           string
+		amount, numerator, denominator int64
+		expected                       int64
+	}{
+		{"Proportional fee", 100000, 10000, 200000, 5000},
+		{"Rounds half away from zero", 5, 1, 2, 3},
+		{"Negative rounds away from zero", -5, 1, 2, -3},
+		{"Zero denominator", 100, 1, 0, 0},
+		{"Large product does not overflow", math.MaxInt64 / 2, 4, 4, math.MaxInt64 / 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := MulDivRound(tt.amount, tt.numerator, tt.denominator); result != tt.expected {
+				t.Errorf("Expected %d, got %d", tt.expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type PayoutAccountStatus string
+
+const (
+	PayoutAccountStatusPending  PayoutAccountStatus = "pending"
+	PayoutAccountStatusApproved PayoutAccountStatus = "approved"
+	PayoutAccountStatusRejected PayoutAccountStatus = "rejected"
+)
+
+// PayoutAccount is a bank account an organizer is paid out to. It is verified with
+// the payment gateway when added and must be approved by an admin before use. The
+// full account number is kept only by the gateway, as a transfer recipient.
+type PayoutAccount struct {
+	ID             uuid.UUID           `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	OrganizerID    uuid.UUID           `gorm:"type:uuid;not null;index" json:"organizer_id"`
+	PaymentGateway string              `gorm:"not null" json:"payment_gateway"`
+	RecipientCode  string              `gorm:"not null" json:"-"`
+	BankCode       string              `gorm:"not null" json:"bank_code"`
+	BankName       string              `gorm:"not null" json:"bank_name"`
+	AccountNumber  string              `gorm:"not null" json:"account_number"` // Masked, e.g. ******6789
+	AccountName    string              `gorm:"not null" json:"account_name"`   // As registered with the bank
+	Status         PayoutAccountStatus `gorm:"type:varchar(20);not null;default:'pending'" json:"status"`
+
+	// Admin review
+	ReviewedBy    *uuid.UUID `gorm:"type:uuid" json:"reviewed_by,omitempty"`
+	ReviewedAt    *time.Time `json:"reviewed_at,omitempty"`
+	ReviewComment string     `gorm:"type:text" json:"review_comment,omitempty"`
+
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Relationships
+	Organizer User `gorm:"foreignKey:OrganizerID" json:"organizer,omitempty"`
+}
+
+// MaskAccountNumber hides all but the last four digits of an account number
+func MaskAccountNumber(accountNumber string) string {
+	if len(accountNumber) <= 4 {
+		return accountNumber
+	}
+	return strings.Repeat("*", len(accountNumber)-4) + accountNumber[len(accountNumber)-4:]
+}
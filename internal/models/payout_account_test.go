@@ -0,0 +1,21 @@
+package models
+
+import "testing"
+
+func TestMaskAccountNumber(t *testing.T) {
+	tests := []struct {
+		accountNumber string
+		expected      string
+	}{
+		{"0123456789", "******6789"},
+		{"12345", "*2345"},
+		{"1234", "1234"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if result := MaskAccountNumber(tt.accountNumber); result != tt.expected {
+			t.Errorf("MaskAccountNumber(%q) = %q, expected %q", tt.accountNumber, result, tt.expected)
+		}
+	}
+}
@@ -12,7 +12,7 @@ type PlatformSettings struct {
 	ID                      uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
 	PlatformFeePercentage   float64   `gorm:"not null;default:5.0" json:"platform_fee_percentage"`
 	WithdrawalFeePercentage float64   `gorm:"not null;default:2.5" json:"withdrawal_fee_percentage"`
-	MinWithdrawalAmount     float64   `gorm:"default:1000" json:"min_withdrawal_amount"`
+	MinWithdrawalAmount     int64     `gorm:"default:100000" json:"min_withdrawal_amount"` // Minor units
 	Currency                string    `gorm:"default:'NGN'" json:"currency"`
 	UpdatedBy               uuid.UUID `gorm:"type:uuid" json:"updated_by"`
 	CreatedAt               time.Time `json:"created_at"`
@@ -29,25 +29,30 @@ func (p *PlatformSettings) BeforeCreate(tx *gorm.DB) error {
 type WithdrawalStatus string
 
 const (
-	WithdrawalStatusPending   WithdrawalStatus = "pending"
-	WithdrawalStatusApproved  WithdrawalStatus = "approved"
-	WithdrawalStatusRejected  WithdrawalStatus = "rejected"
-	WithdrawalStatusProcessed WithdrawalStatus = "processed"
+	WithdrawalStatusPending    WithdrawalStatus = "pending"
+	WithdrawalStatusApproved   WithdrawalStatus = "approved"
+	WithdrawalStatusRejected   WithdrawalStatus = "rejected"
+	WithdrawalStatusProcessing WithdrawalStatus = "processing" // Transfer sent, waiting for the gateway
+	WithdrawalStatusProcessed  WithdrawalStatus = "processed"
+	WithdrawalStatusFailed     WithdrawalStatus = "failed" // Transfer failed; amount returned to the available balance
 )
 
 // WithdrawalRequest represents organizer withdrawal requests
 type WithdrawalRequest struct {
 	ID            uuid.UUID        `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
 	OrganizerID   uuid.UUID        `gorm:"type:uuid;not null" json:"organizer_id"`
-	Amount        float64          `gorm:"not null" json:"amount"`
-	WithdrawalFee float64          `gorm:"not null" json:"withdrawal_fee"`
-	NetAmount     float64          `gorm:"not null" json:"net_amount"`
+	Amount        int64            `gorm:"not null" json:"amount"` // Minor units, like the fee and net amount
+	WithdrawalFee int64            `gorm:"not null" json:"withdrawal_fee"`
+	NetAmount     int64            `gorm:"not null" json:"net_amount"`
 	Status        WithdrawalStatus `gorm:"type:varchar(20);not null;default:'pending'" json:"status"`
 
-	// Bank details
-	BankName      string `gorm:"not null" json:"bank_name"`
-	AccountNumber string `gorm:"not null" json:"account_number"`
-	AccountName   string `gorm:"not null" json:"account_name"`
+	// Bank details, copied from the payout account when the withdrawal is requested
+	PayoutAccountID *uuid.UUID `gorm:"type:uuid" json:"payout_account_id,omitempty"`
+	RecipientCode   string     `json:"-"`
+	BankName        string     `gorm:"not null" json:"bank_name"`
+	AccountNumber   string     `gorm:"not null" json:"account_number"` // Masked for payout accounts
+	AccountName     string     `gorm:"not null" json:"account_name"`
+	BankCode        string     `json:"bank_code,omitempty"`
 
 	// Admin review
 	ReviewedBy    *uuid.UUID `gorm:"type:uuid" json:"reviewed_by,omitempty"`
@@ -57,6 +62,9 @@ type WithdrawalRequest struct {
 	// Processing
 	ProcessedAt    *time.Time `json:"processed_at,omitempty"`
 	TransactionRef string     `json:"transaction_ref,omitempty"`
+	PaymentGateway string     `json:"payment_gateway,omitempty"`
+	TransferCode   string     `json:"transfer_code,omitempty"`
+	FailureReason  string     `gorm:"type:text" json:"failure_reason,omitempty"`
 
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
@@ -74,14 +82,14 @@ func (w *WithdrawalRequest) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
-// OrganizerBalance tracks organizer earnings
+// OrganizerBalance tracks organizer earnings in minor units
 type OrganizerBalance struct {
 	ID               uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
 	OrganizerID      uuid.UUID `gorm:"type:uuid;uniqueIndex;not null" json:"organizer_id"`
-	TotalEarnings    float64   `gorm:"default:0" json:"total_earnings"`
-	AvailableBalance float64   `gorm:"default:0" json:"available_balance"`
-	PendingBalance   float64   `gorm:"default:0" json:"pending_balance"`
-	WithdrawnAmount  float64   `gorm:"default:0" json:"withdrawn_amount"`
+	TotalEarnings    int64     `gorm:"default:0" json:"total_earnings"`
+	AvailableBalance int64     `gorm:"default:0" json:"available_balance"`
+	PendingBalance   int64     `gorm:"default:0" json:"pending_balance"`
+	WithdrawnAmount  int64     `gorm:"default:0" json:"withdrawn_amount"`
 	CreatedAt        time.Time `json:"created_at"`
 	UpdatedAt        time.Time `json:"updated_at"`
 
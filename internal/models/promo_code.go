@@ -0,0 +1,135 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type DiscountType string
+
+const (
+	DiscountTypePercentage DiscountType = "percentage"
+	DiscountTypeFixed      DiscountType = "fixed" // AmountOff off the whole order
+)
+
+// PromoCode is a discount code for one event. With no ticket types it applies to
+// every ticket type of the event. A zero limit means no limit.
+type PromoCode struct {
+	ID           uuid.UUID    `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	EventID      uuid.UUID    `gorm:"type:uuid;not null;uniqueIndex:idx_promo_code_event" json:"event_id"`
+	Code         string       `gorm:"not null;uniqueIndex:idx_promo_code_event" json:"code"` // Stored upper case
+	DiscountType DiscountType `gorm:"type:varchar(20);not null" json:"discount_type"`
+	PercentOff   float64      `gorm:"default:0" json:"percent_off,omitempty"`
+	AmountOff    int64        `gorm:"default:0" json:"amount_off,omitempty"` // Minor units
+
+	MaxUses        int        `gorm:"default:0" json:"max_uses"`
+	MaxUsesPerUser int        `gorm:"default:0" json:"max_uses_per_user"`
+	MinQuantity    int        `gorm:"default:0" json:"min_quantity"` // Tickets the code applies to, per order
+	StartsAt       *time.Time `json:"starts_at,omitempty"`
+	EndsAt         *time.Time `json:"ends_at,omitempty"`
+	IsActive       bool       `gorm:"default:true" json:"is_active"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Relationships
+	TicketTypes []TicketType `gorm:"many2many:promo_code_ticket_types" json:"ticket_types,omitempty"`
+}
+
+// OrderLine is one line of a checkout cart
+type OrderLine struct {
+	TicketTypeID uuid.UUID
+	UnitPrice    int64
+	Quantity     int
+}
+
+// Subtotal returns the line's price before discounts
+func (l OrderLine) Subtotal() int64 {
+	return l.UnitPrice * int64(l.Quantity)
+}
+
+// NormalizeCode returns the form promo and access codes are stored and looked up in
+func NormalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// IsOpenAt reports whether the code is active and inside its validity window
+func (p *PromoCode) IsOpenAt(now time.Time) bool {
+	if !p.IsActive {
+		return false
+	}
+	if p.StartsAt != nil && now.Before(*p.StartsAt) {
+		return false
+	}
+	return p.EndsAt == nil || now.Before(*p.EndsAt)
+}
+
+// AppliesTo reports whether the code discounts the given ticket type
+func (p *PromoCode) AppliesTo(ticketTypeID uuid.UUID) bool {
+	if len(p.TicketTypes) == 0 {
+		return true
+	}
+	for _, ticketType := range p.TicketTypes {
+		if ticketType.ID == ticketTypeID {
+			return true
+		}
+	}
+	return false
+}
+
+// EligibleQuantity counts the tickets in the cart the code applies to
+func (p *PromoCode) EligibleQuantity(lines []OrderLine) int {
+	quantity := 0
+	for _, line := range lines {
+		if p.AppliesTo(line.TicketTypeID) {
+			quantity += line.Quantity
+		}
+	}
+	return quantity
+}
+
+// LineDiscounts returns the discount on each cart line. A fixed discount is capped
+// at the eligible subtotal and spread over the eligible lines by their subtotal,
+// with any rounding remainder on the last one.
+func (p *PromoCode) LineDiscounts(lines []OrderLine) []int64 {
+	discounts := make([]int64, len(lines))
+
+	switch p.DiscountType {
+	case DiscountTypePercentage:
+		for i, line := range lines {
+			if p.AppliesTo(line.TicketTypeID) {
+				discounts[i] = PercentageOf(line.Subtotal(), p.PercentOff)
+			}
+		}
+	case DiscountTypeFixed:
+		var eligible int64
+		last := -1
+		for i, line := range lines {
+			if p.AppliesTo(line.TicketTypeID) && line.Subtotal() > 0 {
+				eligible += line.Subtotal()
+				last = i
+			}
+		}
+		if last < 0 {
+			return discounts
+		}
+
+		total := min(p.AmountOff, eligible)
+		remaining := total
+		for i, line := range lines {
+			if !p.AppliesTo(line.TicketTypeID) || line.Subtotal() == 0 {
+				continue
+			}
+			if i == last {
+				discounts[i] = min(max(remaining, 0), line.Subtotal())
+				break
+			}
+			discounts[i] = MulDivRound(total, line.Subtotal(), eligible)
+			remaining -= discounts[i]
+		}
+	}
+
+	return discounts
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestPromoCodeLineDiscounts(t *testing.T) {
+	vip := uuid.New()
+	regular := uuid.New()
+	lines := []OrderLine{
+		{TicketTypeID: vip, UnitPrice: 1000, Quantity: 1},
+		{TicketTypeID: regular, UnitPrice: 333, Quantity: 2},
+	}
+
+	tests := []struct {
+		name      string
+		promoCode PromoCode
+		expected  []int64
+	}{
+		{"Percentage on every line", PromoCode{DiscountType: DiscountTypePercentage, PercentOff: 10}, []int64{100, 67}},
+		{"Percentage on one ticket type", PromoCode{DiscountType: DiscountTypePercentage, PercentOff: 50, TicketTypes: []TicketType{{ID: regular}}}, []int64{0, 333}},
+		{"Fixed spread by subtotal", PromoCode{DiscountType: DiscountTypeFixed, AmountOff: 500}, []int64{300, 200}},
+		{"Fixed capped at subtotal", PromoCode{DiscountType: DiscountTypeFixed, AmountOff: 5000}, []int64{1000, 666}},
+		{"Fixed on one ticket type", PromoCode{DiscountType: DiscountTypeFixed, AmountOff: 250, TicketTypes: []TicketType{{ID: vip}}}, []int64{250, 0}},
+		{"Fixed for other tickets", PromoCode{DiscountType: DiscountTypeFixed, AmountOff: 250, TicketTypes: []TicketType{{ID: uuid.New()}}}, []int64{0, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := tt.promoCode.LineDiscounts(lines); !slices.Equal(result, tt.expected) {
+				t.Errorf("LineDiscounts() = %v, expected %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPromoCodeIsOpenAt(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+
+	tests := []struct {
+		name      string
+		promoCode PromoCode
+		expected  bool
+	}{
+		{"No window", PromoCode{IsActive: true}, true},
+		{"Inside window", PromoCode{IsActive: true, StartsAt: &past, EndsAt: &future}, true},
+		{"Not started", PromoCode{IsActive: true, StartsAt: &future}, false},
+		{"Ended", PromoCode{IsActive: true, EndsAt: &past}, false},
+		{"Inactive", PromoCode{IsActive: false}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := tt.promoCode.IsOpenAt(now); result != tt.expected {
+				t.Errorf("IsOpenAt() = %v, expected %v", result, tt.expected)
+			}
+		})
+	}
+}
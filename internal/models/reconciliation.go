@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ReconciliationMismatchKind string
+
+const (
+	MismatchUnfulfilledPayment ReconciliationMismatchKind = "unfulfilled_payment" // Paid at the gateway but not completed locally
+	MismatchUnknownPayment     ReconciliationMismatchKind = "unknown_payment"     // Paid at the gateway with no local transaction
+	MismatchMissingPayment     ReconciliationMismatchKind = "missing_payment"     // Completed locally but not paid at the gateway
+	MismatchAmount             ReconciliationMismatchKind = "amount_mismatch"     // Gateway and local amounts differ
+)
+
+// ReconciliationReport compares one day of a gateway's successful payments with
+// the ticket purchases recorded locally
+type ReconciliationReport struct {
+	ID             uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Date           time.Time `gorm:"type:date;not null;uniqueIndex:idx_reconciliation_report_day" json:"date"`
+	PaymentGateway string    `gorm:"not null;uniqueIndex:idx_reconciliation_report_day" json:"payment_gateway"`
+	GatewayCount   int       `json:"gateway_count"`
+	GatewayTotal   int64     `json:"gateway_total"` // Minor units
+	LocalCount     int       `json:"local_count"`
+	LocalTotal     int64     `json:"local_total"`
+	MismatchCount  int       `json:"mismatch_count"`
+	CreatedAt      time.Time `json:"created_at"`
+
+	Mismatches []ReconciliationMismatch `gorm:"foreignKey:ReportID" json:"mismatches,omitempty"`
+}
+
+// ReconciliationMismatch is one payment the gateway and local records disagree on
+type ReconciliationMismatch struct {
+	ID               uuid.UUID                  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ReportID         uuid.UUID                  `gorm:"type:uuid;not null;index" json:"report_id"`
+	Kind             ReconciliationMismatchKind `gorm:"type:varchar(30);not null" json:"kind"`
+	PaymentReference string                     `json:"payment_reference"`
+	TransactionID    *uuid.UUID                 `gorm:"type:uuid" json:"transaction_id,omitempty"`
+	GatewayStatus    string                     `json:"gateway_status,omitempty"`
+	LocalStatus      TransactionStatus          `gorm:"type:varchar(20)" json:"local_status,omitempty"`
+	GatewayAmount    int64                      `json:"gateway_amount"`
+	LocalAmount      int64                      `json:"local_amount"`
+	CreatedAt        time.Time                  `json:"created_at"`
+}
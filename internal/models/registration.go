@@ -0,0 +1,113 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type QuestionType string
+
+const (
+	QuestionTypeText   QuestionType = "text"
+	QuestionTypeSelect QuestionType = "select" // Answer must be one of Options
+)
+
+// maxAnswerLength is the longest answer a ticket holder may give
+const maxAnswerLength = 1000
+
+// RegistrationQuestion is a question an organizer asks every ticket holder of an
+// event, such as dietary needs or t-shirt size
+type RegistrationQuestion struct {
+	ID         uuid.UUID    `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	EventID    uuid.UUID    `gorm:"type:uuid;not null;index" json:"event_id"`
+	Label      string       `gorm:"not null" json:"label"`
+	Type       QuestionType `gorm:"type:varchar(20);not null;default:'text'" json:"type"`
+	Options    []string     `gorm:"serializer:json" json:"options,omitempty"`
+	IsRequired bool         `gorm:"default:false" json:"is_required"`
+	Position   int          `gorm:"default:0" json:"position"`
+	CreatedAt  time.Time    `json:"created_at"`
+	UpdatedAt  time.Time    `json:"updated_at"`
+}
+
+func (q *RegistrationQuestion) BeforeCreate(tx *gorm.DB) error {
+	if q.ID == uuid.Nil {
+		q.ID = uuid.New()
+	}
+	return nil
+}
+
+// Validate checks that the question can be asked as configured
+func (q *RegistrationQuestion) Validate() error {
+	if strings.TrimSpace(q.Label) == "" {
+		return fmt.Errorf("question label is required")
+	}
+	switch q.Type {
+	case QuestionTypeText:
+		if len(q.Options) > 0 {
+			return fmt.Errorf("text questions do not take options")
+		}
+	case QuestionTypeSelect:
+		if len(q.Options) < 2 {
+			return fmt.Errorf("select questions need at least two options")
+		}
+		seen := make(map[string]bool, len(q.Options))
+		for _, option := range q.Options {
+			key := strings.ToLower(strings.TrimSpace(option))
+			if key == "" || seen[key] {
+				return fmt.Errorf("select options must be unique and not empty")
+			}
+			seen[key] = true
+		}
+	default:
+		return fmt.Errorf("unknown question type %q", q.Type)
+	}
+	return nil
+}
+
+// ValidateAnswer checks an answer against the question. An empty answer is only
+// refused for required questions.
+func (q *RegistrationQuestion) ValidateAnswer(answer string) error {
+	answer = strings.TrimSpace(answer)
+	if answer == "" {
+		if q.IsRequired {
+			return fmt.Errorf("%q is required", q.Label)
+		}
+		return nil
+	}
+	if len(answer) > maxAnswerLength {
+		return fmt.Errorf("answer to %q is longer than %d characters", q.Label, maxAnswerLength)
+	}
+	if q.Type == QuestionTypeSelect {
+		for _, option := range q.Options {
+			if strings.EqualFold(option, answer) {
+				return nil
+			}
+		}
+		return fmt.Errorf("answer to %q must be one of: %s", q.Label, strings.Join(q.Options, ", "))
+	}
+	return nil
+}
+
+// TicketAnswer is a ticket holder's answer to one registration question
+type TicketAnswer struct {
+	ID         uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	TicketID   uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_ticket_answer" json:"ticket_id"`
+	QuestionID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_ticket_answer;index" json:"question_id"`
+	Answer     string    `gorm:"type:text" json:"answer"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+
+	// Relationships
+	Question RegistrationQuestion `gorm:"foreignKey:QuestionID" json:"question,omitempty"`
+}
+
+func (a *TicketAnswer) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRegistrationQuestionValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		question RegistrationQuestion
+		valid    bool
+	}{
+		{"Text question", RegistrationQuestion{Label: "Company", Type: QuestionTypeText}, true},
+		{"Select question", RegistrationQuestion{Label: "T-shirt size", Type: QuestionTypeSelect, Options: []string{"S", "M", "L"}}, true},
+		{"Missing label", RegistrationQuestion{Label: " ", Type: QuestionTypeText}, false},
+		{"Text with options", RegistrationQuestion{Label: "Company", Type: QuestionTypeText, Options: []string{"A"}}, false},
+		{"Select with one option", RegistrationQuestion{Label: "Size", Type: QuestionTypeSelect, Options: []string{"M"}}, false},
+		{"Select with duplicate options", RegistrationQuestion{Label: "Size", Type: QuestionTypeSelect, Options: []string{"M", "m"}}, false},
+		{"Unknown type", RegistrationQuestion{Label: "Size", Type: "checkbox"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.question.Validate(); (err == nil) != tt.valid {
+				t.Errorf("Validate() = %v, expected valid %v", err, tt.valid)
+			}
+		})
+	}
+}
+
+func TestRegistrationQuestionValidateAnswer(t *testing.T) {
+	required := RegistrationQuestion{Label: "Dietary needs", Type: QuestionTypeText, IsRequired: true}
+	optional := RegistrationQuestion{Label: "Company", Type: QuestionTypeText}
+	size := RegistrationQuestion{Label: "T-shirt size", Type: QuestionTypeSelect, Options: []string{"S", "M", "L"}}
+
+	tests := []struct {
+		name     string
+		question RegistrationQuestion
+		answer   string
+		valid    bool
+	}{
+		{"Required answered", required, "None", true},
+		{"Required blank", required, "   ", false},
+		{"Optional blank", optional, "", true},
+		{"Too long", optional, strings.Repeat("a", maxAnswerLength+1), false},
+		{"Select option in any case", size, "m", true},
+		{"Select unknown option", size, "XL", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.question.ValidateAnswer(tt.answer); (err == nil) != tt.valid {
+				t.Errorf("ValidateAnswer(%q) = %v, expected valid %v", tt.answer, err, tt.valid)
+			}
+		})
+	}
+}
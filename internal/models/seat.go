@@ -0,0 +1,50 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type SeatStatus string
+
+const (
+	SeatStatusAvailable SeatStatus = "available"
+	SeatStatusHeld      SeatStatus = "held" // Picked by a checkout awaiting payment
+	SeatStatusSold      SeatStatus = "sold"
+)
+
+// Seat is one place on an event's seat map. Its ticket type sets the price level.
+// A seat moves between statuses only through conditional updates, so two buyers
+// can never hold or buy the same seat.
+type Seat struct {
+	ID            uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	EventID       uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_seat_position" json:"event_id"`
+	TicketTypeID  uuid.UUID  `gorm:"type:uuid;not null;index" json:"ticket_type_id"`
+	Section       string     `gorm:"not null;uniqueIndex:idx_seat_position" json:"section"`
+	Row           string     `gorm:"not null;uniqueIndex:idx_seat_position" json:"row"`
+	Number        string     `gorm:"not null;uniqueIndex:idx_seat_position" json:"number"`
+	Position      int        `gorm:"not null" json:"position"` // Order on the seat map
+	Status        SeatStatus `gorm:"type:varchar(20);not null;default:'available';index" json:"status"`
+	TransactionID *uuid.UUID `gorm:"type:uuid;index" json:"transaction_id,omitempty"`  // Checkout holding the seat or purchase that bought it
+	TicketID      *uuid.UUID `gorm:"type:uuid;uniqueIndex" json:"ticket_id,omitempty"` // Ticket issued for the seat
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+
+	// Relationships
+	TicketType TicketType `gorm:"foreignKey:TicketTypeID" json:"ticket_type,omitempty"`
+}
+
+func (s *Seat) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+// Label returns the seat as printed on tickets, e.g. "Section A, Row C, Seat 12"
+func (s *Seat) Label() string {
+	return fmt.Sprintf("Section %s, Row %s, Seat %s", s.Section, s.Row, s.Number)
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// EventSession is one occurrence of a recurring or multi-session event, such as
+// one week of a class or one day of a festival. The event is moderated once as a
+// series and spans all of its sessions.
+type EventSession struct {
+	ID        uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	EventID   uuid.UUID `gorm:"type:uuid;not null;index" json:"event_id"`
+	Title     string    `json:"title,omitempty"` // For example "Day 2"
+	StartDate time.Time `gorm:"not null" json:"start_date"`
+	EndDate   time.Time `gorm:"not null" json:"end_date"`
+	Capacity  int       `gorm:"default:0" json:"capacity"` // Most people admitted across its ticket types and series passes; 0 leaves it to the ticket types
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Relationships
+	TicketTypes []TicketType `gorm:"foreignKey:SessionID" json:"ticket_types,omitempty"`
+}
+
+func (s *EventSession) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+// SessionCheckIn records a series pass admitted to one session. Passes stay
+// confirmed so they can be scanned again at the next session.
+type SessionCheckIn struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	TicketID    uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_session_check_in" json:"ticket_id"`
+	SessionID   uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_session_check_in;index" json:"session_id"`
+	CheckedInAt time.Time `gorm:"not null" json:"checked_in_at"`
+	CheckedInBy uuid.UUID `gorm:"type:uuid;not null" json:"checked_in_by"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func (c *SessionCheckIn) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
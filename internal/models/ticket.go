@@ -1,6 +1,7 @@
 package models
 
 import (
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,6 +15,22 @@ const (
 	TicketStatusConfirmed TicketStatus = "confirmed"
 	TicketStatusCancelled TicketStatus = "cancelled"
 	TicketStatusUsed      TicketStatus = "used"
+	TicketStatusRefunded  TicketStatus = "refunded"
+)
+
+// CheckInResult describes the outcome of scanning a ticket at the gate
+type CheckInResult string
+
+const (
+	CheckInResultValid        CheckInResult = "valid"
+	CheckInResultAlreadyUsed  CheckInResult = "already_used"
+	CheckInResultWrongEvent   CheckInResult = "wrong_event"
+	CheckInResultCancelled    CheckInResult = "cancelled"
+	CheckInResultNotConfirmed CheckInResult = "not_confirmed"
+	CheckInResultUnknown      CheckInResult = "unknown"
+	CheckInResultForged       CheckInResult = "invalid_signature"
+	CheckInResultWrongSection CheckInResult = "wrong_section"
+	CheckInResultWrongSession CheckInResult = "wrong_session"
 )
 
 type Ticket struct {
@@ -25,22 +42,39 @@ type Ticket struct {
 	TransactionID uuid.UUID `gorm:"type:uuid;not null" json:"transaction_id"`
 
 	Status    TicketStatus `gorm:"type:varchar(20);not null;default:'pending'" json:"status"`
-	Price     float64      `gorm:"not null" json:"price"`
+	Price     int64        `gorm:"not null" json:"price"` // Minor units
 	QRCodeURL string       `json:"qr_code_url"`
 	PDFURL    string       `json:"pdf_url"`
+	QRKeyID   string       `gorm:"index" json:"-"` // Signing key used for the current QR code
+
+	// Reserved seating: SeatLabel is kept after a refund frees the seat
+	SeatID    *uuid.UUID `gorm:"type:uuid;index" json:"seat_id,omitempty"`
+	SeatLabel string     `json:"seat_label,omitempty"`
+
+	// Named tickets: the person the ticket is for when it is not the attendee who owns it
+	HolderName  string `json:"holder_name,omitempty"`
+	HolderEmail string `json:"holder_email,omitempty"`
+
+	// Multi-session events: the session the ticket admits to; nil for a series pass
+	SessionID *uuid.UUID `gorm:"type:uuid;index" json:"session_id,omitempty"`
 
 	CheckedInAt *time.Time `json:"checked_in_at,omitempty"`
 	CheckedInBy *uuid.UUID `gorm:"type:uuid" json:"checked_in_by,omitempty"`
 
+	RefundTransactionID *uuid.UUID `gorm:"type:uuid;index" json:"refund_transaction_id,omitempty"`
+
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 
 	// Relationships
-	Event       Event       `gorm:"foreignKey:EventID" json:"event,omitempty"`
-	TicketType  TicketType  `gorm:"foreignKey:TicketTypeID" json:"ticket_type,omitempty"`
-	Attendee    User        `gorm:"foreignKey:AttendeeID" json:"attendee,omitempty"`
-	Transaction Transaction `gorm:"foreignKey:TransactionID" json:"transaction,omitempty"`
+	Event       Event          `gorm:"foreignKey:EventID" json:"event,omitempty"`
+	TicketType  TicketType     `gorm:"foreignKey:TicketTypeID" json:"ticket_type,omitempty"`
+	Attendee    User           `gorm:"foreignKey:AttendeeID" json:"attendee,omitempty"`
+	Transaction Transaction    `gorm:"foreignKey:TransactionID" json:"transaction,omitempty"`
+	Seat        *Seat          `gorm:"foreignKey:SeatID" json:"seat,omitempty"`
+	Session     *EventSession  `gorm:"foreignKey:SessionID" json:"session,omitempty"`
+	Answers     []TicketAnswer `gorm:"foreignKey:TicketID" json:"answers,omitempty"`
 }
 
 func (t *Ticket) BeforeCreate(tx *gorm.DB) error {
@@ -48,11 +82,56 @@ func (t *Ticket) BeforeCreate(tx *gorm.DB) error {
 		t.ID = uuid.New()
 	}
 	if t.TicketNumber == "" {
-		t.TicketNumber = generateTicketNumber()
+		t.TicketNumber = GenerateTicketNumber()
 	}
 	return nil
 }
 
-func generateTicketNumber() string {
+// Holder returns the name and email of the person the ticket is for: the registered
+// holder, or the attendee who owns the ticket when nobody was named
+func (t *Ticket) Holder(attendee *User) (string, string) {
+	name := t.HolderName
+	if name == "" {
+		name = strings.TrimSpace(attendee.FirstName + " " + attendee.LastName)
+	}
+	email := t.HolderEmail
+	if email == "" {
+		email = attendee.Email
+	}
+	return name, email
+}
+
+// CheckInGate is where a ticket is scanned: the event, and optionally the section
+// of a seated venue and the session of a multi-session event
+type CheckInGate struct {
+	EventID   uuid.UUID
+	Section   string
+	SessionID *uuid.UUID
+}
+
+// CheckInResultFor explains why a ticket that was not checked in was refused at the
+// gate. A gate that names its section only admits seated tickets for that section;
+// the ticket's Seat must be loaded for the check. A gate that names its session only
+// admits tickets for that session and series passes.
+func (t *Ticket) CheckInResultFor(gate CheckInGate) CheckInResult {
+	switch {
+	case t.EventID != gate.EventID:
+		return CheckInResultWrongEvent
+	case gate.SessionID != nil && t.SessionID != nil && *t.SessionID != *gate.SessionID:
+		return CheckInResultWrongSession
+	case t.Status == TicketStatusUsed:
+		return CheckInResultAlreadyUsed
+	case t.Status == TicketStatusCancelled, t.Status == TicketStatusRefunded:
+		return CheckInResultCancelled
+	case t.Status != TicketStatusConfirmed:
+		return CheckInResultNotConfirmed
+	case gate.Section != "" && t.Seat != nil && !strings.EqualFold(t.Seat.Section, gate.Section):
+		return CheckInResultWrongSection
+	}
+	return CheckInResultValid
+}
+
+// GenerateTicketNumber returns a new random ticket number
+func GenerateTicketNumber() string {
 	return "TKT-" + uuid.New().String()[:8]
 }
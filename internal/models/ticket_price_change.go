@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TicketPriceChange records a price change to a ticket type of a published event,
+// so buyers charged different prices for the same ticket can be accounted for
+type TicketPriceChange struct {
+	ID           uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	TicketTypeID uuid.UUID `gorm:"type:uuid;not null;index" json:"ticket_type_id"`
+	EventID      uuid.UUID `gorm:"type:uuid;not null;index" json:"event_id"`
+	OldPrice     int64     `gorm:"not null" json:"old_price"` // Minor units
+	NewPrice     int64     `gorm:"not null" json:"new_price"` // Minor units
+	Reason       string    `gorm:"type:text;not null" json:"reason"`
+	ChangedBy    uuid.UUID `gorm:"type:uuid;not null" json:"changed_by"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func (c *TicketPriceChange) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
@@ -3,6 +3,8 @@ package models
 import (
 	"testing"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 func TestTicketTypeIsAvailable(t *testing.T) {
@@ -46,6 +48,18 @@ func TestTicketTypeIsAvailable(t *testing.T) {
 			},
 			expected: false,
 		},
+		{
+			name: "Remaining tickets held by checkouts",
+			ticketType: TicketType{
+				IsActive:  true,
+				Quantity:  100,
+				Sold:      90,
+				Reserved:  10,
+				SaleStart: now.Add(-1 * time.Hour),
+				SaleEnd:   now.Add(1 * time.Hour),
+			},
+			expected: false,
+		},
 		{
 			name: "Sale not started",
 			ticketType: TicketType{
@@ -85,12 +99,15 @@ func TestTicketTypeRemainingTickets(t *testing.T) {
 		name     string
 		quantity int
 		sold     int
+		reserved int
 		expected int
 	}{
-		{"Half sold", 100, 50, 50},
-		{"None sold", 100, 0, 100},
-		{"All sold", 100, 100, 0},
-		{"Over sold (edge case)", 100, 105, -5},
+		{"Half sold", 100, 50, 0, 50},
+		{"None sold", 100, 0, 0, 100},
+		{"All sold", 100, 100, 0, 0},
+		{"Over sold (edge case)", 100, 105, 0, -5},
+		{"Some held", 100, 50, 20, 30},
+		{"Rest held", 100, 60, 40, 0},
 	}
 
 	for _, tt := range tests {
@@ -98,6 +115,7 @@ func TestTicketTypeRemainingTickets(t *testing.T) {
 			ticketType := &TicketType{
 				Quantity: tt.quantity,
 				Sold:     tt.sold,
+				Reserved: tt.reserved,
 			}
 			result := ticketType.RemainingTickets()
 			if result != tt.expected {
@@ -106,3 +124,37 @@ func TestTicketTypeRemainingTickets(t *testing.T) {
 		})
 	}
 }
+
+func TestTicketCheckInResultFor(t *testing.T) {
+	eventID := uuid.New()
+	sessionID := uuid.New()
+	otherSessionID := uuid.New()
+
+	tests := []struct {
+		name     string
+		ticket   Ticket
+		expected CheckInResult
+	}{
+		{"Confirmed ticket", Ticket{EventID: eventID, Status: TicketStatusConfirmed}, CheckInResultValid},
+		{"Used ticket", Ticket{EventID: eventID, Status: TicketStatusUsed}, CheckInResultAlreadyUsed},
+		{"Cancelled ticket", Ticket{EventID: eventID, Status: TicketStatusCancelled}, CheckInResultCancelled},
+		{"Refunded ticket", Ticket{EventID: eventID, Status: TicketStatusRefunded}, CheckInResultCancelled},
+		{"Pending ticket", Ticket{EventID: eventID, Status: TicketStatusPending}, CheckInResultNotConfirmed},
+		{"Other event", Ticket{EventID: uuid.New(), Status: TicketStatusConfirmed}, CheckInResultWrongEvent},
+		{"Seat in gate section", Ticket{EventID: eventID, Status: TicketStatusConfirmed, Seat: &Seat{Section: "Balcony"}}, CheckInResultValid},
+		{"Seat in other section", Ticket{EventID: eventID, Status: TicketStatusConfirmed, Seat: &Seat{Section: "Stalls"}}, CheckInResultWrongSection},
+		{"Used seat in other section", Ticket{EventID: eventID, Status: TicketStatusUsed, Seat: &Seat{Section: "Stalls"}}, CheckInResultAlreadyUsed},
+		{"Ticket for gate session", Ticket{EventID: eventID, Status: TicketStatusConfirmed, SessionID: &sessionID}, CheckInResultValid},
+		{"Ticket for other session", Ticket{EventID: eventID, Status: TicketStatusConfirmed, SessionID: &otherSessionID}, CheckInResultWrongSession},
+		{"Used ticket for other session", Ticket{EventID: eventID, Status: TicketStatusUsed, SessionID: &otherSessionID}, CheckInResultWrongSession},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.ticket.CheckInResultFor(CheckInGate{EventID: eventID, Section: "balcony", SessionID: &sessionID})
+			if result != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, result)
+			}
+		})
+	}
+}
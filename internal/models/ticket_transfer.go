@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type TransferStatus string
+
+const (
+	TransferStatusPending   TransferStatus = "pending"
+	TransferStatusAccepted  TransferStatus = "accepted"
+	TransferStatusDeclined  TransferStatus = "declined"
+	TransferStatusCancelled TransferStatus = "cancelled"
+)
+
+// TicketTransfer offers a ticket to someone else by email. The ticket only changes
+// hands once the recipient accepts it while signed in with that email address.
+type TicketTransfer struct {
+	ID         uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	TicketID   uuid.UUID      `gorm:"type:uuid;not null;index" json:"ticket_id"`
+	EventID    uuid.UUID      `gorm:"type:uuid;not null;index" json:"event_id"`
+	FromUserID uuid.UUID      `gorm:"type:uuid;not null;index" json:"from_user_id"`
+	ToEmail    string         `gorm:"not null;index" json:"to_email"` // Stored lower case
+	ToUserID   *uuid.UUID     `gorm:"type:uuid" json:"to_user_id,omitempty"`
+	Message    string         `gorm:"type:text" json:"message,omitempty"`
+	Status     TransferStatus `gorm:"type:varchar(20);not null;default:'pending';index" json:"status"`
+
+	// Ticket number the ticket had before the transfer; its QR code stops working on accept
+	PreviousTicketNumber string `json:"-"`
+
+	RespondedAt *time.Time `json:"responded_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+
+	// Relationships
+	Ticket   Ticket `gorm:"foreignKey:TicketID" json:"ticket,omitempty"`
+	Event    Event  `gorm:"foreignKey:EventID" json:"event,omitempty"`
+	FromUser User   `gorm:"foreignKey:FromUserID" json:"from_user,omitempty"`
+}
+
+func (t *TicketTransfer) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
@@ -16,9 +16,12 @@ const (
 	TransactionStatusFailed    TransactionStatus = "failed"
 	TransactionStatusRefunded  TransactionStatus = "refunded"
 
+	TransactionStatusPartiallyRefunded TransactionStatus = "partially_refunded"
+
 	TransactionTypeTicketPurchase TransactionType = "ticket_purchase"
 	TransactionTypeRefund         TransactionType = "refund"
 	TransactionTypeWithdrawal     TransactionType = "withdrawal"
+	TransactionTypeComplimentary  TransactionType = "complimentary" // Free tickets issued by the organizer
 )
 
 type Transaction struct {
@@ -27,27 +30,72 @@ type Transaction struct {
 	EventID     *uuid.UUID        `gorm:"type:uuid" json:"event_id,omitempty"`
 	Type        TransactionType   `gorm:"type:varchar(30);not null" json:"type"`
 	Status      TransactionStatus `gorm:"type:varchar(20);not null;default:'pending'" json:"status"`
-	Amount      float64           `gorm:"not null" json:"amount"`
+	Amount      int64             `gorm:"not null" json:"amount"` // Minor units, like every amount below
 	Currency    string            `gorm:"default:'NGN'" json:"currency"`
-	PlatformFee float64           `gorm:"default:0" json:"platform_fee"`
-	NetAmount   float64           `gorm:"not null" json:"net_amount"`
+	PlatformFee int64             `gorm:"default:0" json:"platform_fee"`
+	NetAmount   int64             `gorm:"not null" json:"net_amount"`
 
 	// Payment gateway details
 	PaymentGateway   string  `json:"payment_gateway"`
 	PaymentReference string  `gorm:"uniqueIndex" json:"payment_reference"`
 	PaymentMetadata  *string `gorm:"type:jsonb" json:"payment_metadata,omitempty"`
+	GatewayReference string  `gorm:"index" json:"gateway_reference,omitempty"` // Gateway-side ID, e.g. the Paystack refund ID
+
+	// Discount taken off the ticket prices at checkout; Amount is what was charged
+	PromoCodeID    *uuid.UUID `gorm:"type:uuid;index" json:"promo_code_id,omitempty"`
+	DiscountAmount int64      `gorm:"default:0" json:"discount_amount"`
+
+	// Access code that unlocked hidden ticket types in the order
+	AccessCodeID *uuid.UUID `gorm:"type:uuid;index" json:"access_code_id,omitempty"`
+
+	// Refunds point at the purchase they return money for
+	ParentTransactionID *uuid.UUID `gorm:"type:uuid;index" json:"parent_transaction_id,omitempty"`
 
 	Description   string `json:"description"`
 	FailureReason string `json:"failure_reason,omitempty"`
 
+	// Fulfilment: tickets are issued at FulfilledAt, their QR codes, PDFs and
+	// emails are produced afterwards and DeliveredAt is set once that succeeds
+	FulfilledAt         *time.Time `json:"fulfilled_at,omitempty"`
+	DeliveredAt         *time.Time `json:"delivered_at,omitempty"`
+	DeliveryAttempts    int        `gorm:"default:0" json:"-"`
+	DeliveryError       string     `json:"-"`
+	DeliveryLockedUntil *time.Time `json:"-"`
+
+	// ReconciledAt is when a pending purchase was last checked with the gateway
+	ReconciledAt *time.Time `json:"-"`
+
+	// RefundLockedUntil leases a refund of an unfulfilled payment to the job sending it to the gateway
+	RefundLockedUntil *time.Time `json:"-"`
+
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 
 	// Relationships
-	User    User     `gorm:"foreignKey:UserID" json:"user,omitempty"`
-	Event   *Event   `gorm:"foreignKey:EventID" json:"event,omitempty"`
-	Tickets []Ticket `gorm:"foreignKey:TransactionID" json:"tickets,omitempty"`
+	User      User       `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Event     *Event     `gorm:"foreignKey:EventID" json:"event,omitempty"`
+	PromoCode *PromoCode `gorm:"foreignKey:PromoCodeID" json:"promo_code,omitempty"`
+	Tickets   []Ticket   `gorm:"foreignKey:TransactionID" json:"tickets,omitempty"`
+}
+
+// transactionTransitions lists the statuses each status may move to
+var transactionTransitions = map[TransactionStatus][]TransactionStatus{
+	TransactionStatusPending:   {TransactionStatusCompleted, TransactionStatusFailed},
+	TransactionStatusFailed:    {TransactionStatusCompleted}, // payment confirmed after an earlier failure
+	TransactionStatusCompleted: {TransactionStatusRefunded, TransactionStatusPartiallyRefunded},
+
+	TransactionStatusPartiallyRefunded: {TransactionStatusRefunded, TransactionStatusPartiallyRefunded},
+}
+
+// CanTransitionTo reports whether a transaction in this status may move to next
+func (s TransactionStatus) CanTransitionTo(next TransactionStatus) bool {
+	for _, allowed := range transactionTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
 }
 
 func (t *Transaction) BeforeCreate(tx *gorm.DB) error {
@@ -0,0 +1,32 @@
+package models
+
+import "testing"
+
+func TestTransactionStatusCanTransitionTo(t *testing.T) {
+	tests := []struct {
+		name     string
+		from     TransactionStatus
+		to       TransactionStatus
+		expected bool
+	}{
+		{"Pending to completed", TransactionStatusPending, TransactionStatusCompleted, true},
+		{"Pending to failed", TransactionStatusPending, TransactionStatusFailed, true},
+		{"Failed to completed (late payment)", TransactionStatusFailed, TransactionStatusCompleted, true},
+		{"Completed to refunded", TransactionStatusCompleted, TransactionStatusRefunded, true},
+		{"Completed to partially refunded", TransactionStatusCompleted, TransactionStatusPartiallyRefunded, true},
+		{"Partially refunded to refunded", TransactionStatusPartiallyRefunded, TransactionStatusRefunded, true},
+		{"Completed to failed", TransactionStatusCompleted, TransactionStatusFailed, false},
+		{"Completed to completed", TransactionStatusCompleted, TransactionStatusCompleted, false},
+		{"Refunded to completed", TransactionStatusRefunded, TransactionStatusCompleted, false},
+		{"Pending to refunded", TransactionStatusPending, TransactionStatusRefunded, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.from.CanTransitionTo(tt.to)
+			if result != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
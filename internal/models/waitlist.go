@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type WaitlistStatus string
+
+const (
+	WaitlistStatusWaiting   WaitlistStatus = "waiting"
+	WaitlistStatusOffered   WaitlistStatus = "offered"
+	WaitlistStatusPurchased WaitlistStatus = "purchased"
+	WaitlistStatusExpired   WaitlistStatus = "expired"
+	WaitlistStatusCancelled WaitlistStatus = "cancelled"
+)
+
+// WaitlistEntry queues a buyer for a sold-out ticket type. When stock comes back the
+// earliest entry is offered its quantity: the tickets are added to the ticket type's
+// Reserved count so nobody else can buy them until OfferExpiresAt.
+type WaitlistEntry struct {
+	ID           uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	EventID      uuid.UUID      `gorm:"type:uuid;not null;index" json:"event_id"`
+	TicketTypeID uuid.UUID      `gorm:"type:uuid;not null;index:idx_waitlist_queue" json:"ticket_type_id"`
+	UserID       uuid.UUID      `gorm:"type:uuid;not null;index" json:"user_id"`
+	Quantity     int            `gorm:"not null" json:"quantity"`
+	Status       WaitlistStatus `gorm:"type:varchar(20);not null;default:'waiting';index:idx_waitlist_queue" json:"status"`
+
+	// Offer
+	OfferToken     *string    `gorm:"uniqueIndex" json:"-"` // Sent in the purchase link
+	OfferedAt      *time.Time `json:"offered_at,omitempty"`
+	OfferExpiresAt *time.Time `gorm:"index" json:"offer_expires_at,omitempty"`
+	TransactionID  *uuid.UUID `gorm:"type:uuid" json:"transaction_id,omitempty"` // Purchase that used the offer
+
+	CreatedAt time.Time `gorm:"index:idx_waitlist_queue" json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Relationships
+	Event      Event      `gorm:"foreignKey:EventID" json:"event,omitempty"`
+	TicketType TicketType `gorm:"foreignKey:TicketTypeID" json:"ticket_type,omitempty"`
+	User       User       `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+func (w *WaitlistEntry) BeforeCreate(tx *gorm.DB) error {
+	if w.ID == uuid.Nil {
+		w.ID = uuid.New()
+	}
+	return nil
+}
+
+// IsActive reports whether the entry is still queued or holding an offer
+func (w *WaitlistEntry) IsActive() bool {
+	return w.Status == WaitlistStatusWaiting || w.Status == WaitlistStatusOffered
+}
+
+// OfferValidAt reports whether the entry holds an offer that has not run out
+func (w *WaitlistEntry) OfferValidAt(now time.Time) bool {
+	return w.Status == WaitlistStatusOffered && w.OfferExpiresAt != nil && now.Before(*w.OfferExpiresAt)
+}
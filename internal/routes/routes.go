@@ -10,22 +10,30 @@ import (
 	"gorm.io/gorm"
 )
 
-func SetupRoutes(router *gin.Engine, db *gorm.DB, cfg *config.Config) {
-	// Initialize services
-	storageService, _ := services.NewStorageService(cfg)
-	emailService := services.NewEmailService(cfg)
-	twoFAService := services.NewTwoFAService(cfg)
-	paystackService := services.NewPaystackService(cfg)
-	qrcodeService := services.NewQRCodeService()
-	pdfService := services.NewPDFService()
-	imageService := services.NewImageService()
-
+// SetupRoutes registers the API on router, serving it with the shared service graph
+func SetupRoutes(router *gin.Engine, db *gorm.DB, cfg *config.Config, svc *services.Services) {
 	// Initialize handlers
-	authHandler := handlers.NewAuthHandler(db, cfg, emailService, twoFAService)
-	adminHandler := handlers.NewAdminHandler(db, cfg, emailService)
-	moderatorHandler := handlers.NewModeratorHandler(db, cfg, emailService)
-	organizerHandler := handlers.NewOrganizerHandler(db, cfg, storageService, imageService)
-	attendeeHandler := handlers.NewAttendeeHandler(db, cfg, paystackService, storageService, qrcodeService, pdfService, emailService)
+	authHandler := handlers.NewAuthHandler(db, cfg, svc.Email, svc.TwoFA)
+	adminHandler := handlers.NewAdminHandler(db, cfg, svc.Email, svc.TicketDocuments, svc.Ledger, svc.Payout)
+	moderatorHandler := handlers.NewModeratorHandler(db, cfg, svc.Email, svc.Change, svc.Lifecycle)
+	organizerHandler := handlers.NewOrganizerHandler(db, cfg, svc.Storage, svc.Image, svc.TicketSigner, svc.Ledger, svc.Change, svc.Lifecycle)
+	attendeeHandler := handlers.NewAttendeeHandler(db, cfg, svc.PaymentGateways, svc.Storage, svc.Inventory, svc.Order, svc.PromoCode, svc.AccessCode, svc.Waitlist)
+	webhookHandler := handlers.NewWebhookHandler(db, cfg, svc.PaymentGateways, svc.Order, svc.Refund, svc.Payout, svc.Email)
+	refundHandler := handlers.NewRefundHandler(db, cfg, svc.Refund)
+	cancellationHandler := handlers.NewEventCancellationHandler(db, cfg, svc.Cancellation)
+	ledgerHandler := handlers.NewLedgerHandler(db, cfg, svc.Ledger)
+	payoutAccountHandler := handlers.NewPayoutAccountHandler(db, cfg, svc.PayoutAccount)
+	reconciliationHandler := handlers.NewReconciliationHandler(db, cfg, svc.Reconciliation)
+	promoCodeHandler := handlers.NewPromoCodeHandler(db, cfg)
+	accessCodeHandler := handlers.NewAccessCodeHandler(db, cfg, svc.AccessCode)
+	compTicketHandler := handlers.NewCompTicketHandler(db, cfg, svc.CompTicket)
+	transferHandler := handlers.NewTicketTransferHandler(db, cfg, svc.Transfer)
+	waitlistHandler := handlers.NewWaitlistHandler(db, cfg, svc.Waitlist)
+	seatHandler := handlers.NewSeatHandler(db, cfg, svc.Seat, svc.AccessCode)
+	registrationHandler := handlers.NewRegistrationHandler(db, cfg, svc.Registration)
+	sessionHandler := handlers.NewSessionHandler(db, cfg, svc.Session)
+	templateHandler := handlers.NewEventTemplateHandler(db, cfg, svc.Template)
+	ticketTypeHandler := handlers.NewTicketTypeHandler(db, cfg, svc.TicketType)
 
 	// Rate limiter
 	rate := limiter.Rate{
@@ -57,6 +65,7 @@ func SetupRoutes(router *gin.Engine, db *gorm.DB, cfg *config.Config) {
 			events.GET("", attendeeHandler.GetPublishedEvents)
 			events.GET("/featured", adminHandler.GetFeaturedEvents)
 			events.GET("/:id", attendeeHandler.GetEventDetails)
+			events.GET("/:id/seats", seatHandler.GetEventSeats)
 		}
 
 		// Public category routes
@@ -65,7 +74,9 @@ func SetupRoutes(router *gin.Engine, db *gorm.DB, cfg *config.Config) {
 
 		// Payment verification and callback
 		public.GET("/payments/verify", attendeeHandler.VerifyPayment)
-		public.GET("/payments/callback", attendeeHandler.VerifyPayment) // Paystack redirect endpoint
+		public.GET("/payments/callback", attendeeHandler.VerifyPayment) // Payment provider redirect endpoint
+		public.POST("/payments/webhook", webhookHandler.PaystackWebhook)
+		public.POST("/payments/webhook/:provider", webhookHandler.ProviderWebhook)
 	}
 
 	// Protected routes (require authentication)
@@ -98,6 +109,21 @@ func SetupRoutes(router *gin.Engine, db *gorm.DB, cfg *config.Config) {
 			admin.GET("/withdrawals", adminHandler.GetWithdrawalRequests)
 			admin.POST("/withdrawals/:id/review", adminHandler.ReviewWithdrawalRequest)
 			admin.POST("/withdrawals/:id/process", adminHandler.ProcessWithdrawal)
+			admin.POST("/withdrawals/:id/payout", adminHandler.SendWithdrawalPayout)
+			admin.POST("/withdrawals/payouts", adminHandler.SendWithdrawalPayouts)
+
+			// Payout accounts
+			admin.GET("/payout-accounts", payoutAccountHandler.GetPayoutAccounts)
+			admin.POST("/payout-accounts/:id/review", payoutAccountHandler.ReviewPayoutAccount)
+
+			// Ledger
+			admin.GET("/organizers/:id/statement", ledgerHandler.GetOrganizerStatement)
+			admin.GET("/ledger/check", ledgerHandler.CheckBalances)
+
+			// Payment reconciliation
+			admin.GET("/reconciliation/reports", reconciliationHandler.GetReports)
+			admin.GET("/reconciliation/reports/:id", reconciliationHandler.GetReport)
+			admin.POST("/reconciliation/reports", reconciliationHandler.RunReport)
 
 			// User management
 			admin.GET("/users", adminHandler.GetAllUsers)
@@ -114,6 +140,16 @@ func SetupRoutes(router *gin.Engine, db *gorm.DB, cfg *config.Config) {
 
 			// Featured events management
 			admin.PATCH("/events/:id/featured", adminHandler.ToggleEventFeatured)
+
+			// Event cancellation
+			admin.POST("/events/:id/cancel", cancellationHandler.AdminCancelEvent)
+			admin.GET("/events/:id/cancellation", cancellationHandler.AdminGetCancellation)
+
+			// Refunds (override the event refund policy)
+			admin.POST("/transactions/:id/refund", refundHandler.AdminRefund)
+
+			// Ticket QR re-issue after signing key rotation
+			admin.POST("/tickets/reissue-qr", adminHandler.ReissueTicketQRCodes)
 		}
 
 		// Moderator routes
@@ -123,6 +159,10 @@ func SetupRoutes(router *gin.Engine, db *gorm.DB, cfg *config.Config) {
 			moderator.GET("/events/pending", moderatorHandler.GetPendingEvents)
 			moderator.GET("/events/:id", moderatorHandler.GetEventForReview)
 			moderator.POST("/events/:id/review", moderatorHandler.ReviewEvent)
+			moderator.GET("/events/:id/history", moderatorHandler.GetEventHistory)
+			moderator.GET("/event-changes/pending", moderatorHandler.GetPendingChanges)
+			moderator.GET("/event-changes/:id", moderatorHandler.GetChangeForReview)
+			moderator.POST("/event-changes/:id/review", moderatorHandler.ReviewChange)
 			moderator.GET("/stats", moderatorHandler.GetModerationStats)
 			moderator.GET("/reviews", moderatorHandler.GetMyReviews)
 		}
@@ -136,18 +176,87 @@ func SetupRoutes(router *gin.Engine, db *gorm.DB, cfg *config.Config) {
 			organizer.GET("/events", organizerHandler.GetMyEvents)
 			organizer.GET("/events/:id", organizerHandler.GetMyEvent)
 			organizer.PUT("/events/:id", organizerHandler.UpdateEvent)
+			organizer.GET("/events/:id/changes", organizerHandler.GetEventChanges)
 			organizer.POST("/events/:id/image", organizerHandler.UploadEventImage)
 			organizer.POST("/events/:id/submit", organizerHandler.SubmitEventForReview)
+			organizer.POST("/events/:id/withdraw", organizerHandler.WithdrawEvent)
 			organizer.POST("/events/:id/publish", organizerHandler.PublishEvent)
+			organizer.PUT("/events/:id/publishing", organizerHandler.SetPublishSchedule)
+			organizer.GET("/events/:id/history", organizerHandler.GetEventHistory)
+			organizer.POST("/events/:id/cancel", cancellationHandler.CancelEvent)
+			organizer.GET("/events/:id/cancellation", cancellationHandler.GetCancellation)
 			organizer.GET("/events/:id/stats", organizerHandler.GetEventStats)
+			organizer.POST("/events/:id/check-in", organizerHandler.CheckInTicket)
+			organizer.GET("/ticket-signing-keys", organizerHandler.GetTicketSigningKeys)
+
+			// Refunds
+			organizer.PUT("/events/:id/refund-policy", refundHandler.SetRefundPolicy)
+			organizer.GET("/events/:id/refunds", refundHandler.GetEventRefunds)
+			organizer.POST("/events/:id/refunds", refundHandler.RefundOrder)
+
+			// Ticket transfers
+			organizer.PUT("/events/:id/transfer-policy", transferHandler.SetTransferPolicy)
+
+			// Sessions of recurring and multi-session events
+			organizer.POST("/events/:id/sessions", sessionHandler.AddSessions)
+			organizer.GET("/events/:id/sessions", sessionHandler.GetSessions)
+			organizer.PUT("/sessions/:id", sessionHandler.UpdateSession)
+			organizer.DELETE("/sessions/:id", sessionHandler.DeleteSession)
+
+			// Cloning and templates
+			organizer.POST("/events/:id/clone", templateHandler.CloneEvent)
+			organizer.POST("/events/:id/template", templateHandler.SaveTemplate)
+			organizer.GET("/templates", templateHandler.GetTemplates)
+			organizer.DELETE("/templates/:id", templateHandler.DeleteTemplate)
+			organizer.POST("/templates/:id/events", templateHandler.CreateFromTemplate)
 
 			// Ticket type management
 			organizer.POST("/events/:id/ticket-types", organizerHandler.CreateTicketType)
+			organizer.PUT("/events/:id/ticket-types/order", ticketTypeHandler.ReorderTicketTypes)
+			organizer.GET("/events/:id/price-changes", ticketTypeHandler.GetPriceChanges)
+			organizer.PUT("/ticket-types/:id", ticketTypeHandler.UpdateTicketType)
+			organizer.POST("/ticket-types/:id/deactivate", ticketTypeHandler.DeactivateTicketType)
+			organizer.POST("/ticket-types/:id/activate", ticketTypeHandler.ActivateTicketType)
+			organizer.DELETE("/ticket-types/:id", ticketTypeHandler.DeleteTicketType)
+
+			// Reserved seating
+			organizer.PUT("/events/:id/seat-map", seatHandler.UploadSeatMap)
+			organizer.GET("/events/:id/seat-map", seatHandler.GetSeatMap)
+
+			// Registration questions and attendee exports
+			organizer.POST("/events/:id/questions", registrationHandler.CreateQuestion)
+			organizer.GET("/events/:id/questions", registrationHandler.GetQuestions)
+			organizer.PUT("/questions/:id", registrationHandler.UpdateQuestion)
+			organizer.DELETE("/questions/:id", registrationHandler.DeleteQuestion)
+			organizer.GET("/events/:id/attendees", registrationHandler.GetEventAttendees)
+
+			// Promo codes
+			organizer.POST("/events/:id/promo-codes", promoCodeHandler.CreatePromoCode)
+			organizer.GET("/events/:id/promo-codes", promoCodeHandler.GetPromoCodes)
+			organizer.PUT("/promo-codes/:id", promoCodeHandler.UpdatePromoCode)
+
+			// Access codes for hidden ticket types
+			organizer.POST("/events/:id/access-codes", accessCodeHandler.CreateAccessCode)
+			organizer.GET("/events/:id/access-codes", accessCodeHandler.GetAccessCodes)
+			organizer.POST("/access-codes/:id/revoke", accessCodeHandler.RevokeAccessCode)
+
+			// Complimentary tickets
+			organizer.POST("/events/:id/comp-tickets", compTicketHandler.IssueCompTickets)
+			organizer.GET("/events/:id/comp-tickets", compTicketHandler.GetCompTickets)
+
+			// Waitlists for sold-out ticket types
+			organizer.GET("/events/:id/waitlist", waitlistHandler.GetEventWaitlist)
 
 			// Financial management
 			organizer.GET("/balance", organizerHandler.GetOrganizerBalance)
 			organizer.POST("/withdrawals", organizerHandler.RequestWithdrawal)
 			organizer.GET("/withdrawals", organizerHandler.GetMyWithdrawals)
+
+			// Payout accounts
+			organizer.GET("/banks", payoutAccountHandler.ListBanks)
+			organizer.GET("/payout-accounts", payoutAccountHandler.GetMyPayoutAccounts)
+			organizer.POST("/payout-accounts", payoutAccountHandler.CreatePayoutAccount)
+			organizer.DELETE("/payout-accounts/:id", payoutAccountHandler.DeletePayoutAccount)
 		}
 
 		// Attendee routes (all authenticated users can purchase tickets)
@@ -157,10 +266,24 @@ func SetupRoutes(router *gin.Engine, db *gorm.DB, cfg *config.Config) {
 			tickets.GET("/my-tickets", attendeeHandler.GetMyTickets)
 			tickets.GET("/:id", attendeeHandler.GetTicketDetails)
 			tickets.GET("/:id/download", attendeeHandler.DownloadTicketPDF)
+			tickets.PUT("/:id/registration", registrationHandler.RegisterTicket)
+
+			// Ticket transfers
+			tickets.POST("/:id/transfer", transferHandler.RequestTransfer)
+			tickets.GET("/transfers", transferHandler.GetMyTransfers)
+			tickets.POST("/transfers/:id/accept", transferHandler.AcceptTransfer)
+			tickets.POST("/transfers/:id/decline", transferHandler.DeclineTransfer)
+			tickets.POST("/transfers/:id/cancel", transferHandler.CancelTransfer)
+
+			// Waitlists for sold-out ticket types
+			tickets.POST("/waitlist", waitlistHandler.JoinWaitlist)
+			tickets.GET("/waitlist", waitlistHandler.GetMyWaitlist)
+			tickets.DELETE("/waitlist/:id", waitlistHandler.LeaveWaitlist)
 		}
 
 		// Transaction routes
 		protected.GET("/transactions", attendeeHandler.GetTransactionHistory)
+		protected.POST("/transactions/:id/refund", refundHandler.RequestRefund)
 	}
 
 	// Serve static files for local storage
@@ -7,8 +7,17 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/warui/event-ticketing-api/internal/config"
+	"github.com/warui/event-ticketing-api/internal/services"
 )
 
+func newTestServices(t *testing.T, cfg *config.Config) *services.Services {
+	svc, err := services.NewServices(nil, cfg)
+	if err != nil {
+		t.Fatalf("Failed to create services: %v", err)
+	}
+	return svc
+}
+
 func TestSetupRoutes(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -23,7 +32,7 @@ func TestSetupRoutes(t *testing.T) {
 	}
 
 	// Setup routes without database (will fail on actual requests but routes should be registered)
-	SetupRoutes(router, nil, cfg)
+	SetupRoutes(router, nil, cfg, newTestServices(t, cfg))
 
 	// Test that routes are registered
 	routes := router.Routes()
@@ -84,7 +93,7 @@ func TestPublicRoutes(t *testing.T) {
 		LocalStoragePath: "./test_storage",
 	}
 
-	SetupRoutes(router, nil, cfg)
+	SetupRoutes(router, nil, cfg, newTestServices(t, cfg))
 
 	publicRoutes := []struct {
 		method string
@@ -93,6 +102,7 @@ func TestPublicRoutes(t *testing.T) {
 		{"POST", "/api/v1/auth/register"},
 		{"POST", "/api/v1/auth/login"},
 		{"GET", "/api/v1/events"},
+		{"POST", "/api/v1/payments/webhook"},
 	}
 
 	for _, route := range publicRoutes {
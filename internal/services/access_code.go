@@ -0,0 +1,131 @@
+package services
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/warui/event-ticketing-api/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrAccessCodeInvalid is returned when an access code is unknown, revoked, expired or used up
+var ErrAccessCodeInvalid = errors.New("access code is not valid")
+
+// accessCodeAlphabet leaves out letters and digits that are easily confused
+const accessCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// AccessCodeUsage counts the purchases made with an access code
+type AccessCodeUsage struct {
+	AccessCodeID uuid.UUID `json:"-"`
+	Uses         int64     `json:"uses"`         // Orders holding or having used the code
+	TicketsSold  int64     `json:"tickets_sold"` // Tickets issued and not refunded or cancelled
+}
+
+// AccessCodeService checks the codes that unlock hidden ticket types
+type AccessCodeService struct {
+	db *gorm.DB
+}
+
+func NewAccessCodeService(db *gorm.DB) *AccessCodeService {
+	return &AccessCodeService{db: db}
+}
+
+// GenerateCode returns a random code that is easy to read out and type
+func (s *AccessCodeService) GenerateCode() (string, error) {
+	code := make([]byte, 10)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(accessCodeAlphabet))))
+		if err != nil {
+			return "", fmt.Errorf("failed to generate access code: %w", err)
+		}
+		code[i] = accessCodeAlphabet[n.Int64()]
+	}
+	return string(code), nil
+}
+
+// Find returns the event's access code with the ticket types it unlocks, if it can still be used
+func (s *AccessCodeService) Find(eventID uuid.UUID, code string, now time.Time) (*models.AccessCode, error) {
+	return s.findTx(s.db, false, eventID, code, now)
+}
+
+// UnlockTx locks the event's access code so concurrent checkouts cannot exceed its
+// uses, and returns it if it can still be used. It must run in the transaction
+// that creates the purchase.
+func (s *AccessCodeService) UnlockTx(tx *gorm.DB, eventID uuid.UUID, code string, now time.Time) (*models.AccessCode, error) {
+	return s.findTx(tx, true, eventID, code, now)
+}
+
+func (s *AccessCodeService) findTx(tx *gorm.DB, lock bool, eventID uuid.UUID, code string, now time.Time) (*models.AccessCode, error) {
+	query := tx
+	if lock {
+		query = tx.Clauses(clause.Locking{Strength: "UPDATE"})
+	}
+
+	var accessCode models.AccessCode
+	if err := query.First(&accessCode, "event_id = ? AND code = ?", eventID, models.NormalizeCode(code)).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAccessCodeInvalid
+		}
+		return nil, fmt.Errorf("failed to load access code: %w", err)
+	}
+	if !accessCode.IsValidAt(now) {
+		return nil, ErrAccessCodeInvalid
+	}
+
+	if accessCode.MaxUses > 0 {
+		var uses int64
+		if err := tx.Model(&models.Transaction{}).
+			Where("access_code_id = ? AND type = ?", accessCode.ID, models.TransactionTypeTicketPurchase).
+			Scopes(codeUseScope(now)).
+			Count(&uses).Error; err != nil {
+			return nil, fmt.Errorf("failed to count access code uses: %w", err)
+		}
+		if uses >= int64(accessCode.MaxUses) {
+			return nil, fmt.Errorf("%w: code has been used up", ErrAccessCodeInvalid)
+		}
+	}
+
+	if err := tx.Model(&accessCode).Association("TicketTypes").Find(&accessCode.TicketTypes); err != nil {
+		return nil, fmt.Errorf("failed to load access code ticket types: %w", err)
+	}
+	return &accessCode, nil
+}
+
+// Usage counts the purchases made with each of the event's access codes
+func (s *AccessCodeService) Usage(eventID uuid.UUID) (map[uuid.UUID]AccessCodeUsage, error) {
+	var uses []AccessCodeUsage
+	if err := s.db.Model(&models.Transaction{}).
+		Select("access_code_id, COUNT(*) AS uses").
+		Where("event_id = ? AND access_code_id IS NOT NULL AND type = ?", eventID, models.TransactionTypeTicketPurchase).
+		Scopes(codeUseScope(time.Now())).
+		Group("access_code_id").Scan(&uses).Error; err != nil {
+		return nil, fmt.Errorf("failed to count access code uses: %w", err)
+	}
+
+	var sold []AccessCodeUsage
+	if err := s.db.Model(&models.Ticket{}).
+		Select("transactions.access_code_id, COUNT(*) AS tickets_sold").
+		Joins("JOIN transactions ON transactions.id = tickets.transaction_id").
+		Where("tickets.event_id = ? AND transactions.access_code_id IS NOT NULL AND tickets.status IN ?",
+			eventID, []models.TicketStatus{models.TicketStatusConfirmed, models.TicketStatusUsed}).
+		Group("transactions.access_code_id").Scan(&sold).Error; err != nil {
+		return nil, fmt.Errorf("failed to count access code tickets: %w", err)
+	}
+
+	usage := make(map[uuid.UUID]AccessCodeUsage)
+	for _, u := range uses {
+		usage[u.AccessCodeID] = u
+	}
+	for _, u := range sold {
+		entry := usage[u.AccessCodeID]
+		entry.AccessCodeID = u.AccessCodeID
+		entry.TicketsSold = u.TicketsSold
+		usage[u.AccessCodeID] = entry
+	}
+	return usage, nil
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/warui/event-ticketing-api/internal/models"
+	"gorm.io/gorm"
+)
+
+func TestAccessCodeUnlock(t *testing.T) {
+	db := setupInventoryDB(t)
+	accessCodeService := NewAccessCodeService(db)
+	ticketType := createTestTicketType(t, db, 10)
+	db.Model(ticketType).Update("is_hidden", true)
+
+	var event models.Event
+	db.First(&event, "id = ?", ticketType.EventID)
+
+	newCode := func(maxUses int, expiresAt, revokedAt *time.Time) *models.AccessCode {
+		code, err := accessCodeService.GenerateCode()
+		if err != nil {
+			t.Fatalf("GenerateCode failed: %v", err)
+		}
+		accessCode := &models.AccessCode{
+			EventID:     event.ID,
+			Code:        code,
+			MaxUses:     maxUses,
+			ExpiresAt:   expiresAt,
+			RevokedAt:   revokedAt,
+			CreatedBy:   event.OrganizerID,
+			TicketTypes: []models.TicketType{*ticketType},
+		}
+		if err := db.Omit("TicketTypes.*").Create(accessCode).Error; err != nil {
+			t.Fatalf("Failed to create access code: %v", err)
+		}
+		return accessCode
+	}
+
+	past := time.Now().Add(-time.Hour)
+	open := newCode(0, nil, nil)
+	expired := newCode(0, &past, nil)
+	revoked := newCode(0, nil, &past)
+	single := newCode(1, nil, nil)
+
+	// A pending checkout holds the single use while its tickets are held
+	checkout := &models.Transaction{
+		UserID:           event.OrganizerID,
+		EventID:          &event.ID,
+		Type:             models.TransactionTypeTicketPurchase,
+		Status:           models.TransactionStatusPending,
+		Amount:           100000,
+		NetAmount:        100000,
+		PaymentReference: "TXN-ACCESS-" + uuid.New().String(),
+		AccessCodeID:     &single.ID,
+	}
+	if err := db.Create(checkout).Error; err != nil {
+		t.Fatalf("Failed to create transaction: %v", err)
+	}
+	hold := &models.InventoryHold{TransactionID: checkout.ID, TicketTypeID: ticketType.ID, Quantity: 1, ExpiresAt: time.Now().Add(15 * time.Minute)}
+	if err := db.Create(hold).Error; err != nil {
+		t.Fatalf("Failed to create hold: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		code  string
+		valid bool
+	}{
+		{"Open", open.Code, true},
+		{"Typed in lower case", " " + strings.ToLower(open.Code), true},
+		{"Expired", expired.Code, false},
+		{"Revoked", revoked.Code, false},
+		{"Used up", single.Code, false},
+		{"Unknown", "NOT-A-CODE", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := db.Transaction(func(tx *gorm.DB) error {
+				accessCode, err := accessCodeService.UnlockTx(tx, event.ID, tt.code, time.Now())
+				if err == nil && !accessCode.Unlocks(ticketType.ID) {
+					t.Errorf("Expected the code to unlock %s", ticketType.ID)
+				}
+				return err
+			})
+			if tt.valid && err != nil {
+				t.Errorf("Expected the code to be valid, got %v", err)
+			}
+			if !tt.valid && !errors.Is(err, ErrAccessCodeInvalid) {
+				t.Errorf("Expected ErrAccessCodeInvalid, got %v", err)
+			}
+		})
+	}
+
+	// The abandoned checkout gives the use back once its hold expires
+	db.Model(hold).Update("expires_at", time.Now().Add(-time.Minute))
+	if _, err := accessCodeService.Find(event.ID, single.Code, time.Now()); err != nil {
+		t.Errorf("Expected the released use to be available, got %v", err)
+	}
+}
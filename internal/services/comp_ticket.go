@@ -0,0 +1,315 @@
+package services
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/warui/event-ticketing-api/internal/auth"
+	"github.com/warui/event-ticketing-api/internal/config"
+	"github.com/warui/event-ticketing-api/internal/models"
+	"gorm.io/gorm"
+)
+
+// ErrInvalidGuestList is returned when a guest list cannot be read or is empty
+var ErrInvalidGuestList = errors.New("invalid guest list")
+
+// MaxCompRecipients is the most guests one request may issue tickets to
+const MaxCompRecipients = 500
+
+// guestAccountTokenTTL is how long a new guest has to choose a password
+const guestAccountTokenTTL = 7 * 24 * time.Hour
+
+// CompRecipient is one guest on a complimentary ticket list
+type CompRecipient struct {
+	Email     string `json:"email" binding:"required,email"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Quantity  int    `json:"quantity" binding:"min=0"` // Defaults to 1
+}
+
+// CompTicketRequest issues tickets of one ticket type to a guest list
+type CompTicketRequest struct {
+	EventID      uuid.UUID
+	TicketTypeID uuid.UUID
+	IssuedBy     uuid.UUID
+	Note         string
+	Recipients   []CompRecipient
+}
+
+// CompTicketResult is what one guest received
+type CompTicketResult struct {
+	Email          string    `json:"email"`
+	UserID         uuid.UUID `json:"user_id"`
+	TransactionID  uuid.UUID `json:"transaction_id"`
+	Quantity       int       `json:"quantity"`
+	AccountCreated bool      `json:"account_created"`
+}
+
+// CompTicketService issues free tickets to guests chosen by the organizer
+type CompTicketService struct {
+	db           *gorm.DB
+	cfg          *config.Config
+	orderService *OrderService
+	emailService *EmailService
+}
+
+func NewCompTicketService(db *gorm.DB, cfg *config.Config, orderService *OrderService, emailService *EmailService) *CompTicketService {
+	return &CompTicketService{
+		db:           db,
+		cfg:          cfg,
+		orderService: orderService,
+		emailService: emailService,
+	}
+}
+
+// ParseCompRecipientsCSV reads a guest list with a header row. An email column is
+// required; first_name, last_name and quantity are optional.
+func ParseCompRecipientsCSV(r io.Reader) ([]CompRecipient, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidGuestList, err)
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	if _, ok := columns["email"]; !ok {
+		return nil, fmt.Errorf("%w: missing email column", ErrInvalidGuestList)
+	}
+
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var recipients []CompRecipient
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidGuestList, err)
+		}
+
+		recipient := CompRecipient{
+			Email:     field(record, "email"),
+			FirstName: field(record, "first_name"),
+			LastName:  field(record, "last_name"),
+		}
+		if recipient.Email == "" {
+			continue
+		}
+		if quantity := field(record, "quantity"); quantity != "" {
+			recipient.Quantity, err = strconv.Atoi(quantity)
+			if err != nil || recipient.Quantity < 0 {
+				return nil, fmt.Errorf("%w: line %d: invalid quantity %q", ErrInvalidGuestList, line, quantity)
+			}
+		}
+		recipients = append(recipients, recipient)
+	}
+
+	return recipients, nil
+}
+
+// normalizeRecipients checks every email and merges guests listed more than once
+func normalizeRecipients(recipients []CompRecipient) ([]CompRecipient, error) {
+	var merged []CompRecipient
+	index := make(map[string]int)
+	for _, recipient := range recipients {
+		address, err := mail.ParseAddress(recipient.Email)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid email %q", ErrInvalidGuestList, recipient.Email)
+		}
+		recipient.Email = strings.ToLower(address.Address)
+		if recipient.Quantity == 0 {
+			recipient.Quantity = 1
+		}
+
+		if i, ok := index[recipient.Email]; ok {
+			merged[i].Quantity += recipient.Quantity
+			continue
+		}
+		index[recipient.Email] = len(merged)
+		merged = append(merged, recipient)
+	}
+
+	if len(merged) == 0 {
+		return nil, fmt.Errorf("%w: no recipients", ErrInvalidGuestList)
+	}
+	if len(merged) > MaxCompRecipients {
+		return nil, fmt.Errorf("%w: at most %d recipients per request", ErrInvalidGuestList, MaxCompRecipients)
+	}
+	return merged, nil
+}
+
+// IssueTickets gives every guest their tickets as a zero-value transaction. The
+// whole list is issued or none of it is: stock is taken in one database
+// transaction, and accounts are created for guests who do not have one. QR codes,
+// PDFs and emails are produced afterwards, like paid tickets.
+func (s *CompTicketService) IssueTickets(req CompTicketRequest) ([]CompTicketResult, error) {
+	recipients, err := normalizeRecipients(req.Recipients)
+	if err != nil {
+		return nil, err
+	}
+
+	var event models.Event
+	if err := s.db.First(&event, "id = ?", req.EventID).Error; err != nil {
+		return nil, fmt.Errorf("failed to load event: %w", err)
+	}
+	if event.Status == models.EventStatusCancelled {
+		return nil, ErrEventCancelled
+	}
+
+	var ticketType models.TicketType
+	if err := s.db.First(&ticketType, "id = ? AND event_id = ?", req.TicketTypeID, event.ID).Error; err != nil {
+		return nil, fmt.Errorf("failed to load ticket type: %w", err)
+	}
+
+	now := time.Now()
+	results := make([]CompTicketResult, 0, len(recipients))
+	var transactions []*models.Transaction
+	invites := make(map[uuid.UUID]string)
+	users := make(map[uuid.UUID]*models.User)
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		for _, recipient := range recipients {
+			user, token, err := s.findOrCreateGuestTx(tx, recipient, now)
+			if err != nil {
+				return err
+			}
+			users[user.ID] = user
+			if token != "" {
+				invites[user.ID] = token
+			}
+
+			// Comp tickets are issued at no charge: the whole line is discounted
+			items := []cartItem{{TicketTypeID: ticketType.ID, Quantity: recipient.Quantity, Discount: ticketType.Price * int64(recipient.Quantity)}}
+			metadata, _ := json.Marshal(map[string]interface{}{
+				"event_id":  event.ID.String(),
+				"issued_by": req.IssuedBy.String(),
+				"items": []map[string]interface{}{
+					{"ticket_type_id": ticketType.ID.String(), "quantity": recipient.Quantity, "price": ticketType.Price, "discount": items[0].Discount, "name": ticketType.Name},
+				},
+			})
+			paymentMetadata := string(metadata)
+
+			description := req.Note
+			if description == "" {
+				description = fmt.Sprintf("Complimentary tickets for %s", event.Title)
+			}
+
+			transaction := &models.Transaction{
+				UserID:           user.ID,
+				EventID:          &event.ID,
+				Type:             models.TransactionTypeComplimentary,
+				Status:           models.TransactionStatusCompleted,
+				Currency:         s.cfg.Currency,
+				PaymentReference: fmt.Sprintf("COMP-%s-%d", uuid.New().String()[:8], now.Unix()),
+				PaymentMetadata:  &paymentMetadata,
+				Description:      description,
+				FulfilledAt:      &now,
+			}
+			if err := tx.Create(transaction).Error; err != nil {
+				return fmt.Errorf("failed to create transaction: %w", err)
+			}
+
+			if err := s.orderService.inventoryService.SellTx(tx, transaction.ID, stockRequests(items)); err != nil {
+				return err
+			}
+			if err := s.orderService.issueTicketsTx(tx, transaction, items); err != nil {
+				return err
+			}
+
+			transactions = append(transactions, transaction)
+			results = append(results, CompTicketResult{
+				Email:          user.Email,
+				UserID:         user.ID,
+				TransactionID:  transaction.ID,
+				Quantity:       recipient.Quantity,
+				AccountCreated: token != "",
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Documents for a long list take a while; the delivery retry job picks up
+	// anything this misses
+	go func() {
+		for _, transaction := range transactions {
+			if err := s.orderService.DeliverTickets(transaction); err != nil {
+				log.Printf("Ticket delivery for complimentary transaction %s failed, will retry: %v", transaction.ID, err)
+			}
+		}
+		for userID, token := range invites {
+			if err := s.emailService.SendGuestAccountEmail(users[userID], &event, token); err != nil {
+				log.Printf("Failed to send guest account email to %s: %v", users[userID].Email, err)
+			}
+		}
+	}()
+
+	return results, nil
+}
+
+// findOrCreateGuestTx returns the guest's account, creating one with an unusable
+// password if needed. For new accounts it also returns a token to choose a password.
+func (s *CompTicketService) findOrCreateGuestTx(tx *gorm.DB, recipient CompRecipient, now time.Time) (*models.User, string, error) {
+	var user models.User
+	err := tx.Where("LOWER(email) = ?", recipient.Email).First(&user).Error
+	if err == nil {
+		return &user, "", nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, "", fmt.Errorf("failed to load user: %w", err)
+	}
+
+	secret, err := s.emailService.GenerateVerificationToken()
+	if err != nil {
+		return nil, "", err
+	}
+	password, err := auth.HashPassword(secret)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to hash password: %w", err)
+	}
+	token, err := s.emailService.GenerateVerificationToken()
+	if err != nil {
+		return nil, "", err
+	}
+	expiry := now.Add(guestAccountTokenTTL)
+
+	firstName := recipient.FirstName
+	if firstName == "" {
+		firstName = strings.Split(recipient.Email, "@")[0]
+	}
+	user = models.User{
+		Email:               recipient.Email,
+		Password:            password,
+		FirstName:           firstName,
+		LastName:            recipient.LastName,
+		Role:                models.RoleAttendee,
+		PasswordResetToken:  &token,
+		PasswordResetExpiry: &expiry,
+	}
+	if err := tx.Create(&user).Error; err != nil {
+		return nil, "", fmt.Errorf("failed to create account for %s: %w", recipient.Email, err)
+	}
+	return &user, token, nil
+}
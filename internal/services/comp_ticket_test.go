@@ -0,0 +1,145 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/warui/event-ticketing-api/internal/models"
+)
+
+func TestParseCompRecipientsCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		csv     string
+		want    []CompRecipient
+		wantErr bool
+	}{
+		{
+			name: "All columns in any order",
+			csv:  "quantity,Email,first_name,last_name\n2,ada@example.com,Ada,Lovelace\n,bob@example.com,,\n",
+			want: []CompRecipient{
+				{Email: "ada@example.com", FirstName: "Ada", LastName: "Lovelace", Quantity: 2},
+				{Email: "bob@example.com"},
+			},
+		},
+		{
+			name: "Email only, blank rows skipped",
+			csv:  "email\nada@example.com\n\n , \n",
+			want: []CompRecipient{{Email: "ada@example.com"}},
+		},
+		{name: "Missing email column", csv: "name\nAda\n", wantErr: true},
+		{name: "Invalid quantity", csv: "email,quantity\nada@example.com,two\n", wantErr: true},
+		{name: "Empty file", csv: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCompRecipientsCSV(strings.NewReader(tt.csv))
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidGuestList) {
+					t.Errorf("Expected ErrInvalidGuestList, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestIssueCompTickets(t *testing.T) {
+	db := setupInventoryDB(t)
+	orderService := newTestOrderService(t, db)
+	service := NewCompTicketService(db, orderService.cfg, orderService, orderService.emailService)
+	ticketType := createTestTicketType(t, db, 5)
+
+	var event models.Event
+	db.First(&event, "id = ?", ticketType.EventID)
+
+	existing := &models.User{
+		Email:     fmt.Sprintf("guest-%s@example.com", uuid.New().String()[:8]),
+		Password:  "hashed",
+		FirstName: "Existing",
+		LastName:  "Guest",
+		Role:      models.RoleAttendee,
+	}
+	if err := db.Create(existing).Error; err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+	newEmail := fmt.Sprintf("new-%s@example.com", uuid.New().String()[:8])
+
+	// More tickets than are left: nothing is issued
+	_, err := service.IssueTickets(CompTicketRequest{
+		EventID:      event.ID,
+		TicketTypeID: ticketType.ID,
+		IssuedBy:     event.OrganizerID,
+		Recipients:   []CompRecipient{{Email: newEmail, Quantity: 2}, {Email: existing.Email, Quantity: 4}},
+	})
+	if !errors.Is(err, ErrInsufficientStock) {
+		t.Fatalf("Expected ErrInsufficientStock, got %v", err)
+	}
+	var accounts int64
+	db.Model(&models.User{}).Where("email = ?", newEmail).Count(&accounts)
+	if accounts != 0 {
+		t.Errorf("Expected the failed request to create no accounts, got %d", accounts)
+	}
+
+	results, err := service.IssueTickets(CompTicketRequest{
+		EventID:      event.ID,
+		TicketTypeID: ticketType.ID,
+		IssuedBy:     event.OrganizerID,
+		Recipients: []CompRecipient{
+			{Email: strings.ToUpper(existing.Email)},
+			{Email: newEmail, FirstName: "New"},
+			{Email: existing.Email, Quantity: 2},
+		},
+	})
+	if err != nil {
+		t.Fatalf("IssueTickets failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected duplicate emails to be merged into 2 recipients, got %d", len(results))
+	}
+	if results[0].UserID != existing.ID || results[0].Quantity != 3 || results[0].AccountCreated {
+		t.Errorf("Expected 3 tickets for the existing account, got %+v", results[0])
+	}
+	if !results[1].AccountCreated {
+		t.Errorf("Expected an account to be created for %s", newEmail)
+	}
+
+	var guest models.User
+	db.First(&guest, "id = ?", results[1].UserID)
+	if guest.PasswordResetToken == nil || guest.PasswordResetExpiry == nil {
+		t.Error("Expected the new account to have a token to choose a password")
+	}
+
+	var tickets []models.Ticket
+	db.Where("event_id = ?", event.ID).Find(&tickets)
+	if len(tickets) != 4 {
+		t.Fatalf("Expected 4 tickets, got %d", len(tickets))
+	}
+	for _, ticket := range tickets {
+		if ticket.Price != 0 || ticket.Status != models.TicketStatusConfirmed {
+			t.Errorf("Expected a free confirmed ticket, got price %d status %s", ticket.Price, ticket.Status)
+		}
+	}
+
+	var transaction models.Transaction
+	db.First(&transaction, "id = ?", results[0].TransactionID)
+	if transaction.Type != models.TransactionTypeComplimentary || transaction.Amount != 0 || transaction.FulfilledAt == nil {
+		t.Errorf("Expected a fulfilled zero-value complimentary transaction, got %+v", transaction)
+	}
+
+	var updated models.TicketType
+	db.First(&updated, "id = ?", ticketType.ID)
+	if updated.Sold != 4 {
+		t.Errorf("Expected 4 tickets taken from stock, got %d", updated.Sold)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"fmt"
+	"html"
+	"strings"
 
 	"github.com/resendlabs/resend-go/v2"
 	"github.com/warui/event-ticketing-api/internal/config"
@@ -65,14 +67,14 @@ func (e *EmailService) SendTicketEmail(ticket *models.Ticket, event *models.Even
 			<p><strong>Ticket Number:</strong> %s</p>
 			<p><strong>Venue:</strong> %s</p>
 			<p><strong>Date:</strong> %s</p>
-			<p><strong>Price:</strong> %s %.2f</p>
+			<p><strong>Price:</strong> %s %s</p>
 			<br>
 			<p>Please find your ticket PDF attached. Show the QR code at the venue for entry.</p>
 			<p>See you at the event!</p>
 			<br>
 			<p>Best regards,<br>Event Ticketing Team</p>
 		`, user.FirstName, event.Title, ticket.TicketNumber, event.Venue,
-			event.StartDate.Format("Mon, Jan 2, 2006 at 3:04 PM"), e.cfg.Currency, ticket.Price),
+			event.StartDate.Format("Mon, Jan 2, 2006 at 3:04 PM"), e.cfg.Currency, models.FormatAmount(ticket.Price)),
 	}
 
 	// Attach PDF if available
@@ -97,6 +99,9 @@ func (e *EmailService) SendEventApprovalEmail(event *models.Event, organizer *mo
 
 	status := "Approved"
 	message := "Your event has been approved and is now live!"
+	if approved && event.Status == models.EventStatusApproved && event.PublishAt != nil {
+		message = fmt.Sprintf("Your event has been approved and will go live on %s.", event.PublishAt.Format("Mon, Jan 2, 2006 at 3:04 PM"))
+	}
 	if !approved {
 		status = "Rejected"
 		message = fmt.Sprintf("Your event has been rejected. Reason: %s", event.ModerationComment)
@@ -124,6 +129,230 @@ func (e *EmailService) SendEventApprovalEmail(event *models.Event, organizer *mo
 	return err
 }
 
+// SendEventCancellationEmail tells a ticket holder an event was cancelled and their tickets refunded
+func (e *EmailService) SendEventCancellationEmail(event *models.Event, attendee *models.User, reason string) error {
+	if e.cfg.ResendAPIKey == "" {
+		return fmt.Errorf("email service not configured")
+	}
+
+	reasonHTML := ""
+	if reason != "" {
+		reasonHTML = fmt.Sprintf("<p><strong>Reason:</strong> %s</p>", html.EscapeString(reason))
+	}
+
+	params := &resend.SendEmailRequest{
+		From:    fmt.Sprintf("%s <%s>", e.cfg.FromName, e.cfg.FromEmail),
+		To:      []string{attendee.Email},
+		Subject: fmt.Sprintf("Event Cancelled: %s", event.Title),
+		Html: fmt.Sprintf(`
+			<h1>Event Cancelled</h1>
+			<p>Hi %s,</p>
+			<p>We're sorry to let you know that the following event has been cancelled:</p>
+			<h2>%s</h2>
+			<p><strong>Date:</strong> %s</p>
+			<p><strong>Venue:</strong> %s</p>
+			%s
+			<p>Your tickets are no longer valid. Paid tickets are refunded in full to your original payment method; refunds can take a few business days to appear.</p>
+			<br>
+			<p>Best regards,<br>Event Ticketing Team</p>
+		`, attendee.FirstName, event.Title,
+			event.StartDate.Format("Mon, Jan 2, 2006"), event.Venue, reasonHTML),
+	}
+
+	_, err := e.client.Emails.Send(params)
+	return err
+}
+
+// SendEventChangeEmail tells a ticket holder what changed about an event and sends
+// their tickets again, regenerated with the new details
+func (e *EmailService) SendEventChangeEmail(event *models.Event, attendee *models.User, changes []models.EventDetailChange, tickets []models.Ticket, pdfs [][]byte) error {
+	if e.cfg.ResendAPIKey == "" {
+		return fmt.Errorf("email service not configured")
+	}
+
+	var rows strings.Builder
+	for _, change := range changes {
+		fmt.Fprintf(&rows, "<tr><td><strong>%s</strong></td><td><s>%s</s></td><td>%s</td></tr>",
+			change.Field, html.EscapeString(change.Old), html.EscapeString(change.New))
+	}
+
+	params := &resend.SendEmailRequest{
+		From:    fmt.Sprintf("%s <%s>", e.cfg.FromName, e.cfg.FromEmail),
+		To:      []string{attendee.Email},
+		Subject: fmt.Sprintf("Event Updated: %s", event.Title),
+		Html: fmt.Sprintf(`
+			<h1>Event Updated</h1>
+			<p>Hi %s,</p>
+			<p>The organizer has changed the details of an event you have tickets for:</p>
+			<h2>%s</h2>
+			<table cellpadding="6">
+				<tr><th></th><th>Before</th><th>Now</th></tr>
+				%s
+			</table>
+			<p>Your tickets are attached again with the new details. Please use these from now on.</p>
+			<p>If you can no longer attend, you may be able to request a refund or transfer your tickets from your account.</p>
+			<br>
+			<p>Best regards,<br>Event Ticketing Team</p>
+		`, html.EscapeString(attendee.FirstName), html.EscapeString(event.Title), rows.String()),
+	}
+
+	for i, ticket := range tickets {
+		if i < len(pdfs) && len(pdfs[i]) > 0 {
+			params.Attachments = append(params.Attachments, &resend.Attachment{
+				Filename: fmt.Sprintf("ticket-%s.pdf", ticket.TicketNumber),
+				Content:  pdfs[i],
+			})
+		}
+	}
+
+	_, err := e.client.Emails.Send(params)
+	return err
+}
+
+// SendEventChangeReviewEmail tells an organizer whether a change to their published event was approved
+func (e *EmailService) SendEventChangeReviewEmail(change *models.EventChangeRequest, event *models.Event, organizer *models.User) error {
+	if e.cfg.ResendAPIKey == "" {
+		return fmt.Errorf("email service not configured")
+	}
+
+	status := "Approved"
+	message := "Your changes are now live, and every ticket holder is being sent the new details with updated tickets."
+	if change.Status == models.EventChangeStatusRejected {
+		status = "Rejected"
+		message = fmt.Sprintf("Your changes were not approved and the event keeps its current details. Reason: %s", html.EscapeString(change.ModerationComment))
+	}
+
+	params := &resend.SendEmailRequest{
+		From:    fmt.Sprintf("%s <%s>", e.cfg.FromName, e.cfg.FromEmail),
+		To:      []string{organizer.Email},
+		Subject: fmt.Sprintf("Event Change %s: %s", status, event.Title),
+		Html: fmt.Sprintf(`
+			<h1>Event Change %s</h1>
+			<p>Hi %s,</p>
+			<p>%s</p>
+			<br>
+			<p>Best regards,<br>Event Ticketing Team</p>
+		`, status, html.EscapeString(organizer.FirstName), message),
+	}
+
+	_, err := e.client.Emails.Send(params)
+	return err
+}
+
+// SendTicketTransferOfferEmail invites the recipient of a ticket transfer to accept it
+func (e *EmailService) SendTicketTransferOfferEmail(transfer *models.TicketTransfer, event *models.Event, sender *models.User) error {
+	if e.cfg.ResendAPIKey == "" {
+		return fmt.Errorf("email service not configured")
+	}
+
+	transferURL := fmt.Sprintf("%s/transfers/%s", e.cfg.FrontendURL, transfer.ID)
+
+	messageHTML := ""
+	if transfer.Message != "" {
+		messageHTML = fmt.Sprintf("<p><strong>Message:</strong> %s</p>", html.EscapeString(transfer.Message))
+	}
+
+	params := &resend.SendEmailRequest{
+		From:    fmt.Sprintf("%s <%s>", e.cfg.FromName, e.cfg.FromEmail),
+		To:      []string{transfer.ToEmail},
+		Subject: fmt.Sprintf("%s sent you a ticket for %s", sender.FirstName, event.Title),
+		Html: fmt.Sprintf(`
+			<h1>You've been sent a ticket</h1>
+			<p>%s %s wants to give you their ticket for:</p>
+			<h2>%s</h2>
+			<p><strong>Date:</strong> %s</p>
+			<p><strong>Venue:</strong> %s</p>
+			%s
+			<p>Sign in or create an account with this email address to accept it. Once you accept, a new ticket is issued in your name.</p>
+			<br>
+			<div style="text-align: center; margin: 30px 0;">
+				<a href="%s" style="background-color: #007bff; color: white; padding: 12px 24px; text-decoration: none; border-radius: 6px; display: inline-block; font-weight: bold;">
+					View Ticket Transfer
+				</a>
+			</div>
+			<br>
+			<p>If you weren't expecting this, you can ignore this email or decline the transfer.</p>
+			<br>
+			<p>Best regards,<br>Event Ticketing Team</p>
+		`, html.EscapeString(sender.FirstName), html.EscapeString(sender.LastName), html.EscapeString(event.Title),
+			event.StartDate.Format("Mon, Jan 2, 2006 at 3:04 PM"), html.EscapeString(event.Venue), messageHTML, transferURL),
+	}
+
+	_, err := e.client.Emails.Send(params)
+	return err
+}
+
+// SendTicketTransferredEmail tells the sender of a ticket transfer it was accepted
+// and their copy of the ticket no longer admits anyone
+func (e *EmailService) SendTicketTransferredEmail(transfer *models.TicketTransfer, event *models.Event, sender, recipient *models.User) error {
+	if e.cfg.ResendAPIKey == "" {
+		return fmt.Errorf("email service not configured")
+	}
+
+	params := &resend.SendEmailRequest{
+		From:    fmt.Sprintf("%s <%s>", e.cfg.FromName, e.cfg.FromEmail),
+		To:      []string{sender.Email},
+		Subject: fmt.Sprintf("Your ticket for %s has been transferred", event.Title),
+		Html: fmt.Sprintf(`
+			<h1>Ticket Transferred</h1>
+			<p>Hi %s,</p>
+			<p>%s %s (%s) accepted your ticket for <strong>%s</strong>.</p>
+			<p>Ticket <strong>%s</strong> is no longer valid and its QR code will not be admitted. A new ticket has been sent to the recipient.</p>
+			<br>
+			<p>Best regards,<br>Event Ticketing Team</p>
+		`, html.EscapeString(sender.FirstName), html.EscapeString(recipient.FirstName), html.EscapeString(recipient.LastName),
+			recipient.Email, html.EscapeString(event.Title), transfer.PreviousTicketNumber),
+	}
+
+	_, err := e.client.Emails.Send(params)
+	return err
+}
+
+// SendWaitlistOfferEmail sends a waitlisted buyer the link to buy the tickets set aside for them
+func (e *EmailService) SendWaitlistOfferEmail(entry *models.WaitlistEntry, event *models.Event, ticketType *models.TicketType, user *models.User) error {
+	if e.cfg.ResendAPIKey == "" {
+		return fmt.Errorf("email service not configured")
+	}
+	if entry.OfferToken == nil || entry.OfferExpiresAt == nil {
+		return fmt.Errorf("waitlist entry %s has no offer", entry.ID)
+	}
+
+	purchaseURL := fmt.Sprintf("%s/events/%s/checkout?ticket_type_id=%s&quantity=%d&waitlist_token=%s",
+		e.cfg.FrontendURL, event.ID, ticketType.ID, entry.Quantity, *entry.OfferToken)
+
+	params := &resend.SendEmailRequest{
+		From:    fmt.Sprintf("%s <%s>", e.cfg.FromName, e.cfg.FromEmail),
+		To:      []string{user.Email},
+		Subject: fmt.Sprintf("Tickets are available for %s", event.Title),
+		Html: fmt.Sprintf(`
+			<h1>Your tickets are waiting</h1>
+			<p>Hi %s,</p>
+			<p>Good news! Tickets you were waiting for have become available:</p>
+			<h2>%s</h2>
+			<p><strong>Ticket Type:</strong> %s</p>
+			<p><strong>Quantity:</strong> %d</p>
+			<p><strong>Date:</strong> %s</p>
+			<p>We're holding them for you until <strong>%s</strong>. After that they go to the next person on the waitlist.</p>
+			<br>
+			<div style="text-align: center; margin: 30px 0;">
+				<a href="%s" style="background-color: #007bff; color: white; padding: 12px 24px; text-decoration: none; border-radius: 6px; display: inline-block; font-weight: bold;">
+					Buy Tickets
+				</a>
+			</div>
+			<br>
+			<p>If the button doesn't work, you can also copy and paste this link into your browser:</p>
+			<p><a href="%s">%s</a></p>
+			<br>
+			<p>Best regards,<br>Event Ticketing Team</p>
+		`, html.EscapeString(user.FirstName), html.EscapeString(event.Title), html.EscapeString(ticketType.Name), entry.Quantity,
+			event.StartDate.Format("Mon, Jan 2, 2006 at 3:04 PM"), entry.OfferExpiresAt.Format("Mon, Jan 2, 2006 at 3:04 PM MST"),
+			purchaseURL, purchaseURL, purchaseURL),
+	}
+
+	_, err := e.client.Emails.Send(params)
+	return err
+}
+
 // SendWithdrawalStatusEmail notifies organizer about withdrawal request status
 func (e *EmailService) SendWithdrawalStatusEmail(withdrawal *models.WithdrawalRequest, organizer *models.User) error {
 	if e.cfg.ResendAPIKey == "" {
@@ -138,6 +367,8 @@ func (e *EmailService) SendWithdrawalStatusEmail(withdrawal *models.WithdrawalRe
 		statusMessage = fmt.Sprintf("Your withdrawal request has been rejected. Reason: %s", withdrawal.ReviewComment)
 	case models.WithdrawalStatusProcessed:
 		statusMessage = "Your withdrawal has been processed successfully. Funds should arrive in your account shortly."
+	case models.WithdrawalStatusFailed:
+		statusMessage = "We could not pay out your withdrawal. The amount has been returned to your available balance."
 	}
 
 	params := &resend.SendEmailRequest{
@@ -149,15 +380,15 @@ func (e *EmailService) SendWithdrawalStatusEmail(withdrawal *models.WithdrawalRe
 			<p>Hi %s,</p>
 			<p>%s</p>
 			<h3>Withdrawal Details:</h3>
-			<p><strong>Amount:</strong> %s %.2f</p>
-			<p><strong>Fee:</strong> %s %.2f</p>
-			<p><strong>Net Amount:</strong> %s %.2f</p>
+			<p><strong>Amount:</strong> %s %s</p>
+			<p><strong>Fee:</strong> %s %s</p>
+			<p><strong>Net Amount:</strong> %s %s</p>
 			<p><strong>Bank:</strong> %s</p>
 			<p><strong>Account:</strong> %s</p>
 			<br>
 			<p>Best regards,<br>Event Ticketing Team</p>
-		`, organizer.FirstName, statusMessage, e.cfg.Currency, withdrawal.Amount,
-			e.cfg.Currency, withdrawal.WithdrawalFee, e.cfg.Currency, withdrawal.NetAmount,
+		`, organizer.FirstName, statusMessage, e.cfg.Currency, models.FormatAmount(withdrawal.Amount),
+			e.cfg.Currency, models.FormatAmount(withdrawal.WithdrawalFee), e.cfg.Currency, models.FormatAmount(withdrawal.NetAmount),
 			withdrawal.BankName, withdrawal.AccountNumber),
 	}
 
@@ -212,6 +443,44 @@ func (e *EmailService) SendVerificationEmail(user *models.User, token string) er
 	return err
 }
 
+// SendGuestAccountEmail tells someone given complimentary tickets that an account
+// was created to hold them, with a link to choose a password
+func (e *EmailService) SendGuestAccountEmail(user *models.User, event *models.Event, token string) error {
+	if e.cfg.ResendAPIKey == "" {
+		return fmt.Errorf("email service not configured")
+	}
+
+	resetURL := fmt.Sprintf("%s/reset-password?token=%s", e.cfg.FrontendURL, token)
+
+	params := &resend.SendEmailRequest{
+		From:    fmt.Sprintf("%s <%s>", e.cfg.FromName, e.cfg.FromEmail),
+		To:      []string{user.Email},
+		Subject: fmt.Sprintf("You're on the guest list for %s", event.Title),
+		Html: fmt.Sprintf(`
+			<h1>You're on the guest list!</h1>
+			<p>Hi %s,</p>
+			<p>You have been given complimentary tickets for <strong>%s</strong>. Your tickets are attached to a separate email.</p>
+			<p>We created an account for you so you can find your tickets at any time. Choose a password to sign in:</p>
+			<br>
+			<div style="text-align: center; margin: 30px 0;">
+				<a href="%s" style="background-color: #007bff; color: white; padding: 12px 24px; text-decoration: none; border-radius: 6px; display: inline-block; font-weight: bold;">
+					Choose Password
+				</a>
+			</div>
+			<br>
+			<p>If the button doesn't work, you can also copy and paste this link into your browser:</p>
+			<p><a href="%s">%s</a></p>
+			<br>
+			<p><strong>This link will expire in 7 days.</strong> You can request a new one from the login page at any time.</p>
+			<br>
+			<p>Best regards,<br>Event Ticketing Team</p>
+		`, html.EscapeString(user.FirstName), html.EscapeString(event.Title), resetURL, resetURL, resetURL),
+	}
+
+	_, err := e.client.Emails.Send(params)
+	return err
+}
+
 // SendPasswordResetEmail sends password reset link
 func (e *EmailService) SendPasswordResetEmail(user *models.User, token string) error {
 	if e.cfg.ResendAPIKey == "" {
@@ -0,0 +1,245 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/warui/event-ticketing-api/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrEventNotCancellable is returned for events that can no longer be cancelled
+var ErrEventNotCancellable = errors.New("event cannot be cancelled")
+
+// cancellationLease is how long one worker may own a cancellation before another may resume it
+const cancellationLease = 5 * time.Minute
+
+// EventCancellationService cancels events and works through the follow-up refunds
+// and emails in small batches, saving progress after every purchase and attendee
+type EventCancellationService struct {
+	db            *gorm.DB
+	refundService *RefundService
+	emailService  *EmailService
+}
+
+func NewEventCancellationService(db *gorm.DB, refundService *RefundService, emailService *EmailService) *EventCancellationService {
+	return &EventCancellationService{
+		db:            db,
+		refundService: refundService,
+		emailService:  emailService,
+	}
+}
+
+// CancelEvent stops sales for an event and queues its refunds and notifications.
+// Cancelling an already cancelled event returns its cancellation, retrying any
+// purchases whose refunds failed.
+func (s *EventCancellationService) CancelEvent(eventID, requestedBy uuid.UUID, reason string) (*models.EventCancellation, error) {
+	var cancellation models.EventCancellation
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var event models.Event
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&event, "id = ?", eventID).Error; err != nil {
+			return err
+		}
+
+		if event.Status == models.EventStatusCompleted {
+			return fmt.Errorf("%w: event has already taken place", ErrEventNotCancellable)
+		}
+
+		if event.Status == models.EventStatusCancelled {
+			if err := tx.First(&cancellation, "event_id = ?", event.ID).Error; err != nil {
+				return err
+			}
+			if cancellation.Status != models.CancellationStatusCompleted || cancellation.FailedPurchases == 0 {
+				return nil
+			}
+
+			// Retry the purchases that could not be refunded; attendees were already notified
+			cancellation.Status = models.CancellationStatusRefunding
+			cancellation.FailedPurchases = 0
+			cancellation.LastTransactionID = nil
+			cancellation.LastError = ""
+			cancellation.CompletedAt = nil
+			return tx.Model(&cancellation).Select("status", "failed_purchases", "last_transaction_id", "last_error", "completed_at").Updates(&cancellation).Error
+		}
+
+		if err := transitionEventTx(tx, &event, models.EventStatusCancelled, &requestedBy, reason); err != nil {
+			return fmt.Errorf("failed to cancel event: %w", err)
+		}
+
+		// Stop sales
+		if err := tx.Model(&models.TicketType{}).Where("event_id = ?", event.ID).Update("is_active", false).Error; err != nil {
+			return fmt.Errorf("failed to stop ticket sales: %w", err)
+		}
+
+		// Complimentary tickets have nothing to refund and are cancelled straight away
+		if err := tx.Model(&models.Ticket{}).
+			Where("event_id = ? AND status = ? AND transaction_id IN (?)", event.ID, models.TicketStatusConfirmed,
+				tx.Model(&models.Transaction{}).Select("id").Where("type = ?", models.TransactionTypeComplimentary)).
+			Update("status", models.TicketStatusCancelled).Error; err != nil {
+			return fmt.Errorf("failed to cancel complimentary tickets: %w", err)
+		}
+
+		var purchases int64
+		if err := s.refundablePurchases(tx, event.ID).Count(&purchases).Error; err != nil {
+			return fmt.Errorf("failed to count purchases: %w", err)
+		}
+
+		cancellation = models.EventCancellation{
+			EventID:        event.ID,
+			RequestedBy:    requestedBy,
+			Reason:         reason,
+			Status:         models.CancellationStatusRefunding,
+			TotalPurchases: int(purchases),
+		}
+		return tx.Create(&cancellation).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &cancellation, nil
+}
+
+// ProcessPending runs one batch for every cancellation that still has work to do
+// and is not currently owned by another worker
+func (s *EventCancellationService) ProcessPending(batchSize int) error {
+	var cancellations []models.EventCancellation
+	if err := s.db.Where("status IN ? AND (locked_until IS NULL OR locked_until < ?)",
+		[]models.CancellationStatus{models.CancellationStatusRefunding, models.CancellationStatusNotifying}, time.Now()).
+		Order("created_at ASC").Find(&cancellations).Error; err != nil {
+		return fmt.Errorf("failed to load cancellations: %w", err)
+	}
+
+	for i := range cancellations {
+		if err := s.ProcessBatch(&cancellations[i], batchSize); err != nil {
+			log.Printf("Cancellation of event %s failed, will resume: %v", cancellations[i].EventID, err)
+		}
+	}
+
+	return nil
+}
+
+// ProcessBatch refunds or notifies up to batchSize purchases or attendees for one
+// cancellation. Progress is saved after each item, so a crash repeats at most one.
+func (s *EventCancellationService) ProcessBatch(cancellation *models.EventCancellation, batchSize int) error {
+	now := time.Now()
+	lease := s.db.Model(&models.EventCancellation{}).
+		Where("id = ? AND status IN ? AND (locked_until IS NULL OR locked_until < ?)", cancellation.ID,
+			[]models.CancellationStatus{models.CancellationStatusRefunding, models.CancellationStatusNotifying}, now).
+		Update("locked_until", now.Add(cancellationLease))
+	if lease.Error != nil {
+		return fmt.Errorf("failed to lease cancellation: %w", lease.Error)
+	}
+	if lease.RowsAffected == 0 {
+		return nil
+	}
+	defer s.db.Model(&models.EventCancellation{}).Where("id = ?", cancellation.ID).Update("locked_until", nil)
+
+	// Reload so a stale copy never rewinds the cursors
+	if err := s.db.First(cancellation, "id = ?", cancellation.ID).Error; err != nil {
+		return fmt.Errorf("failed to load cancellation: %w", err)
+	}
+
+	switch cancellation.Status {
+	case models.CancellationStatusRefunding:
+		return s.refundBatch(cancellation, batchSize)
+	case models.CancellationStatusNotifying:
+		return s.notifyBatch(cancellation, batchSize)
+	}
+	return nil
+}
+
+// refundBatch refunds the next purchases in full and cancels their tickets
+func (s *EventCancellationService) refundBatch(cancellation *models.EventCancellation, batchSize int) error {
+	query := s.refundablePurchases(s.db, cancellation.EventID)
+	if cancellation.LastTransactionID != nil {
+		query = query.Where("id > ?", *cancellation.LastTransactionID)
+	}
+
+	var purchases []models.Transaction
+	if err := query.Order("id ASC").Limit(batchSize).Find(&purchases).Error; err != nil {
+		return fmt.Errorf("failed to load purchases: %w", err)
+	}
+
+	for _, purchase := range purchases {
+		_, err := s.refundService.RefundTickets(RefundRequest{
+			TransactionID: purchase.ID,
+			Percentage:    100,
+			Reason:        "Event cancelled",
+			CancelTickets: true,
+		})
+
+		switch {
+		case err == nil, errors.Is(err, ErrNothingToRefund):
+			cancellation.RefundedPurchases++
+		default:
+			cancellation.FailedPurchases++
+			cancellation.LastError = fmt.Sprintf("%s: %v", purchase.PaymentReference, err)
+		}
+
+		purchaseID := purchase.ID
+		cancellation.LastTransactionID = &purchaseID
+		if err := s.db.Model(cancellation).Select("last_transaction_id", "refunded_purchases", "failed_purchases", "last_error").Updates(cancellation).Error; err != nil {
+			return fmt.Errorf("failed to save cancellation progress: %w", err)
+		}
+	}
+
+	if len(purchases) < batchSize {
+		cancellation.Status = models.CancellationStatusNotifying
+		return s.db.Model(cancellation).Update("status", cancellation.Status).Error
+	}
+	return nil
+}
+
+// notifyBatch emails the next ticket holders
+func (s *EventCancellationService) notifyBatch(cancellation *models.EventCancellation, batchSize int) error {
+	var event models.Event
+	if err := s.db.First(&event, "id = ?", cancellation.EventID).Error; err != nil {
+		return fmt.Errorf("failed to load event: %w", err)
+	}
+
+	query := s.db.Model(&models.Ticket{}).
+		Where("event_id = ? AND status IN ?", event.ID, []models.TicketStatus{models.TicketStatusCancelled, models.TicketStatusConfirmed})
+	if cancellation.LastAttendeeID != nil {
+		query = query.Where("attendee_id > ?", *cancellation.LastAttendeeID)
+	}
+
+	var attendeeIDs []uuid.UUID
+	if err := query.Distinct("attendee_id").Order("attendee_id ASC").Limit(batchSize).Pluck("attendee_id", &attendeeIDs).Error; err != nil {
+		return fmt.Errorf("failed to load ticket holders: %w", err)
+	}
+
+	for _, attendeeID := range attendeeIDs {
+		var attendee models.User
+		if err := s.db.First(&attendee, "id = ?", attendeeID).Error; err == nil {
+			if err := s.emailService.SendEventCancellationEmail(&event, &attendee, cancellation.Reason); err != nil {
+				log.Printf("Failed to send cancellation email to %s: %v", attendee.Email, err)
+			}
+		}
+
+		id := attendeeID
+		cancellation.LastAttendeeID = &id
+		cancellation.NotifiedAttendees++
+		if err := s.db.Model(cancellation).Select("last_attendee_id", "notified_attendees").Updates(cancellation).Error; err != nil {
+			return fmt.Errorf("failed to save cancellation progress: %w", err)
+		}
+	}
+
+	if len(attendeeIDs) < batchSize {
+		now := time.Now()
+		cancellation.Status = models.CancellationStatusCompleted
+		cancellation.CompletedAt = &now
+		return s.db.Model(cancellation).Select("status", "completed_at").Updates(cancellation).Error
+	}
+	return nil
+}
+
+// refundablePurchases selects the paid purchases for an event that still hold tickets
+func (s *EventCancellationService) refundablePurchases(db *gorm.DB, eventID uuid.UUID) *gorm.DB {
+	return db.Model(&models.Transaction{}).Where("event_id = ? AND type = ? AND status IN ?", eventID, models.TransactionTypeTicketPurchase,
+		[]models.TransactionStatus{models.TransactionStatusCompleted, models.TransactionStatusPartiallyRefunded})
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/warui/event-ticketing-api/internal/models"
+)
+
+func TestCancelEventRefundsInResumableBatches(t *testing.T) {
+	db := setupInventoryDB(t)
+	orderService := newTestOrderService(t, db)
+	ticketType := createTestTicketType(t, db, 10)
+
+	// Free tickets keep the refunds off the payment gateway
+	db.Model(ticketType).Update("price", 0)
+
+	var event models.Event
+	db.First(&event, "id = ?", ticketType.EventID)
+
+	for i := 0; i < 3; i++ {
+		purchase := &models.Transaction{
+			UserID:           event.OrganizerID,
+			EventID:          &event.ID,
+			Type:             models.TransactionTypeTicketPurchase,
+			Status:           models.TransactionStatusPending,
+			Currency:         "NGN",
+			PaymentReference: fmt.Sprintf("TXN-CNL-%d-%s", i, ticketType.ID),
+		}
+		if err := db.Create(purchase).Error; err != nil {
+			t.Fatalf("Failed to create transaction: %v", err)
+		}
+
+		_, err := orderService.FulfillPayment(purchase, &PaymentResult{
+			Successful: true,
+			Metadata: map[string]interface{}{
+				"event_id": event.ID.String(),
+				"items": []interface{}{
+					map[string]interface{}{"ticket_type_id": ticketType.ID.String(), "quantity": float64(1)},
+				},
+			},
+		})
+		if err != nil {
+			t.Fatalf("Failed to fulfil purchase: %v", err)
+		}
+	}
+
+	refundService := NewRefundService(db, orderService.cfg, newTestGateways(t, orderService.cfg), orderService.ledgerService)
+	service := NewEventCancellationService(db, refundService, orderService.emailService)
+
+	cancellation, err := service.CancelEvent(event.ID, event.OrganizerID, "Venue unavailable")
+	if err != nil {
+		t.Fatalf("Failed to cancel event: %v", err)
+	}
+	if cancellation.TotalPurchases != 3 {
+		t.Errorf("Expected 3 purchases to refund, got %d", cancellation.TotalPurchases)
+	}
+
+	// Each batch reloads its progress, as a restarted worker would
+	for i := 0; i < 10 && cancellation.Status != models.CancellationStatusCompleted; i++ {
+		if err := service.ProcessBatch(cancellation, 2); err != nil {
+			t.Fatalf("Batch failed: %v", err)
+		}
+	}
+
+	if cancellation.Status != models.CancellationStatusCompleted {
+		t.Fatalf("Expected cancellation to complete, got %s", cancellation.Status)
+	}
+	if cancellation.RefundedPurchases != 3 || cancellation.FailedPurchases != 0 {
+		t.Errorf("Expected 3 refunded and 0 failed, got %d and %d", cancellation.RefundedPurchases, cancellation.FailedPurchases)
+	}
+	if cancellation.NotifiedAttendees != 1 {
+		t.Errorf("Expected 1 notified attendee, got %d", cancellation.NotifiedAttendees)
+	}
+
+	var confirmed int64
+	db.Model(&models.Ticket{}).Where("event_id = ? AND status <> ?", event.ID, models.TicketStatusCancelled).Count(&confirmed)
+	if confirmed != 0 {
+		t.Errorf("Expected every ticket cancelled, %d are not", confirmed)
+	}
+
+	var stored models.TicketType
+	db.First(&stored, "id = ?", ticketType.ID)
+	if stored.IsActive || stored.Sold != 0 {
+		t.Errorf("Expected sales stopped and stock returned, got active=%v sold=%d", stored.IsActive, stored.Sold)
+	}
+}
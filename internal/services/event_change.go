@@ -0,0 +1,302 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/warui/event-ticketing-api/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrInvalidEventChange is returned when an edit to a published event cannot be made as given
+	ErrInvalidEventChange = errors.New("invalid event change")
+	// ErrEventNotEditable is returned when an event's status does not allow the edit
+	ErrEventNotEditable = errors.New("event cannot be edited in its current status")
+	// ErrChangeNotPending is returned when reviewing a change that was already reviewed
+	ErrChangeNotPending = errors.New("change is not pending review")
+)
+
+// eventChangeLease is how long one worker may own a change's notifications before another may resume them
+const eventChangeLease = 5 * time.Minute
+
+// EventEdit is an organizer's edit to a published event. Description and category
+// apply straight away; changed details wait for a moderator.
+type EventEdit struct {
+	Description string
+	Category    string
+	Details     models.EventDetails
+}
+
+// EventChangeService edits published events. Material changes are queued for
+// moderation, and once approved every ticket holder is sent what changed with
+// regenerated tickets, in small batches that survive a restart.
+type EventChangeService struct {
+	db              *gorm.DB
+	ticketDocuments *TicketDocumentService
+	emailService    *EmailService
+}
+
+func NewEventChangeService(db *gorm.DB, ticketDocuments *TicketDocumentService, emailService *EmailService) *EventChangeService {
+	return &EventChangeService{
+		db:              db,
+		ticketDocuments: ticketDocuments,
+		emailService:    emailService,
+	}
+}
+
+// EditPublishedEvent applies the small parts of an edit and queues the material
+// ones. A new material edit replaces the event's change still waiting for review;
+// an edit that leaves the details as they are keeps it. The returned change is nil
+// when nothing waits for review.
+func (s *EventChangeService) EditPublishedEvent(eventID, organizerID uuid.UUID, edit EventEdit) (*models.Event, *models.EventChangeRequest, error) {
+	var event models.Event
+	var change *models.EventChangeRequest
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&event, "id = ? AND organizer_id = ?", eventID, organizerID).Error; err != nil {
+			return err
+		}
+		if event.Status != models.EventStatusPublished {
+			return fmt.Errorf("%w: event is %s", ErrEventNotEditable, event.Status)
+		}
+
+		event.Description = edit.Description
+		event.Category = edit.Category
+		if err := tx.Model(&event).Select("description", "category").Updates(&event).Error; err != nil {
+			return fmt.Errorf("failed to update event: %w", err)
+		}
+
+		current := event.Details()
+		proposed := edit.Details
+		proposed.Title = strings.TrimSpace(proposed.Title)
+		proposed.Venue = strings.TrimSpace(proposed.Venue)
+		if len(current.Changes(proposed)) == 0 {
+			return nil
+		}
+		if err := s.validateDetails(tx, &event, proposed); err != nil {
+			return err
+		}
+
+		change = &models.EventChangeRequest{}
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("event_id = ? AND status = ?", event.ID, models.EventChangeStatusPending).First(change).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			change = &models.EventChangeRequest{
+				EventID:     event.ID,
+				RequestedBy: organizerID,
+				Previous:    current,
+				Proposed:    proposed,
+				Status:      models.EventChangeStatusPending,
+			}
+			if err := tx.Create(change).Error; err != nil {
+				return fmt.Errorf("failed to queue event change: %w", err)
+			}
+		case err != nil:
+			return fmt.Errorf("failed to load pending change: %w", err)
+		default:
+			change.RequestedBy = organizerID
+			change.Previous = current
+			change.Proposed = proposed
+			if err := tx.Model(change).Select("requested_by", "previous", "proposed").Updates(change).Error; err != nil {
+				return fmt.Errorf("failed to update pending change: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &event, change, nil
+}
+
+// validateDetails checks proposed details against the event. The dates of a
+// series follow its sessions, so they are changed through the sessions.
+func (s *EventChangeService) validateDetails(tx *gorm.DB, event *models.Event, proposed models.EventDetails) error {
+	if proposed.Title == "" || proposed.Venue == "" {
+		return fmt.Errorf("%w: title and venue are required", ErrInvalidEventChange)
+	}
+	if proposed.EndDate.Before(proposed.StartDate) {
+		return fmt.Errorf("%w: end date must be after start date", ErrInvalidEventChange)
+	}
+
+	datesChanged := !proposed.StartDate.Equal(event.StartDate) || !proposed.EndDate.Equal(event.EndDate)
+	if !datesChanged {
+		return nil
+	}
+	if !proposed.StartDate.After(time.Now()) {
+		return fmt.Errorf("%w: the event cannot be moved into the past", ErrInvalidEventChange)
+	}
+
+	var sessions int64
+	if err := tx.Model(&models.EventSession{}).Where("event_id = ?", event.ID).Count(&sessions).Error; err != nil {
+		return fmt.Errorf("failed to check sessions: %w", err)
+	}
+	if sessions > 0 {
+		return fmt.Errorf("%w: the dates of a series follow its sessions; change the sessions instead", ErrInvalidEventChange)
+	}
+	return nil
+}
+
+// ReviewChange approves or rejects a pending change. Approval applies the change
+// to the event and starts notifying its ticket holders.
+func (s *EventChangeService) ReviewChange(changeID, moderatorID uuid.UUID, approve bool, comment string) (*models.EventChangeRequest, error) {
+	var change models.EventChangeRequest
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&change, "id = ?", changeID).Error; err != nil {
+			return err
+		}
+		if change.Status != models.EventChangeStatusPending {
+			return fmt.Errorf("%w: change is %s", ErrChangeNotPending, change.Status)
+		}
+
+		var event models.Event
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&event, "id = ?", change.EventID).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		change.ModeratorID = &moderatorID
+		change.ModerationComment = comment
+		change.ModeratedAt = &now
+		change.Status = models.EventChangeStatusRejected
+
+		if approve {
+			if event.Status != models.EventStatusPublished {
+				return fmt.Errorf("%w: event is %s", ErrEventNotEditable, event.Status)
+			}
+
+			change.Proposed.ApplyChanges(&event, change.Previous)
+			if err := tx.Model(&event).Select("title", "venue", "address", "city", "country", "start_date", "end_date").
+				Updates(&event).Error; err != nil {
+				return fmt.Errorf("failed to apply event change: %w", err)
+			}
+			change.Status = models.EventChangeStatusNotifying
+		}
+
+		if err := tx.Model(&change).Select("status", "moderator_id", "moderation_comment", "moderated_at").
+			Updates(&change).Error; err != nil {
+			return fmt.Errorf("failed to save review: %w", err)
+		}
+		change.Event = &event
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &change, nil
+}
+
+// ProcessPending runs one batch for every approved change whose ticket holders are
+// still being notified and which is not currently owned by another worker
+func (s *EventChangeService) ProcessPending(batchSize int) error {
+	var changes []models.EventChangeRequest
+	if err := s.db.Where("status = ? AND (locked_until IS NULL OR locked_until < ?)", models.EventChangeStatusNotifying, time.Now()).
+		Order("moderated_at ASC").Find(&changes).Error; err != nil {
+		return fmt.Errorf("failed to load event changes: %w", err)
+	}
+
+	for i := range changes {
+		if err := s.ProcessBatch(&changes[i], batchSize); err != nil {
+			log.Printf("Notifying holders of change %s to event %s failed, will resume: %v", changes[i].ID, changes[i].EventID, err)
+		}
+	}
+
+	return nil
+}
+
+// ProcessBatch regenerates the tickets of up to batchSize holders and emails each
+// of them what changed. Progress is saved after each holder, so a crash repeats at most one.
+func (s *EventChangeService) ProcessBatch(change *models.EventChangeRequest, batchSize int) error {
+	now := time.Now()
+	lease := s.db.Model(&models.EventChangeRequest{}).
+		Where("id = ? AND status = ? AND (locked_until IS NULL OR locked_until < ?)", change.ID, models.EventChangeStatusNotifying, now).
+		Update("locked_until", now.Add(eventChangeLease))
+	if lease.Error != nil {
+		return fmt.Errorf("failed to lease event change: %w", lease.Error)
+	}
+	if lease.RowsAffected == 0 {
+		return nil
+	}
+	defer s.db.Model(&models.EventChangeRequest{}).Where("id = ?", change.ID).Update("locked_until", nil)
+
+	// Reload so a stale copy never rewinds the cursor
+	if err := s.db.First(change, "id = ?", change.ID).Error; err != nil {
+		return fmt.Errorf("failed to load event change: %w", err)
+	}
+
+	query := s.db.Model(&models.Ticket{}).Where("event_id = ? AND status = ?", change.EventID, models.TicketStatusConfirmed)
+	if change.LastAttendeeID != nil {
+		query = query.Where("attendee_id > ?", *change.LastAttendeeID)
+	}
+
+	var attendeeIDs []uuid.UUID
+	if err := query.Distinct("attendee_id").Order("attendee_id ASC").Limit(batchSize).Pluck("attendee_id", &attendeeIDs).Error; err != nil {
+		return fmt.Errorf("failed to load ticket holders: %w", err)
+	}
+
+	changes := change.Changes()
+	for _, attendeeID := range attendeeIDs {
+		if err := s.notifyHolder(change, changes, attendeeID); err != nil {
+			change.LastError = err.Error()
+			s.db.Model(change).Update("last_error", change.LastError)
+			return err
+		}
+
+		id := attendeeID
+		change.LastAttendeeID = &id
+		change.NotifiedAttendees++
+		change.LastError = ""
+		if err := s.db.Model(change).Select("last_attendee_id", "notified_attendees", "last_error").Updates(change).Error; err != nil {
+			return fmt.Errorf("failed to save notification progress: %w", err)
+		}
+	}
+
+	if len(attendeeIDs) < batchSize {
+		completedAt := time.Now()
+		change.Status = models.EventChangeStatusCompleted
+		change.CompletedAt = &completedAt
+		return s.db.Model(change).Select("status", "completed_at").Updates(change).Error
+	}
+	return nil
+}
+
+// notifyHolder regenerates one holder's tickets for the event and emails them with the changes
+func (s *EventChangeService) notifyHolder(change *models.EventChangeRequest, changes []models.EventDetailChange, attendeeID uuid.UUID) error {
+	var tickets []models.Ticket
+	if err := s.db.Preload("Event").Preload("TicketType").Preload("Attendee").Preload("Session").Preload("Answers.Question").
+		Where("event_id = ? AND attendee_id = ? AND status = ?", change.EventID, attendeeID, models.TicketStatusConfirmed).
+		Order("created_at ASC").Find(&tickets).Error; err != nil {
+		return fmt.Errorf("failed to load tickets: %w", err)
+	}
+	if len(tickets) == 0 {
+		return nil
+	}
+
+	pdfs := make([][]byte, len(tickets))
+	for i := range tickets {
+		ticket := &tickets[i]
+		pdfData, err := s.ticketDocuments.GenerateTicketDocuments(ticket, &ticket.Event, &ticket.Attendee)
+		if err != nil {
+			return fmt.Errorf("ticket %s: %w", ticket.TicketNumber, err)
+		}
+		if err := s.db.Model(ticket).Select("qr_code_url", "pdf_url", "qr_key_id").Updates(ticket).Error; err != nil {
+			return fmt.Errorf("failed to save documents for ticket %s: %w", ticket.TicketNumber, err)
+		}
+		pdfs[i] = pdfData
+	}
+
+	attendee := tickets[0].Attendee
+	if err := s.emailService.SendEventChangeEmail(&tickets[0].Event, &attendee, changes, tickets, pdfs); err != nil {
+		log.Printf("Failed to send event change email to %s: %v", attendee.Email, err)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/warui/event-ticketing-api/internal/models"
+)
+
+func TestEventChangeServiceQueuesMaterialEdits(t *testing.T) {
+	db := setupInventoryDB(t)
+	service := NewEventChangeService(db, nil, nil)
+
+	ticketType := createTestTicketType(t, db, 10)
+	var event models.Event
+	if err := db.First(&event, "id = ?", ticketType.EventID).Error; err != nil {
+		t.Fatalf("Failed to load event: %v", err)
+	}
+
+	draftEdit := EventEdit{Description: "Updated", Details: event.Details()}
+	if _, _, err := service.EditPublishedEvent(event.ID, event.OrganizerID, draftEdit); !errors.Is(err, ErrEventNotEditable) {
+		t.Fatalf("Expected editing a draft here to fail, got %v", err)
+	}
+
+	if err := db.Model(&event).Update("status", models.EventStatusPublished).Error; err != nil {
+		t.Fatalf("Failed to publish event: %v", err)
+	}
+
+	// Small edits apply straight away
+	updated, change, err := service.EditPublishedEvent(event.ID, event.OrganizerID, EventEdit{Description: "Bring a jacket", Category: "Music", Details: event.Details()})
+	if err != nil {
+		t.Fatalf("Failed to edit event: %v", err)
+	}
+	if change != nil || updated.Description != "Bring a jacket" {
+		t.Fatalf("Expected the description to change without review, got change %+v", change)
+	}
+
+	// Material edits wait for review, and a second one replaces the first
+	details := event.Details()
+	details.Venue = "Hall A"
+	if _, change, err = service.EditPublishedEvent(event.ID, event.OrganizerID, EventEdit{Details: details}); err != nil || change == nil {
+		t.Fatalf("Expected a pending change, got %+v, %v", change, err)
+	}
+	firstID := change.ID
+
+	details.Venue = "Hall B"
+	if _, change, err = service.EditPublishedEvent(event.ID, event.OrganizerID, EventEdit{Details: details}); err != nil {
+		t.Fatalf("Failed to replace change: %v", err)
+	}
+	if change.ID != firstID || change.Proposed.Venue != "Hall B" {
+		t.Errorf("Expected the pending change to be replaced, got %+v", change)
+	}
+
+	var reloaded models.Event
+	db.First(&reloaded, "id = ?", event.ID)
+	if reloaded.Venue != event.Venue {
+		t.Errorf("Expected the venue to wait for review, got %s", reloaded.Venue)
+	}
+
+	approved, err := service.ReviewChange(change.ID, uuid.New(), true, "")
+	if err != nil {
+		t.Fatalf("Failed to approve change: %v", err)
+	}
+	if approved.Status != models.EventChangeStatusNotifying || approved.Event.Venue != "Hall B" {
+		t.Errorf("Expected the change applied and holders queued, got %+v", approved)
+	}
+	if _, err := service.ReviewChange(change.ID, uuid.New(), false, ""); !errors.Is(err, ErrChangeNotPending) {
+		t.Errorf("Expected a second review to fail, got %v", err)
+	}
+
+	// Without ticket holders the notifications finish at once
+	if err := service.ProcessBatch(approved, 10); err != nil {
+		t.Fatalf("Failed to notify holders: %v", err)
+	}
+	if approved.Status != models.EventChangeStatusCompleted {
+		t.Errorf("Expected the change to complete, got %s", approved.Status)
+	}
+
+	moved := event.Details()
+	moved.StartDate = time.Now().Add(-time.Hour)
+	if _, _, err := service.EditPublishedEvent(event.ID, event.OrganizerID, EventEdit{Details: moved}); !errors.Is(err, ErrInvalidEventChange) {
+		t.Errorf("Expected moving the event into the past to fail, got %v", err)
+	}
+}
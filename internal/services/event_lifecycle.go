@@ -0,0 +1,259 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/warui/event-ticketing-api/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrInvalidEventTransition is returned when an event cannot move to the requested status
+	ErrInvalidEventTransition = errors.New("invalid event status change")
+	// ErrEventIncomplete is returned when an event is submitted without what moderators need to review it
+	ErrEventIncomplete = errors.New("event is not ready for review")
+	// ErrInvalidPublishSchedule is returned when a publish schedule cannot be used
+	ErrInvalidPublishSchedule = errors.New("invalid publish schedule")
+)
+
+// EventLifecycleService moves events through draft, review and publishing. Every
+// status change goes through transitionEventTx, which checks it against the
+// allowed transitions and records it in the event's history.
+type EventLifecycleService struct {
+	db *gorm.DB
+}
+
+func NewEventLifecycleService(db *gorm.DB) *EventLifecycleService {
+	return &EventLifecycleService{db: db}
+}
+
+// Create saves a new event as a draft and starts its history
+func (s *EventLifecycleService) Create(event *models.Event, organizerID uuid.UUID) error {
+	event.OrganizerID = organizerID
+	event.Status = models.EventStatusDraft
+	if event.PublishMode == "" {
+		event.PublishMode = models.PublishModeOnApproval
+	}
+	if event.PublishMode == models.PublishModeOnApproval {
+		event.PublishAt = nil
+	}
+	if err := validatePublishSchedule(event, time.Now()); err != nil {
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(event).Error; err != nil {
+			return fmt.Errorf("failed to create event: %w", err)
+		}
+		return recordEventStatusTx(tx, event.ID, "", event.Status, &organizerID, "")
+	})
+}
+
+// Submit sends a draft or rejected event to moderators. It needs at least one ticket type.
+func (s *EventLifecycleService) Submit(eventID, organizerID uuid.UUID) (*models.Event, error) {
+	return s.organizerTransition(eventID, organizerID, func(tx *gorm.DB, event *models.Event) error {
+		var ticketTypes int64
+		if err := tx.Model(&models.TicketType{}).Where("event_id = ?", event.ID).Count(&ticketTypes).Error; err != nil {
+			return fmt.Errorf("failed to check ticket types: %w", err)
+		}
+		if ticketTypes == 0 {
+			return fmt.Errorf("%w: event must have at least one ticket type", ErrEventIncomplete)
+		}
+		if err := validatePublishSchedule(event, time.Now()); err != nil {
+			return err
+		}
+		return transitionEventTx(tx, event, models.EventStatusPending, &organizerID, "")
+	})
+}
+
+// Withdraw takes an event out of review and back to draft so it can be edited
+func (s *EventLifecycleService) Withdraw(eventID, organizerID uuid.UUID) (*models.Event, error) {
+	return s.organizerTransition(eventID, organizerID, func(tx *gorm.DB, event *models.Event) error {
+		return transitionEventTx(tx, event, models.EventStatusDraft, &organizerID, "Withdrawn from review")
+	})
+}
+
+// Publish puts an approved event live straight away, ahead of any schedule
+func (s *EventLifecycleService) Publish(eventID, organizerID uuid.UUID) (*models.Event, error) {
+	return s.organizerTransition(eventID, organizerID, func(tx *gorm.DB, event *models.Event) error {
+		return transitionEventTx(tx, event, models.EventStatusPublished, &organizerID, "")
+	})
+}
+
+// SetPublishSchedule chooses when the event goes live once approved. An approved
+// event whose new publish time has already come is published straight away.
+func (s *EventLifecycleService) SetPublishSchedule(eventID, organizerID uuid.UUID, mode models.PublishMode, publishAt *time.Time) (*models.Event, error) {
+	return s.organizerTransition(eventID, organizerID, func(tx *gorm.DB, event *models.Event) error {
+		switch event.Status {
+		case models.EventStatusDraft, models.EventStatusRejected, models.EventStatusPending, models.EventStatusApproved:
+		default:
+			return fmt.Errorf("%w: a %s event has no publish schedule", ErrInvalidPublishSchedule, event.Status)
+		}
+
+		event.PublishMode = mode
+		event.PublishAt = publishAt
+		if mode == models.PublishModeOnApproval {
+			event.PublishAt = nil
+		}
+		if err := validatePublishSchedule(event, time.Now()); err != nil {
+			return err
+		}
+		if err := tx.Model(event).Select("publish_mode", "publish_at").Updates(event).Error; err != nil {
+			return fmt.Errorf("failed to save publish schedule: %w", err)
+		}
+
+		if event.Status == models.EventStatusApproved && event.PublishesAt(time.Now()) {
+			return transitionEventTx(tx, event, models.EventStatusPublished, &organizerID, "")
+		}
+		return nil
+	})
+}
+
+// Review approves or rejects a pending event. An approved event is published
+// straight away unless the organizer scheduled it for later.
+func (s *EventLifecycleService) Review(eventID, moderatorID uuid.UUID, approve bool, comment string) (*models.Event, error) {
+	var event models.Event
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&event, "id = ?", eventID).Error; err != nil {
+			return err
+		}
+		if event.Status != models.EventStatusPending {
+			return fmt.Errorf("%w: event is not pending review", ErrInvalidEventTransition)
+		}
+
+		now := time.Now()
+		event.ModeratorID = &moderatorID
+		event.ModerationComment = comment
+		event.ModeratedAt = &now
+		if err := tx.Model(&event).Select("moderator_id", "moderation_comment", "moderated_at").Updates(&event).Error; err != nil {
+			return fmt.Errorf("failed to save review: %w", err)
+		}
+
+		if !approve {
+			return transitionEventTx(tx, &event, models.EventStatusRejected, &moderatorID, comment)
+		}
+		if err := transitionEventTx(tx, &event, models.EventStatusApproved, &moderatorID, comment); err != nil {
+			return err
+		}
+		if event.PublishesAt(now) {
+			return transitionEventTx(tx, &event, models.EventStatusPublished, nil, "Published on approval")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &event, nil
+}
+
+// PublishScheduled publishes approved events whose publish time has come
+func (s *EventLifecycleService) PublishScheduled(limit int) (int, error) {
+	var eventIDs []uuid.UUID
+	if err := s.db.Model(&models.Event{}).
+		Where("status = ? AND publish_mode = ? AND publish_at <= ?", models.EventStatusApproved, models.PublishModeScheduled, time.Now()).
+		Order("publish_at ASC").Limit(limit).Pluck("id", &eventIDs).Error; err != nil {
+		return 0, fmt.Errorf("failed to load scheduled events: %w", err)
+	}
+
+	published := 0
+	for _, eventID := range eventIDs {
+		err := s.db.Transaction(func(tx *gorm.DB) error {
+			var event models.Event
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&event, "id = ?", eventID).Error; err != nil {
+				return err
+			}
+			// Published by hand or rescheduled since it was picked
+			if event.Status != models.EventStatusApproved || !event.PublishesAt(time.Now()) {
+				return nil
+			}
+			return transitionEventTx(tx, &event, models.EventStatusPublished, nil, "Published as scheduled")
+		})
+		if err != nil {
+			return published, err
+		}
+		published++
+	}
+
+	return published, nil
+}
+
+// organizerTransition runs fn on one of the organizer's events, locked inside a transaction
+func (s *EventLifecycleService) organizerTransition(eventID, organizerID uuid.UUID, fn func(tx *gorm.DB, event *models.Event) error) (*models.Event, error) {
+	var event models.Event
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&event, "id = ? AND organizer_id = ?", eventID, organizerID).Error; err != nil {
+			return err
+		}
+		return fn(tx, &event)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &event, nil
+}
+
+// validatePublishSchedule checks a scheduled publish time is set and falls before the event ends
+func validatePublishSchedule(event *models.Event, now time.Time) error {
+	switch event.PublishMode {
+	case models.PublishModeOnApproval:
+		return nil
+	case models.PublishModeScheduled:
+	default:
+		return fmt.Errorf("%w: unknown publish mode %q", ErrInvalidPublishSchedule, event.PublishMode)
+	}
+
+	if event.PublishAt == nil {
+		return fmt.Errorf("%w: a scheduled publish needs a publish time", ErrInvalidPublishSchedule)
+	}
+	if !event.PublishAt.Before(event.EndDate) {
+		return fmt.Errorf("%w: the event must be published before it ends", ErrInvalidPublishSchedule)
+	}
+	if event.Status != models.EventStatusApproved && event.PublishAt.Before(now) {
+		return fmt.Errorf("%w: the publish time has already passed", ErrInvalidPublishSchedule)
+	}
+	return nil
+}
+
+// transitionEventTx moves an event to a new status inside the caller's transaction
+// and records the move. It fails with ErrInvalidEventTransition for a move the
+// lifecycle does not allow, or if the event changed status since it was loaded.
+func transitionEventTx(tx *gorm.DB, event *models.Event, to models.EventStatus, changedBy *uuid.UUID, comment string) error {
+	from := event.Status
+	if !from.CanTransitionTo(to) {
+		return fmt.Errorf("%w: a %s event cannot become %s", ErrInvalidEventTransition, from, to)
+	}
+
+	result := tx.Model(&models.Event{}).Where("id = ? AND status = ?", event.ID, from).Update("status", to)
+	if result.Error != nil {
+		return fmt.Errorf("failed to change event status: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%w: event is no longer %s", ErrInvalidEventTransition, from)
+	}
+	event.Status = to
+
+	return recordEventStatusTx(tx, event.ID, from, to, changedBy, comment)
+}
+
+// recordEventStatusTx adds a move to an event's history. A new event is recorded
+// with an empty from status.
+func recordEventStatusTx(tx *gorm.DB, eventID uuid.UUID, from, to models.EventStatus, changedBy *uuid.UUID, comment string) error {
+	change := &models.EventStatusChange{
+		EventID:    eventID,
+		FromStatus: from,
+		ToStatus:   to,
+		ChangedBy:  changedBy,
+		Comment:    comment,
+	}
+	if err := tx.Create(change).Error; err != nil {
+		return fmt.Errorf("failed to record event status change: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/warui/event-ticketing-api/internal/models"
+)
+
+func TestEventLifecycleScheduledPublish(t *testing.T) {
+	db := setupInventoryDB(t)
+	service := NewEventLifecycleService(db)
+
+	ticketType := createTestTicketType(t, db, 10)
+	var event models.Event
+	if err := db.First(&event, "id = ?", ticketType.EventID).Error; err != nil {
+		t.Fatalf("Failed to load event: %v", err)
+	}
+
+	if _, err := service.Publish(event.ID, event.OrganizerID); !errors.Is(err, ErrInvalidEventTransition) {
+		t.Fatalf("Expected publishing a draft to fail, got %v", err)
+	}
+
+	publishAt := time.Now().Add(2 * time.Hour)
+	if _, err := service.SetPublishSchedule(event.ID, event.OrganizerID, models.PublishModeScheduled, &publishAt); err != nil {
+		t.Fatalf("Failed to schedule publishing: %v", err)
+	}
+	if _, err := service.Submit(event.ID, event.OrganizerID); err != nil {
+		t.Fatalf("Failed to submit event: %v", err)
+	}
+
+	reviewed, err := service.Review(event.ID, uuid.New(), true, "")
+	if err != nil {
+		t.Fatalf("Failed to approve event: %v", err)
+	}
+	if reviewed.Status != models.EventStatusApproved {
+		t.Fatalf("Expected a scheduled event to wait as approved, got %s", reviewed.Status)
+	}
+
+	// The publish time comes
+	if err := db.Model(&models.Event{}).Where("id = ?", event.ID).Update("publish_at", time.Now().Add(-time.Minute)).Error; err != nil {
+		t.Fatalf("Failed to move publish time: %v", err)
+	}
+	if _, err := service.PublishScheduled(100); err != nil {
+		t.Fatalf("Failed to publish scheduled events: %v", err)
+	}
+
+	db.First(&event, "id = ?", event.ID)
+	if event.Status != models.EventStatusPublished {
+		t.Errorf("Expected the event to be published, got %s", event.Status)
+	}
+
+	var history []models.EventStatusChange
+	db.Where("event_id = ?", event.ID).Order("created_at ASC").Find(&history)
+	want := []models.EventStatus{models.EventStatusPending, models.EventStatusApproved, models.EventStatusPublished}
+	if len(history) != len(want) {
+		t.Fatalf("Expected %d status changes, got %+v", len(want), history)
+	}
+	for i, status := range want {
+		if history[i].ToStatus != status {
+			t.Errorf("Change %d: expected %s, got %s", i, status, history[i].ToStatus)
+		}
+	}
+	if history[2].ChangedBy != nil {
+		t.Errorf("Expected the scheduled publish to be made by the platform")
+	}
+}
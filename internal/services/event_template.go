@@ -0,0 +1,135 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/warui/event-ticketing-api/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrInvalidEventCopy is returned when an event cannot be copied or created from a template as asked
+var ErrInvalidEventCopy = errors.New("invalid event copy")
+
+// EventTemplateService copies events, directly or through saved templates. Copies
+// start as drafts with no sales and go through moderation like any new event.
+type EventTemplateService struct {
+	db *gorm.DB
+}
+
+func NewEventTemplateService(db *gorm.DB) *EventTemplateService {
+	return &EventTemplateService{db: db}
+}
+
+// Blueprint captures one of the organizer's events with everything needed to set it up again
+func (s *EventTemplateService) Blueprint(eventID, organizerID uuid.UUID) (models.EventBlueprint, error) {
+	var event models.Event
+	if err := s.db.Preload("TicketTypes", func(db *gorm.DB) *gorm.DB { return db.Order(TicketTypeOrder) }).
+		Preload("Sessions").
+		Preload("Questions", func(db *gorm.DB) *gorm.DB { return db.Order("position ASC") }).
+		First(&event, "id = ? AND organizer_id = ?", eventID, organizerID).Error; err != nil {
+		return models.EventBlueprint{}, err
+	}
+
+	var seats []models.Seat
+	if err := s.db.Where("event_id = ?", event.ID).Order("position ASC").Find(&seats).Error; err != nil {
+		return models.EventBlueprint{}, fmt.Errorf("failed to load seats: %w", err)
+	}
+
+	return models.NewEventBlueprint(&event, seats), nil
+}
+
+// CloneEvent copies one of the organizer's events into a new draft starting at
+// start, with every other date moved by the same amount. An empty title keeps the original.
+func (s *EventTemplateService) CloneEvent(eventID, organizerID uuid.UUID, start time.Time, title string) (*models.Event, error) {
+	blueprint, err := s.Blueprint(eventID, organizerID)
+	if err != nil {
+		return nil, err
+	}
+	return s.createEvent(blueprint, organizerID, start, title)
+}
+
+// SaveTemplate stores one of the organizer's events as a named template
+func (s *EventTemplateService) SaveTemplate(eventID, organizerID uuid.UUID, name string) (*models.EventTemplate, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: template name is required", ErrInvalidEventCopy)
+	}
+
+	blueprint, err := s.Blueprint(eventID, organizerID)
+	if err != nil {
+		return nil, err
+	}
+
+	template := &models.EventTemplate{
+		OrganizerID: organizerID,
+		Name:        name,
+		Blueprint:   blueprint,
+	}
+	if err := s.db.Create(template).Error; err != nil {
+		return nil, fmt.Errorf("failed to save template: %w", err)
+	}
+
+	return template, nil
+}
+
+// CreateFromTemplate creates a draft event from one of the organizer's templates
+func (s *EventTemplateService) CreateFromTemplate(templateID, organizerID uuid.UUID, start time.Time, title string) (*models.Event, error) {
+	var template models.EventTemplate
+	if err := s.db.First(&template, "id = ? AND organizer_id = ?", templateID, organizerID).Error; err != nil {
+		return nil, err
+	}
+	return s.createEvent(template.Blueprint, organizerID, start, title)
+}
+
+// createEvent builds a draft event from a blueprint and saves it with its parts.
+// Parts are saved one kind at a time so ticket types find their sessions.
+func (s *EventTemplateService) createEvent(blueprint models.EventBlueprint, organizerID uuid.UUID, start time.Time, title string) (*models.Event, error) {
+	if !start.After(time.Now()) {
+		return nil, fmt.Errorf("%w: the new event must start in the future", ErrInvalidEventCopy)
+	}
+	if title = strings.TrimSpace(title); title != "" {
+		blueprint.Title = title
+	}
+
+	event, seats := blueprint.Build(organizerID, start)
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// Select("*") keeps false flags that would otherwise take their column defaults
+		if err := tx.Select("*").Omit(clause.Associations).Create(event).Error; err != nil {
+			return fmt.Errorf("failed to create event: %w", err)
+		}
+		if err := recordEventStatusTx(tx, event.ID, "", event.Status, &organizerID, ""); err != nil {
+			return err
+		}
+		if len(event.Sessions) > 0 {
+			if err := tx.Omit(clause.Associations).Create(&event.Sessions).Error; err != nil {
+				return fmt.Errorf("failed to create sessions: %w", err)
+			}
+		}
+		if len(event.TicketTypes) > 0 {
+			if err := tx.Select("*").Omit(clause.Associations).Create(&event.TicketTypes).Error; err != nil {
+				return fmt.Errorf("failed to create ticket types: %w", err)
+			}
+		}
+		if len(event.Questions) > 0 {
+			if err := tx.Create(&event.Questions).Error; err != nil {
+				return fmt.Errorf("failed to create questions: %w", err)
+			}
+		}
+		if len(seats) > 0 {
+			if err := tx.Omit(clause.Associations).CreateInBatches(seats, 500).Error; err != nil {
+				return fmt.Errorf("failed to create seats: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return event, nil
+}
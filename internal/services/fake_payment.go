@@ -0,0 +1,202 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/warui/event-ticketing-api/internal/config"
+)
+
+// FakePaymentProvider is an in-memory provider for development and tests. Every
+// payment it starts succeeds for the full amount as soon as it is verified, and
+// refunds and transfers succeed immediately. It is only registered when
+// PAYMENT_FAKE_ENABLED is set, and never in production.
+type FakePaymentProvider struct {
+	cfg       *config.Config
+	mu        sync.Mutex
+	payments  map[string]PaymentRequest
+	startedAt map[string]time.Time
+}
+
+type fakeWebhook struct {
+	Event     WebhookEventType `json:"event"`
+	Reference string           `json:"reference"`
+	ID        string           `json:"id"`
+}
+
+func NewFakePaymentProvider(cfg *config.Config) *FakePaymentProvider {
+	return &FakePaymentProvider{
+		cfg:       cfg,
+		payments:  make(map[string]PaymentRequest),
+		startedAt: make(map[string]time.Time),
+	}
+}
+
+// Name implements PaymentProvider
+func (f *FakePaymentProvider) Name() string {
+	return "fake"
+}
+
+// InitializePayment implements PaymentProvider. The buyer is sent straight to the callback URL.
+func (f *FakePaymentProvider) InitializePayment(req PaymentRequest) (*PaymentSession, error) {
+	f.mu.Lock()
+	f.payments[req.Reference] = req
+	f.startedAt[req.Reference] = time.Now()
+	f.mu.Unlock()
+
+	separator := "?"
+	if strings.Contains(f.cfg.PaymentCallbackURL, "?") {
+		separator = "&"
+	}
+
+	return &PaymentSession{
+		AuthorizationURL: f.cfg.PaymentCallbackURL + separator + "reference=" + url.QueryEscape(req.Reference),
+		Reference:        req.Reference,
+	}, nil
+}
+
+// VerifyPayment implements PaymentProvider
+func (f *FakePaymentProvider) VerifyPayment(reference string) (*PaymentResult, error) {
+	f.mu.Lock()
+	payment, ok := f.payments[reference]
+	f.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("fake payment %s not found", reference)
+	}
+
+	return &PaymentResult{
+		Reference:  reference,
+		Successful: true,
+		Status:     "success",
+		Amount:     payment.Amount,
+		Currency:   payment.Currency,
+		PaidAt:     time.Now(),
+		Metadata:   payment.Metadata,
+	}, nil
+}
+
+// ListPayments implements PaymentProvider. Every payment started in the range counts as paid.
+func (f *FakePaymentProvider) ListPayments(from, to time.Time) ([]PaymentResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var payments []PaymentResult
+	for reference, payment := range f.payments {
+		startedAt := f.startedAt[reference]
+		if startedAt.Before(from) || !startedAt.Before(to) {
+			continue
+		}
+		payments = append(payments, PaymentResult{
+			Reference:  reference,
+			Successful: true,
+			Status:     "success",
+			Amount:     payment.Amount,
+			Currency:   payment.Currency,
+			PaidAt:     startedAt,
+			Metadata:   payment.Metadata,
+		})
+	}
+	return payments, nil
+}
+
+// Refund implements PaymentProvider
+func (f *FakePaymentProvider) Refund(req PaymentRefundRequest) (*PaymentRefundResult, error) {
+	return &PaymentRefundResult{
+		ID:               "fake-refund-" + uuid.New().String(),
+		PaymentReference: req.PaymentReference,
+		Status:           "processed",
+		Amount:           req.Amount,
+	}, nil
+}
+
+// VerifyWebhookSignature checks a hex HMAC-SHA256 of the body made with
+// PAYMENT_FAKE_WEBHOOK_SECRET. Without a secret every delivery is rejected.
+func (f *FakePaymentProvider) VerifyWebhookSignature(body []byte, signature string) bool {
+	if f.cfg.PaymentFakeWebhookSecret == "" || signature == "" {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(f.cfg.PaymentFakeWebhookSecret))
+	mac.Write(body)
+	expected := hex.EncodeToString(mac.Sum(nil))
+
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
+
+// ParseWebhook implements PaymentProvider. Fake webhooks are JSON such as
+// {"event": "payment.succeeded", "reference": "TKT-..."}, signed in x-fake-signature.
+func (f *FakePaymentProvider) ParseWebhook(body []byte, headers http.Header) (*WebhookEvent, error) {
+	if !f.VerifyWebhookSignature(body, headers.Get("x-fake-signature")) {
+		return nil, ErrInvalidWebhookSignature
+	}
+
+	var event fakeWebhook
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, fmt.Errorf("failed to parse webhook event: %w", err)
+	}
+
+	webhook := &WebhookEvent{Type: event.Event, ProviderType: string(event.Event)}
+	switch event.Event {
+	case WebhookPaymentSucceeded:
+		payment, err := f.VerifyPayment(event.Reference)
+		if err != nil {
+			return nil, err
+		}
+		webhook.Payment = payment
+	case WebhookRefundProcessed, WebhookRefundFailed:
+		webhook.Refund = &PaymentRefundResult{ID: event.ID, Status: string(event.Event)}
+	case WebhookTransferSucceeded, WebhookTransferFailed:
+		webhook.Transfer = &TransferResult{ID: event.ID, Reference: event.Reference, Status: string(event.Event)}
+	default:
+		webhook.Type = WebhookIgnored
+	}
+
+	return webhook, nil
+}
+
+// Transfer implements PaymentProvider
+func (f *FakePaymentProvider) Transfer(req TransferRequest) (*TransferResult, error) {
+	return &TransferResult{
+		ID:        "fake-transfer-" + uuid.New().String(),
+		Reference: req.Reference,
+		Status:    "success",
+		Amount:    req.Amount,
+	}, nil
+}
+
+// fakeBanks are the banks the fake provider pays out to
+var fakeBanks = []Bank{
+	{Code: "001", Name: "Fake Bank"},
+	{Code: "002", Name: "Test Bank"},
+}
+
+// ListBanks implements PaymentProvider
+func (f *FakePaymentProvider) ListBanks(currency string) ([]Bank, error) {
+	return fakeBanks, nil
+}
+
+// ResolveAccount implements PaymentProvider. Any ten digit account at a fake bank resolves.
+func (f *FakePaymentProvider) ResolveAccount(bankCode, accountNumber string) (string, error) {
+	known := false
+	for _, bank := range fakeBanks {
+		known = known || bank.Code == bankCode
+	}
+	if !known || len(accountNumber) != 10 || strings.Trim(accountNumber, "0123456789") != "" {
+		return "", fmt.Errorf("could not resolve account %s", accountNumber)
+	}
+	return "Fake Account Holder", nil
+}
+
+// CreateRecipient implements PaymentProvider
+func (f *FakePaymentProvider) CreateRecipient(req RecipientRequest) (string, error) {
+	return "fake-recipient-" + uuid.New().String(), nil
+}
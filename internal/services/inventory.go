@@ -0,0 +1,263 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/warui/event-ticketing-api/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrInsufficientStock is returned when a ticket type cannot cover the requested quantity
+var ErrInsufficientStock = errors.New("not enough tickets available")
+
+// ErrSeatUnavailable is returned when a chosen seat is held or sold to someone else.
+// It wraps ErrInsufficientStock so callers that handle sold-out stock handle it too.
+var ErrSeatUnavailable = fmt.Errorf("%w: seat is no longer available", ErrInsufficientStock)
+
+// InventoryService keeps TicketType.Sold and TicketType.Reserved consistent under
+// concurrent checkouts. Every counter change is a conditional UPDATE so two buyers
+// can never both take the last ticket.
+type InventoryService struct {
+	db *gorm.DB
+}
+
+func NewInventoryService(db *gorm.DB) *InventoryService {
+	return &InventoryService{db: db}
+}
+
+// StockRequest is a quantity of one ticket type requested by an order
+type StockRequest struct {
+	TicketTypeID uuid.UUID
+	Quantity     int
+}
+
+// SeatRequest is the seats a buyer picked for one ticket type
+type SeatRequest struct {
+	TicketTypeID uuid.UUID
+	SeatIDs      []uuid.UUID
+}
+
+// ReserveTx holds stock for a checkout inside the caller's database transaction.
+// It fails with ErrInsufficientStock if any ticket type cannot cover its quantity,
+// or with ErrSessionFull if a session of a multi-session event is full.
+func (s *InventoryService) ReserveTx(tx *gorm.DB, transactionID uuid.UUID, requests []StockRequest, expiresAt time.Time) error {
+	ticketTypeIDs := make([]uuid.UUID, 0, len(requests))
+	for _, request := range requests {
+		ticketTypeIDs = append(ticketTypeIDs, request.TicketTypeID)
+
+		result := tx.Model(&models.TicketType{}).
+			Where("id = ? AND sold + reserved + ? <= quantity", request.TicketTypeID, request.Quantity).
+			Update("reserved", gorm.Expr("reserved + ?", request.Quantity))
+		if result.Error != nil {
+			return fmt.Errorf("failed to reserve tickets: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("%w for ticket type %s", ErrInsufficientStock, request.TicketTypeID)
+		}
+
+		hold := &models.InventoryHold{
+			TransactionID: transactionID,
+			TicketTypeID:  request.TicketTypeID,
+			Quantity:      request.Quantity,
+			Status:        models.HoldStatusActive,
+			ExpiresAt:     expiresAt,
+		}
+		if err := tx.Create(hold).Error; err != nil {
+			return fmt.Errorf("failed to create inventory hold: %w", err)
+		}
+	}
+
+	return checkSessionCapacityTx(tx, ticketTypeIDs)
+}
+
+// HoldSeatsTx holds the seats a buyer picked inside the caller's database transaction.
+// The seats are sold or released together with the checkout's stock hold. It fails
+// with ErrSeatUnavailable if any seat is taken or not of the requested ticket type.
+func (s *InventoryService) HoldSeatsTx(tx *gorm.DB, transactionID uuid.UUID, request SeatRequest) error {
+	result := tx.Model(&models.Seat{}).
+		Where("id IN ? AND ticket_type_id = ? AND status = ?", request.SeatIDs, request.TicketTypeID, models.SeatStatusAvailable).
+		Updates(map[string]interface{}{"status": models.SeatStatusHeld, "transaction_id": transactionID})
+	if result.Error != nil {
+		return fmt.Errorf("failed to hold seats: %w", result.Error)
+	}
+	if int(result.RowsAffected) != len(request.SeatIDs) {
+		return fmt.Errorf("%w for ticket type %s", ErrSeatUnavailable, request.TicketTypeID)
+	}
+
+	return nil
+}
+
+// SellSeatsTx marks seats sold to a purchase inside the caller's database transaction
+// and returns them in seat map order. Seats the buyer picked must be held by the
+// purchase or still free, for example after its hold expired. Without picked seats
+// the first free seats of the ticket type are taken, as for complimentary tickets.
+func (s *InventoryService) SellSeatsTx(tx *gorm.DB, transactionID uuid.UUID, request SeatRequest, quantity int) ([]models.Seat, error) {
+	seatIDs := request.SeatIDs
+	if len(seatIDs) == 0 {
+		if err := tx.Model(&models.Seat{}).
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("ticket_type_id = ? AND status = ?", request.TicketTypeID, models.SeatStatusAvailable).
+			Order("position ASC").Limit(quantity).
+			Pluck("id", &seatIDs).Error; err != nil {
+			return nil, fmt.Errorf("failed to find free seats: %w", err)
+		}
+	}
+	if len(seatIDs) != quantity {
+		return nil, fmt.Errorf("%w for ticket type %s", ErrSeatUnavailable, request.TicketTypeID)
+	}
+
+	result := tx.Model(&models.Seat{}).
+		Where("id IN ? AND ticket_type_id = ? AND (status = ? OR (status = ? AND transaction_id = ?))",
+			seatIDs, request.TicketTypeID, models.SeatStatusAvailable, models.SeatStatusHeld, transactionID).
+		Updates(map[string]interface{}{"status": models.SeatStatusSold, "transaction_id": transactionID})
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to sell seats: %w", result.Error)
+	}
+	if int(result.RowsAffected) != quantity {
+		return nil, fmt.Errorf("%w for ticket type %s", ErrSeatUnavailable, request.TicketTypeID)
+	}
+
+	var seats []models.Seat
+	if err := tx.Where("id IN ?", seatIDs).Order("position ASC").Find(&seats).Error; err != nil {
+		return nil, fmt.Errorf("failed to load seats: %w", err)
+	}
+	return seats, nil
+}
+
+// SellTx turns stock into sold tickets inside the caller's database transaction.
+// Quantities covered by an active hold are moved from reserved to sold; anything
+// else (for example a payment that arrived after its hold expired) is taken from
+// free stock and fails with ErrInsufficientStock if there is none left.
+func (s *InventoryService) SellTx(tx *gorm.DB, transactionID uuid.UUID, requests []StockRequest) error {
+	var unheld []uuid.UUID
+	for _, request := range requests {
+		remaining := request.Quantity
+
+		var holds []models.InventoryHold
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("transaction_id = ? AND ticket_type_id = ? AND status = ?", transactionID, request.TicketTypeID, models.HoldStatusActive).
+			Find(&holds).Error; err != nil {
+			return fmt.Errorf("failed to load inventory holds: %w", err)
+		}
+
+		for _, hold := range holds {
+			quantity := hold.Quantity
+			if quantity > remaining {
+				quantity = remaining
+			}
+
+			if err := tx.Model(&models.InventoryHold{}).Where("id = ?", hold.ID).Update("status", models.HoldStatusConverted).Error; err != nil {
+				return fmt.Errorf("failed to convert inventory hold: %w", err)
+			}
+
+			// Return any part of the hold the order did not use
+			if err := tx.Model(&models.TicketType{}).Where("id = ?", request.TicketTypeID).Updates(map[string]interface{}{
+				"reserved": gorm.Expr("reserved - ?", hold.Quantity),
+				"sold":     gorm.Expr("sold + ?", quantity),
+			}).Error; err != nil {
+				return fmt.Errorf("failed to convert reserved tickets: %w", err)
+			}
+
+			remaining -= quantity
+		}
+
+		if remaining == 0 {
+			continue
+		}
+
+		result := tx.Model(&models.TicketType{}).
+			Where("id = ? AND sold + reserved + ? <= quantity", request.TicketTypeID, remaining).
+			Update("sold", gorm.Expr("sold + ?", remaining))
+		if result.Error != nil {
+			return fmt.Errorf("failed to sell tickets: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("%w for ticket type %s", ErrInsufficientStock, request.TicketTypeID)
+		}
+		unheld = append(unheld, request.TicketTypeID)
+	}
+
+	// Held stock was counted against its sessions when it was reserved
+	if len(unheld) == 0 {
+		return nil
+	}
+	return checkSessionCapacityTx(tx, unheld)
+}
+
+// ReleaseHolds returns the stock held by a transaction that will not be paid
+func (s *InventoryService) ReleaseHolds(transactionID uuid.UUID) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		return s.ReleaseHoldsTx(tx, transactionID)
+	})
+}
+
+// ReleaseHoldsTx returns the stock held by a transaction inside the caller's database transaction
+func (s *InventoryService) ReleaseHoldsTx(tx *gorm.DB, transactionID uuid.UUID) error {
+	var holds []models.InventoryHold
+	if err := tx.Where("transaction_id = ? AND status = ?", transactionID, models.HoldStatusActive).Find(&holds).Error; err != nil {
+		return fmt.Errorf("failed to load inventory holds: %w", err)
+	}
+
+	for i := range holds {
+		if err := s.releaseHoldTx(tx, &holds[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ReleaseExpiredHolds returns stock from holds whose checkout window has passed
+func (s *InventoryService) ReleaseExpiredHolds(limit int) (int, error) {
+	var holds []models.InventoryHold
+	if err := s.db.Where("status = ? AND expires_at < ?", models.HoldStatusActive, time.Now()).
+		Order("expires_at ASC").Limit(limit).Find(&holds).Error; err != nil {
+		return 0, fmt.Errorf("failed to load expired holds: %w", err)
+	}
+
+	released := 0
+	for i := range holds {
+		err := s.db.Transaction(func(tx *gorm.DB) error {
+			return s.releaseHoldTx(tx, &holds[i])
+		})
+		if err != nil {
+			return released, err
+		}
+		released++
+	}
+
+	return released, nil
+}
+
+// releaseHoldTx flips a single hold to released and gives its quantity back.
+// The conditional status update makes a concurrent release or sale a no-op.
+func (s *InventoryService) releaseHoldTx(tx *gorm.DB, hold *models.InventoryHold) error {
+	result := tx.Model(&models.InventoryHold{}).
+		Where("id = ? AND status = ?", hold.ID, models.HoldStatusActive).
+		Update("status", models.HoldStatusReleased)
+	if result.Error != nil {
+		return fmt.Errorf("failed to release inventory hold: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil
+	}
+
+	if err := tx.Model(&models.TicketType{}).Where("id = ?", hold.TicketTypeID).
+		Update("reserved", gorm.Expr("reserved - ?", hold.Quantity)).Error; err != nil {
+		return fmt.Errorf("failed to release reserved tickets: %w", err)
+	}
+
+	// Seats picked for the checkout go back on the map with the stock
+	if err := tx.Model(&models.Seat{}).
+		Where("transaction_id = ? AND ticket_type_id = ? AND status = ?", hold.TransactionID, hold.TicketTypeID, models.SeatStatusHeld).
+		Updates(map[string]interface{}{"status": models.SeatStatusAvailable, "transaction_id": nil}).Error; err != nil {
+		return fmt.Errorf("failed to release held seats: %w", err)
+	}
+
+	hold.Status = models.HoldStatusReleased
+	return nil
+}
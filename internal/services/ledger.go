@@ -0,0 +1,362 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/warui/event-ticketing-api/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrUnbalancedEntry is returned for journal entries whose lines do not sum to zero
+var ErrUnbalancedEntry = errors.New("journal entry does not balance")
+
+// LedgerService records every change to organizer money as a double-entry journal
+// entry. OrganizerBalance rows are a running projection of the ledger, updated in
+// the same database transaction as each entry and checked against it by CheckBalances.
+type LedgerService struct {
+	db *gorm.DB
+}
+
+func NewLedgerService(db *gorm.DB) *LedgerService {
+	return &LedgerService{db: db}
+}
+
+// StatementLine is one journal entry as it affected an organizer's balances
+type StatementLine struct {
+	EntryID          uuid.UUID               `json:"entry_id"`
+	Date             time.Time               `json:"date"`
+	Type             models.JournalEntryType `json:"type"`
+	Description      string                  `json:"description"`
+	TransactionID    *uuid.UUID              `json:"transaction_id,omitempty"`
+	WithdrawalID     *uuid.UUID              `json:"withdrawal_id,omitempty"`
+	Available        int64                   `json:"available"` // Change to the available balance
+	Pending          int64                   `json:"pending"`   // Change to the pending balance
+	AvailableBalance int64                   `json:"available_balance"`
+	PendingBalance   int64                   `json:"pending_balance"`
+}
+
+// OrganizerStatement lists an organizer's ledger entries with running balances
+type OrganizerStatement struct {
+	OrganizerID      uuid.UUID                `json:"organizer_id"`
+	OpeningAvailable int64                    `json:"opening_available"`
+	OpeningPending   int64                    `json:"opening_pending"`
+	Lines            []StatementLine          `json:"lines"`
+	ClosingAvailable int64                    `json:"closing_available"`
+	ClosingPending   int64                    `json:"closing_pending"`
+	StoredBalance    *models.OrganizerBalance `json:"stored_balance,omitempty"`
+	InBalance        bool                     `json:"in_balance"` // Stored balance matches the whole ledger
+}
+
+// BalanceMismatch is an organizer whose stored balance differs from the ledger
+type BalanceMismatch struct {
+	OrganizerID      uuid.UUID `json:"organizer_id"`
+	AvailableBalance int64     `json:"available_balance"`
+	PendingBalance   int64     `json:"pending_balance"`
+	LedgerAvailable  int64     `json:"ledger_available"`
+	LedgerPending    int64     `json:"ledger_pending"`
+}
+
+// PostTx writes a journal entry and applies it to the organizer's stored balance.
+// Zero lines are dropped; an entry that moves no money is not written.
+func (s *LedgerService) PostTx(tx *gorm.DB, entry *models.JournalEntry) error {
+	lines := entry.Lines[:0]
+	for _, line := range entry.Lines {
+		if line.Amount != 0 {
+			lines = append(lines, line)
+		}
+	}
+	entry.Lines = lines
+	if len(entry.Lines) == 0 {
+		return nil
+	}
+
+	if !entry.IsBalanced() {
+		return fmt.Errorf("%w: %s", ErrUnbalancedEntry, entry.Type)
+	}
+
+	if err := tx.Create(entry).Error; err != nil {
+		return fmt.Errorf("failed to record journal entry: %w", err)
+	}
+
+	if entry.OrganizerID == nil {
+		return nil
+	}
+	return s.applyToBalanceTx(tx, entry)
+}
+
+// RecordSaleTx records a completed ticket purchase: the gateway holds the amount
+// paid, the organizer earns the net amount and the platform keeps its fee
+func (s *LedgerService) RecordSaleTx(tx *gorm.DB, purchase *models.Transaction, organizerID uuid.UUID) error {
+	return s.PostTx(tx, &models.JournalEntry{
+		Type:          models.JournalEntryTicketSale,
+		OrganizerID:   &organizerID,
+		TransactionID: &purchase.ID,
+		Currency:      purchase.Currency,
+		Description:   "Ticket sale " + purchase.PaymentReference,
+		Lines: []models.LedgerLine{
+			models.Debit(models.LedgerAccountGatewayClearing, purchase.Amount),
+			models.Credit(models.LedgerAccountOrganizerAvailable, purchase.NetAmount),
+			models.Credit(models.LedgerAccountPlatformFees, purchase.PlatformFee),
+		},
+	})
+}
+
+// RecordRefundTx takes a refund back from the organizer and the platform fee, or
+// puts it back when the gateway rejected the refund
+func (s *LedgerService) RecordRefundTx(tx *gorm.DB, refund *models.Transaction, organizerID uuid.UUID, reversed bool) error {
+	entryType := models.JournalEntryRefund
+	description := "Refund " + refund.PaymentReference
+	lines := []models.LedgerLine{
+		models.Debit(models.LedgerAccountOrganizerAvailable, refund.NetAmount),
+		models.Debit(models.LedgerAccountPlatformFees, refund.PlatformFee),
+		models.Credit(models.LedgerAccountGatewayClearing, refund.Amount),
+	}
+	if reversed {
+		entryType = models.JournalEntryRefundReversal
+		description = "Reversal of failed refund " + refund.PaymentReference
+		for i := range lines {
+			lines[i].Amount = -lines[i].Amount
+		}
+	}
+
+	return s.PostTx(tx, &models.JournalEntry{
+		Type:          entryType,
+		OrganizerID:   &organizerID,
+		TransactionID: &refund.ID,
+		Currency:      refund.Currency,
+		Description:   description,
+		Lines:         lines,
+	})
+}
+
+// RecordWithdrawalRequestTx moves the requested amount from available to pending
+func (s *LedgerService) RecordWithdrawalRequestTx(tx *gorm.DB, withdrawal *models.WithdrawalRequest, currency string) error {
+	return s.PostTx(tx, &models.JournalEntry{
+		Type:         models.JournalEntryWithdrawalRequest,
+		OrganizerID:  &withdrawal.OrganizerID,
+		WithdrawalID: &withdrawal.ID,
+		Currency:     currency,
+		Description:  "Withdrawal requested",
+		Lines: []models.LedgerLine{
+			models.Debit(models.LedgerAccountOrganizerAvailable, withdrawal.Amount),
+			models.Credit(models.LedgerAccountOrganizerPending, withdrawal.Amount),
+		},
+	})
+}
+
+// RecordWithdrawalRejectedTx returns a rejected withdrawal to the available balance
+func (s *LedgerService) RecordWithdrawalRejectedTx(tx *gorm.DB, withdrawal *models.WithdrawalRequest, currency string) error {
+	return s.PostTx(tx, &models.JournalEntry{
+		Type:         models.JournalEntryWithdrawalRejected,
+		OrganizerID:  &withdrawal.OrganizerID,
+		WithdrawalID: &withdrawal.ID,
+		Currency:     currency,
+		Description:  "Withdrawal rejected",
+		Lines: []models.LedgerLine{
+			models.Debit(models.LedgerAccountOrganizerPending, withdrawal.Amount),
+			models.Credit(models.LedgerAccountOrganizerAvailable, withdrawal.Amount),
+		},
+	})
+}
+
+// RecordWithdrawalPaidTx settles a withdrawal: the net amount leaves as a payout
+// and the platform keeps the withdrawal fee
+func (s *LedgerService) RecordWithdrawalPaidTx(tx *gorm.DB, withdrawal *models.WithdrawalRequest, currency string) error {
+	return s.PostTx(tx, &models.JournalEntry{
+		Type:         models.JournalEntryWithdrawalPaid,
+		OrganizerID:  &withdrawal.OrganizerID,
+		WithdrawalID: &withdrawal.ID,
+		Currency:     currency,
+		Description:  "Withdrawal paid " + withdrawal.TransactionRef,
+		Lines: []models.LedgerLine{
+			models.Debit(models.LedgerAccountOrganizerPending, withdrawal.Amount),
+			models.Credit(models.LedgerAccountPayouts, withdrawal.NetAmount),
+			models.Credit(models.LedgerAccountPlatformFees, withdrawal.WithdrawalFee),
+		},
+	})
+}
+
+// RecordWithdrawalFailedTx returns a withdrawal whose transfer failed to the
+// available balance. A paid withdrawal whose transfer was reversed also undoes
+// the payout and the withdrawal fee.
+func (s *LedgerService) RecordWithdrawalFailedTx(tx *gorm.DB, withdrawal *models.WithdrawalRequest, currency string, paid bool) error {
+	lines := []models.LedgerLine{
+		models.Debit(models.LedgerAccountOrganizerPending, withdrawal.Amount),
+		models.Credit(models.LedgerAccountOrganizerAvailable, withdrawal.Amount),
+	}
+	if paid {
+		lines = []models.LedgerLine{
+			models.Debit(models.LedgerAccountPayouts, withdrawal.NetAmount),
+			models.Debit(models.LedgerAccountPlatformFees, withdrawal.WithdrawalFee),
+			models.Credit(models.LedgerAccountOrganizerAvailable, withdrawal.Amount),
+		}
+	}
+
+	return s.PostTx(tx, &models.JournalEntry{
+		Type:         models.JournalEntryWithdrawalFailed,
+		OrganizerID:  &withdrawal.OrganizerID,
+		WithdrawalID: &withdrawal.ID,
+		Currency:     currency,
+		Description:  "Withdrawal transfer failed " + withdrawal.TransactionRef,
+		Lines:        lines,
+	})
+}
+
+// OrganizerStatement lists the organizer's entries between from and to (either may
+// be nil) with running available and pending balances
+func (s *LedgerService) OrganizerStatement(organizerID uuid.UUID, from, to *time.Time) (*OrganizerStatement, error) {
+	statement := &OrganizerStatement{OrganizerID: organizerID, Lines: []StatementLine{}}
+
+	if from != nil {
+		available, pending, err := s.ledgerBalance(organizerID, from)
+		if err != nil {
+			return nil, err
+		}
+		statement.OpeningAvailable = available
+		statement.OpeningPending = pending
+	}
+
+	query := s.db.Preload("Lines").Where("organizer_id = ?", organizerID)
+	if from != nil {
+		query = query.Where("created_at >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("created_at < ?", *to)
+	}
+
+	var entries []models.JournalEntry
+	if err := query.Order("created_at ASC, id ASC").Find(&entries).Error; err != nil {
+		return nil, fmt.Errorf("failed to load journal entries: %w", err)
+	}
+
+	available, pending := statement.OpeningAvailable, statement.OpeningPending
+	for _, entry := range entries {
+		line := StatementLine{
+			EntryID:       entry.ID,
+			Date:          entry.CreatedAt,
+			Type:          entry.Type,
+			Description:   entry.Description,
+			TransactionID: entry.TransactionID,
+			WithdrawalID:  entry.WithdrawalID,
+		}
+		for _, ledgerLine := range entry.Lines {
+			switch ledgerLine.Account {
+			case models.LedgerAccountOrganizerAvailable:
+				line.Available -= ledgerLine.Amount
+			case models.LedgerAccountOrganizerPending:
+				line.Pending -= ledgerLine.Amount
+			}
+		}
+
+		available += line.Available
+		pending += line.Pending
+		line.AvailableBalance = available
+		line.PendingBalance = pending
+		statement.Lines = append(statement.Lines, line)
+	}
+	statement.ClosingAvailable = available
+	statement.ClosingPending = pending
+
+	var balance models.OrganizerBalance
+	if err := s.db.First(&balance, "organizer_id = ?", organizerID).Error; err == nil {
+		ledgerAvailable, ledgerPending, err := s.ledgerBalance(organizerID, nil)
+		if err != nil {
+			return nil, err
+		}
+		statement.StoredBalance = &balance
+		statement.InBalance = balance.AvailableBalance == ledgerAvailable && balance.PendingBalance == ledgerPending
+	}
+
+	return statement, nil
+}
+
+// CheckBalances returns the organizers whose stored available or pending balance
+// differs from their ledger
+func (s *LedgerService) CheckBalances() ([]BalanceMismatch, error) {
+	mismatches := []BalanceMismatch{}
+	err := s.db.Raw(`
+		SELECT b.organizer_id, b.available_balance, b.pending_balance, ledger.available AS ledger_available, ledger.pending AS ledger_pending
+		FROM organizer_balances b
+		CROSS JOIN LATERAL (
+			SELECT
+				COALESCE(-SUM(CASE WHEN l.account = ? THEN l.amount END), 0) AS available,
+				COALESCE(-SUM(CASE WHEN l.account = ? THEN l.amount END), 0) AS pending
+			FROM journal_entries e
+			JOIN ledger_lines l ON l.journal_entry_id = e.id
+			WHERE e.organizer_id = b.organizer_id
+		) ledger
+		WHERE b.available_balance <> ledger.available OR b.pending_balance <> ledger.pending
+		ORDER BY b.organizer_id`,
+		models.LedgerAccountOrganizerAvailable, models.LedgerAccountOrganizerPending).
+		Scan(&mismatches).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to check balances: %w", err)
+	}
+	return mismatches, nil
+}
+
+// ledgerBalance sums the organizer's available and pending accounts, optionally
+// only for entries before a point in time
+func (s *LedgerService) ledgerBalance(organizerID uuid.UUID, before *time.Time) (int64, int64, error) {
+	query := s.db.Table("ledger_lines l").
+		Joins("JOIN journal_entries e ON e.id = l.journal_entry_id").
+		Where("e.organizer_id = ?", organizerID)
+	if before != nil {
+		query = query.Where("e.created_at < ?", *before)
+	}
+
+	var totals struct {
+		Available int64
+		Pending   int64
+	}
+	if err := query.Select(`
+		COALESCE(-SUM(CASE WHEN l.account = ? THEN l.amount END), 0) AS available,
+		COALESCE(-SUM(CASE WHEN l.account = ? THEN l.amount END), 0) AS pending`,
+		models.LedgerAccountOrganizerAvailable, models.LedgerAccountOrganizerPending).
+		Scan(&totals).Error; err != nil {
+		return 0, 0, fmt.Errorf("failed to sum ledger: %w", err)
+	}
+	return totals.Available, totals.Pending, nil
+}
+
+// applyToBalanceTx updates the organizer's stored balance by the entry's lines.
+// Organizer accounts carry credit balances, so credits increase them.
+func (s *LedgerService) applyToBalanceTx(tx *gorm.DB, entry *models.JournalEntry) error {
+	var available, pending, payouts int64
+	for _, line := range entry.Lines {
+		switch line.Account {
+		case models.LedgerAccountOrganizerAvailable:
+			available -= line.Amount
+		case models.LedgerAccountOrganizerPending:
+			pending -= line.Amount
+		case models.LedgerAccountPayouts:
+			payouts -= line.Amount
+		}
+	}
+
+	// Earnings only change with sales and refunds, not withdrawals
+	var earnings int64
+	switch entry.Type {
+	case models.JournalEntryTicketSale, models.JournalEntryRefund, models.JournalEntryRefundReversal:
+		earnings = available
+	}
+
+	if err := tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "organizer_id"}}, DoNothing: true}).
+		Create(&models.OrganizerBalance{OrganizerID: *entry.OrganizerID}).Error; err != nil {
+		return fmt.Errorf("failed to create organizer balance: %w", err)
+	}
+
+	if err := tx.Model(&models.OrganizerBalance{}).Where("organizer_id = ?", *entry.OrganizerID).Updates(map[string]interface{}{
+		"total_earnings":    gorm.Expr("total_earnings + ?", earnings),
+		"available_balance": gorm.Expr("available_balance + ?", available),
+		"pending_balance":   gorm.Expr("pending_balance + ?", pending),
+		"withdrawn_amount":  gorm.Expr("withdrawn_amount + ?", payouts),
+	}).Error; err != nil {
+		return fmt.Errorf("failed to update organizer balance: %w", err)
+	}
+	return nil
+}
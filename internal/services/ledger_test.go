@@ -0,0 +1,87 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/warui/event-ticketing-api/internal/models"
+	"gorm.io/gorm"
+)
+
+func TestLedgerPostingsKeepBalanceInStep(t *testing.T) {
+	db := setupInventoryDB(t)
+	ledgerService := NewLedgerService(db)
+
+	organizer := &models.User{
+		Email:     fmt.Sprintf("ledger-%s@example.com", uuid.New().String()[:8]),
+		Password:  "hashed",
+		FirstName: "Ledger",
+		LastName:  "Organizer",
+		Role:      models.RoleOrganizer,
+	}
+	if err := db.Create(organizer).Error; err != nil {
+		t.Fatalf("Failed to create organizer: %v", err)
+	}
+
+	sale := &models.Transaction{ID: uuid.New(), Amount: 200000, PlatformFee: 10000, NetAmount: 190000, Currency: "NGN", PaymentReference: "TXN-LEDGER"}
+	refund := &models.Transaction{ID: uuid.New(), Amount: 100000, PlatformFee: 5000, NetAmount: 95000, Currency: "NGN", PaymentReference: "RFD-LEDGER"}
+	withdrawal := &models.WithdrawalRequest{ID: uuid.New(), OrganizerID: organizer.ID, Amount: 50000, WithdrawalFee: 1250, NetAmount: 48750}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := ledgerService.RecordSaleTx(tx, sale, organizer.ID); err != nil {
+			return err
+		}
+		if err := ledgerService.RecordRefundTx(tx, refund, organizer.ID, false); err != nil {
+			return err
+		}
+		if err := ledgerService.RecordWithdrawalRequestTx(tx, withdrawal, "NGN"); err != nil {
+			return err
+		}
+		return ledgerService.RecordWithdrawalPaidTx(tx, withdrawal, "NGN")
+	})
+	if err != nil {
+		t.Fatalf("Failed to post entries: %v", err)
+	}
+
+	var balance models.OrganizerBalance
+	db.First(&balance, "organizer_id = ?", organizer.ID)
+	if balance.AvailableBalance != 45000 || balance.PendingBalance != 0 || balance.TotalEarnings != 95000 || balance.WithdrawnAmount != 48750 {
+		t.Errorf("Unexpected balance %+v", balance)
+	}
+
+	statement, err := ledgerService.OrganizerStatement(organizer.ID, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to build statement: %v", err)
+	}
+	if len(statement.Lines) != 4 || statement.ClosingAvailable != 45000 || !statement.InBalance {
+		t.Errorf("Unexpected statement %+v", statement)
+	}
+
+	mismatches, err := ledgerService.CheckBalances()
+	if err != nil {
+		t.Fatalf("Failed to check balances: %v", err)
+	}
+	for _, mismatch := range mismatches {
+		if mismatch.OrganizerID == organizer.ID {
+			t.Errorf("Expected organizer to be in balance, got %+v", mismatch)
+		}
+	}
+}
+
+func TestLedgerRejectsUnbalancedEntry(t *testing.T) {
+	db := setupInventoryDB(t)
+	ledgerService := NewLedgerService(db)
+
+	err := ledgerService.PostTx(db, &models.JournalEntry{
+		Type: models.JournalEntryTicketSale,
+		Lines: []models.LedgerLine{
+			models.Debit(models.LedgerAccountGatewayClearing, 200000),
+			models.Credit(models.LedgerAccountPlatformFees, 10000),
+		},
+	})
+	if !errors.Is(err, ErrUnbalancedEntry) {
+		t.Errorf("Expected ErrUnbalancedEntry, got %v", err)
+	}
+}
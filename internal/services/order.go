@@ -0,0 +1,552 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/warui/event-ticketing-api/internal/config"
+	"github.com/warui/event-ticketing-api/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrPaymentMismatch is returned when the gateway reports a payment that does not cover the order
+var ErrPaymentMismatch = errors.New("payment does not match transaction")
+
+// ErrEventCancelled is returned when a payment arrives for an event that was cancelled
+var ErrEventCancelled = errors.New("event has been cancelled")
+
+// ErrInvalidTransition is returned when a transaction cannot move to the requested status
+var ErrInvalidTransition = errors.New("invalid transaction status transition")
+
+// ErrPaymentRefunded is returned when a payment that could not be fulfilled was already refunded
+var ErrPaymentRefunded = errors.New("payment has been refunded")
+
+// deliveryLease is how long one caller may spend producing ticket documents
+// before another caller is allowed to retry the delivery
+const deliveryLease = 5 * time.Minute
+
+// deliverableStatuses are the purchase statuses whose tickets may still need
+// documents, including partly refunded ones whose remaining tickets change hands
+var deliverableStatuses = []models.TransactionStatus{
+	models.TransactionStatusCompleted,
+	models.TransactionStatusPartiallyRefunded,
+}
+
+// OrderService turns confirmed payments into tickets. It is shared by the payment
+// redirect, the gateway webhook and any other path that learns a payment succeeded.
+type OrderService struct {
+	db               *gorm.DB
+	cfg              *config.Config
+	inventoryService *InventoryService
+	ledgerService    *LedgerService
+	ticketDocuments  *TicketDocumentService
+	emailService     *EmailService
+}
+
+func NewOrderService(db *gorm.DB, cfg *config.Config, inventoryService *InventoryService, ledgerService *LedgerService, ticketDocuments *TicketDocumentService, emailService *EmailService) *OrderService {
+	return &OrderService{
+		db:               db,
+		cfg:              cfg,
+		inventoryService: inventoryService,
+		ledgerService:    ledgerService,
+		ticketDocuments:  ticketDocuments,
+		emailService:     emailService,
+	}
+}
+
+// FulfillPayment issues tickets for a ticket purchase the gateway confirmed as paid.
+// Tickets, stock and the organizer balance are written in one database transaction
+// that holds a lock on the transaction row, so any number of calls issue exactly one
+// set of tickets. QR codes, PDFs and emails are produced after commit by DeliverTickets.
+func (s *OrderService) FulfillPayment(transaction *models.Transaction, payment *PaymentResult) ([]models.Ticket, error) {
+	if payment.Amount < transaction.Amount {
+		reason := fmt.Sprintf("Amount paid (%d) is less than amount due (%d)", payment.Amount, transaction.Amount)
+		s.FailPayment(transaction, reason)
+		return nil, fmt.Errorf("%w: %s", ErrPaymentMismatch, reason)
+	}
+
+	if payment.Currency != "" && transaction.Currency != "" && payment.Currency != transaction.Currency {
+		reason := fmt.Sprintf("Paid in %s but transaction is in %s", payment.Currency, transaction.Currency)
+		s.FailPayment(transaction, reason)
+		return nil, fmt.Errorf("%w: %s", ErrPaymentMismatch, reason)
+	}
+
+	cartItems := transactionCartItems(transaction, payment)
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var locked models.Transaction
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&locked, "id = ?", transaction.ID).Error; err != nil {
+			return fmt.Errorf("failed to lock transaction: %w", err)
+		}
+
+		// Another caller already issued the tickets
+		if locked.Status == models.TransactionStatusCompleted {
+			*transaction = locked
+			return nil
+		}
+		if !locked.Status.CanTransitionTo(models.TransactionStatusCompleted) {
+			return fmt.Errorf("%w: %s to %s", ErrInvalidTransition, locked.Status, models.TransactionStatusCompleted)
+		}
+
+		// A payment refunded because it could not be fulfilled never becomes tickets,
+		// even if stock has since come back
+		var refunds int64
+		if err := tx.Model(&models.Transaction{}).
+			Where("parent_transaction_id = ? AND type = ? AND status <> ?", locked.ID, models.TransactionTypeRefund, models.TransactionStatusFailed).
+			Count(&refunds).Error; err != nil {
+			return fmt.Errorf("failed to check for refunds: %w", err)
+		}
+		if refunds > 0 {
+			return ErrPaymentRefunded
+		}
+
+		if locked.EventID != nil {
+			var event models.Event
+			if err := tx.Select("status").First(&event, "id = ?", *locked.EventID).Error; err != nil {
+				return fmt.Errorf("failed to load event: %w", err)
+			}
+			if event.Status == models.EventStatusCancelled {
+				return ErrEventCancelled
+			}
+		}
+
+		if err := s.inventoryService.SellTx(tx, locked.ID, stockRequests(cartItems)); err != nil {
+			return err
+		}
+
+		if err := s.issueTicketsTx(tx, &locked, cartItems); err != nil {
+			return err
+		}
+
+		// Credit the organizer
+		if locked.EventID != nil {
+			var event models.Event
+			if err := tx.First(&event, "id = ?", *locked.EventID).Error; err != nil {
+				return fmt.Errorf("failed to load event: %w", err)
+			}
+
+			if err := s.ledgerService.RecordSaleTx(tx, &locked, event.OrganizerID); err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		if err := tx.Model(&locked).Updates(map[string]interface{}{
+			"status":         models.TransactionStatusCompleted,
+			"failure_reason": "",
+			"fulfilled_at":   now,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to complete transaction: %w", err)
+		}
+
+		locked.Status = models.TransactionStatusCompleted
+		locked.FailureReason = ""
+		locked.FulfilledAt = &now
+		*transaction = locked
+		return nil
+	})
+	if errors.Is(err, ErrSeatUnavailable) {
+		s.failAndRefund(transaction, payment, "Seats taken before payment completed; payment refunded")
+		return nil, err
+	}
+	if errors.Is(err, ErrInsufficientStock) {
+		s.failAndRefund(transaction, payment, "Tickets sold out before payment completed; payment refunded")
+		return nil, err
+	}
+	if errors.Is(err, ErrEventCancelled) {
+		s.failAndRefund(transaction, payment, "Event cancelled before payment completed; payment refunded")
+		return nil, err
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.DeliverTickets(transaction); err != nil {
+		log.Printf("Ticket delivery for transaction %s failed, will retry: %v", transaction.ID, err)
+	}
+
+	return s.IssuedTickets(transaction)
+}
+
+// issueTicketsTx creates the tickets for every cart item inside the fulfilment
+// transaction, giving each ticket of a reserved seating type its own seat and
+// naming the holders given at checkout
+func (s *OrderService) issueTicketsTx(tx *gorm.DB, transaction *models.Transaction, cartItems []cartItem) error {
+	for _, item := range cartItems {
+		var ticketType models.TicketType
+		if err := tx.First(&ticketType, "id = ?", item.TicketTypeID).Error; err != nil {
+			return fmt.Errorf("failed to load ticket type %s: %w", item.TicketTypeID, err)
+		}
+
+		var seats []models.Seat
+		if ticketType.ReservedSeating {
+			var err error
+			seats, err = s.inventoryService.SellSeatsTx(tx, transaction.ID, SeatRequest{TicketTypeID: ticketType.ID, SeatIDs: item.SeatIDs}, item.Quantity)
+			if err != nil {
+				return err
+			}
+		}
+
+		for i := 0; i < item.Quantity; i++ {
+			ticket := models.Ticket{
+				EventID:       ticketType.EventID,
+				TicketTypeID:  ticketType.ID,
+				AttendeeID:    transaction.UserID,
+				TransactionID: transaction.ID,
+				Status:        models.TicketStatusConfirmed,
+				Price:         item.unitPrice(&ticketType) - item.ticketDiscount(i),
+				SessionID:     ticketType.SessionID,
+			}
+			if seats != nil {
+				ticket.SeatID = &seats[i].ID
+				ticket.SeatLabel = seats[i].Label()
+			}
+			if i < len(item.Holders) {
+				ticket.HolderName = item.Holders[i].Name
+				ticket.HolderEmail = item.Holders[i].Email
+			}
+
+			if err := tx.Create(&ticket).Error; err != nil {
+				return fmt.Errorf("failed to create ticket: %w", err)
+			}
+			if i < len(item.Holders) {
+				if err := saveTicketAnswersTx(tx, ticket.ID, item.Holders[i].Answers); err != nil {
+					return err
+				}
+			}
+			if seats != nil {
+				if err := tx.Model(&models.Seat{}).Where("id = ?", seats[i].ID).Update("ticket_id", ticket.ID).Error; err != nil {
+					return fmt.Errorf("failed to assign seat: %w", err)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// DeliverTickets produces the QR code and PDF for every issued ticket that does not
+// have them yet and emails them to the attendee. It is safe to call repeatedly:
+// a lease on the transaction keeps concurrent callers from delivering twice, and
+// tickets that already have documents are skipped.
+func (s *OrderService) DeliverTickets(transaction *models.Transaction) error {
+	if !slices.Contains(deliverableStatuses, transaction.Status) || transaction.DeliveredAt != nil {
+		return nil
+	}
+
+	now := time.Now()
+	lease := s.db.Model(&models.Transaction{}).
+		Where("id = ? AND status IN ? AND delivered_at IS NULL AND (delivery_locked_until IS NULL OR delivery_locked_until < ?)",
+			transaction.ID, deliverableStatuses, now).
+		Updates(map[string]interface{}{
+			"delivery_locked_until": now.Add(deliveryLease),
+			"delivery_attempts":     gorm.Expr("delivery_attempts + 1"),
+		})
+	if lease.Error != nil {
+		return fmt.Errorf("failed to lease ticket delivery: %w", lease.Error)
+	}
+	if lease.RowsAffected == 0 {
+		// Delivered already or another caller is delivering right now
+		return nil
+	}
+
+	deliveryErr := s.deliverPendingTickets(transaction)
+
+	updates := map[string]interface{}{"delivery_locked_until": nil, "delivery_error": ""}
+	if deliveryErr != nil {
+		updates["delivery_error"] = deliveryErr.Error()
+	} else {
+		updates["delivered_at"] = time.Now()
+	}
+	if err := s.db.Model(&models.Transaction{}).Where("id = ?", transaction.ID).Updates(updates).Error; err != nil {
+		return fmt.Errorf("failed to record ticket delivery: %w", err)
+	}
+
+	if deliveryErr == nil {
+		delivered := updates["delivered_at"].(time.Time)
+		transaction.DeliveredAt = &delivered
+	}
+	return deliveryErr
+}
+
+// deliverPendingTickets generates documents for the tickets still missing them and
+// sends each to its current holder, who is not the buyer once a ticket is transferred
+func (s *OrderService) deliverPendingTickets(transaction *models.Transaction) error {
+	var tickets []models.Ticket
+	if err := s.db.Preload("Event").Preload("TicketType").Preload("Attendee").Preload("Session").Preload("Answers.Question").
+		Where("transaction_id = ? AND (pdf_url = '' OR pdf_url IS NULL)", transaction.ID).
+		Find(&tickets).Error; err != nil {
+		return fmt.Errorf("failed to load tickets: %w", err)
+	}
+
+	for i := range tickets {
+		ticket := &tickets[i]
+		if ticket.Attendee.ID == uuid.Nil {
+			return fmt.Errorf("ticket %s: attendee not found", ticket.TicketNumber)
+		}
+
+		pdfData, err := s.ticketDocuments.GenerateTicketDocuments(ticket, &ticket.Event, &ticket.Attendee)
+		if err != nil {
+			return fmt.Errorf("ticket %s: %w", ticket.TicketNumber, err)
+		}
+
+		if err := s.db.Model(ticket).Updates(map[string]interface{}{
+			"qr_code_url": ticket.QRCodeURL,
+			"pdf_url":     ticket.PDFURL,
+			"qr_key_id":   ticket.QRKeyID,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to save documents for ticket %s: %w", ticket.TicketNumber, err)
+		}
+
+		// Send email with PDF attachment
+		go s.emailService.SendTicketEmail(ticket, &ticket.Event, &ticket.Attendee, pdfData)
+	}
+
+	return nil
+}
+
+// RetryPendingDeliveries delivers tickets for fulfilled transactions whose documents
+// could not be produced earlier. It returns how many transactions were delivered.
+func (s *OrderService) RetryPendingDeliveries(limit int) (int, error) {
+	var transactions []models.Transaction
+	if err := s.db.Where("status IN ? AND fulfilled_at IS NOT NULL AND delivered_at IS NULL AND (delivery_locked_until IS NULL OR delivery_locked_until < ?)",
+		deliverableStatuses, time.Now()).
+		Order("fulfilled_at ASC").Limit(limit).Find(&transactions).Error; err != nil {
+		return 0, fmt.Errorf("failed to load undelivered transactions: %w", err)
+	}
+
+	delivered := 0
+	for i := range transactions {
+		if err := s.DeliverTickets(&transactions[i]); err != nil {
+			log.Printf("Ticket delivery for transaction %s failed: %v", transactions[i].ID, err)
+			continue
+		}
+		delivered++
+	}
+
+	return delivered, nil
+}
+
+// failAndRefund fails a purchase whose payment went through but could not become
+// tickets, and queues a refund of everything paid. RefundService.ProcessUnfulfilledRefunds
+// sends it to the gateway.
+func (s *OrderService) failAndRefund(transaction *models.Transaction, payment *PaymentResult, reason string) {
+	if err := s.FailPayment(transaction, reason); err != nil {
+		log.Printf("Failed to mark transaction %s failed: %v", transaction.ID, err)
+	}
+	if payment.Amount <= 0 {
+		return
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var purchase models.Transaction
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&purchase, "id = ?", transaction.ID).Error; err != nil {
+			return fmt.Errorf("failed to lock transaction: %w", err)
+		}
+		// Fulfilled by another caller after all
+		if purchase.Status != models.TransactionStatusFailed {
+			return nil
+		}
+
+		var refunds int64
+		if err := tx.Model(&models.Transaction{}).
+			Where("parent_transaction_id = ? AND type = ? AND status <> ?", purchase.ID, models.TransactionTypeRefund, models.TransactionStatusFailed).
+			Count(&refunds).Error; err != nil {
+			return fmt.Errorf("failed to check for refunds: %w", err)
+		}
+		if refunds > 0 {
+			return nil
+		}
+
+		currency := payment.Currency
+		if currency == "" {
+			currency = purchase.Currency
+		}
+		refund := &models.Transaction{
+			UserID:              purchase.UserID,
+			EventID:             purchase.EventID,
+			Type:                models.TransactionTypeRefund,
+			Status:              models.TransactionStatusPending,
+			Amount:              payment.Amount,
+			Currency:            currency,
+			NetAmount:           payment.Amount,
+			PaymentGateway:      purchase.PaymentGateway,
+			PaymentReference:    fmt.Sprintf("RFD-%s-%d", uuid.New().String()[:8], time.Now().Unix()),
+			ParentTransactionID: &purchase.ID,
+			Description:         reason,
+		}
+		if err := tx.Create(refund).Error; err != nil {
+			return fmt.Errorf("failed to queue refund: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("Failed to queue refund for transaction %s: %v", transaction.ID, err)
+	}
+}
+
+// FailPayment marks a pending transaction as failed and releases the stock it held.
+// Completed transactions are left untouched.
+func (s *OrderService) FailPayment(transaction *models.Transaction, reason string) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Transaction{}).
+			Where("id = ? AND status = ?", transaction.ID, models.TransactionStatusPending).
+			Updates(map[string]interface{}{"status": models.TransactionStatusFailed, "failure_reason": reason})
+		if result.Error != nil {
+			return fmt.Errorf("failed to mark transaction failed: %w", result.Error)
+		}
+
+		if result.RowsAffected > 0 {
+			transaction.Status = models.TransactionStatusFailed
+			transaction.FailureReason = reason
+		}
+
+		return s.inventoryService.ReleaseHoldsTx(tx, transaction.ID)
+	})
+}
+
+// IssuedTickets returns the tickets already issued for a transaction
+func (s *OrderService) IssuedTickets(transaction *models.Transaction) ([]models.Ticket, error) {
+	var tickets []models.Ticket
+	if err := s.db.Preload("Event").Preload("TicketType").Where("transaction_id = ?", transaction.ID).Find(&tickets).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch tickets: %w", err)
+	}
+
+	return tickets, nil
+}
+
+// cartItem is one line of the cart stored in the payment metadata
+type cartItem struct {
+	TicketTypeID uuid.UUID
+	Quantity     int
+	Price        *int64               // Unit price charged at checkout; nil in carts saved before it was stored
+	Discount     int64                // Promo code discount on the whole line
+	SeatIDs      []uuid.UUID          // Seats picked for a reserved seating ticket type
+	Holders      []TicketRegistration // Named holders of the first tickets, in order
+}
+
+// unitPrice is the price the line was charged at. A later price change does not
+// reach checkouts that already hold stock.
+func (c cartItem) unitPrice(ticketType *models.TicketType) int64 {
+	if c.Price != nil {
+		return *c.Price
+	}
+	return ticketType.Price
+}
+
+// ticketDiscount spreads the line discount over its tickets so each ticket's price
+// is what was paid for it, with the remainder on the first tickets
+func (c cartItem) ticketDiscount(i int) int64 {
+	if c.Discount == 0 || c.Quantity <= 0 {
+		return 0
+	}
+	discount := c.Discount / int64(c.Quantity)
+	if int64(i) < c.Discount%int64(c.Quantity) {
+		discount++
+	}
+	return discount
+}
+
+// transactionCartItems reads the cart saved on the transaction at checkout. Older
+// transactions only carried it in the gateway metadata.
+func transactionCartItems(transaction *models.Transaction, payment *PaymentResult) []cartItem {
+	if transaction.PaymentMetadata != nil {
+		var metadata map[string]interface{}
+		if err := json.Unmarshal([]byte(*transaction.PaymentMetadata), &metadata); err == nil {
+			if items := parseCartItems(metadata); len(items) > 0 {
+				return items
+			}
+		}
+	}
+	return parseCartItems(payment.Metadata)
+}
+
+// parseCartItems reads the cart stored in the payment metadata at checkout
+func parseCartItems(metadata map[string]interface{}) []cartItem {
+	var cartItems []cartItem
+	items, _ := metadata["items"].([]interface{})
+	for _, item := range items {
+		itemMap, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		ticketTypeIDStr, _ := itemMap["ticket_type_id"].(string)
+		ticketTypeID, err := uuid.Parse(ticketTypeIDStr)
+		if err != nil {
+			continue
+		}
+
+		// Handle quantity - could be float64 or string
+		var quantity int
+		switch v := itemMap["quantity"].(type) {
+		case float64:
+			quantity = int(v)
+		case string:
+			if parsed, err := strconv.Atoi(v); err == nil {
+				quantity = parsed
+			} else {
+				quantity = 1
+			}
+		default:
+			quantity = 1
+		}
+
+		var price *int64
+		switch v := itemMap["price"].(type) {
+		case float64:
+			unitPrice := int64(v)
+			price = &unitPrice
+		case int64:
+			price = &v
+		}
+
+		discount, _ := itemMap["discount"].(float64)
+
+		var seatIDs []uuid.UUID
+		seats, _ := itemMap["seat_ids"].([]interface{})
+		for _, seat := range seats {
+			seatIDStr, _ := seat.(string)
+			if seatID, err := uuid.Parse(seatIDStr); err == nil {
+				seatIDs = append(seatIDs, seatID)
+			}
+		}
+
+		// Holders were validated at checkout; reading them back only needs the JSON shape
+		var holders []TicketRegistration
+		if raw, ok := itemMap["attendees"]; ok {
+			if encoded, err := json.Marshal(raw); err == nil {
+				if err := json.Unmarshal(encoded, &holders); err != nil {
+					holders = nil
+				}
+			}
+		}
+
+		cartItems = append(cartItems, cartItem{TicketTypeID: ticketTypeID, Quantity: quantity, Price: price, Discount: int64(discount), SeatIDs: seatIDs, Holders: holders})
+	}
+
+	return cartItems
+}
+
+// stockRequests totals cart quantities per ticket type
+func stockRequests(items []cartItem) []StockRequest {
+	var requests []StockRequest
+	index := make(map[uuid.UUID]int)
+	for _, item := range items {
+		if i, ok := index[item.TicketTypeID]; ok {
+			requests[i].Quantity += item.Quantity
+			continue
+		}
+		index[item.TicketTypeID] = len(requests)
+		requests = append(requests, StockRequest{TicketTypeID: item.TicketTypeID, Quantity: item.Quantity})
+	}
+
+	return requests
+}
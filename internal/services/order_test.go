@@ -0,0 +1,163 @@
+package services
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/warui/event-ticketing-api/internal/config"
+	"github.com/warui/event-ticketing-api/internal/models"
+	"gorm.io/gorm"
+)
+
+// newTestOrderService wires an OrderService that stores ticket documents in a temp dir
+func newTestOrderService(t *testing.T, db *gorm.DB) *OrderService {
+	cfg := &config.Config{
+		JWTSecret:        "test-secret",
+		StorageType:      "local",
+		LocalStoragePath: t.TempDir(),
+		Currency:         "NGN",
+	}
+
+	storageService, err := NewStorageService(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create storage service: %v", err)
+	}
+	signer, err := NewTicketSigner(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create ticket signer: %v", err)
+	}
+
+	ticketDocuments := NewTicketDocumentService(storageService, NewQRCodeService(), NewPDFService(), signer)
+	return NewOrderService(db, cfg, NewInventoryService(db), NewLedgerService(db), ticketDocuments, NewEmailService(cfg))
+}
+
+func TestFulfillPaymentConcurrentCallsIssueOneSetOfTickets(t *testing.T) {
+	db := setupInventoryDB(t)
+	service := newTestOrderService(t, db)
+	ticketType := createTestTicketType(t, db, 10)
+
+	var event models.Event
+	db.First(&event, "id = ?", ticketType.EventID)
+
+	transaction := &models.Transaction{
+		UserID:           event.OrganizerID,
+		EventID:          &event.ID,
+		Type:             models.TransactionTypeTicketPurchase,
+		Status:           models.TransactionStatusPending,
+		Amount:           200000,
+		Currency:         "NGN",
+		NetAmount:        190000,
+		PaymentReference: "TXN-" + ticketType.ID.String(),
+	}
+	if err := db.Create(transaction).Error; err != nil {
+		t.Fatalf("Failed to create transaction: %v", err)
+	}
+
+	payment := &PaymentResult{
+		Successful: true,
+		Reference:  transaction.PaymentReference,
+		Amount:     200000,
+		Currency:   "NGN",
+		Metadata: map[string]interface{}{
+			"event_id": event.ID.String(),
+			"items": []interface{}{
+				map[string]interface{}{"ticket_type_id": ticketType.ID.String(), "quantity": float64(2)},
+			},
+		},
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var current models.Transaction
+			db.First(&current, "id = ?", transaction.ID)
+			if _, err := service.FulfillPayment(&current, payment); err != nil {
+				t.Errorf("FulfillPayment failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	var ticketCount int64
+	db.Model(&models.Ticket{}).Where("transaction_id = ?", transaction.ID).Count(&ticketCount)
+	if ticketCount != 2 {
+		t.Errorf("Expected 2 tickets, got %d", ticketCount)
+	}
+
+	var stored models.TicketType
+	db.First(&stored, "id = ?", ticketType.ID)
+	if stored.Sold != 2 {
+		t.Errorf("Expected sold=2, got %d", stored.Sold)
+	}
+
+	var fulfilled models.Transaction
+	db.First(&fulfilled, "id = ?", transaction.ID)
+	if fulfilled.Status != models.TransactionStatusCompleted || fulfilled.FulfilledAt == nil {
+		t.Errorf("Expected a fulfilled completed transaction, got status %s", fulfilled.Status)
+	}
+
+	// Delivery runs synchronously inside whichever call held the lease
+	var undelivered int64
+	db.Model(&models.Ticket{}).Where("transaction_id = ? AND (pdf_url = '' OR pdf_url IS NULL)", transaction.ID).Count(&undelivered)
+	if undelivered != 0 {
+		t.Errorf("Expected all tickets to have documents, %d missing", undelivered)
+	}
+}
+
+func TestFulfillPaymentKeepsCheckoutPriceAfterPriceChange(t *testing.T) {
+	db := setupInventoryDB(t)
+	service := newTestOrderService(t, db)
+	ticketType := createTestTicketType(t, db, 10)
+
+	var event models.Event
+	db.First(&event, "id = ?", ticketType.EventID)
+
+	transaction := &models.Transaction{
+		UserID:           event.OrganizerID,
+		EventID:          &event.ID,
+		Type:             models.TransactionTypeTicketPurchase,
+		Status:           models.TransactionStatusPending,
+		Amount:           190000,
+		Currency:         "NGN",
+		NetAmount:        180500,
+		DiscountAmount:   10000,
+		PaymentReference: "TXN-PRC-" + ticketType.ID.String(),
+	}
+	if err := db.Create(transaction).Error; err != nil {
+		t.Fatalf("Failed to create transaction: %v", err)
+	}
+	if err := service.inventoryService.ReserveTx(db, transaction.ID, []StockRequest{{TicketTypeID: ticketType.ID, Quantity: 2}}, time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("Failed to reserve stock: %v", err)
+	}
+
+	// The organizer cuts the price while the checkout holds stock
+	newPrice := int64(5000)
+	if _, err := NewTicketTypeService(db).UpdateTicketType(ticketType.ID, event.OrganizerID, TicketTypeUpdate{Price: &newPrice}); err != nil {
+		t.Fatalf("Failed to change price: %v", err)
+	}
+
+	tickets, err := service.FulfillPayment(transaction, &PaymentResult{
+		Successful: true,
+		Reference:  transaction.PaymentReference,
+		Amount:     190000,
+		Currency:   "NGN",
+		Metadata: map[string]interface{}{
+			"event_id": event.ID.String(),
+			"items": []interface{}{
+				map[string]interface{}{"ticket_type_id": ticketType.ID.String(), "quantity": float64(2), "price": float64(100000), "discount": float64(10000)},
+			},
+		},
+	})
+	if err != nil || len(tickets) != 2 {
+		t.Fatalf("Failed to fulfil purchase: %v", err)
+	}
+
+	for _, ticket := range tickets {
+		if ticket.Price != 95000 {
+			t.Errorf("Expected ticket priced as charged at checkout (95000), got %d", ticket.Price)
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/warui/event-ticketing-api/internal/config"
+)
+
+// ErrInvalidWebhookSignature is returned when a webhook is not signed by the provider
+var ErrInvalidWebhookSignature = errors.New("invalid webhook signature")
+
+// ErrWebhookVerificationFailed is returned when a webhook could not be confirmed
+// with the provider's API. The provider should retry it.
+var ErrWebhookVerificationFailed = errors.New("failed to verify webhook with provider")
+
+// PaymentProvider is a payment gateway that can take ticket payments, refund them
+// and pay organizers out. All amounts are in minor units (kobo, cents).
+type PaymentProvider interface {
+	// Name identifies the provider; it is stored on transactions as PaymentGateway
+	Name() string
+	InitializePayment(req PaymentRequest) (*PaymentSession, error)
+	VerifyPayment(reference string) (*PaymentResult, error)
+	// ListPayments returns the successful payments made from from until to
+	ListPayments(from, to time.Time) ([]PaymentResult, error)
+	Refund(req PaymentRefundRequest) (*PaymentRefundResult, error)
+	// ParseWebhook authenticates a webhook delivery and translates it into a WebhookEvent
+	ParseWebhook(body []byte, headers http.Header) (*WebhookEvent, error)
+	Transfer(req TransferRequest) (*TransferResult, error)
+	// ListBanks returns the banks payouts can be sent to in the currency
+	ListBanks(currency string) ([]Bank, error)
+	// ResolveAccount returns the name on a bank account
+	ResolveAccount(bankCode, accountNumber string) (string, error)
+	// CreateRecipient saves a bank account with the provider and returns the code
+	// transfers to it are made with
+	CreateRecipient(req RecipientRequest) (string, error)
+}
+
+// PaymentRequest starts a checkout with the provider
+type PaymentRequest struct {
+	Email     string
+	Amount    int64
+	Currency  string
+	Reference string
+	Metadata  map[string]interface{}
+}
+
+// PaymentSession is where the buyer is sent to pay
+type PaymentSession struct {
+	AuthorizationURL string
+	Reference        string
+}
+
+// PaymentResult is the provider's view of a payment
+type PaymentResult struct {
+	Reference  string
+	Successful bool
+	Failed     bool   // The provider reports the payment failed or abandoned; it cannot still succeed
+	Status     string // Provider-specific status, kept for logs and errors
+	Amount     int64
+	Currency   string
+	PaidAt     time.Time
+	Metadata   map[string]interface{}
+}
+
+// PaymentRefundRequest returns money for a payment made through the provider
+type PaymentRefundRequest struct {
+	PaymentReference string
+	Amount           int64
+	Currency         string
+	Note             string
+}
+
+// PaymentRefundResult identifies a refund at the provider
+type PaymentRefundResult struct {
+	ID               string
+	PaymentReference string
+	Status           string
+	Amount           int64
+}
+
+// TransferRequest pays money out to a saved recipient or, without one, to the bank account
+type TransferRequest struct {
+	Reference     string
+	Amount        int64
+	Currency      string
+	RecipientCode string
+	BankCode      string
+	AccountNumber string
+	AccountName   string
+	Reason        string
+}
+
+// Bank is a bank the provider can pay out to
+type Bank struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
+
+// RecipientRequest is a bank account to save as a transfer recipient
+type RecipientRequest struct {
+	BankCode      string
+	AccountNumber string
+	AccountName   string
+	Currency      string
+}
+
+// TransferResult identifies a transfer at the provider
+type TransferResult struct {
+	ID        string
+	Reference string
+	Status    string
+	Amount    int64
+}
+
+type WebhookEventType string
+
+const (
+	WebhookPaymentSucceeded  WebhookEventType = "payment.succeeded"
+	WebhookRefundProcessed   WebhookEventType = "refund.processed"
+	WebhookRefundFailed      WebhookEventType = "refund.failed"
+	WebhookTransferSucceeded WebhookEventType = "transfer.succeeded"
+	WebhookTransferFailed    WebhookEventType = "transfer.failed"
+	WebhookIgnored           WebhookEventType = "ignored"
+)
+
+// WebhookEvent is a provider webhook translated into a gateway-neutral event.
+// Only the field matching Type is set.
+type WebhookEvent struct {
+	Type         WebhookEventType
+	ProviderType string // The provider's own event name
+	Payment      *PaymentResult
+	Refund       *PaymentRefundResult
+	Transfer     *TransferResult
+}
+
+// PaymentGateways holds the configured providers and picks one per currency
+type PaymentGateways struct {
+	providers       map[string]PaymentProvider
+	defaultProvider string
+	byCurrency      map[string]string
+}
+
+// NewPaymentGateways registers Paystack, Flutterwave and, when PAYMENT_FAKE_ENABLED
+// is set outside production, the fake provider. PAYMENT_PROVIDER sets the platform default and
+// PAYMENT_PROVIDERS_BY_CURRENCY ("USD:flutterwave,NGN:paystack") overrides it per currency.
+func NewPaymentGateways(cfg *config.Config) (*PaymentGateways, error) {
+	gateways := &PaymentGateways{
+		providers:       make(map[string]PaymentProvider),
+		defaultProvider: cfg.PaymentProvider,
+		byCurrency:      make(map[string]string),
+	}
+
+	gateways.Register(NewPaystackService(cfg))
+	gateways.Register(NewFlutterwaveService(cfg))
+	if cfg.PaymentFakeEnabled && cfg.Environment != "production" {
+		gateways.Register(NewFakePaymentProvider(cfg))
+	}
+
+	if gateways.defaultProvider == "" {
+		gateways.defaultProvider = "paystack"
+	}
+	if _, ok := gateways.providers[gateways.defaultProvider]; !ok {
+		return nil, fmt.Errorf("payment provider %s is not available", gateways.defaultProvider)
+	}
+
+	for _, entry := range strings.Split(cfg.PaymentProvidersByCurrency, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		currency, name, ok := strings.Cut(entry, ":")
+		if !ok || currency == "" || name == "" {
+			return nil, fmt.Errorf("invalid payment provider mapping %q", entry)
+		}
+		if _, ok := gateways.providers[name]; !ok {
+			return nil, fmt.Errorf("payment provider %s for %s is not available", name, currency)
+		}
+		gateways.byCurrency[strings.ToUpper(currency)] = name
+	}
+
+	return gateways, nil
+}
+
+// Register adds or replaces a provider
+func (g *PaymentGateways) Register(provider PaymentProvider) {
+	g.providers[provider.Name()] = provider
+}
+
+// ForCurrency returns the provider new payments in the currency should use
+func (g *PaymentGateways) ForCurrency(currency string) PaymentProvider {
+	if name, ok := g.byCurrency[strings.ToUpper(currency)]; ok {
+		return g.providers[name]
+	}
+	return g.providers[g.defaultProvider]
+}
+
+// Get returns a provider by name. Transactions created before providers were
+// configurable have no gateway recorded and were all made through Paystack.
+func (g *PaymentGateways) Get(name string) (PaymentProvider, error) {
+	if name == "" {
+		name = "paystack"
+	}
+
+	provider, ok := g.providers[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown payment provider %s", name)
+	}
+	return provider, nil
+}
+
+// StoredGatewayNames returns the PaymentGateway values recorded for a provider's
+// transactions and withdrawals. Paystack's include those recorded before providers
+// were configurable, which have none.
+func StoredGatewayNames(name string) []string {
+	if name == "paystack" {
+		return []string{"paystack", ""}
+	}
+	return []string{name}
+}
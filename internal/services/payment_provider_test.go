@@ -0,0 +1,202 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/warui/event-ticketing-api/internal/config"
+)
+
+func newTestGateways(t *testing.T, cfg *config.Config) *PaymentGateways {
+	gateways, err := NewPaymentGateways(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create payment gateways: %v", err)
+	}
+	return gateways
+}
+
+func TestPaymentGatewaysForCurrency(t *testing.T) {
+	gateways := newTestGateways(t, &config.Config{
+		PaymentProvider:            "paystack",
+		PaymentProvidersByCurrency: "USD:flutterwave, ghs:fake",
+		PaymentFakeEnabled:         true,
+	})
+
+	tests := []struct {
+		currency string
+		expected string
+	}{
+		{"NGN", "paystack"},
+		{"USD", "flutterwave"},
+		{"usd", "flutterwave"},
+		{"GHS", "fake"},
+		{"", "paystack"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.currency, func(t *testing.T) {
+			if name := gateways.ForCurrency(tt.currency).Name(); name != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, name)
+			}
+		})
+	}
+}
+
+func TestNewPaymentGatewaysRejectsInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.Config
+	}{
+		{"Unknown default", config.Config{PaymentProvider: "stripe"}},
+		{"Unknown currency provider", config.Config{PaymentProvidersByCurrency: "USD:stripe"}},
+		{"Malformed mapping", config.Config{PaymentProvidersByCurrency: "USD"}},
+		{"Fake provider not enabled", config.Config{PaymentProvider: "fake"}},
+		{"Fake provider in production", config.Config{Environment: "production", PaymentProvider: "fake", PaymentFakeEnabled: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewPaymentGateways(&tt.cfg); err == nil {
+				t.Error("Expected configuration error")
+			}
+		})
+	}
+}
+
+func TestPaymentGatewaysGet(t *testing.T) {
+	gateways := newTestGateways(t, &config.Config{})
+
+	// Transactions from before providers were configurable have no gateway recorded
+	provider, err := gateways.Get("")
+	if err != nil || provider.Name() != "paystack" {
+		t.Errorf("Expected paystack for an empty gateway, got %v, %v", provider, err)
+	}
+
+	if _, err := gateways.Get("stripe"); err == nil {
+		t.Error("Expected error for unknown provider")
+	}
+
+	if names := StoredGatewayNames("paystack"); len(names) != 2 || names[1] != "" {
+		t.Errorf("Expected paystack to match records without a gateway, got %v", names)
+	}
+	if names := StoredGatewayNames("flutterwave"); len(names) != 1 || names[0] != "flutterwave" {
+		t.Errorf("Expected flutterwave to match only its own records, got %v", names)
+	}
+}
+
+func TestFakePaymentProvider(t *testing.T) {
+	provider := NewFakePaymentProvider(&config.Config{
+		PaymentCallbackURL:       "http://localhost:8080/api/v1/payments/callback",
+		PaymentFakeWebhookSecret: "fake-secret",
+	})
+
+	session, err := provider.InitializePayment(PaymentRequest{
+		Email:     "buyer@example.com",
+		Amount:    150050,
+		Currency:  "NGN",
+		Reference: "TXN-fake",
+	})
+	if err != nil {
+		t.Fatalf("InitializePayment failed: %v", err)
+	}
+	if session.AuthorizationURL != "http://localhost:8080/api/v1/payments/callback?reference=TXN-fake" {
+		t.Errorf("Unexpected authorization URL %s", session.AuthorizationURL)
+	}
+
+	payment, err := provider.VerifyPayment("TXN-fake")
+	if err != nil {
+		t.Fatalf("VerifyPayment failed: %v", err)
+	}
+	if !payment.Successful || payment.Amount != 150050 || payment.Currency != "NGN" {
+		t.Errorf("Unexpected payment %+v", payment)
+	}
+
+	if _, err := provider.VerifyPayment("TXN-unknown"); err == nil {
+		t.Error("Expected error for unknown reference")
+	}
+
+	body := []byte(`{"event":"payment.succeeded","reference":"TXN-fake"}`)
+	if _, err := provider.ParseWebhook(body, http.Header{}); !errors.Is(err, ErrInvalidWebhookSignature) {
+		t.Errorf("Expected an unsigned webhook to be rejected, got %v", err)
+	}
+
+	mac := hmac.New(sha256.New, []byte("fake-secret"))
+	mac.Write(body)
+	event, err := provider.ParseWebhook(body, http.Header{"X-Fake-Signature": []string{hex.EncodeToString(mac.Sum(nil))}})
+	if err != nil {
+		t.Fatalf("ParseWebhook failed: %v", err)
+	}
+	if event.Type != WebhookPaymentSucceeded || event.Payment.Reference != "TXN-fake" {
+		t.Errorf("Unexpected webhook event %+v", event)
+	}
+}
+
+func TestFlutterwaveParseWebhook(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := "successful"
+		if r.URL.Query().Get("tx_ref") == "TXN-2" {
+			status = "failed"
+		}
+		w.Write([]byte(`{"status":"success","data":{"id":1,"tx_ref":"` + r.URL.Query().Get("tx_ref") + `","amount":2000,"currency":"NGN","status":"` + status + `"}}`))
+	}))
+	defer api.Close()
+
+	provider := NewFlutterwaveService(&config.Config{FlutterwaveSecretKey: "sk", FlutterwaveWebhookHash: "secret-hash"})
+	provider.baseURL = api.URL
+	signed := http.Header{"Verif-Hash": []string{"secret-hash"}}
+
+	tests := []struct {
+		name     string
+		body     string
+		headers  http.Header
+		expected WebhookEventType
+		err      error
+	}{
+		{"Successful charge", `{"event":"charge.completed","data":{"id":1,"tx_ref":"TXN-1","amount":2000,"currency":"NGN","status":"successful"}}`, signed, WebhookPaymentSucceeded, nil},
+		{"Failed charge", `{"event":"charge.completed","data":{"id":1,"tx_ref":"TXN-1","amount":2000,"currency":"NGN","status":"failed"}}`, signed, WebhookIgnored, nil},
+		{"Charge not confirmed by Flutterwave", `{"event":"charge.completed","data":{"id":1,"tx_ref":"TXN-2","amount":2000,"currency":"NGN","status":"successful"}}`, signed, WebhookIgnored, nil},
+		{"Successful transfer", `{"event":"transfer.completed","data":{"id":2,"reference":"WD-1","amount":500,"status":"SUCCESSFUL"}}`, signed, WebhookTransferSucceeded, nil},
+		{"Failed transfer", `{"event":"transfer.completed","data":{"id":2,"reference":"WD-1","amount":500,"status":"FAILED"}}`, signed, WebhookTransferFailed, nil},
+		{"Wrong hash", `{"event":"charge.completed","data":{}}`, http.Header{"Verif-Hash": []string{"other"}}, "", ErrInvalidWebhookSignature},
+		{"Missing hash", `{"event":"charge.completed","data":{}}`, http.Header{}, "", ErrInvalidWebhookSignature},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event, err := provider.ParseWebhook([]byte(tt.body), tt.headers)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("Expected %v, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseWebhook failed: %v", err)
+			}
+			if event.Type != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, event.Type)
+			}
+		})
+	}
+
+	// The amount comes from the verified charge, not the webhook body
+	event, err := provider.ParseWebhook([]byte(`{"event":"charge.completed","data":{"id":1,"tx_ref":"TXN-1","amount":1,"currency":"NGN","status":"successful"}}`), signed)
+	if err != nil {
+		t.Fatalf("ParseWebhook failed: %v", err)
+	}
+	if event.Payment == nil || event.Payment.Amount != 200000 {
+		t.Errorf("Expected the verified amount, got %+v", event.Payment)
+	}
+}
+
+func TestFlutterwavePaymentResultUsesMinorUnits(t *testing.T) {
+	result := flutterwavePaymentResult(&FlutterwaveTransactionData{TxRef: "TXN-1", Amount: 2000.25, Currency: "NGN", Status: "successful"})
+	if !result.Successful || result.Amount != 200025 {
+		t.Errorf("Unexpected payment result %+v", result)
+	}
+}
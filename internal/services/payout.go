@@ -0,0 +1,222 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/warui/event-ticketing-api/internal/config"
+	"github.com/warui/event-ticketing-api/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrPayoutNotAllowed is returned when a withdrawal cannot be paid out
+var ErrPayoutNotAllowed = errors.New("payout not allowed")
+
+// PayoutFailure is a withdrawal a batch payout could not send
+type PayoutFailure struct {
+	WithdrawalID uuid.UUID `json:"withdrawal_id"`
+	Error        string    `json:"error"`
+}
+
+// PayoutService pays approved withdrawals out through the payment provider's
+// transfer API and settles them when the provider reports the result.
+type PayoutService struct {
+	db            *gorm.DB
+	cfg           *config.Config
+	gateways      *PaymentGateways
+	ledgerService *LedgerService
+}
+
+func NewPayoutService(db *gorm.DB, cfg *config.Config, gateways *PaymentGateways, ledgerService *LedgerService) *PayoutService {
+	return &PayoutService{
+		db:            db,
+		cfg:           cfg,
+		gateways:      gateways,
+		ledgerService: ledgerService,
+	}
+}
+
+// TransferReference is the provider reference for a withdrawal's transfer. It never
+// changes, so the provider rejects a second transfer for the same withdrawal.
+func TransferReference(withdrawalID uuid.UUID) string {
+	return "wd-" + withdrawalID.String()
+}
+
+// SendPayout starts a transfer for an approved withdrawal. The withdrawal stays
+// processing until the provider's webhook completes or fails it.
+func (s *PayoutService) SendPayout(withdrawalID uuid.UUID) (*models.WithdrawalRequest, error) {
+	var withdrawal models.WithdrawalRequest
+	if err := s.db.First(&withdrawal, "id = ?", withdrawalID).Error; err != nil {
+		return nil, err
+	}
+
+	if withdrawal.Status != models.WithdrawalStatusApproved {
+		return nil, fmt.Errorf("%w: withdrawal is %s", ErrPayoutNotAllowed, withdrawal.Status)
+	}
+	if withdrawal.RecipientCode == "" && withdrawal.BankCode == "" {
+		return nil, fmt.Errorf("%w: withdrawal has no verified payout account", ErrPayoutNotAllowed)
+	}
+
+	// Recipients only exist at the gateway they were saved with
+	provider := s.gateways.ForCurrency(s.cfg.Currency)
+	if withdrawal.RecipientCode != "" {
+		recipientProvider, err := s.gateways.Get(withdrawal.PaymentGateway)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrPayoutNotAllowed, err)
+		}
+		provider = recipientProvider
+	}
+	reference := TransferReference(withdrawal.ID)
+
+	// Claim the withdrawal so two payouts cannot send it at once
+	result := s.db.Model(&models.WithdrawalRequest{}).
+		Where("id = ? AND status = ?", withdrawal.ID, models.WithdrawalStatusApproved).
+		Updates(map[string]interface{}{
+			"status":          models.WithdrawalStatusProcessing,
+			"payment_gateway": provider.Name(),
+			"transaction_ref": reference,
+			"failure_reason":  "",
+		})
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to claim withdrawal: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("%w: withdrawal is already being paid out", ErrPayoutNotAllowed)
+	}
+
+	transfer, err := provider.Transfer(TransferRequest{
+		Reference:     reference,
+		Amount:        withdrawal.NetAmount,
+		Currency:      s.cfg.Currency,
+		RecipientCode: withdrawal.RecipientCode,
+		BankCode:      withdrawal.BankCode,
+		AccountNumber: withdrawal.AccountNumber,
+		AccountName:   withdrawal.AccountName,
+		Reason:        "Organizer payout",
+	})
+	if err != nil {
+		// Leave the withdrawal approved for a retry; the fixed reference stops the
+		// provider paying it twice if the transfer went through after all
+		s.db.Model(&models.WithdrawalRequest{}).
+			Where("id = ? AND status = ?", withdrawal.ID, models.WithdrawalStatusProcessing).
+			Updates(map[string]interface{}{
+				"status":         models.WithdrawalStatusApproved,
+				"failure_reason": err.Error(),
+			})
+		return nil, fmt.Errorf("failed to start transfer: %w", err)
+	}
+
+	if err := s.db.Model(&models.WithdrawalRequest{}).Where("id = ?", withdrawal.ID).
+		Update("transfer_code", transfer.ID).Error; err != nil {
+		return nil, fmt.Errorf("failed to save transfer code: %w", err)
+	}
+
+	withdrawal.Status = models.WithdrawalStatusProcessing
+	withdrawal.PaymentGateway = provider.Name()
+	withdrawal.TransactionRef = reference
+	withdrawal.TransferCode = transfer.ID
+	withdrawal.FailureReason = ""
+	return &withdrawal, nil
+}
+
+// SendPayouts starts transfers for up to limit approved withdrawals, or only the
+// given ones. A withdrawal that cannot be sent does not stop the rest.
+func (s *PayoutService) SendPayouts(withdrawalIDs []uuid.UUID, limit int) ([]models.WithdrawalRequest, []PayoutFailure, error) {
+	query := s.db.Model(&models.WithdrawalRequest{}).Where("status = ?", models.WithdrawalStatusApproved)
+	if len(withdrawalIDs) > 0 {
+		query = query.Where("id IN ?", withdrawalIDs)
+	}
+
+	var ids []uuid.UUID
+	if err := query.Order("created_at ASC").Limit(limit).Pluck("id", &ids).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to load withdrawals: %w", err)
+	}
+
+	sent := []models.WithdrawalRequest{}
+	failed := []PayoutFailure{}
+	for _, id := range ids {
+		withdrawal, err := s.SendPayout(id)
+		if err != nil {
+			failed = append(failed, PayoutFailure{WithdrawalID: id, Error: err.Error()})
+			continue
+		}
+		sent = append(sent, *withdrawal)
+	}
+
+	return sent, failed, nil
+}
+
+// CompleteWithdrawal marks an approved or processing withdrawal as paid out and
+// records the payout in the ledger. It reports false if the withdrawal had
+// already been settled, for example by an earlier webhook delivery.
+func (s *PayoutService) CompleteWithdrawal(withdrawal *models.WithdrawalRequest, transactionRef string) (bool, error) {
+	now := time.Now()
+	completed := false
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.WithdrawalRequest{}).
+			Where("id = ? AND status IN ?", withdrawal.ID, []models.WithdrawalStatus{
+				models.WithdrawalStatusApproved,
+				models.WithdrawalStatusProcessing,
+			}).
+			Updates(map[string]interface{}{
+				"status":          models.WithdrawalStatusProcessed,
+				"processed_at":    now,
+				"transaction_ref": transactionRef,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		withdrawal.Status = models.WithdrawalStatusProcessed
+		withdrawal.ProcessedAt = &now
+		withdrawal.TransactionRef = transactionRef
+		completed = true
+		return s.ledgerService.RecordWithdrawalPaidTx(tx, withdrawal, s.cfg.Currency)
+	})
+	if err != nil {
+		return false, err
+	}
+	return completed, nil
+}
+
+// FailWithdrawal marks a withdrawal whose transfer failed or was reversed and
+// returns the amount to the organizer's available balance. It reports false if
+// the withdrawal had already failed or been rejected.
+func (s *PayoutService) FailWithdrawal(withdrawal *models.WithdrawalRequest, reason string) (bool, error) {
+	failed := false
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var locked models.WithdrawalRequest
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&locked, "id = ?", withdrawal.ID).Error; err != nil {
+			return err
+		}
+
+		switch locked.Status {
+		case models.WithdrawalStatusApproved, models.WithdrawalStatusProcessing, models.WithdrawalStatusProcessed:
+		default:
+			return nil
+		}
+
+		paid := locked.Status == models.WithdrawalStatusProcessed
+		if err := tx.Model(&locked).Updates(map[string]interface{}{
+			"status":         models.WithdrawalStatusFailed,
+			"failure_reason": reason,
+		}).Error; err != nil {
+			return err
+		}
+
+		withdrawal.Status = models.WithdrawalStatusFailed
+		withdrawal.FailureReason = reason
+		failed = true
+		return s.ledgerService.RecordWithdrawalFailedTx(tx, &locked, s.cfg.Currency, paid)
+	})
+	if err != nil {
+		return false, err
+	}
+	return failed, nil
+}
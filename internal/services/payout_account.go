@@ -0,0 +1,89 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/warui/event-ticketing-api/internal/config"
+	"github.com/warui/event-ticketing-api/internal/models"
+	"gorm.io/gorm"
+)
+
+// ErrAccountNotVerified is returned when the gateway does not recognise a bank account
+var ErrAccountNotVerified = errors.New("bank account could not be verified")
+
+// PayoutAccountService verifies organizers' bank accounts with the payment
+// gateway and saves them as transfer recipients
+type PayoutAccountService struct {
+	db       *gorm.DB
+	cfg      *config.Config
+	gateways *PaymentGateways
+}
+
+func NewPayoutAccountService(db *gorm.DB, cfg *config.Config, gateways *PaymentGateways) *PayoutAccountService {
+	return &PayoutAccountService{
+		db:       db,
+		cfg:      cfg,
+		gateways: gateways,
+	}
+}
+
+// ListBanks returns the banks organizers can be paid out to
+func (s *PayoutAccountService) ListBanks() ([]Bank, error) {
+	return s.gateways.ForCurrency(s.cfg.Currency).ListBanks(s.cfg.Currency)
+}
+
+// AddAccount checks the bank and account number with the gateway, saves the
+// account as a transfer recipient and stores it pending admin approval
+func (s *PayoutAccountService) AddAccount(organizerID uuid.UUID, bankCode, accountNumber string) (*models.PayoutAccount, error) {
+	provider := s.gateways.ForCurrency(s.cfg.Currency)
+	accountNumber = strings.TrimSpace(accountNumber)
+
+	banks, err := provider.ListBanks(s.cfg.Currency)
+	if err != nil {
+		return nil, err
+	}
+	var bank *Bank
+	for i := range banks {
+		if banks[i].Code == bankCode {
+			bank = &banks[i]
+			break
+		}
+	}
+	if bank == nil {
+		return nil, fmt.Errorf("%w: unknown bank code %s", ErrAccountNotVerified, bankCode)
+	}
+
+	accountName, err := provider.ResolveAccount(bank.Code, accountNumber)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrAccountNotVerified, err)
+	}
+
+	recipientCode, err := provider.CreateRecipient(RecipientRequest{
+		BankCode:      bank.Code,
+		AccountNumber: accountNumber,
+		AccountName:   accountName,
+		Currency:      s.cfg.Currency,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	account := &models.PayoutAccount{
+		OrganizerID:    organizerID,
+		PaymentGateway: provider.Name(),
+		RecipientCode:  recipientCode,
+		BankCode:       bank.Code,
+		BankName:       bank.Name,
+		AccountNumber:  models.MaskAccountNumber(accountNumber),
+		AccountName:    accountName,
+		Status:         models.PayoutAccountStatusPending,
+	}
+	if err := s.db.Create(account).Error; err != nil {
+		return nil, fmt.Errorf("failed to save payout account: %w", err)
+	}
+
+	return account, nil
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/warui/event-ticketing-api/internal/config"
+	"github.com/warui/event-ticketing-api/internal/models"
+)
+
+func TestFakeProviderResolveAccount(t *testing.T) {
+	provider := NewFakePaymentProvider(&config.Config{})
+
+	tests := []struct {
+		name          string
+		bankCode      string
+		accountNumber string
+		valid         bool
+	}{
+		{"Known bank", "001", "0123456789", true},
+		{"Unknown bank", "999", "0123456789", false},
+		{"Short account number", "001", "12345", false},
+		{"Non-numeric account number", "002", "01234abcde", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := provider.ResolveAccount(tt.bankCode, tt.accountNumber)
+			if (err == nil) != tt.valid {
+				t.Errorf("Expected valid=%v, got error %v", tt.valid, err)
+			}
+		})
+	}
+}
+
+func TestAddPayoutAccountStoresMaskedRecipient(t *testing.T) {
+	db := setupInventoryDB(t)
+	cfg := &config.Config{PaymentProvider: "fake", PaymentFakeEnabled: true, Currency: "NGN"}
+	payoutAccountService := NewPayoutAccountService(db, cfg, newTestGateways(t, cfg))
+
+	organizer := &models.User{
+		Email:     fmt.Sprintf("account-%s@example.com", uuid.New().String()[:8]),
+		Password:  "hashed",
+		FirstName: "Account",
+		LastName:  "Organizer",
+		Role:      models.RoleOrganizer,
+	}
+	if err := db.Create(organizer).Error; err != nil {
+		t.Fatalf("Failed to create organizer: %v", err)
+	}
+
+	account, err := payoutAccountService.AddAccount(organizer.ID, "001", "0123456789")
+	if err != nil {
+		t.Fatalf("AddAccount failed: %v", err)
+	}
+
+	var stored models.PayoutAccount
+	db.First(&stored, "id = ?", account.ID)
+	if stored.AccountNumber != "******6789" || stored.BankName != "Fake Bank" || stored.AccountName != "Fake Account Holder" {
+		t.Errorf("Unexpected account details %+v", stored)
+	}
+	if stored.Status != models.PayoutAccountStatusPending || stored.RecipientCode == "" || stored.PaymentGateway != "fake" {
+		t.Errorf("Expected a pending account with a fake recipient, got %+v", stored)
+	}
+
+	if _, err := payoutAccountService.AddAccount(organizer.ID, "999", "0123456789"); !errors.Is(err, ErrAccountNotVerified) {
+		t.Errorf("Expected ErrAccountNotVerified for an unknown bank, got %v", err)
+	}
+}
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/warui/event-ticketing-api/internal/config"
+	"github.com/warui/event-ticketing-api/internal/models"
+	"gorm.io/gorm"
+)
+
+// createApprovedWithdrawal creates an organizer with an approved withdrawal whose
+// amount has already moved to the pending balance
+func createApprovedWithdrawal(t *testing.T, db *gorm.DB, ledgerService *LedgerService, bankCode string) *models.WithdrawalRequest {
+	organizer := &models.User{
+		Email:     fmt.Sprintf("payout-%s@example.com", uuid.New().String()[:8]),
+		Password:  "hashed",
+		FirstName: "Payout",
+		LastName:  "Organizer",
+		Role:      models.RoleOrganizer,
+	}
+	if err := db.Create(organizer).Error; err != nil {
+		t.Fatalf("Failed to create organizer: %v", err)
+	}
+
+	withdrawal := &models.WithdrawalRequest{
+		OrganizerID:   organizer.ID,
+		Amount:        100000,
+		WithdrawalFee: 2500,
+		NetAmount:     97500,
+		BankName:      "Test Bank",
+		AccountNumber: "0123456789",
+		AccountName:   "Payout Organizer",
+		BankCode:      bankCode,
+		Status:        models.WithdrawalStatusApproved,
+	}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		sale := &models.Transaction{ID: uuid.New(), Amount: 100000, NetAmount: 100000, Currency: "NGN"}
+		if err := ledgerService.RecordSaleTx(tx, sale, organizer.ID); err != nil {
+			return err
+		}
+		if err := tx.Create(withdrawal).Error; err != nil {
+			return err
+		}
+		return ledgerService.RecordWithdrawalRequestTx(tx, withdrawal, "NGN")
+	})
+	if err != nil {
+		t.Fatalf("Failed to create withdrawal: %v", err)
+	}
+	return withdrawal
+}
+
+func newTestPayoutService(t *testing.T, db *gorm.DB) *PayoutService {
+	cfg := &config.Config{PaymentProvider: "fake", PaymentFakeEnabled: true, Currency: "NGN"}
+	return NewPayoutService(db, cfg, newTestGateways(t, cfg), NewLedgerService(db))
+}
+
+func TestPayoutFailureReturnsAmountToAvailableBalance(t *testing.T) {
+	db := setupInventoryDB(t)
+	payoutService := newTestPayoutService(t, db)
+	withdrawal := createApprovedWithdrawal(t, db, payoutService.ledgerService, "058")
+
+	sent, err := payoutService.SendPayout(withdrawal.ID)
+	if err != nil {
+		t.Fatalf("SendPayout failed: %v", err)
+	}
+	if sent.Status != models.WithdrawalStatusProcessing || sent.TransactionRef != TransferReference(withdrawal.ID) || sent.TransferCode == "" {
+		t.Errorf("Unexpected withdrawal after payout %+v", sent)
+	}
+
+	// A second payout of the same withdrawal is refused
+	if _, err := payoutService.SendPayout(withdrawal.ID); !errors.Is(err, ErrPayoutNotAllowed) {
+		t.Errorf("Expected ErrPayoutNotAllowed, got %v", err)
+	}
+
+	failed, err := payoutService.FailWithdrawal(sent, "Transfer failed at fake")
+	if err != nil || !failed {
+		t.Fatalf("FailWithdrawal failed: %v", err)
+	}
+
+	// Duplicate webhook deliveries change nothing
+	if failed, err := payoutService.FailWithdrawal(sent, "Transfer failed at fake"); err != nil || failed {
+		t.Errorf("Expected repeated failure to be ignored, got %v, %v", failed, err)
+	}
+
+	var balance models.OrganizerBalance
+	db.First(&balance, "organizer_id = ?", withdrawal.OrganizerID)
+	if balance.AvailableBalance != 100000 || balance.PendingBalance != 0 {
+		t.Errorf("Expected amount back in available balance, got %+v", balance)
+	}
+}
+
+func TestPayoutReversedAfterCompletion(t *testing.T) {
+	db := setupInventoryDB(t)
+	payoutService := newTestPayoutService(t, db)
+	withdrawal := createApprovedWithdrawal(t, db, payoutService.ledgerService, "058")
+
+	sent, err := payoutService.SendPayout(withdrawal.ID)
+	if err != nil {
+		t.Fatalf("SendPayout failed: %v", err)
+	}
+
+	completed, err := payoutService.CompleteWithdrawal(sent, sent.TransactionRef)
+	if err != nil || !completed {
+		t.Fatalf("CompleteWithdrawal failed: %v", err)
+	}
+
+	var balance models.OrganizerBalance
+	db.First(&balance, "organizer_id = ?", withdrawal.OrganizerID)
+	if balance.AvailableBalance != 0 || balance.PendingBalance != 0 || balance.WithdrawnAmount != 97500 {
+		t.Errorf("Unexpected balance after payout %+v", balance)
+	}
+
+	if _, err := payoutService.FailWithdrawal(sent, "Transfer reversed at fake"); err != nil {
+		t.Fatalf("FailWithdrawal failed: %v", err)
+	}
+
+	db.First(&balance, "organizer_id = ?", withdrawal.OrganizerID)
+	if balance.AvailableBalance != 100000 || balance.WithdrawnAmount != 0 {
+		t.Errorf("Expected reversed payout back in available balance, got %+v", balance)
+	}
+}
+
+func TestSendPayoutsSkipsWithdrawalsWithoutBankCode(t *testing.T) {
+	db := setupInventoryDB(t)
+	payoutService := newTestPayoutService(t, db)
+	ready := createApprovedWithdrawal(t, db, payoutService.ledgerService, "058")
+	missing := createApprovedWithdrawal(t, db, payoutService.ledgerService, "")
+
+	sent, failed, err := payoutService.SendPayouts([]uuid.UUID{ready.ID, missing.ID}, 10)
+	if err != nil {
+		t.Fatalf("SendPayouts failed: %v", err)
+	}
+	if len(sent) != 1 || sent[0].ID != ready.ID {
+		t.Errorf("Expected only %s sent, got %+v", ready.ID, sent)
+	}
+	if len(failed) != 1 || failed[0].WithdrawalID != missing.ID {
+		t.Errorf("Expected %s to fail, got %+v", missing.ID, failed)
+	}
+}
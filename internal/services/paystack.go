@@ -2,11 +2,16 @@ package services
 
 import (
 	"bytes"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
+	"net/url"
+	"strconv"
 	"time"
 
 	"github.com/warui/event-ticketing-api/internal/config"
@@ -20,7 +25,8 @@ type PaystackService struct {
 
 type PaystackInitializeRequest struct {
 	Email       string                 `json:"email"`
-	Amount      int                    `json:"amount"` // Amount in kobo (smallest currency unit)
+	Amount      int64                  `json:"amount"` // Amount in kobo (smallest currency unit)
+	Currency    string                 `json:"currency,omitempty"`
 	Reference   string                 `json:"reference"`
 	CallbackURL string                 `json:"callback_url,omitempty"`
 	Metadata    map[string]interface{} `json:"metadata,omitempty"`
@@ -37,23 +43,59 @@ type PaystackInitializeResponse struct {
 }
 
 type PaystackVerifyResponse struct {
-	Status  bool   `json:"status"`
-	Message string `json:"message"`
-	Data    struct {
-		ID            int64                  `json:"id"`
-		Domain        string                 `json:"domain"`
-		Status        string                 `json:"status"`
-		Reference     string                 `json:"reference"`
-		Amount        int                    `json:"amount"`
-		PaidAt        time.Time              `json:"paid_at"`
-		CreatedAt     time.Time              `json:"created_at"`
-		Channel       string                 `json:"channel"`
-		Currency      string                 `json:"currency"`
-		IPAddress     string                 `json:"ip_address"`
-		Metadata      map[string]interface{} `json:"metadata"`
-		Customer      map[string]interface{} `json:"customer"`
-		Authorization map[string]interface{} `json:"authorization"`
-	} `json:"data"`
+	Status  bool                    `json:"status"`
+	Message string                  `json:"message"`
+	Data    PaystackTransactionData `json:"data"`
+}
+
+// PaystackTransactionData is the transaction object returned by verify and sent with charge webhooks
+type PaystackTransactionData struct {
+	ID            int64                  `json:"id"`
+	Domain        string                 `json:"domain"`
+	Status        string                 `json:"status"`
+	Reference     string                 `json:"reference"`
+	Amount        int64                  `json:"amount"`
+	PaidAt        time.Time              `json:"paid_at"`
+	CreatedAt     time.Time              `json:"created_at"`
+	Channel       string                 `json:"channel"`
+	Currency      string                 `json:"currency"`
+	IPAddress     string                 `json:"ip_address"`
+	Metadata      map[string]interface{} `json:"metadata"`
+	Customer      map[string]interface{} `json:"customer"`
+	Authorization map[string]interface{} `json:"authorization"`
+}
+
+// PaystackWebhookEvent is the envelope Paystack posts to the webhook endpoint
+type PaystackWebhookEvent struct {
+	Event string          `json:"event"`
+	Data  json.RawMessage `json:"data"`
+}
+
+// PaystackRefundData is the refund object sent with refund webhooks
+type PaystackRefundData struct {
+	ID                   int64  `json:"id"`
+	Status               string `json:"status"`
+	TransactionReference string `json:"transaction_reference"`
+	Amount               int64  `json:"amount"`
+	Currency             string `json:"currency"`
+}
+
+// PaystackRefundResponse represents the response from creating a refund
+type PaystackRefundResponse struct {
+	Status  bool               `json:"status"`
+	Message string             `json:"message"`
+	Data    PaystackRefundData `json:"data"`
+}
+
+// PaystackTransferData is the transfer object sent with transfer webhooks
+type PaystackTransferData struct {
+	ID           int64  `json:"id"`
+	Status       string `json:"status"`
+	Reference    string `json:"reference"`
+	TransferCode string `json:"transfer_code"`
+	Amount       int64  `json:"amount"`
+	Currency     string `json:"currency"`
+	Reason       string `json:"reason"`
 }
 
 func NewPaystackService(cfg *config.Config) *PaystackService {
@@ -79,18 +121,16 @@ func NewPaystackService(cfg *config.Config) *PaystackService {
 	}
 }
 
-// InitializeTransaction initializes a payment transaction
-func (p *PaystackService) InitializeTransaction(email string, amount float64, reference string, metadata map[string]interface{}) (*PaystackInitializeResponse, error) {
+// InitializeTransaction initializes a payment transaction for an amount in kobo
+func (p *PaystackService) InitializeTransaction(email string, amount int64, currency string, reference string, metadata map[string]interface{}) (*PaystackInitializeResponse, error) {
 	if p.cfg.PaystackSecretKey == "" {
 		return nil, fmt.Errorf("paystack not configured")
 	}
 
-	// Convert amount to kobo (smallest unit)
-	amountInKobo := int(amount * 100)
-
 	reqBody := PaystackInitializeRequest{
 		Email:       email,
-		Amount:      amountInKobo,
+		Amount:      amount,
+		Currency:    currency,
 		Reference:   reference,
 		CallbackURL: p.cfg.PaystackCallbackURL,
 		Metadata:    metadata,
@@ -168,12 +208,365 @@ func (p *PaystackService) VerifyTransaction(reference string) (*PaystackVerifyRe
 	return &result, nil
 }
 
+// CreateRefund refunds all or part of a paid transaction. Paystack processes the
+// refund asynchronously and reports the outcome through refund webhooks. The amount is in kobo.
+func (p *PaystackService) CreateRefund(transactionReference string, amount int64, note string) (*PaystackRefundResponse, error) {
+	if p.cfg.PaystackSecretKey == "" {
+		return nil, fmt.Errorf("paystack not configured")
+	}
+
+	reqBody := map[string]interface{}{
+		"transaction":   transactionReference,
+		"amount":        amount,
+		"merchant_note": note,
+	}
+
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", p.baseURL+"/refund", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+p.cfg.PaystackSecretKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var result PaystackRefundResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	if !result.Status {
+		return nil, fmt.Errorf("paystack error: %s", result.Message)
+	}
+
+	return &result, nil
+}
+
 // IsTransactionSuccessful checks if a transaction was successful
 func (p *PaystackService) IsTransactionSuccessful(verification *PaystackVerifyResponse) bool {
 	return verification.Status && verification.Data.Status == "success"
 }
 
-// GetTransactionAmount returns the transaction amount in main currency unit
-func (p *PaystackService) GetTransactionAmount(verification *PaystackVerifyResponse) float64 {
-	return float64(verification.Data.Amount) / 100.0
+// GetTransactionAmount returns the transaction amount in kobo
+func (p *PaystackService) GetTransactionAmount(verification *PaystackVerifyResponse) int64 {
+	return verification.Data.Amount
+}
+
+// VerifyWebhookSignature checks the x-paystack-signature header, an HMAC-SHA512
+// of the raw request body keyed with the secret key
+func (p *PaystackService) VerifyWebhookSignature(body []byte, signature string) bool {
+	if p.cfg.PaystackSecretKey == "" || signature == "" {
+		return false
+	}
+
+	mac := hmac.New(sha512.New, []byte(p.cfg.PaystackSecretKey))
+	mac.Write(body)
+	expected := hex.EncodeToString(mac.Sum(nil))
+
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
+
+// ParseWebhookEvent decodes a webhook body into its event envelope
+func (p *PaystackService) ParseWebhookEvent(body []byte) (*PaystackWebhookEvent, error) {
+	var event PaystackWebhookEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal webhook: %w", err)
+	}
+
+	if event.Event == "" {
+		return nil, fmt.Errorf("webhook event type missing")
+	}
+
+	return &event, nil
+}
+
+// Name implements PaymentProvider
+func (p *PaystackService) Name() string {
+	return "paystack"
+}
+
+// InitializePayment implements PaymentProvider
+func (p *PaystackService) InitializePayment(req PaymentRequest) (*PaymentSession, error) {
+	result, err := p.InitializeTransaction(req.Email, req.Amount, req.Currency, req.Reference, req.Metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PaymentSession{
+		AuthorizationURL: result.Data.AuthorizationURL,
+		Reference:        req.Reference,
+	}, nil
+}
+
+// VerifyPayment implements PaymentProvider
+func (p *PaystackService) VerifyPayment(reference string) (*PaymentResult, error) {
+	verification, err := p.VerifyTransaction(reference)
+	if err != nil {
+		return nil, err
+	}
+
+	return paystackPaymentResult(&verification.Data), nil
+}
+
+// Refund implements PaymentProvider
+func (p *PaystackService) Refund(req PaymentRefundRequest) (*PaymentRefundResult, error) {
+	result, err := p.CreateRefund(req.PaymentReference, req.Amount, req.Note)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PaymentRefundResult{
+		ID:               strconv.FormatInt(result.Data.ID, 10),
+		PaymentReference: req.PaymentReference,
+		Status:           result.Data.Status,
+		Amount:           result.Data.Amount,
+	}, nil
+}
+
+// ParseWebhook implements PaymentProvider. Deliveries must carry a valid x-paystack-signature.
+func (p *PaystackService) ParseWebhook(body []byte, headers http.Header) (*WebhookEvent, error) {
+	if !p.VerifyWebhookSignature(body, headers.Get("x-paystack-signature")) {
+		return nil, ErrInvalidWebhookSignature
+	}
+
+	event, err := p.ParseWebhookEvent(body)
+	if err != nil {
+		return nil, err
+	}
+
+	webhook := &WebhookEvent{Type: WebhookIgnored, ProviderType: event.Event}
+	switch event.Event {
+	case "charge.success":
+		var data PaystackTransactionData
+		if err := json.Unmarshal(event.Data, &data); err != nil {
+			return nil, fmt.Errorf("invalid charge payload: %w", err)
+		}
+		if data.Status == "success" {
+			webhook.Type = WebhookPaymentSucceeded
+			webhook.Payment = paystackPaymentResult(&data)
+		}
+
+	case "refund.processed", "refund.failed":
+		var data PaystackRefundData
+		if err := json.Unmarshal(event.Data, &data); err != nil {
+			return nil, fmt.Errorf("invalid refund payload: %w", err)
+		}
+		webhook.Type = WebhookRefundProcessed
+		if event.Event == "refund.failed" {
+			webhook.Type = WebhookRefundFailed
+		}
+		webhook.Refund = &PaymentRefundResult{
+			ID:               strconv.FormatInt(data.ID, 10),
+			PaymentReference: data.TransactionReference,
+			Status:           data.Status,
+			Amount:           data.Amount,
+		}
+
+	case "transfer.success", "transfer.failed", "transfer.reversed":
+		var data PaystackTransferData
+		if err := json.Unmarshal(event.Data, &data); err != nil {
+			return nil, fmt.Errorf("invalid transfer payload: %w", err)
+		}
+		webhook.Type = WebhookTransferFailed
+		if event.Event == "transfer.success" {
+			webhook.Type = WebhookTransferSucceeded
+		}
+		webhook.Transfer = &TransferResult{
+			ID:        data.TransferCode,
+			Reference: data.Reference,
+			Status:    data.Status,
+			Amount:    data.Amount,
+		}
+	}
+
+	return webhook, nil
+}
+
+// Transfer implements PaymentProvider. Paystack pays out to a transfer recipient,
+// so one is created first when the request has only bank details.
+func (p *PaystackService) Transfer(req TransferRequest) (*TransferResult, error) {
+	recipientCode := req.RecipientCode
+	if recipientCode == "" {
+		code, err := p.CreateRecipient(RecipientRequest{
+			BankCode:      req.BankCode,
+			AccountNumber: req.AccountNumber,
+			AccountName:   req.AccountName,
+			Currency:      req.Currency,
+		})
+		if err != nil {
+			return nil, err
+		}
+		recipientCode = code
+	}
+
+	var transfer PaystackTransferData
+	if err := p.post("/transfer", map[string]interface{}{
+		"source":    "balance",
+		"amount":    req.Amount,
+		"recipient": recipientCode,
+		"reason":    req.Reason,
+		"reference": req.Reference,
+	}, &transfer); err != nil {
+		return nil, err
+	}
+
+	return &TransferResult{
+		ID:        transfer.TransferCode,
+		Reference: transfer.Reference,
+		Status:    transfer.Status,
+		Amount:    transfer.Amount,
+	}, nil
+}
+
+// ListPayments implements PaymentProvider
+func (p *PaystackService) ListPayments(from, to time.Time) ([]PaymentResult, error) {
+	const perPage = 100
+
+	var payments []PaymentResult
+	for page := 1; ; page++ {
+		query := url.Values{
+			"status":  {"success"},
+			"from":    {from.UTC().Format(time.RFC3339)},
+			"to":      {to.UTC().Format(time.RFC3339)},
+			"perPage": {strconv.Itoa(perPage)},
+			"page":    {strconv.Itoa(page)},
+		}
+
+		var transactions []PaystackTransactionData
+		if err := p.do("GET", "/transaction?"+query.Encode(), nil, &transactions); err != nil {
+			return nil, fmt.Errorf("failed to list transactions: %w", err)
+		}
+		for i := range transactions {
+			payments = append(payments, *paystackPaymentResult(&transactions[i]))
+		}
+
+		if len(transactions) < perPage {
+			return payments, nil
+		}
+	}
+}
+
+// ListBanks implements PaymentProvider
+func (p *PaystackService) ListBanks(currency string) ([]Bank, error) {
+	var banks []Bank
+	if err := p.do("GET", "/bank?currency="+url.QueryEscape(currency), nil, &banks); err != nil {
+		return nil, fmt.Errorf("failed to list banks: %w", err)
+	}
+	return banks, nil
+}
+
+// ResolveAccount implements PaymentProvider
+func (p *PaystackService) ResolveAccount(bankCode, accountNumber string) (string, error) {
+	query := url.Values{"account_number": {accountNumber}, "bank_code": {bankCode}}
+	var account struct {
+		AccountName string `json:"account_name"`
+	}
+	if err := p.do("GET", "/bank/resolve?"+query.Encode(), nil, &account); err != nil {
+		return "", err
+	}
+	return account.AccountName, nil
+}
+
+// CreateRecipient implements PaymentProvider
+func (p *PaystackService) CreateRecipient(req RecipientRequest) (string, error) {
+	var recipient struct {
+		RecipientCode string `json:"recipient_code"`
+	}
+	if err := p.post("/transferrecipient", map[string]interface{}{
+		"type":           "nuban",
+		"name":           req.AccountName,
+		"account_number": req.AccountNumber,
+		"bank_code":      req.BankCode,
+		"currency":       req.Currency,
+	}, &recipient); err != nil {
+		return "", fmt.Errorf("failed to create transfer recipient: %w", err)
+	}
+	return recipient.RecipientCode, nil
+}
+
+// post sends a JSON request to the Paystack API and decodes the data field of the response
+func (p *PaystackService) post(path string, payload interface{}, data interface{}) error {
+	return p.do("POST", path, payload, data)
+}
+
+// do sends a request to the Paystack API and decodes the data field of the response
+func (p *PaystackService) do(method, path string, payload interface{}, data interface{}) error {
+	if p.cfg.PaystackSecretKey == "" {
+		return fmt.Errorf("paystack not configured")
+	}
+
+	var body io.Reader
+	if payload != nil {
+		jsonData, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		body = bytes.NewBuffer(jsonData)
+	}
+
+	req, err := http.NewRequest(method, p.baseURL+path, body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+p.cfg.PaystackSecretKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var result struct {
+		Status  bool            `json:"status"`
+		Message string          `json:"message"`
+		Data    json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	if !result.Status {
+		return fmt.Errorf("paystack error: %s", result.Message)
+	}
+
+	if err := json.Unmarshal(result.Data, data); err != nil {
+		return fmt.Errorf("failed to unmarshal response data: %w", err)
+	}
+	return nil
+}
+
+// paystackPaymentResult converts a Paystack transaction into a PaymentResult
+func paystackPaymentResult(data *PaystackTransactionData) *PaymentResult {
+	return &PaymentResult{
+		Reference:  data.Reference,
+		Successful: data.Status == "success",
+		Failed:     data.Status == "failed" || data.Status == "abandoned",
+		Status:     data.Status,
+		Amount:     data.Amount,
+		Currency:   data.Currency,
+		PaidAt:     data.PaidAt,
+		Metadata:   data.Metadata,
+	}
 }
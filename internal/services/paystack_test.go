@@ -0,0 +1,109 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/warui/event-ticketing-api/internal/config"
+)
+
+func signPaystackBody(secret string, body []byte) string {
+	mac := hmac.New(sha512.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerifyWebhookSignature(t *testing.T) {
+	service := NewPaystackService(&config.Config{PaystackSecretKey: "sk_test_secret"})
+	body := []byte(`{"event":"charge.success","data":{"reference":"TXN-abc12345"}}`)
+
+	tests := []struct {
+		name      string
+		body      []byte
+		signature string
+		expected  bool
+	}{
+		{"Valid signature", body, signPaystackBody("sk_test_secret", body), true},
+		{"Signed with another key", body, signPaystackBody("sk_test_other", body), false},
+		{"Tampered body", []byte(`{"event":"charge.success","data":{"reference":"TXN-other"}}`), signPaystackBody("sk_test_secret", body), false},
+		{"Missing signature", body, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := service.VerifyWebhookSignature(tt.body, tt.signature)
+			if result != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestVerifyWebhookSignatureWithoutSecret(t *testing.T) {
+	service := NewPaystackService(&config.Config{})
+	body := []byte(`{"event":"charge.success"}`)
+
+	if service.VerifyWebhookSignature(body, signPaystackBody("", body)) {
+		t.Error("Webhooks must be rejected when Paystack is not configured")
+	}
+}
+
+func TestParseWebhookEvent(t *testing.T) {
+	service := NewPaystackService(&config.Config{})
+
+	event, err := service.ParseWebhookEvent([]byte(`{"event":"transfer.success","data":{"reference":"WD-1"}}`))
+	if err != nil {
+		t.Fatalf("ParseWebhookEvent failed: %v", err)
+	}
+	if event.Event != "transfer.success" {
+		t.Errorf("Expected transfer.success, got %s", event.Event)
+	}
+
+	if _, err := service.ParseWebhookEvent([]byte(`{"data":{}}`)); err == nil {
+		t.Error("Expected error for webhook without event type")
+	}
+
+	if _, err := service.ParseWebhookEvent([]byte(`not json`)); err == nil {
+		t.Error("Expected error for invalid JSON")
+	}
+}
+
+func TestPaystackParseWebhook(t *testing.T) {
+	service := NewPaystackService(&config.Config{PaystackSecretKey: "sk_test_secret"})
+
+	tests := []struct {
+		name     string
+		body     string
+		expected WebhookEventType
+	}{
+		{"Successful charge", `{"event":"charge.success","data":{"reference":"TXN-1","status":"success","amount":200000,"currency":"NGN"}}`, WebhookPaymentSucceeded},
+		{"Refund processed", `{"event":"refund.processed","data":{"id":42,"transaction_reference":"TXN-1","status":"processed"}}`, WebhookRefundProcessed},
+		{"Refund failed", `{"event":"refund.failed","data":{"id":42,"transaction_reference":"TXN-1","status":"failed"}}`, WebhookRefundFailed},
+		{"Transfer success", `{"event":"transfer.success","data":{"reference":"WD-1","status":"success"}}`, WebhookTransferSucceeded},
+		{"Transfer reversed", `{"event":"transfer.reversed","data":{"reference":"WD-1","status":"reversed"}}`, WebhookTransferFailed},
+		{"Other event", `{"event":"subscription.create","data":{}}`, WebhookIgnored},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headers := http.Header{}
+			headers.Set("x-paystack-signature", signPaystackBody("sk_test_secret", []byte(tt.body)))
+
+			event, err := service.ParseWebhook([]byte(tt.body), headers)
+			if err != nil {
+				t.Fatalf("ParseWebhook failed: %v", err)
+			}
+			if event.Type != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, event.Type)
+			}
+		})
+	}
+
+	if _, err := service.ParseWebhook([]byte(`{"event":"charge.success"}`), http.Header{}); !errors.Is(err, ErrInvalidWebhookSignature) {
+		t.Errorf("Expected ErrInvalidWebhookSignature, got %v", err)
+	}
+}
@@ -3,6 +3,7 @@ package services
 import (
 	"bytes"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/jung-kurt/gofpdf"
@@ -49,18 +50,32 @@ func (p *PDFService) GenerateTicketPDF(ticket *models.Ticket, event *models.Even
 	pdf.Cell(0, 8, "Attendee Information")
 	pdf.Ln(8)
 
+	holderName, holderEmail := ticket.Holder(attendee)
 	pdf.SetFont("Arial", "", 11)
 	grayColor()
 	pdf.Cell(50, 7, "Name:")
 	blackColor()
-	pdf.Cell(0, 7, fmt.Sprintf("%s %s", attendee.FirstName, attendee.LastName))
+	pdf.Cell(0, 7, holderName)
 	pdf.Ln(7)
 
 	grayColor()
 	pdf.Cell(50, 7, "Email:")
 	blackColor()
-	pdf.Cell(0, 7, attendee.Email)
-	pdf.Ln(12)
+	pdf.Cell(0, 7, holderEmail)
+	pdf.Ln(7)
+
+	// Answers to the event's registration questions, in the organizer's order
+	answers := append([]models.TicketAnswer(nil), ticket.Answers...)
+	sort.SliceStable(answers, func(i, j int) bool {
+		return answers[i].Question.Position < answers[j].Question.Position
+	})
+	for _, answer := range answers {
+		grayColor()
+		pdf.Cell(50, 7, answer.Question.Label+":")
+		blackColor()
+		pdf.MultiCell(0, 7, answer.Answer, "", "L", false)
+	}
+	pdf.Ln(5)
 
 	// Event Details
 	pdf.SetFont("Arial", "B", 12)
@@ -116,10 +131,34 @@ func (p *PDFService) GenerateTicketPDF(ticket *models.Ticket, event *models.Even
 		pdf.Ln(7)
 	}
 
+	if ticket.Session != nil {
+		session := ticket.Session.StartDate.Format("Monday, January 2, 2006 at 3:04 PM")
+		if ticket.Session.Title != "" {
+			session = ticket.Session.Title + " - " + session
+		}
+		grayColor()
+		pdf.Cell(50, 7, "Session:")
+		pdf.SetFont("Arial", "B", 11)
+		blackColor()
+		pdf.Cell(0, 7, session)
+		pdf.SetFont("Arial", "", 11)
+		pdf.Ln(7)
+	}
+
+	if ticket.SeatLabel != "" {
+		grayColor()
+		pdf.Cell(50, 7, "Seat:")
+		pdf.SetFont("Arial", "B", 11)
+		blackColor()
+		pdf.Cell(0, 7, ticket.SeatLabel)
+		pdf.SetFont("Arial", "", 11)
+		pdf.Ln(7)
+	}
+
 	grayColor()
 	pdf.Cell(50, 7, "Price:")
 	blackColor()
-	pdf.Cell(0, 7, fmt.Sprintf("NGN %.2f", ticket.Price))
+	pdf.Cell(0, 7, "NGN "+models.FormatAmount(ticket.Price))
 	pdf.Ln(7)
 
 	grayColor()
@@ -159,7 +198,7 @@ func (p *PDFService) GenerateTicketPDF(ticket *models.Ticket, event *models.Even
 	grayColor()
 	pdf.Cell(0, 5, fmt.Sprintf("Generated on %s", time.Now().Format("January 2, 2006 at 3:04 PM")))
 	pdf.Ln(5)
-	pdf.Cell(0, 5, "This ticket is non-transferable and valid for single entry only")
+	pdf.Cell(0, 5, "This ticket is valid for single entry only")
 	pdf.Ln(5)
 	pdf.Cell(0, 5, "For support, contact: support@eventtickets.com")
 
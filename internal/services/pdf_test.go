@@ -42,8 +42,15 @@ func TestGenerateTicketPDF(t *testing.T) {
 	ticket := &models.Ticket{
 		ID:           uuid.New(),
 		TicketNumber: "TKT-12345678",
-		Price:        5000,
-		Status:       models.TicketStatusConfirmed,
+		SeatLabel:    "Section Stalls, Row C, Seat 12",
+		Session:      &models.EventSession{Title: "Day 2", StartDate: time.Now().Add(48 * time.Hour)},
+		HolderName:   "Guest Holder",
+		HolderEmail:  "guest@example.com",
+		Answers: []models.TicketAnswer{
+			{Answer: "Vegetarian", Question: models.RegistrationQuestion{Label: "Dietary needs", Position: 1}},
+		},
+		Price:  5000,
+		Status: models.TicketStatusConfirmed,
 		TicketType: models.TicketType{
 			Name:        "General Admission",
 			Description: "Standard entry",
@@ -52,7 +59,7 @@ func TestGenerateTicketPDF(t *testing.T) {
 
 	// Generate simple QR code data
 	qrService := NewQRCodeService()
-	qrData, _ := qrService.GenerateTicketQRCode(ticket.TicketNumber, ticket.ID.String())
+	qrData, _ := qrService.GenerateTicketQRCode("TICKET:" + ticket.TicketNumber + ":ID:" + ticket.ID.String())
 
 	// Generate PDF
 	pdfData, err := service.GenerateTicketPDF(ticket, event, attendee, qrData)
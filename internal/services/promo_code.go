@@ -0,0 +1,104 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/warui/event-ticketing-api/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrPromoCodeInvalid is returned when a promo code cannot be used for a cart
+var ErrPromoCodeInvalid = errors.New("promo code cannot be applied")
+
+// PromoCodeService checks promo codes at checkout. A code counts as used by paid
+// purchases and by checkouts still holding their tickets; an abandoned checkout
+// gives its use back when its hold expires.
+type PromoCodeService struct {
+	db *gorm.DB
+}
+
+func NewPromoCodeService(db *gorm.DB) *PromoCodeService {
+	return &PromoCodeService{db: db}
+}
+
+// ApplyTx finds the event's code, locks it so concurrent checkouts cannot exceed
+// its limits, and returns it with the discount on each cart line. It must run in
+// the transaction that creates the purchase.
+func (s *PromoCodeService) ApplyTx(tx *gorm.DB, eventID, userID uuid.UUID, code string, lines []models.OrderLine, now time.Time) (*models.PromoCode, []int64, error) {
+	var promoCode models.PromoCode
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&promoCode, "event_id = ? AND code = ?", eventID, models.NormalizeCode(code)).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, fmt.Errorf("%w: unknown code", ErrPromoCodeInvalid)
+		}
+		return nil, nil, fmt.Errorf("failed to load promo code: %w", err)
+	}
+	if err := tx.Model(&promoCode).Association("TicketTypes").Find(&promoCode.TicketTypes); err != nil {
+		return nil, nil, fmt.Errorf("failed to load promo code ticket types: %w", err)
+	}
+
+	if !promoCode.IsOpenAt(now) {
+		return nil, nil, fmt.Errorf("%w: code is not active", ErrPromoCodeInvalid)
+	}
+
+	eligible := promoCode.EligibleQuantity(lines)
+	if eligible == 0 {
+		return nil, nil, fmt.Errorf("%w: code does not apply to these tickets", ErrPromoCodeInvalid)
+	}
+	if eligible < promoCode.MinQuantity {
+		return nil, nil, fmt.Errorf("%w: code needs at least %d eligible tickets", ErrPromoCodeInvalid, promoCode.MinQuantity)
+	}
+
+	if promoCode.MaxUses > 0 {
+		uses, err := s.usesTx(tx, promoCode.ID, nil, now)
+		if err != nil {
+			return nil, nil, err
+		}
+		if uses >= int64(promoCode.MaxUses) {
+			return nil, nil, fmt.Errorf("%w: code has been used up", ErrPromoCodeInvalid)
+		}
+	}
+	if promoCode.MaxUsesPerUser > 0 {
+		uses, err := s.usesTx(tx, promoCode.ID, &userID, now)
+		if err != nil {
+			return nil, nil, err
+		}
+		if uses >= int64(promoCode.MaxUsesPerUser) {
+			return nil, nil, fmt.Errorf("%w: you have already used this code", ErrPromoCodeInvalid)
+		}
+	}
+
+	return &promoCode, promoCode.LineDiscounts(lines), nil
+}
+
+// usesTx counts the purchases holding or having used a code, optionally for one user
+func (s *PromoCodeService) usesTx(tx *gorm.DB, promoCodeID uuid.UUID, userID *uuid.UUID, now time.Time) (int64, error) {
+	query := tx.Model(&models.Transaction{}).
+		Where("promo_code_id = ? AND type = ?", promoCodeID, models.TransactionTypeTicketPurchase).
+		Scopes(codeUseScope(now))
+	if userID != nil {
+		query = query.Where("user_id = ?", *userID)
+	}
+
+	var uses int64
+	if err := query.Count(&uses).Error; err != nil {
+		return 0, fmt.Errorf("failed to count promo code uses: %w", err)
+	}
+	return uses, nil
+}
+
+// codeUseScope limits purchases to those that use up a promo or access code: paid
+// ones, and pending checkouts whose ticket hold has not expired. Pending purchases
+// are only failed long after their hold ends, so counting them all would lock a
+// code up for every abandoned checkout.
+func codeUseScope(now time.Time) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("transactions.status IN ? OR (transactions.status = ? AND EXISTS ("+
+			"SELECT 1 FROM inventory_holds WHERE inventory_holds.transaction_id = transactions.id AND inventory_holds.status = ? AND inventory_holds.expires_at > ?))",
+			paidStatuses, models.TransactionStatusPending, models.HoldStatusActive, now)
+	}
+}
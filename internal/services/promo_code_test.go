@@ -0,0 +1,121 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/warui/event-ticketing-api/internal/models"
+	"gorm.io/gorm"
+)
+
+func TestPromoCodeLimits(t *testing.T) {
+	db := setupInventoryDB(t)
+	promoCodeService := NewPromoCodeService(db)
+	ticketType := createTestTicketType(t, db, 10)
+
+	var event models.Event
+	db.First(&event, "id = ?", ticketType.EventID)
+
+	promoCode := &models.PromoCode{
+		EventID:        event.ID,
+		Code:           models.NormalizeCode("EARLY" + uuid.New().String()[:8]),
+		DiscountType:   models.DiscountTypePercentage,
+		PercentOff:     20,
+		MaxUses:        2,
+		MaxUsesPerUser: 1,
+		MinQuantity:    2,
+		IsActive:       true,
+	}
+	if err := db.Create(promoCode).Error; err != nil {
+		t.Fatalf("Failed to create promo code: %v", err)
+	}
+
+	// use applies the code and records a purchase holding tickets with it, as checkout does
+	use := func(userID uuid.UUID, code string, quantity int) error {
+		lines := []models.OrderLine{{TicketTypeID: ticketType.ID, UnitPrice: ticketType.Price, Quantity: quantity}}
+		return db.Transaction(func(tx *gorm.DB) error {
+			applied, discounts, err := promoCodeService.ApplyTx(tx, event.ID, userID, code, lines, time.Now())
+			if err != nil {
+				return err
+			}
+			if discounts[0] != models.PercentageOf(lines[0].Subtotal(), 20) {
+				t.Errorf("Unexpected discount %d", discounts[0])
+			}
+			purchase := &models.Transaction{
+				UserID:           userID,
+				EventID:          &event.ID,
+				Type:             models.TransactionTypeTicketPurchase,
+				Status:           models.TransactionStatusPending,
+				Amount:           lines[0].Subtotal() - discounts[0],
+				NetAmount:        lines[0].Subtotal() - discounts[0],
+				PaymentReference: "TXN-PROMO-" + uuid.New().String(),
+				PromoCodeID:      &applied.ID,
+				DiscountAmount:   discounts[0],
+			}
+			if err := tx.Create(purchase).Error; err != nil {
+				return err
+			}
+			return tx.Create(&models.InventoryHold{
+				TransactionID: purchase.ID,
+				TicketTypeID:  ticketType.ID,
+				Quantity:      quantity,
+				ExpiresAt:     time.Now().Add(15 * time.Minute),
+			}).Error
+		})
+	}
+
+	newBuyer := func() uuid.UUID {
+		buyer := &models.User{Email: "promo-" + uuid.New().String()[:8] + "@example.com", Password: "hashed", FirstName: "Promo", LastName: "Buyer", Role: models.RoleAttendee}
+		if err := db.Create(buyer).Error; err != nil {
+			t.Fatalf("Failed to create buyer: %v", err)
+		}
+		return buyer.ID
+	}
+
+	first, second, third := newBuyer(), newBuyer(), newBuyer()
+	tests := []struct {
+		name     string
+		userID   uuid.UUID
+		code     string
+		quantity int
+		valid    bool
+	}{
+		{"Below minimum quantity", first, promoCode.Code, 1, false},
+		{"Lower case code", first, strings.ToLower(promoCode.Code), 2, true},
+		{"Second use by the same user", first, promoCode.Code, 2, false},
+		{"Another user", second, promoCode.Code, 3, true},
+		{"Used up", third, promoCode.Code, 2, false},
+		{"Unknown code", third, "NOPE", 2, false},
+	}
+
+	for _, tt := range tests {
+		err := use(tt.userID, tt.code, tt.quantity)
+		if tt.valid && err != nil {
+			t.Errorf("%s: expected the code to apply, got %v", tt.name, err)
+		}
+		if !tt.valid && !errors.Is(err, ErrPromoCodeInvalid) {
+			t.Errorf("%s: expected ErrPromoCodeInvalid, got %v", tt.name, err)
+		}
+	}
+
+	// A failed checkout gives its use back
+	db.Model(&models.Transaction{}).Where("promo_code_id = ? AND user_id = ?", promoCode.ID, second).
+		Update("status", models.TransactionStatusFailed)
+	if err := use(third, promoCode.Code, 2); err != nil {
+		t.Errorf("Expected the released use to be available, got %v", err)
+	}
+
+	// So does an abandoned checkout once its hold expires, long before it is failed
+	if err := use(newBuyer(), promoCode.Code, 2); !errors.Is(err, ErrPromoCodeInvalid) {
+		t.Fatalf("Expected the code to be used up, got %v", err)
+	}
+	db.Model(&models.InventoryHold{}).
+		Where("transaction_id IN (?)", db.Model(&models.Transaction{}).Select("id").Where("promo_code_id = ? AND user_id = ?", promoCode.ID, third)).
+		Update("expires_at", time.Now().Add(-time.Minute))
+	if err := use(newBuyer(), promoCode.Code, 2); err != nil {
+		t.Errorf("Expected the abandoned checkout's use to be available, got %v", err)
+	}
+}
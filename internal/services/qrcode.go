@@ -6,6 +6,9 @@ import (
 	"github.com/skip2/go-qrcode"
 )
 
+// ticketQRPrefix marks QR payloads produced by TicketSigner.SignTicket
+const ticketQRPrefix = "TICKET"
+
 type QRCodeService struct{}
 
 func NewQRCodeService() *QRCodeService {
@@ -28,9 +31,7 @@ func (q *QRCodeService) GenerateQRCode(data string, size int) ([]byte, error) {
 	return qr.PNG(size)
 }
 
-// GenerateTicketQRCode generates QR code specifically for tickets
-func (q *QRCodeService) GenerateTicketQRCode(ticketNumber, ticketID string) ([]byte, error) {
-	// Create QR code data with ticket information
-	data := fmt.Sprintf("TICKET:%s:ID:%s", ticketNumber, ticketID)
-	return q.GenerateQRCode(data, 256)
+// GenerateTicketQRCode generates QR code specifically for tickets from a signed payload
+func (q *QRCodeService) GenerateTicketQRCode(payload string) ([]byte, error) {
+	return q.GenerateQRCode(payload, 256)
 }
@@ -46,10 +46,9 @@ func TestGenerateQRCodeDefaultSize(t *testing.T) {
 
 func TestGenerateTicketQRCode(t *testing.T) {
 	service := NewQRCodeService()
-	ticketNumber := "TKT-12345678"
-	ticketID := "uuid-test-id"
+	payload := "TICKET:TKT-12345678:ID:uuid-test-id:EVT:uuid-event-id:KID:default:SIG:c2lnbmF0dXJl"
 
-	qrCode, err := service.GenerateTicketQRCode(ticketNumber, ticketID)
+	qrCode, err := service.GenerateTicketQRCode(payload)
 	if err != nil {
 		t.Fatalf("GenerateTicketQRCode failed: %v", err)
 	}
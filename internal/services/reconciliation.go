@@ -0,0 +1,276 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/warui/event-ticketing-api/internal/config"
+	"github.com/warui/event-ticketing-api/internal/models"
+	"gorm.io/gorm"
+)
+
+// paidStatuses are the purchase statuses that mean the gateway took the money
+var paidStatuses = []models.TransactionStatus{
+	models.TransactionStatusCompleted,
+	models.TransactionStatusPartiallyRefunded,
+	models.TransactionStatusRefunded,
+}
+
+// ReconcileResult counts what ReconcilePending did with the purchases it checked
+type ReconcileResult struct {
+	Fulfilled int
+	Failed    int
+	Pending   int
+}
+
+// ReconciliationService checks local purchases against the payment gateways:
+// it settles purchases left pending and reports days on which the gateway and
+// local records disagree
+type ReconciliationService struct {
+	db           *gorm.DB
+	cfg          *config.Config
+	gateways     *PaymentGateways
+	orderService *OrderService
+}
+
+func NewReconciliationService(db *gorm.DB, cfg *config.Config, gateways *PaymentGateways, orderService *OrderService) *ReconciliationService {
+	return &ReconciliationService{
+		db:           db,
+		cfg:          cfg,
+		gateways:     gateways,
+		orderService: orderService,
+	}
+}
+
+// ReconcilePending asks the gateway about up to limit purchases pending for longer
+// than staleAfter. Paid ones are fulfilled through the normal order path; unpaid
+// ones older than abandonAfter are marked failed.
+func (s *ReconciliationService) ReconcilePending(staleAfter, abandonAfter time.Duration, limit int) (*ReconcileResult, error) {
+	now := time.Now()
+
+	var transactions []models.Transaction
+	if err := s.db.Where("type = ? AND status = ? AND created_at < ?",
+		models.TransactionTypeTicketPurchase, models.TransactionStatusPending, now.Add(-staleAfter)).
+		Order("reconciled_at ASC NULLS FIRST, created_at ASC").
+		Limit(limit).Find(&transactions).Error; err != nil {
+		return nil, fmt.Errorf("failed to load pending transactions: %w", err)
+	}
+
+	result := &ReconcileResult{}
+	for i := range transactions {
+		transaction := &transactions[i]
+		abandoned := now.Sub(transaction.CreatedAt) > abandonAfter
+
+		if err := s.reconcileTransaction(transaction, abandoned); err != nil {
+			log.Printf("Failed to reconcile transaction %s: %v", transaction.ID, err)
+		}
+
+		switch transaction.Status {
+		case models.TransactionStatusCompleted:
+			result.Fulfilled++
+		case models.TransactionStatusFailed:
+			result.Failed++
+		default:
+			result.Pending++
+		}
+	}
+
+	return result, nil
+}
+
+// reconcileTransaction verifies one pending purchase with its gateway
+func (s *ReconciliationService) reconcileTransaction(transaction *models.Transaction, abandoned bool) error {
+	if err := s.db.Model(&models.Transaction{}).Where("id = ?", transaction.ID).
+		UpdateColumn("reconciled_at", time.Now()).Error; err != nil {
+		return fmt.Errorf("failed to mark transaction reconciled: %w", err)
+	}
+
+	provider, err := s.gateways.Get(transaction.PaymentGateway)
+	if err != nil {
+		return err
+	}
+
+	payment, err := provider.VerifyPayment(transaction.PaymentReference)
+	if err == nil && payment.Successful {
+		_, err := s.orderService.FulfillPayment(transaction, payment)
+		if errors.Is(err, ErrPaymentMismatch) || errors.Is(err, ErrInsufficientStock) || errors.Is(err, ErrSeatUnavailable) ||
+			errors.Is(err, ErrEventCancelled) || errors.Is(err, ErrPaymentRefunded) {
+			// FulfillPayment marked the transaction failed with the reason
+			transaction.Status = models.TransactionStatusFailed
+			return nil
+		}
+		return err
+	}
+
+	if abandoned {
+		return s.orderService.FailPayment(transaction, "Payment not completed before checkout was abandoned")
+	}
+	return nil
+}
+
+// BuildDailyReports reconciles the given day for every gateway that took payments
+// on it, and for the default gateway. It returns only the reports it built;
+// gateways already reported for the day are skipped.
+func (s *ReconciliationService) BuildDailyReports(day time.Time) ([]models.ReconciliationReport, error) {
+	from, to := reportDay(day)
+
+	var names []string
+	if err := s.db.Model(&models.Transaction{}).
+		Where("type = ? AND ((created_at >= ? AND created_at < ?) OR (fulfilled_at >= ? AND fulfilled_at < ?))",
+			models.TransactionTypeTicketPurchase, from, to, from, to).
+		Distinct().Pluck("payment_gateway", &names).Error; err != nil {
+		return nil, fmt.Errorf("failed to load payment gateways: %w", err)
+	}
+
+	gateways := []string{s.gateways.ForCurrency(s.cfg.Currency).Name()}
+	for _, name := range names {
+		provider, err := s.gateways.Get(name)
+		if err != nil {
+			log.Printf("Skipping reconciliation for unknown payment gateway %q", name)
+			continue
+		}
+		if !slices.Contains(gateways, provider.Name()) {
+			gateways = append(gateways, provider.Name())
+		}
+	}
+
+	var reports []models.ReconciliationReport
+	for _, name := range gateways {
+		var existing int64
+		if err := s.db.Model(&models.ReconciliationReport{}).Where("date = ? AND payment_gateway = ?", from, name).Count(&existing).Error; err != nil {
+			return reports, fmt.Errorf("failed to check for report: %w", err)
+		}
+		if existing > 0 {
+			continue
+		}
+
+		report, err := s.BuildDailyReport(name, day)
+		if err != nil {
+			return reports, err
+		}
+		reports = append(reports, *report)
+	}
+	return reports, nil
+}
+
+// BuildDailyReport compares the gateway's successful payments on day with the
+// purchases paid locally that day and stores the mismatches found. A day already
+// reported returns the stored report.
+func (s *ReconciliationService) BuildDailyReport(gatewayName string, day time.Time) (*models.ReconciliationReport, error) {
+	from, to := reportDay(day)
+
+	provider, err := s.gateways.Get(gatewayName)
+	if err != nil {
+		return nil, err
+	}
+
+	var existing models.ReconciliationReport
+	if err := s.db.Preload("Mismatches").First(&existing, "date = ? AND payment_gateway = ?", from, provider.Name()).Error; err == nil {
+		return &existing, nil
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to load report: %w", err)
+	}
+
+	payments, err := provider.ListPayments(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.ReconciliationReport{Date: from, PaymentGateway: provider.Name()}
+	paidAtGateway := make(map[string]bool)
+	for _, payment := range payments {
+		if !payment.Successful {
+			continue
+		}
+		paidAtGateway[payment.Reference] = true
+		report.GatewayCount++
+		report.GatewayTotal += payment.Amount
+
+		mismatch := models.ReconciliationMismatch{
+			PaymentReference: payment.Reference,
+			GatewayStatus:    payment.Status,
+			GatewayAmount:    payment.Amount,
+		}
+
+		var local models.Transaction
+		if err := s.db.First(&local, "payment_reference = ? AND type = ?", payment.Reference, models.TransactionTypeTicketPurchase).Error; err != nil {
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fmt.Errorf("failed to load transaction: %w", err)
+			}
+			mismatch.Kind = models.MismatchUnknownPayment
+			report.Mismatches = append(report.Mismatches, mismatch)
+			continue
+		}
+
+		mismatch.TransactionID = &local.ID
+		mismatch.LocalStatus = local.Status
+		mismatch.LocalAmount = local.Amount
+		switch {
+		case !slices.Contains(paidStatuses, local.Status):
+			mismatch.Kind = models.MismatchUnfulfilledPayment
+		case local.Amount != payment.Amount:
+			mismatch.Kind = models.MismatchAmount
+		default:
+			continue
+		}
+		report.Mismatches = append(report.Mismatches, mismatch)
+	}
+
+	gatewayNames := []string{provider.Name()}
+	if provider.Name() == "paystack" {
+		// Purchases from before providers were configurable have no gateway recorded
+		gatewayNames = append(gatewayNames, "")
+	}
+
+	// Free orders never reach the gateway
+	var locals []models.Transaction
+	if err := s.db.Where("type = ? AND status IN ? AND payment_gateway IN ? AND amount > 0 AND fulfilled_at >= ? AND fulfilled_at < ?",
+		models.TransactionTypeTicketPurchase, paidStatuses, gatewayNames, from, to).
+		Find(&locals).Error; err != nil {
+		return nil, fmt.Errorf("failed to load transactions: %w", err)
+	}
+
+	for i := range locals {
+		local := &locals[i]
+		report.LocalCount++
+		report.LocalTotal += local.Amount
+		if paidAtGateway[local.PaymentReference] {
+			continue
+		}
+
+		// The gateway may have dated the payment the day before or after
+		payment, err := provider.VerifyPayment(local.PaymentReference)
+		if err == nil && payment.Successful {
+			continue
+		}
+
+		mismatch := models.ReconciliationMismatch{
+			Kind:             models.MismatchMissingPayment,
+			PaymentReference: local.PaymentReference,
+			TransactionID:    &local.ID,
+			LocalStatus:      local.Status,
+			LocalAmount:      local.Amount,
+		}
+		if payment != nil {
+			mismatch.GatewayStatus = payment.Status
+			mismatch.GatewayAmount = payment.Amount
+		}
+		report.Mismatches = append(report.Mismatches, mismatch)
+	}
+
+	report.MismatchCount = len(report.Mismatches)
+	if err := s.db.Create(report).Error; err != nil {
+		return nil, fmt.Errorf("failed to save report: %w", err)
+	}
+
+	return report, nil
+}
+
+// reportDay returns the start and end of the UTC day containing t
+func reportDay(t time.Time) (time.Time, time.Time) {
+	from := time.Date(t.UTC().Year(), t.UTC().Month(), t.UTC().Day(), 0, 0, 0, 0, time.UTC)
+	return from, from.AddDate(0, 0, 1)
+}
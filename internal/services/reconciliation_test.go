@@ -0,0 +1,186 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/warui/event-ticketing-api/internal/models"
+	"gorm.io/gorm"
+)
+
+// newTestReconciliationService uses the fake gateway, which the test drives directly
+func newTestReconciliationService(t *testing.T, db *gorm.DB) (*ReconciliationService, *FakePaymentProvider) {
+	orderService := newTestOrderService(t, db)
+	orderService.cfg.PaymentProvider = "fake"
+	orderService.cfg.PaymentFakeEnabled = true
+	gateways := newTestGateways(t, orderService.cfg)
+
+	provider, err := gateways.Get("fake")
+	if err != nil {
+		t.Fatalf("Fake provider not registered: %v", err)
+	}
+	return NewReconciliationService(db, orderService.cfg, gateways, orderService), provider.(*FakePaymentProvider)
+}
+
+func TestReconcilePendingFulfilsPaidAndFailsAbandoned(t *testing.T) {
+	db := setupInventoryDB(t)
+	reconciliationService, provider := newTestReconciliationService(t, db)
+	ticketType := createTestTicketType(t, db, 10)
+
+	var event models.Event
+	db.First(&event, "id = ?", ticketType.EventID)
+
+	newPending := func(reference string, age time.Duration) *models.Transaction {
+		transaction := &models.Transaction{
+			UserID:           event.OrganizerID,
+			EventID:          &event.ID,
+			Type:             models.TransactionTypeTicketPurchase,
+			Status:           models.TransactionStatusPending,
+			Amount:           100000,
+			Currency:         "NGN",
+			PlatformFee:      5000,
+			NetAmount:        95000,
+			PaymentGateway:   "fake",
+			PaymentReference: reference,
+			CreatedAt:        time.Now().Add(-age),
+		}
+		if err := db.Create(transaction).Error; err != nil {
+			t.Fatalf("Failed to create transaction: %v", err)
+		}
+		return transaction
+	}
+
+	// The buyer paid but never came back to the callback
+	paid := newPending("TXN-RECON-PAID-"+uuid.New().String(), time.Hour)
+	provider.InitializePayment(PaymentRequest{
+		Amount:    100000,
+		Currency:  "NGN",
+		Reference: paid.PaymentReference,
+		Metadata: map[string]interface{}{
+			"event_id": event.ID.String(),
+			"items": []interface{}{
+				map[string]interface{}{"ticket_type_id": ticketType.ID.String(), "quantity": float64(1)},
+			},
+		},
+	})
+
+	// The buyer left checkout a day ago, and someone is still paying now
+	abandoned := newPending("TXN-RECON-GONE-"+uuid.New().String(), 48*time.Hour)
+	recent := newPending("TXN-RECON-WAIT-"+uuid.New().String(), time.Minute)
+
+	if _, err := reconciliationService.ReconcilePending(15*time.Minute, 24*time.Hour, 500); err != nil {
+		t.Fatalf("ReconcilePending failed: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		transaction *models.Transaction
+		expected    models.TransactionStatus
+	}{
+		{"Paid", paid, models.TransactionStatusCompleted},
+		{"Abandoned", abandoned, models.TransactionStatusFailed},
+		{"Still in checkout", recent, models.TransactionStatusPending},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stored models.Transaction
+			db.First(&stored, "id = ?", tt.transaction.ID)
+			if stored.Status != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, stored.Status)
+			}
+		})
+	}
+
+	var tickets int64
+	db.Model(&models.Ticket{}).Where("transaction_id = ?", paid.ID).Count(&tickets)
+	if tickets != 1 {
+		t.Errorf("Expected 1 ticket for the paid transaction, got %d", tickets)
+	}
+}
+
+func TestBuildDailyReportFindsMismatches(t *testing.T) {
+	db := setupInventoryDB(t)
+	reconciliationService, provider := newTestReconciliationService(t, db)
+
+	// A day no other test uses, cleared of earlier runs
+	day := time.Date(2001, 2, 3, 0, 0, 0, 0, time.UTC)
+	paidAt := day.Add(12 * time.Hour)
+	db.Where("report_id IN (?)", db.Model(&models.ReconciliationReport{}).Select("id").Where("date = ?", day)).Delete(&models.ReconciliationMismatch{})
+	db.Where("date = ?", day).Delete(&models.ReconciliationReport{})
+	db.Unscoped().Where("payment_gateway = ? AND fulfilled_at >= ? AND fulfilled_at < ?", "fake", day, day.AddDate(0, 0, 1)).Delete(&models.Transaction{})
+
+	user := &models.User{Email: "recon-" + uuid.New().String()[:8] + "@example.com", Password: "hashed", FirstName: "Recon", LastName: "Buyer", Role: models.RoleAttendee}
+	if err := db.Create(user).Error; err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+
+	newLocal := func(reference string, status models.TransactionStatus, amount int64) {
+		transaction := &models.Transaction{
+			UserID:           user.ID,
+			Type:             models.TransactionTypeTicketPurchase,
+			Status:           status,
+			Amount:           amount,
+			Currency:         "NGN",
+			NetAmount:        amount,
+			PaymentGateway:   "fake",
+			PaymentReference: reference,
+			FulfilledAt:      &paidAt,
+		}
+		if status != models.TransactionStatusCompleted {
+			transaction.FulfilledAt = nil
+		}
+		if err := db.Create(transaction).Error; err != nil {
+			t.Fatalf("Failed to create transaction: %v", err)
+		}
+	}
+	paidAtGateway := func(reference string, amount int64) {
+		provider.payments[reference] = PaymentRequest{Reference: reference, Amount: amount, Currency: "NGN"}
+		provider.startedAt[reference] = paidAt
+	}
+
+	suffix := uuid.New().String()
+	newLocal("TXN-MATCH-"+suffix, models.TransactionStatusCompleted, 100000)
+	paidAtGateway("TXN-MATCH-"+suffix, 100000)
+	newLocal("TXN-AMOUNT-"+suffix, models.TransactionStatusCompleted, 100000)
+	paidAtGateway("TXN-AMOUNT-"+suffix, 90000)
+	newLocal("TXN-UNFULFILLED-"+suffix, models.TransactionStatusFailed, 50000)
+	paidAtGateway("TXN-UNFULFILLED-"+suffix, 50000)
+	paidAtGateway("TXN-UNKNOWN-"+suffix, 20000)
+	newLocal("TXN-MISSING-"+suffix, models.TransactionStatusCompleted, 30000)
+
+	report, err := reconciliationService.BuildDailyReport("fake", paidAt)
+	if err != nil {
+		t.Fatalf("BuildDailyReport failed: %v", err)
+	}
+
+	if report.GatewayCount != 4 || report.GatewayTotal != 260000 || report.LocalCount != 3 || report.LocalTotal != 230000 {
+		t.Errorf("Unexpected totals %+v", report)
+	}
+
+	kinds := make(map[string]models.ReconciliationMismatchKind)
+	for _, mismatch := range report.Mismatches {
+		kinds[mismatch.PaymentReference] = mismatch.Kind
+	}
+	expected := map[string]models.ReconciliationMismatchKind{
+		"TXN-AMOUNT-" + suffix:      models.MismatchAmount,
+		"TXN-UNFULFILLED-" + suffix: models.MismatchUnfulfilledPayment,
+		"TXN-UNKNOWN-" + suffix:     models.MismatchUnknownPayment,
+		"TXN-MISSING-" + suffix:     models.MismatchMissingPayment,
+	}
+	if len(kinds) != len(expected) || report.MismatchCount != len(expected) {
+		t.Errorf("Expected %d mismatches, got %+v", len(expected), kinds)
+	}
+	for reference, kind := range expected {
+		if kinds[reference] != kind {
+			t.Errorf("Expected %s for %s, got %q", kind, reference, kinds[reference])
+		}
+	}
+
+	// The day is only reported once
+	again, err := reconciliationService.BuildDailyReport("fake", paidAt)
+	if err != nil || again.ID != report.ID {
+		t.Errorf("Expected the stored report back, got %v, %v", again, err)
+	}
+}
@@ -0,0 +1,433 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/warui/event-ticketing-api/internal/config"
+	"github.com/warui/event-ticketing-api/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrRefundNotAllowed is returned when the purchase or tickets cannot be refunded
+	ErrRefundNotAllowed = errors.New("refund not allowed")
+	// ErrNothingToRefund is returned when none of the requested tickets are refundable
+	ErrNothingToRefund = errors.New("no refundable tickets")
+)
+
+// RefundRequest describes which tickets of a purchase to refund and how much of their price to return
+type RefundRequest struct {
+	TransactionID uuid.UUID
+	TicketIDs     []uuid.UUID // Empty refunds every refundable ticket in the purchase
+	Percentage    float64     // Share of each ticket price returned, 0-100
+	Reason        string
+	CancelTickets bool      // Mark tickets cancelled instead of refunded, e.g. when the event is called off
+	HolderID      uuid.UUID // Only refund tickets the buyer still holds, not ones transferred away; empty for any ticket
+}
+
+// refundLease is how long one worker may spend sending a queued refund to the gateway
+// before another may retry it
+const refundLease = 5 * time.Minute
+
+// RefundService returns money for ticket purchases. The tickets, stock and organizer
+// balance are adjusted as soon as the refund is requested; if the gateway later
+// rejects the refund every change is reversed.
+type RefundService struct {
+	db            *gorm.DB
+	cfg           *config.Config
+	gateways      *PaymentGateways
+	ledgerService *LedgerService
+}
+
+func NewRefundService(db *gorm.DB, cfg *config.Config, gateways *PaymentGateways, ledgerService *LedgerService) *RefundService {
+	return &RefundService{
+		db:            db,
+		cfg:           cfg,
+		gateways:      gateways,
+		ledgerService: ledgerService,
+	}
+}
+
+// RefundTickets records a refund for the requested tickets and asks the gateway to pay it out
+func (s *RefundService) RefundTickets(req RefundRequest) (*models.Transaction, error) {
+	if req.Percentage <= 0 || req.Percentage > 100 {
+		return nil, fmt.Errorf("%w: refund percentage must be between 0 and 100", ErrRefundNotAllowed)
+	}
+
+	var purchase models.Transaction
+	var refund *models.Transaction
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&purchase, "id = ?", req.TransactionID).Error; err != nil {
+			return fmt.Errorf("failed to load transaction: %w", err)
+		}
+
+		if purchase.Type != models.TransactionTypeTicketPurchase {
+			return fmt.Errorf("%w: only ticket purchases can be refunded", ErrRefundNotAllowed)
+		}
+		if !purchase.Status.CanTransitionTo(models.TransactionStatusPartiallyRefunded) {
+			return fmt.Errorf("%w: transaction is %s", ErrRefundNotAllowed, purchase.Status)
+		}
+
+		query := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("transaction_id = ? AND status = ?", purchase.ID, models.TicketStatusConfirmed)
+		if len(req.TicketIDs) > 0 {
+			query = query.Where("id IN ?", req.TicketIDs)
+		}
+		if req.HolderID != uuid.Nil {
+			query = query.Where("attendee_id = ?", req.HolderID)
+		}
+
+		var tickets []models.Ticket
+		if err := query.Find(&tickets).Error; err != nil {
+			return fmt.Errorf("failed to load tickets: %w", err)
+		}
+		if len(tickets) == 0 {
+			return ErrNothingToRefund
+		}
+		if len(req.TicketIDs) > 0 && len(tickets) != len(req.TicketIDs) {
+			return fmt.Errorf("%w: some tickets are used, already refunded or not part of this purchase", ErrRefundNotAllowed)
+		}
+
+		var ticketTotal int64
+		for _, ticket := range tickets {
+			ticketTotal += ticket.Price
+		}
+
+		// Reverse the platform fee in proportion to the amount returned
+		amount := models.PercentageOf(ticketTotal, req.Percentage)
+		platformFee := models.MulDivRound(amount, purchase.PlatformFee, purchase.Amount)
+
+		description := req.Reason
+		if description == "" {
+			description = fmt.Sprintf("Refund of %d ticket(s) from %s", len(tickets), purchase.PaymentReference)
+		}
+
+		refund = &models.Transaction{
+			UserID:              purchase.UserID,
+			EventID:             purchase.EventID,
+			Type:                models.TransactionTypeRefund,
+			Status:              models.TransactionStatusPending,
+			Amount:              amount,
+			Currency:            purchase.Currency,
+			PlatformFee:         platformFee,
+			NetAmount:           amount - platformFee,
+			PaymentGateway:      purchase.PaymentGateway,
+			PaymentReference:    fmt.Sprintf("RFD-%s-%d", uuid.New().String()[:8], time.Now().Unix()),
+			ParentTransactionID: &purchase.ID,
+			Description:         description,
+		}
+		if err := tx.Create(refund).Error; err != nil {
+			return fmt.Errorf("failed to create refund: %w", err)
+		}
+
+		if err := s.applyRefundTx(tx, refund, tickets, -1); err != nil {
+			return err
+		}
+
+		ticketStatus := models.TicketStatusRefunded
+		if req.CancelTickets {
+			ticketStatus = models.TicketStatusCancelled
+		}
+
+		if err := tx.Model(&models.Ticket{}).Where("id IN ?", ticketIDs(tickets)).Updates(map[string]interface{}{
+			"status":                ticketStatus,
+			"refund_transaction_id": refund.ID,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to mark tickets refunded: %w", err)
+		}
+
+		return syncPurchaseStatusTx(tx, purchase.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Free tickets have nothing to pay back
+	if refund.Amount == 0 {
+		refund.Status = models.TransactionStatusCompleted
+		return refund, s.db.Model(refund).Update("status", models.TransactionStatusCompleted).Error
+	}
+
+	result, err := s.requestGatewayRefund(&purchase, refund)
+	if err != nil {
+		if reverseErr := s.reverseRefund(refund, "Gateway refund failed: "+err.Error()); reverseErr != nil {
+			return nil, fmt.Errorf("gateway refund failed (%v) and could not be reversed: %w", err, reverseErr)
+		}
+		return nil, fmt.Errorf("failed to create gateway refund: %w", err)
+	}
+
+	refund.GatewayReference = result.ID
+	if err := s.db.Model(refund).Update("gateway_reference", refund.GatewayReference).Error; err != nil {
+		return nil, fmt.Errorf("failed to record gateway refund: %w", err)
+	}
+
+	return refund, nil
+}
+
+// ProcessUnfulfilledRefunds sends the gateway the refunds queued for payments that went
+// through but could not become tickets. A refund the gateway does not accept stays
+// queued and is retried once its lease expires.
+func (s *RefundService) ProcessUnfulfilledRefunds(limit int) (int, error) {
+	var refunds []models.Transaction
+	if err := s.db.Where("type = ? AND status = ? AND gateway_reference = '' AND (refund_locked_until IS NULL OR refund_locked_until < ?)",
+		models.TransactionTypeRefund, models.TransactionStatusPending, time.Now()).
+		Where("parent_transaction_id IN (?)", s.db.Model(&models.Transaction{}).Select("id").
+			Where("type = ? AND status = ?", models.TransactionTypeTicketPurchase, models.TransactionStatusFailed)).
+		Order("created_at ASC").Limit(limit).Find(&refunds).Error; err != nil {
+		return 0, fmt.Errorf("failed to load queued refunds: %w", err)
+	}
+
+	sent := 0
+	for i := range refunds {
+		refund := &refunds[i]
+		now := time.Now()
+		lease := s.db.Model(&models.Transaction{}).
+			Where("id = ? AND gateway_reference = '' AND (refund_locked_until IS NULL OR refund_locked_until < ?)", refund.ID, now).
+			Update("refund_locked_until", now.Add(refundLease))
+		if lease.Error != nil {
+			return sent, fmt.Errorf("failed to lease refund: %w", lease.Error)
+		}
+		if lease.RowsAffected == 0 {
+			continue
+		}
+
+		var purchase models.Transaction
+		if err := s.db.First(&purchase, "id = ?", *refund.ParentTransactionID).Error; err != nil {
+			return sent, fmt.Errorf("failed to load purchase: %w", err)
+		}
+
+		result, err := s.requestGatewayRefund(&purchase, refund)
+		if err != nil {
+			log.Printf("Refund %s of unfulfilled payment %s failed, will retry: %v", refund.ID, purchase.PaymentReference, err)
+			s.db.Model(refund).Update("failure_reason", "Gateway refund failed: "+err.Error())
+			continue
+		}
+
+		if err := s.db.Model(refund).Updates(map[string]interface{}{
+			"gateway_reference":   result.ID,
+			"failure_reason":      "",
+			"refund_locked_until": nil,
+		}).Error; err != nil {
+			return sent, fmt.Errorf("failed to record gateway refund: %w", err)
+		}
+		sent++
+	}
+
+	return sent, nil
+}
+
+// requestGatewayRefund asks the provider that took the payment to return the refund amount
+func (s *RefundService) requestGatewayRefund(purchase, refund *models.Transaction) (*PaymentRefundResult, error) {
+	provider, err := s.gateways.Get(purchase.PaymentGateway)
+	if err != nil {
+		return nil, err
+	}
+
+	return provider.Refund(PaymentRefundRequest{
+		PaymentReference: purchase.PaymentReference,
+		Amount:           refund.Amount,
+		Currency:         refund.Currency,
+		Note:             refund.Description,
+	})
+}
+
+// CompleteRefund marks a pending refund made through the gateway completed once it has paid it out
+func (s *RefundService) CompleteRefund(gateway, gatewayReference string) error {
+	return s.db.Model(&models.Transaction{}).
+		Where("gateway_reference = ? AND type = ? AND status = ? AND payment_gateway IN ?", gatewayReference,
+			models.TransactionTypeRefund, models.TransactionStatusPending, StoredGatewayNames(gateway)).
+		Update("status", models.TransactionStatusCompleted).Error
+}
+
+// FailRefund reverses a pending refund made through the gateway that it could not pay out
+func (s *RefundService) FailRefund(gateway, gatewayReference string, reason string) error {
+	var refund models.Transaction
+	if err := s.db.First(&refund, "gateway_reference = ? AND type = ? AND payment_gateway IN ?", gatewayReference,
+		models.TransactionTypeRefund, StoredGatewayNames(gateway)).Error; err != nil {
+		return err
+	}
+
+	return s.reverseRefund(&refund, reason)
+}
+
+// reverseRefund restores the tickets, stock and balance taken by a refund that did not go through.
+// If the event was cancelled or the stock sold again meanwhile nothing is restored: the
+// refund stays pending and it and its purchase get a failure reason for an admin to
+// settle by hand.
+func (s *RefundService) reverseRefund(refund *models.Transaction, reason string) error {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Transaction{}).
+			Where("id = ? AND status = ?", refund.ID, models.TransactionStatusPending).
+			Updates(map[string]interface{}{"status": models.TransactionStatusFailed, "failure_reason": reason})
+		if result.Error != nil {
+			return fmt.Errorf("failed to mark refund failed: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		// A refund of a payment that never became tickets took nothing to restore
+		if refund.ParentTransactionID != nil {
+			var purchase models.Transaction
+			if err := tx.Select("status").First(&purchase, "id = ?", *refund.ParentTransactionID).Error; err != nil {
+				return fmt.Errorf("failed to load purchase: %w", err)
+			}
+			if purchase.Status == models.TransactionStatusFailed {
+				return nil
+			}
+		}
+
+		// Tickets of a cancelled event must not become admissible again
+		if refund.EventID != nil {
+			var event models.Event
+			if err := tx.Select("status").First(&event, "id = ?", *refund.EventID).Error; err != nil {
+				return fmt.Errorf("failed to load event: %w", err)
+			}
+			if event.Status == models.EventStatusCancelled {
+				return ErrEventCancelled
+			}
+		}
+
+		var tickets []models.Ticket
+		if err := tx.Where("refund_transaction_id = ?", refund.ID).Find(&tickets).Error; err != nil {
+			return fmt.Errorf("failed to load refunded tickets: %w", err)
+		}
+
+		if err := s.applyRefundTx(tx, refund, tickets, 1); err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.Ticket{}).Where("refund_transaction_id = ?", refund.ID).Updates(map[string]interface{}{
+			"status":                models.TicketStatusConfirmed,
+			"refund_transaction_id": nil,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to restore tickets: %w", err)
+		}
+
+		if refund.ParentTransactionID == nil {
+			return nil
+		}
+		return syncPurchaseStatusTx(tx, *refund.ParentTransactionID)
+	})
+	// The tickets cannot be given back, so the refund stays pending and the buyer
+	// has to be refunded by hand
+	if errors.Is(err, ErrEventCancelled) {
+		return s.flagForManualRefund(refund, reason+"; event is cancelled, refund needs manual handling")
+	}
+	if errors.Is(err, ErrInsufficientStock) {
+		return s.flagForManualRefund(refund, reason+"; tickets were resold, refund needs manual handling")
+	}
+	if err != nil {
+		return err
+	}
+
+	refund.Status = models.TransactionStatusFailed
+	refund.FailureReason = reason
+	return nil
+}
+
+// flagForManualRefund records why a failed refund could not be reversed on the
+// refund and its purchase, leaving both for an admin to settle
+func (s *RefundService) flagForManualRefund(refund *models.Transaction, reason string) error {
+	log.Printf("Refund %s needs manual handling: %s", refund.ID, reason)
+	refund.FailureReason = reason
+
+	ids := []uuid.UUID{refund.ID}
+	if refund.ParentTransactionID != nil {
+		ids = append(ids, *refund.ParentTransactionID)
+	}
+	return s.db.Model(&models.Transaction{}).Where("id IN ?", ids).Update("failure_reason", reason).Error
+}
+
+// applyRefundTx moves stock, seats and organizer earnings for the refunded tickets.
+// direction is -1 when a refund is taken and 1 when it is reversed. A reversal fails
+// with ErrInsufficientStock when the stock was sold again meanwhile.
+func (s *RefundService) applyRefundTx(tx *gorm.DB, refund *models.Transaction, tickets []models.Ticket, direction int) error {
+	perType := make(map[uuid.UUID]int)
+	for _, ticket := range tickets {
+		perType[ticket.TicketTypeID]++
+	}
+
+	ticketTypeIDs := make([]uuid.UUID, 0, len(perType))
+	for ticketTypeID, count := range perType {
+		ticketTypeIDs = append(ticketTypeIDs, ticketTypeID)
+
+		// Stock freed by the refund may have been sold since, so it is only taken back if still free
+		query := tx.Model(&models.TicketType{}).Where("id = ?", ticketTypeID)
+		if direction > 0 {
+			query = query.Where("sold + reserved + ? <= quantity", count)
+		}
+		result := query.Update("sold", gorm.Expr("sold + ?", direction*count))
+		if result.Error != nil {
+			return fmt.Errorf("failed to update ticket stock: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("%w for ticket type %s", ErrInsufficientStock, ticketTypeID)
+		}
+	}
+	if direction > 0 {
+		if err := checkSessionCapacityTx(tx, ticketTypeIDs); err != nil {
+			return err
+		}
+	}
+
+	moveSeats := releaseTicketSeatsTx
+	if direction > 0 {
+		moveSeats = reclaimTicketSeatsTx
+	}
+	if err := moveSeats(tx, tickets); err != nil {
+		return err
+	}
+
+	if refund.EventID == nil {
+		return nil
+	}
+
+	var event models.Event
+	if err := tx.First(&event, "id = ?", *refund.EventID).Error; err != nil {
+		return fmt.Errorf("failed to load event: %w", err)
+	}
+
+	return s.ledgerService.RecordRefundTx(tx, refund, event.OrganizerID, direction > 0)
+}
+
+// syncPurchaseStatusTx sets a purchase to completed, partially_refunded or refunded
+// depending on how many of its tickets are currently refunded
+func syncPurchaseStatusTx(tx *gorm.DB, purchaseID uuid.UUID) error {
+	var total, refunded int64
+	if err := tx.Model(&models.Ticket{}).Where("transaction_id = ?", purchaseID).Count(&total).Error; err != nil {
+		return fmt.Errorf("failed to count tickets: %w", err)
+	}
+	if err := tx.Model(&models.Ticket{}).Where("transaction_id = ? AND refund_transaction_id IS NOT NULL", purchaseID).Count(&refunded).Error; err != nil {
+		return fmt.Errorf("failed to count refunded tickets: %w", err)
+	}
+
+	status := models.TransactionStatusCompleted
+	switch {
+	case refunded > 0 && refunded == total:
+		status = models.TransactionStatusRefunded
+	case refunded > 0:
+		status = models.TransactionStatusPartiallyRefunded
+	}
+
+	return tx.Model(&models.Transaction{}).
+		Where("id = ? AND status IN ?", purchaseID, []models.TransactionStatus{
+			models.TransactionStatusCompleted,
+			models.TransactionStatusPartiallyRefunded,
+			models.TransactionStatusRefunded,
+		}).
+		Update("status", status).Error
+}
+
+func ticketIDs(tickets []models.Ticket) []uuid.UUID {
+	ids := make([]uuid.UUID, len(tickets))
+	for i, ticket := range tickets {
+		ids[i] = ticket.ID
+	}
+	return ids
+}
@@ -0,0 +1,275 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/warui/event-ticketing-api/internal/models"
+	"gorm.io/gorm"
+)
+
+func TestRefundReversedWhenGatewayFails(t *testing.T) {
+	db := setupInventoryDB(t)
+	orderService := newTestOrderService(t, db)
+	ticketType := createTestTicketType(t, db, 10)
+
+	var event models.Event
+	db.First(&event, "id = ?", ticketType.EventID)
+
+	purchase := &models.Transaction{
+		UserID:           event.OrganizerID,
+		EventID:          &event.ID,
+		Type:             models.TransactionTypeTicketPurchase,
+		Status:           models.TransactionStatusPending,
+		Amount:           200000,
+		Currency:         "NGN",
+		PlatformFee:      10000,
+		NetAmount:        190000,
+		PaymentReference: "TXN-RFD-" + ticketType.ID.String(),
+	}
+	if err := db.Create(purchase).Error; err != nil {
+		t.Fatalf("Failed to create transaction: %v", err)
+	}
+
+	tickets, err := orderService.FulfillPayment(purchase, &PaymentResult{
+		Successful: true,
+		Amount:     200000,
+		Currency:   "NGN",
+		Metadata: map[string]interface{}{
+			"event_id": event.ID.String(),
+			"items": []interface{}{
+				map[string]interface{}{"ticket_type_id": ticketType.ID.String(), "quantity": float64(2)},
+			},
+		},
+	})
+	if err != nil || len(tickets) != 2 {
+		t.Fatalf("Failed to fulfil purchase: %v", err)
+	}
+
+	// Paystack is not configured in tests, so the gateway call fails
+	refundService := NewRefundService(db, orderService.cfg, newTestGateways(t, orderService.cfg), orderService.ledgerService)
+	_, err = refundService.RefundTickets(RefundRequest{
+		TransactionID: purchase.ID,
+		TicketIDs:     ticketIDs(tickets[:1]),
+		Percentage:    100,
+	})
+	if err == nil {
+		t.Fatal("Expected refund to fail without a payment gateway")
+	}
+	if errors.Is(err, ErrRefundNotAllowed) {
+		t.Fatalf("Expected a gateway error, got %v", err)
+	}
+
+	var refund models.Transaction
+	db.First(&refund, "parent_transaction_id = ?", purchase.ID)
+	if refund.Status != models.TransactionStatusFailed {
+		t.Errorf("Expected refund status failed, got %s", refund.Status)
+	}
+
+	var ticket models.Ticket
+	db.First(&ticket, "id = ?", tickets[0].ID)
+	if ticket.Status != models.TicketStatusConfirmed || ticket.RefundTransactionID != nil {
+		t.Errorf("Expected ticket restored to confirmed, got %s", ticket.Status)
+	}
+
+	var stored models.TicketType
+	db.First(&stored, "id = ?", ticketType.ID)
+	if stored.Sold != 2 {
+		t.Errorf("Expected sold=2 after reversal, got %d", stored.Sold)
+	}
+
+	var restored models.Transaction
+	db.First(&restored, "id = ?", purchase.ID)
+	if restored.Status != models.TransactionStatusCompleted {
+		t.Errorf("Expected purchase back to completed, got %s", restored.Status)
+	}
+}
+
+func TestPaymentForCancelledEventIsRefunded(t *testing.T) {
+	db := setupInventoryDB(t)
+	orderService := newTestOrderService(t, db)
+	orderService.cfg.PaymentFakeEnabled = true
+	ticketType := createTestTicketType(t, db, 10)
+
+	var event models.Event
+	db.First(&event, "id = ?", ticketType.EventID)
+
+	purchase := &models.Transaction{
+		UserID:           event.OrganizerID,
+		EventID:          &event.ID,
+		Type:             models.TransactionTypeTicketPurchase,
+		Status:           models.TransactionStatusPending,
+		Amount:           200000,
+		Currency:         "NGN",
+		NetAmount:        190000,
+		PaymentGateway:   "fake",
+		PaymentReference: "TXN-UNF-" + ticketType.ID.String(),
+	}
+	if err := db.Create(purchase).Error; err != nil {
+		t.Fatalf("Failed to create transaction: %v", err)
+	}
+	payment := &PaymentResult{
+		Successful: true,
+		Amount:     200000,
+		Currency:   "NGN",
+		Metadata: map[string]interface{}{
+			"event_id": event.ID.String(),
+			"items": []interface{}{
+				map[string]interface{}{"ticket_type_id": ticketType.ID.String(), "quantity": float64(2)},
+			},
+		},
+	}
+
+	db.Model(&event).Update("status", models.EventStatusCancelled)
+	if _, err := orderService.FulfillPayment(purchase, payment); !errors.Is(err, ErrEventCancelled) {
+		t.Fatalf("Expected ErrEventCancelled, got %v", err)
+	}
+
+	var refund models.Transaction
+	if err := db.First(&refund, "parent_transaction_id = ? AND type = ?", purchase.ID, models.TransactionTypeRefund).Error; err != nil {
+		t.Fatalf("Expected a queued refund: %v", err)
+	}
+	if refund.Amount != 200000 || refund.Status != models.TransactionStatusPending {
+		t.Errorf("Expected a pending refund of 200000, got %d %s", refund.Amount, refund.Status)
+	}
+
+	// The payment never becomes tickets once refunded, even if the event comes back
+	db.Model(&event).Update("status", models.EventStatusPublished)
+	if _, err := orderService.FulfillPayment(purchase, payment); !errors.Is(err, ErrPaymentRefunded) {
+		t.Fatalf("Expected ErrPaymentRefunded, got %v", err)
+	}
+
+	refundService := NewRefundService(db, orderService.cfg, newTestGateways(t, orderService.cfg), orderService.ledgerService)
+	if _, err := refundService.ProcessUnfulfilledRefunds(100); err != nil {
+		t.Fatalf("Failed to send queued refunds: %v", err)
+	}
+	db.First(&refund, "id = ?", refund.ID)
+	if refund.GatewayReference == "" {
+		t.Errorf("Expected the refund to be sent to the gateway")
+	}
+}
+
+// fulfillFakePurchase buys tickets of the type through the fake gateway
+func fulfillFakePurchase(t *testing.T, db *gorm.DB, orderService *OrderService, ticketType *models.TicketType, quantity int) (*models.Transaction, []models.Ticket) {
+	var event models.Event
+	db.First(&event, "id = ?", ticketType.EventID)
+
+	amount := ticketType.Price * int64(quantity)
+	purchase := &models.Transaction{
+		UserID:           event.OrganizerID,
+		EventID:          &event.ID,
+		Type:             models.TransactionTypeTicketPurchase,
+		Status:           models.TransactionStatusPending,
+		Amount:           amount,
+		Currency:         "NGN",
+		NetAmount:        amount,
+		PaymentGateway:   "fake",
+		PaymentReference: "TXN-FAKE-" + uuid.New().String(),
+	}
+	if err := db.Create(purchase).Error; err != nil {
+		t.Fatalf("Failed to create transaction: %v", err)
+	}
+
+	tickets, err := orderService.FulfillPayment(purchase, &PaymentResult{
+		Successful: true,
+		Amount:     amount,
+		Currency:   "NGN",
+		Metadata: map[string]interface{}{
+			"event_id": event.ID.String(),
+			"items": []interface{}{
+				map[string]interface{}{"ticket_type_id": ticketType.ID.String(), "quantity": float64(quantity)},
+			},
+		},
+	})
+	if err != nil || len(tickets) != quantity {
+		t.Fatalf("Failed to fulfil purchase: %v", err)
+	}
+	return purchase, tickets
+}
+
+func TestFailedRefundOfResoldTicketsNeedsManualHandling(t *testing.T) {
+	db := setupInventoryDB(t)
+	orderService := newTestOrderService(t, db)
+	orderService.cfg.PaymentFakeEnabled = true
+	ticketType := createTestTicketType(t, db, 2)
+	purchase, tickets := fulfillFakePurchase(t, db, orderService, ticketType, 2)
+
+	refundService := NewRefundService(db, orderService.cfg, newTestGateways(t, orderService.cfg), orderService.ledgerService)
+	refund, err := refundService.RefundTickets(RefundRequest{
+		TransactionID: purchase.ID,
+		TicketIDs:     ticketIDs(tickets[:1]),
+		Percentage:    100,
+	})
+	if err != nil {
+		t.Fatalf("Failed to refund ticket: %v", err)
+	}
+
+	// The freed ticket is sold to someone else before the gateway reports the refund failed
+	db.Model(&models.TicketType{}).Where("id = ?", ticketType.ID).Update("sold", 2)
+
+	if err := refundService.FailRefund("fake", refund.GatewayReference, "Refund failed at fake"); err != nil {
+		t.Fatalf("FailRefund failed: %v", err)
+	}
+
+	var stored models.TicketType
+	db.First(&stored, "id = ?", ticketType.ID)
+	if stored.Sold != 2 {
+		t.Errorf("Expected stock not to be oversold, got sold=%d", stored.Sold)
+	}
+
+	var flagged models.Transaction
+	db.First(&flagged, "id = ?", refund.ID)
+	if flagged.Status != models.TransactionStatusPending || flagged.FailureReason == "" {
+		t.Errorf("Expected refund left pending for manual handling, got %s %q", flagged.Status, flagged.FailureReason)
+	}
+
+	var ticket models.Ticket
+	db.First(&ticket, "id = ?", tickets[0].ID)
+	if ticket.Status != models.TicketStatusRefunded {
+		t.Errorf("Expected ticket to stay refunded, got %s", ticket.Status)
+	}
+}
+
+func TestFailedRefundForCancelledEventKeepsTicketsCancelled(t *testing.T) {
+	db := setupInventoryDB(t)
+	orderService := newTestOrderService(t, db)
+	orderService.cfg.PaymentFakeEnabled = true
+	ticketType := createTestTicketType(t, db, 10)
+	purchase, tickets := fulfillFakePurchase(t, db, orderService, ticketType, 2)
+
+	refundService := NewRefundService(db, orderService.cfg, newTestGateways(t, orderService.cfg), orderService.ledgerService)
+	refund, err := refundService.RefundTickets(RefundRequest{
+		TransactionID: purchase.ID,
+		Percentage:    100,
+		Reason:        "Event cancelled",
+		CancelTickets: true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to refund purchase: %v", err)
+	}
+	db.Model(&models.Event{}).Where("id = ?", ticketType.EventID).Update("status", models.EventStatusCancelled)
+
+	if err := refundService.FailRefund("fake", refund.GatewayReference, "Refund failed at fake"); err != nil {
+		t.Fatalf("FailRefund failed: %v", err)
+	}
+
+	var cancelled int64
+	db.Model(&models.Ticket{}).Where("id IN ? AND status = ?", ticketIDs(tickets), models.TicketStatusCancelled).Count(&cancelled)
+	if cancelled != 2 {
+		t.Errorf("Expected both tickets to stay cancelled, %d are", cancelled)
+	}
+
+	var stored models.TicketType
+	db.First(&stored, "id = ?", ticketType.ID)
+	if stored.Sold != 0 {
+		t.Errorf("Expected stock not to be taken back, got sold=%d", stored.Sold)
+	}
+
+	var flaggedRefund, flaggedPurchase models.Transaction
+	db.First(&flaggedRefund, "id = ?", refund.ID)
+	db.First(&flaggedPurchase, "id = ?", purchase.ID)
+	if flaggedRefund.Status != models.TransactionStatusPending || flaggedPurchase.FailureReason == "" {
+		t.Errorf("Expected refund left pending and purchase flagged, got %s %q", flaggedRefund.Status, flaggedPurchase.FailureReason)
+	}
+}
@@ -0,0 +1,242 @@
+package services
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/warui/event-ticketing-api/internal/models"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrInvalidRegistration is returned when holder details or answers do not fit the event's questions
+	ErrInvalidRegistration = errors.New("invalid registration")
+	// ErrRegistrationClosed is returned when a ticket's holder details can no longer be changed
+	ErrRegistrationClosed = errors.New("registration is closed for this ticket")
+)
+
+// TicketRegistration names the holder of one ticket and carries their answers to
+// the event's registration questions
+type TicketRegistration struct {
+	Name    string               `json:"name"`
+	Email   string               `json:"email"`
+	Answers map[uuid.UUID]string `json:"answers"` // Keyed by question ID
+}
+
+// Validate checks the registration against the event's questions and tidies it in
+// place: answers are trimmed and select answers take the spelling of their option
+func (r *TicketRegistration) Validate(questions []models.RegistrationQuestion) error {
+	r.Name = strings.TrimSpace(r.Name)
+	r.Email = strings.ToLower(strings.TrimSpace(r.Email))
+	if r.Email != "" {
+		if _, err := mail.ParseAddress(r.Email); err != nil {
+			return fmt.Errorf("%w: %s is not a valid email", ErrInvalidRegistration, r.Email)
+		}
+	}
+
+	known := make(map[uuid.UUID]bool, len(questions))
+	for _, question := range questions {
+		known[question.ID] = true
+	}
+	for questionID := range r.Answers {
+		if !known[questionID] {
+			return fmt.Errorf("%w: question %s does not belong to the event", ErrInvalidRegistration, questionID)
+		}
+	}
+
+	for _, question := range questions {
+		answer := strings.TrimSpace(r.Answers[question.ID])
+		if err := question.ValidateAnswer(answer); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidRegistration, err)
+		}
+		if answer == "" {
+			delete(r.Answers, question.ID)
+			continue
+		}
+		for _, option := range question.Options {
+			if question.Type == models.QuestionTypeSelect && strings.EqualFold(option, answer) {
+				answer = option
+			}
+		}
+		r.Answers[question.ID] = answer
+	}
+
+	return nil
+}
+
+// AttendeeRow is one ticket holder in an event's attendee export
+type AttendeeRow struct {
+	TicketID     uuid.UUID            `json:"ticket_id"`
+	TicketNumber string               `json:"ticket_number"`
+	TicketType   string               `json:"ticket_type"`
+	SeatLabel    string               `json:"seat_label,omitempty"`
+	Status       models.TicketStatus  `json:"status"`
+	HolderName   string               `json:"holder_name"`
+	HolderEmail  string               `json:"holder_email"`
+	BuyerName    string               `json:"buyer_name"`
+	BuyerEmail   string               `json:"buyer_email"`
+	CheckedInAt  *time.Time           `json:"checked_in_at,omitempty"`
+	Answers      map[uuid.UUID]string `json:"answers"` // Keyed by question ID
+}
+
+// RegistrationService records who each ticket is for and their answers to the
+// event's registration questions
+type RegistrationService struct {
+	db           *gorm.DB
+	orderService *OrderService
+}
+
+func NewRegistrationService(db *gorm.DB, orderService *OrderService) *RegistrationService {
+	return &RegistrationService{
+		db:           db,
+		orderService: orderService,
+	}
+}
+
+// RegisterTicket names the holder of one of the attendee's tickets and saves their
+// answers. Details can be changed until the event starts; the ticket PDF is produced
+// again so it shows them.
+func (s *RegistrationService) RegisterTicket(ticketID, attendeeID uuid.UUID, registration TicketRegistration) (*models.Ticket, error) {
+	var ticket models.Ticket
+	if err := s.db.Preload("Event").First(&ticket, "id = ? AND attendee_id = ?", ticketID, attendeeID).Error; err != nil {
+		return nil, fmt.Errorf("failed to load ticket: %w", err)
+	}
+	if ticket.Status != models.TicketStatusConfirmed || !time.Now().Before(ticket.Event.StartDate) {
+		return nil, ErrRegistrationClosed
+	}
+
+	var questions []models.RegistrationQuestion
+	if err := s.db.Where("event_id = ?", ticket.EventID).Find(&questions).Error; err != nil {
+		return nil, fmt.Errorf("failed to load registration questions: %w", err)
+	}
+	if err := registration.Validate(questions); err != nil {
+		return nil, err
+	}
+
+	var transaction models.Transaction
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&ticket).Updates(map[string]interface{}{
+			"holder_name":  registration.Name,
+			"holder_email": registration.Email,
+			"qr_code_url":  "",
+			"pdf_url":      "",
+			"qr_key_id":    "",
+		}).Error; err != nil {
+			return fmt.Errorf("failed to update ticket holder: %w", err)
+		}
+		if err := saveTicketAnswersTx(tx, ticket.ID, registration.Answers); err != nil {
+			return err
+		}
+
+		// Delivery picks the ticket up again; the retry job covers a failed attempt
+		if err := tx.Model(&models.Transaction{}).Where("id = ?", ticket.TransactionID).
+			Update("delivered_at", nil).Error; err != nil {
+			return fmt.Errorf("failed to queue ticket delivery: %w", err)
+		}
+		return tx.First(&transaction, "id = ?", ticket.TransactionID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.orderService.DeliverTickets(&transaction); err != nil {
+		log.Printf("Ticket delivery after registration of %s failed, will retry: %v", ticket.TicketNumber, err)
+	}
+
+	if err := s.db.Preload("TicketType").Preload("Answers.Question").First(&ticket, "id = ?", ticket.ID).Error; err != nil {
+		return nil, fmt.Errorf("failed to load ticket: %w", err)
+	}
+	return &ticket, nil
+}
+
+// Attendees lists the holders of an event's valid tickets with their answers, and
+// the event's questions in display order
+func (s *RegistrationService) Attendees(eventID uuid.UUID) ([]AttendeeRow, []models.RegistrationQuestion, error) {
+	var questions []models.RegistrationQuestion
+	if err := s.db.Where("event_id = ?", eventID).Order("position ASC, created_at ASC").Find(&questions).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to load registration questions: %w", err)
+	}
+
+	var tickets []models.Ticket
+	if err := s.db.Preload("TicketType").Preload("Attendee").Preload("Answers").
+		Where("event_id = ? AND status IN ?", eventID, []models.TicketStatus{models.TicketStatusConfirmed, models.TicketStatusUsed}).
+		Order("created_at ASC").Find(&tickets).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to load tickets: %w", err)
+	}
+
+	rows := make([]AttendeeRow, len(tickets))
+	for i, ticket := range tickets {
+		holderName, holderEmail := ticket.Holder(&ticket.Attendee)
+		answers := make(map[uuid.UUID]string, len(ticket.Answers))
+		for _, answer := range ticket.Answers {
+			answers[answer.QuestionID] = answer.Answer
+		}
+
+		rows[i] = AttendeeRow{
+			TicketID:     ticket.ID,
+			TicketNumber: ticket.TicketNumber,
+			TicketType:   ticket.TicketType.Name,
+			SeatLabel:    ticket.SeatLabel,
+			Status:       ticket.Status,
+			HolderName:   holderName,
+			HolderEmail:  holderEmail,
+			BuyerName:    strings.TrimSpace(ticket.Attendee.FirstName + " " + ticket.Attendee.LastName),
+			BuyerEmail:   ticket.Attendee.Email,
+			CheckedInAt:  ticket.CheckedInAt,
+			Answers:      answers,
+		}
+	}
+
+	return rows, questions, nil
+}
+
+// WriteAttendeesCSV writes an attendee export with a header row and one column per question
+func WriteAttendeesCSV(w io.Writer, questions []models.RegistrationQuestion, rows []AttendeeRow) error {
+	writer := csv.NewWriter(w)
+
+	header := []string{"ticket_number", "ticket_type", "seat", "status", "holder_name", "holder_email", "buyer_name", "buyer_email", "checked_in_at"}
+	for _, question := range questions {
+		header = append(header, question.Label)
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		checkedInAt := ""
+		if row.CheckedInAt != nil {
+			checkedInAt = row.CheckedInAt.Format(time.RFC3339)
+		}
+		record := []string{row.TicketNumber, row.TicketType, row.SeatLabel, string(row.Status), row.HolderName, row.HolderEmail, row.BuyerName, row.BuyerEmail, checkedInAt}
+		for _, question := range questions {
+			record = append(record, row.Answers[question.ID])
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// saveTicketAnswersTx replaces a ticket's answers inside the caller's database transaction
+func saveTicketAnswersTx(tx *gorm.DB, ticketID uuid.UUID, answers map[uuid.UUID]string) error {
+	if err := tx.Where("ticket_id = ?", ticketID).Delete(&models.TicketAnswer{}).Error; err != nil {
+		return fmt.Errorf("failed to clear answers: %w", err)
+	}
+
+	for questionID, answer := range answers {
+		if err := tx.Create(&models.TicketAnswer{TicketID: ticketID, QuestionID: questionID, Answer: answer}).Error; err != nil {
+			return fmt.Errorf("failed to save answer: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/warui/event-ticketing-api/internal/models"
+)
+
+func TestTicketRegistrationValidate(t *testing.T) {
+	diet := models.RegistrationQuestion{ID: uuid.New(), Label: "Dietary needs", Type: models.QuestionTypeText, IsRequired: true}
+	size := models.RegistrationQuestion{ID: uuid.New(), Label: "T-shirt size", Type: models.QuestionTypeSelect, Options: []string{"S", "M", "L"}}
+	questions := []models.RegistrationQuestion{diet, size}
+
+	registration := TicketRegistration{
+		Name:    "  Ada Lovelace ",
+		Email:   "Ada@Example.com",
+		Answers: map[uuid.UUID]string{diet.ID: " Vegan ", size.ID: "m"},
+	}
+	if err := registration.Validate(questions); err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	if registration.Name != "Ada Lovelace" || registration.Email != "ada@example.com" {
+		t.Errorf("Expected holder details to be tidied, got %q <%s>", registration.Name, registration.Email)
+	}
+	if registration.Answers[diet.ID] != "Vegan" || registration.Answers[size.ID] != "M" {
+		t.Errorf("Expected answers to be tidied, got %v", registration.Answers)
+	}
+
+	tests := []struct {
+		name         string
+		registration TicketRegistration
+	}{
+		{"Invalid email", TicketRegistration{Email: "not-an-email", Answers: map[uuid.UUID]string{diet.ID: "None"}}},
+		{"Required question unanswered", TicketRegistration{Answers: map[uuid.UUID]string{size.ID: "S"}}},
+		{"Unknown option", TicketRegistration{Answers: map[uuid.UUID]string{diet.ID: "None", size.ID: "XL"}}},
+		{"Question of another event", TicketRegistration{Answers: map[uuid.UUID]string{diet.ID: "None", uuid.New(): "?"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.registration.Validate(questions); !errors.Is(err, ErrInvalidRegistration) {
+				t.Errorf("Expected ErrInvalidRegistration, got %v", err)
+			}
+		})
+	}
+}
+
+func TestWriteAttendeesCSV(t *testing.T) {
+	diet := models.RegistrationQuestion{ID: uuid.New(), Label: "Dietary needs"}
+	size := models.RegistrationQuestion{ID: uuid.New(), Label: "T-shirt size"}
+
+	rows := []AttendeeRow{
+		{TicketNumber: "TKT-1", TicketType: "VIP", Status: models.TicketStatusConfirmed, HolderName: "Ada Lovelace", HolderEmail: "ada@example.com",
+			BuyerName: "Charles Babbage", BuyerEmail: "charles@example.com", Answers: map[uuid.UUID]string{size.ID: "M"}},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteAttendeesCSV(&buf, []models.RegistrationQuestion{diet, size}, rows); err != nil {
+		t.Fatalf("WriteAttendeesCSV failed: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected a header and one row, got %d lines", len(lines))
+	}
+	if !strings.HasSuffix(lines[0], ",Dietary needs,T-shirt size") {
+		t.Errorf("Expected a column per question, got %q", lines[0])
+	}
+	if lines[1] != "TKT-1,VIP,,confirmed,Ada Lovelace,ada@example.com,Charles Babbage,charles@example.com,,,M" {
+		t.Errorf("Unexpected row %q", lines[1])
+	}
+}
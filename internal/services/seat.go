@@ -0,0 +1,220 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/warui/event-ticketing-api/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrInvalidSeatMap is returned when a seat map cannot be laid out as given
+	ErrInvalidSeatMap = errors.New("invalid seat map")
+	// ErrSeatMapLocked is returned when seats or their ticket types have already been sold
+	ErrSeatMapLocked = errors.New("seat map can no longer be changed")
+)
+
+// MaxSeatMapSeats is the most seats one event's seat map may have
+const MaxSeatMapSeats = 100000
+
+// SeatMapRow is a row of seats numbered from 1. A row may use a different
+// ticket type from the rest of its section, for example a premium front row.
+type SeatMapRow struct {
+	Name         string     `json:"name" binding:"required"`
+	Seats        int        `json:"seats" binding:"required,min=1"`
+	TicketTypeID *uuid.UUID `json:"ticket_type_id"`
+}
+
+// SeatMapSection is a named block of rows, such as "Stalls" or "Balcony"
+type SeatMapSection struct {
+	Name         string       `json:"name" binding:"required"`
+	TicketTypeID *uuid.UUID   `json:"ticket_type_id"`
+	Rows         []SeatMapRow `json:"rows" binding:"required,min=1,dive"`
+}
+
+// SeatService lays out the seat maps of reserved seating events
+type SeatService struct {
+	db *gorm.DB
+}
+
+func NewSeatService(db *gorm.DB) *SeatService {
+	return &SeatService{db: db}
+}
+
+// ReplaceSeatMap replaces an event's seats. Ticket types with seats on the map
+// become reserved seating and their quantity is set to their number of seats;
+// the others go back to general admission. An empty map removes reserved seating.
+// The map cannot change once a seat has been picked or one of its ticket types sold.
+func (s *SeatService) ReplaceSeatMap(eventID uuid.UUID, sections []SeatMapSection) ([]models.Seat, error) {
+	var seats []models.Seat
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var event models.Event
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("TicketTypes").First(&event, "id = ?", eventID).Error; err != nil {
+			return err
+		}
+		if event.Status == models.EventStatusCancelled || event.Status == models.EventStatusCompleted {
+			return fmt.Errorf("%w: event is %s", ErrSeatMapLocked, event.Status)
+		}
+
+		var taken int64
+		if err := tx.Model(&models.Seat{}).Where("event_id = ? AND status <> ?", event.ID, models.SeatStatusAvailable).
+			Count(&taken).Error; err != nil {
+			return fmt.Errorf("failed to check seats: %w", err)
+		}
+		if taken > 0 {
+			return fmt.Errorf("%w: seats have already been picked or sold", ErrSeatMapLocked)
+		}
+
+		var err error
+		seats, err = layoutSeats(event.ID, sections)
+		if err != nil {
+			return err
+		}
+
+		ticketTypes := make(map[uuid.UUID]*models.TicketType)
+		for i := range event.TicketTypes {
+			ticketTypes[event.TicketTypes[i].ID] = &event.TicketTypes[i]
+		}
+		perType := make(map[uuid.UUID]int)
+		for _, seat := range seats {
+			if _, ok := ticketTypes[seat.TicketTypeID]; !ok {
+				return fmt.Errorf("%w: ticket type %s does not belong to the event", ErrInvalidSeatMap, seat.TicketTypeID)
+			}
+			perType[seat.TicketTypeID]++
+		}
+
+		for _, ticketType := range ticketTypes {
+			_, seated := perType[ticketType.ID]
+			if (seated || ticketType.ReservedSeating) && ticketType.Sold+ticketType.Reserved > 0 {
+				return fmt.Errorf("%w: %s already has sales", ErrSeatMapLocked, ticketType.Name)
+			}
+		}
+
+		if err := tx.Model(&models.TicketType{}).Where("event_id = ?", event.ID).
+			Update("reserved_seating", false).Error; err != nil {
+			return fmt.Errorf("failed to update ticket types: %w", err)
+		}
+		for ticketTypeID, count := range perType {
+			if err := tx.Model(&models.TicketType{}).Where("id = ?", ticketTypeID).Updates(map[string]interface{}{
+				"reserved_seating": true,
+				"quantity":         count,
+			}).Error; err != nil {
+				return fmt.Errorf("failed to update ticket types: %w", err)
+			}
+		}
+
+		if err := tx.Where("event_id = ?", event.ID).Delete(&models.Seat{}).Error; err != nil {
+			return fmt.Errorf("failed to remove old seats: %w", err)
+		}
+		if len(seats) == 0 {
+			return nil
+		}
+		if err := tx.CreateInBatches(seats, 500).Error; err != nil {
+			return fmt.Errorf("failed to create seats: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return seats, nil
+}
+
+// layoutSeats turns a seat map into seats in map order
+func layoutSeats(eventID uuid.UUID, sections []SeatMapSection) ([]models.Seat, error) {
+	var seats []models.Seat
+	sectionNames := make(map[string]bool)
+	for _, section := range sections {
+		sectionName := strings.TrimSpace(section.Name)
+		if sectionName == "" || sectionNames[strings.ToLower(sectionName)] {
+			return nil, fmt.Errorf("%w: section names must be unique and not empty", ErrInvalidSeatMap)
+		}
+		sectionNames[strings.ToLower(sectionName)] = true
+
+		rowNames := make(map[string]bool)
+		for _, row := range section.Rows {
+			rowName := strings.TrimSpace(row.Name)
+			if rowName == "" || rowNames[strings.ToLower(rowName)] {
+				return nil, fmt.Errorf("%w: row names in section %s must be unique and not empty", ErrInvalidSeatMap, sectionName)
+			}
+			rowNames[strings.ToLower(rowName)] = true
+
+			ticketTypeID := section.TicketTypeID
+			if row.TicketTypeID != nil {
+				ticketTypeID = row.TicketTypeID
+			}
+			if ticketTypeID == nil {
+				return nil, fmt.Errorf("%w: row %s of section %s has no ticket type", ErrInvalidSeatMap, rowName, sectionName)
+			}
+			if len(seats)+row.Seats > MaxSeatMapSeats {
+				return nil, fmt.Errorf("%w: a seat map may have up to %d seats", ErrInvalidSeatMap, MaxSeatMapSeats)
+			}
+
+			for number := 1; number <= row.Seats; number++ {
+				seats = append(seats, models.Seat{
+					EventID:      eventID,
+					TicketTypeID: *ticketTypeID,
+					Section:      sectionName,
+					Row:          rowName,
+					Number:       strconv.Itoa(number),
+					Position:     len(seats) + 1,
+					Status:       models.SeatStatusAvailable,
+				})
+			}
+		}
+	}
+
+	return seats, nil
+}
+
+// releaseTicketSeatsTx puts the seats of refunded tickets back on sale. The tickets
+// keep their seat label so the refund history still shows where they sat.
+func releaseTicketSeatsTx(tx *gorm.DB, tickets []models.Ticket) error {
+	if err := tx.Model(&models.Seat{}).Where("ticket_id IN ?", ticketIDs(tickets)).Updates(map[string]interface{}{
+		"status":         models.SeatStatusAvailable,
+		"transaction_id": nil,
+		"ticket_id":      nil,
+	}).Error; err != nil {
+		return fmt.Errorf("failed to release seats: %w", err)
+	}
+	return nil
+}
+
+// reclaimTicketSeatsTx gives tickets restored by a failed refund their seats back.
+// A seat sold to someone else in the meantime is not taken from them: the restored
+// ticket loses its seat and the organizer has to seat its holder.
+func reclaimTicketSeatsTx(tx *gorm.DB, tickets []models.Ticket) error {
+	for _, ticket := range tickets {
+		if ticket.SeatID == nil {
+			continue
+		}
+
+		result := tx.Model(&models.Seat{}).
+			Where("id = ? AND status = ?", *ticket.SeatID, models.SeatStatusAvailable).
+			Updates(map[string]interface{}{
+				"status":         models.SeatStatusSold,
+				"transaction_id": ticket.TransactionID,
+				"ticket_id":      ticket.ID,
+			})
+		if result.Error != nil {
+			return fmt.Errorf("failed to reclaim seat: %w", result.Error)
+		}
+		if result.RowsAffected == 1 {
+			continue
+		}
+
+		log.Printf("Seat %s of ticket %s was resold while its refund was pending; ticket needs a new seat", ticket.SeatLabel, ticket.TicketNumber)
+		if err := tx.Model(&models.Ticket{}).Where("id = ?", ticket.ID).
+			Updates(map[string]interface{}{"seat_id": nil, "seat_label": ""}).Error; err != nil {
+			return fmt.Errorf("failed to clear lost seat: %w", err)
+		}
+	}
+	return nil
+}
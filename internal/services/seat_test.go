@@ -0,0 +1,172 @@
+package services
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/warui/event-ticketing-api/internal/models"
+	"gorm.io/gorm"
+)
+
+func TestLayoutSeats(t *testing.T) {
+	eventID := uuid.New()
+	stalls := uuid.New()
+	premium := uuid.New()
+
+	seats, err := layoutSeats(eventID, []SeatMapSection{
+		{Name: "Stalls", TicketTypeID: &stalls, Rows: []SeatMapRow{
+			{Name: "A", Seats: 2, TicketTypeID: &premium},
+			{Name: "B", Seats: 3},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("layoutSeats failed: %v", err)
+	}
+	if len(seats) != 5 {
+		t.Fatalf("Expected 5 seats, got %d", len(seats))
+	}
+	if seats[0].TicketTypeID != premium || seats[2].TicketTypeID != stalls {
+		t.Error("Expected the row ticket type to override the section ticket type")
+	}
+	if seats[4].Label() != "Section Stalls, Row B, Seat 3" || seats[4].Position != 5 {
+		t.Errorf("Unexpected last seat %q at position %d", seats[4].Label(), seats[4].Position)
+	}
+
+	tests := []struct {
+		name     string
+		sections []SeatMapSection
+	}{
+		{"Duplicate section", []SeatMapSection{
+			{Name: "Stalls", TicketTypeID: &stalls, Rows: []SeatMapRow{{Name: "A", Seats: 1}}},
+			{Name: "stalls", TicketTypeID: &stalls, Rows: []SeatMapRow{{Name: "B", Seats: 1}}},
+		}},
+		{"Duplicate row", []SeatMapSection{
+			{Name: "Stalls", TicketTypeID: &stalls, Rows: []SeatMapRow{{Name: "A", Seats: 1}, {Name: "A", Seats: 2}}},
+		}},
+		{"Row without ticket type", []SeatMapSection{
+			{Name: "Stalls", Rows: []SeatMapRow{{Name: "A", Seats: 1}}},
+		}},
+		{"Too many seats", []SeatMapSection{
+			{Name: "Stalls", TicketTypeID: &stalls, Rows: []SeatMapRow{{Name: "A", Seats: MaxSeatMapSeats + 1}}},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := layoutSeats(eventID, tt.sections); !errors.Is(err, ErrInvalidSeatMap) {
+				t.Errorf("Expected ErrInvalidSeatMap, got %v", err)
+			}
+		})
+	}
+}
+
+func TestSeatMapSetsReservedSeating(t *testing.T) {
+	db := setupInventoryDB(t)
+	service := NewSeatService(db)
+	ticketType := createTestTicketType(t, db, 100)
+
+	seats, err := service.ReplaceSeatMap(ticketType.EventID, []SeatMapSection{
+		{Name: "Stalls", TicketTypeID: &ticketType.ID, Rows: []SeatMapRow{{Name: "A", Seats: 4}, {Name: "B", Seats: 4}}},
+	})
+	if err != nil {
+		t.Fatalf("ReplaceSeatMap failed: %v", err)
+	}
+	if len(seats) != 8 {
+		t.Fatalf("Expected 8 seats, got %d", len(seats))
+	}
+
+	var stored models.TicketType
+	db.First(&stored, "id = ?", ticketType.ID)
+	if !stored.ReservedSeating || stored.Quantity != 8 {
+		t.Errorf("Expected reserved seating with quantity 8, got %v and %d", stored.ReservedSeating, stored.Quantity)
+	}
+
+	// Once a seat is held the map is fixed
+	err = db.Transaction(func(tx *gorm.DB) error {
+		return NewInventoryService(db).HoldSeatsTx(tx, uuid.New(), SeatRequest{TicketTypeID: ticketType.ID, SeatIDs: []uuid.UUID{seats[0].ID}})
+	})
+	if err != nil {
+		t.Fatalf("HoldSeatsTx failed: %v", err)
+	}
+	if _, err := service.ReplaceSeatMap(ticketType.EventID, nil); !errors.Is(err, ErrSeatMapLocked) {
+		t.Errorf("Expected ErrSeatMapLocked, got %v", err)
+	}
+}
+
+func TestSeatConcurrentHolds(t *testing.T) {
+	db := setupInventoryDB(t)
+	service := NewInventoryService(db)
+	ticketType := createTestTicketType(t, db, 10)
+
+	seats, err := NewSeatService(db).ReplaceSeatMap(ticketType.EventID, []SeatMapSection{
+		{Name: "Stalls", TicketTypeID: &ticketType.ID, Rows: []SeatMapRow{{Name: "A", Seats: 2}}},
+	})
+	if err != nil {
+		t.Fatalf("ReplaceSeatMap failed: %v", err)
+	}
+	wanted := SeatRequest{TicketTypeID: ticketType.ID, SeatIDs: []uuid.UUID{seats[0].ID}}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var held []uuid.UUID
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			transactionID := uuid.New()
+			err := db.Transaction(func(tx *gorm.DB) error {
+				if err := service.ReserveTx(tx, transactionID, []StockRequest{{TicketTypeID: ticketType.ID, Quantity: 1}}, time.Now().Add(time.Minute)); err != nil {
+					return err
+				}
+				return service.HoldSeatsTx(tx, transactionID, wanted)
+			})
+			if err == nil {
+				mu.Lock()
+				held = append(held, transactionID)
+				mu.Unlock()
+			} else if !errors.Is(err, ErrSeatUnavailable) {
+				t.Errorf("Unexpected hold error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(held) != 1 {
+		t.Fatalf("Expected exactly one checkout to hold the seat, got %d", len(held))
+	}
+
+	// Releasing the checkout puts the seat back on the map
+	if err := service.ReleaseHolds(held[0]); err != nil {
+		t.Fatalf("ReleaseHolds failed: %v", err)
+	}
+	var seat models.Seat
+	db.First(&seat, "id = ?", seats[0].ID)
+	if seat.Status != models.SeatStatusAvailable || seat.TransactionID != nil {
+		t.Errorf("Expected the seat to be available again, got %s", seat.Status)
+	}
+
+	// Without picked seats the first free seat is sold
+	transactionID := uuid.New()
+	var sold []models.Seat
+	err = db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		sold, err = service.SellSeatsTx(tx, transactionID, SeatRequest{TicketTypeID: ticketType.ID}, 2)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("SellSeatsTx failed: %v", err)
+	}
+	if len(sold) != 2 || sold[0].ID != seats[0].ID {
+		t.Errorf("Expected both seats sold in map order, got %d", len(sold))
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		_, err := service.SellSeatsTx(tx, uuid.New(), wanted, 1)
+		return err
+	})
+	if !errors.Is(err, ErrSeatUnavailable) || !errors.Is(err, ErrInsufficientStock) {
+		t.Errorf("Expected a sold seat to be unavailable, got %v", err)
+	}
+}
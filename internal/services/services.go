@@ -0,0 +1,90 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/warui/event-ticketing-api/internal/config"
+	"gorm.io/gorm"
+)
+
+// Services is the service graph shared by the HTTP routes and the background jobs.
+// It is built once so both see the same state, such as the fake payment provider's
+// in-memory payments.
+type Services struct {
+	Storage         *StorageService
+	Email           *EmailService
+	TwoFA           *TwoFAService
+	PaymentGateways *PaymentGateways
+	Image           *ImageService
+	TicketSigner    *TicketSigner
+	TicketDocuments *TicketDocumentService
+	Inventory       *InventoryService
+	Ledger          *LedgerService
+	Order           *OrderService
+	Refund          *RefundService
+	Payout          *PayoutService
+	PayoutAccount   *PayoutAccountService
+	Reconciliation  *ReconciliationService
+	PromoCode       *PromoCodeService
+	AccessCode      *AccessCodeService
+	Cancellation    *EventCancellationService
+	CompTicket      *CompTicketService
+	Transfer        *TicketTransferService
+	Waitlist        *WaitlistService
+	Seat            *SeatService
+	Registration    *RegistrationService
+	Session         *SessionService
+	Template        *EventTemplateService
+	TicketType      *TicketTypeService
+	Change          *EventChangeService
+	Lifecycle       *EventLifecycleService
+}
+
+func NewServices(db *gorm.DB, cfg *config.Config) (*Services, error) {
+	paymentGateways, err := NewPaymentGateways(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure payment providers: %w", err)
+	}
+	ticketSigner, err := NewTicketSigner(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load ticket signing keys: %w", err)
+	}
+
+	storageService, _ := NewStorageService(cfg)
+	emailService := NewEmailService(cfg)
+	ticketDocuments := NewTicketDocumentService(storageService, NewQRCodeService(), NewPDFService(), ticketSigner)
+	inventoryService := NewInventoryService(db)
+	ledgerService := NewLedgerService(db)
+	orderService := NewOrderService(db, cfg, inventoryService, ledgerService, ticketDocuments, emailService)
+	refundService := NewRefundService(db, cfg, paymentGateways, ledgerService)
+
+	return &Services{
+		Storage:         storageService,
+		Email:           emailService,
+		TwoFA:           NewTwoFAService(cfg),
+		PaymentGateways: paymentGateways,
+		Image:           NewImageService(),
+		TicketSigner:    ticketSigner,
+		TicketDocuments: ticketDocuments,
+		Inventory:       inventoryService,
+		Ledger:          ledgerService,
+		Order:           orderService,
+		Refund:          refundService,
+		Payout:          NewPayoutService(db, cfg, paymentGateways, ledgerService),
+		PayoutAccount:   NewPayoutAccountService(db, cfg, paymentGateways),
+		Reconciliation:  NewReconciliationService(db, cfg, paymentGateways, orderService),
+		PromoCode:       NewPromoCodeService(db),
+		AccessCode:      NewAccessCodeService(db),
+		Cancellation:    NewEventCancellationService(db, refundService, emailService),
+		CompTicket:      NewCompTicketService(db, cfg, orderService, emailService),
+		Transfer:        NewTicketTransferService(db, orderService, emailService),
+		Waitlist:        NewWaitlistService(db, cfg, emailService),
+		Seat:            NewSeatService(db),
+		Registration:    NewRegistrationService(db, orderService),
+		Session:         NewSessionService(db),
+		Template:        NewEventTemplateService(db),
+		TicketType:      NewTicketTypeService(db),
+		Change:          NewEventChangeService(db, ticketDocuments, emailService),
+		Lifecycle:       NewEventLifecycleService(db),
+	}, nil
+}
@@ -0,0 +1,358 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/warui/event-ticketing-api/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrInvalidSession is returned when sessions or a recurrence rule cannot be used as given
+	ErrInvalidSession = errors.New("invalid session")
+	// ErrSessionLocked is returned when a session already has tickets sold or people admitted
+	ErrSessionLocked = errors.New("session can no longer be changed")
+	// ErrSessionFull is returned when a session's capacity cannot cover an order. It
+	// wraps ErrInsufficientStock so callers that handle sold-out stock handle it too.
+	ErrSessionFull = fmt.Errorf("%w: session is full", ErrInsufficientStock)
+)
+
+// MaxEventSessions is the most sessions one event may have
+const MaxEventSessions = 366
+
+// RecurrenceFrequency is how often a recurring event repeats
+type RecurrenceFrequency string
+
+const (
+	RecurrenceDaily   RecurrenceFrequency = "daily"
+	RecurrenceWeekly  RecurrenceFrequency = "weekly"
+	RecurrenceMonthly RecurrenceFrequency = "monthly"
+)
+
+// SessionInput is a session as the organizer describes it
+type SessionInput struct {
+	Title     string    `json:"title"`
+	StartDate time.Time `json:"start_date" binding:"required"`
+	EndDate   time.Time `json:"end_date" binding:"required"`
+	Capacity  int       `json:"capacity" binding:"min=0"`
+}
+
+// RecurrenceRule repeats a first session every Interval days, weeks or months,
+// Count times or until Until, whichever is given
+type RecurrenceRule struct {
+	Frequency RecurrenceFrequency `json:"frequency" binding:"required,oneof=daily weekly monthly"`
+	Interval  int                 `json:"interval" binding:"min=0"` // Defaults to 1
+	Count     int                 `json:"count" binding:"min=0"`
+	Until     *time.Time          `json:"until"`
+	First     SessionInput        `json:"first" binding:"required"`
+}
+
+// Sessions expands the rule into its occurrences. Months are added to the first
+// session's date, so a series on the 31st falls on the last day of shorter months.
+func (r RecurrenceRule) Sessions() ([]SessionInput, error) {
+	interval := r.Interval
+	if interval == 0 {
+		interval = 1
+	}
+	if (r.Count == 0) == (r.Until == nil) {
+		return nil, fmt.Errorf("%w: give either count or until", ErrInvalidSession)
+	}
+
+	duration := r.First.EndDate.Sub(r.First.StartDate)
+	var sessions []SessionInput
+	for i := 0; r.Count == 0 || i < r.Count; i++ {
+		start := r.First.StartDate
+		switch r.Frequency {
+		case RecurrenceDaily:
+			start = start.AddDate(0, 0, i*interval)
+		case RecurrenceWeekly:
+			start = start.AddDate(0, 0, 7*i*interval)
+		case RecurrenceMonthly:
+			start = addMonthsClamped(start, i*interval)
+		default:
+			return nil, fmt.Errorf("%w: unknown frequency %q", ErrInvalidSession, r.Frequency)
+		}
+		if r.Until != nil && start.After(*r.Until) {
+			break
+		}
+		if len(sessions) == MaxEventSessions {
+			return nil, fmt.Errorf("%w: an event may have up to %d sessions", ErrInvalidSession, MaxEventSessions)
+		}
+
+		sessions = append(sessions, SessionInput{
+			Title:     r.First.Title,
+			StartDate: start,
+			EndDate:   start.Add(duration),
+			Capacity:  r.First.Capacity,
+		})
+	}
+
+	return sessions, nil
+}
+
+// addMonthsClamped adds months to t, keeping to the last day of the month
+// instead of overflowing into the next one
+func addMonthsClamped(t time.Time, months int) time.Time {
+	firstOfMonth := time.Date(t.Year(), t.Month(), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location()).AddDate(0, months, 0)
+	lastDay := firstOfMonth.AddDate(0, 1, -1).Day()
+	day := t.Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return firstOfMonth.AddDate(0, 0, day-1)
+}
+
+// SessionService manages the sessions of recurring and multi-session events
+type SessionService struct {
+	db *gorm.DB
+}
+
+func NewSessionService(db *gorm.DB) *SessionService {
+	return &SessionService{db: db}
+}
+
+// AddSessions adds sessions to an event and stretches the event's dates to cover
+// the whole series. Sessions can be added to a published series without another
+// review.
+func (s *SessionService) AddSessions(eventID uuid.UUID, inputs []SessionInput) ([]models.EventSession, error) {
+	if len(inputs) == 0 {
+		return nil, fmt.Errorf("%w: no sessions given", ErrInvalidSession)
+	}
+	for _, input := range inputs {
+		if err := validateSessionInput(input); err != nil {
+			return nil, err
+		}
+	}
+
+	sessions := make([]models.EventSession, len(inputs))
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		event, err := lockSeriesTx(tx, eventID)
+		if err != nil {
+			return err
+		}
+
+		var existing int64
+		if err := tx.Model(&models.EventSession{}).Where("event_id = ?", event.ID).Count(&existing).Error; err != nil {
+			return fmt.Errorf("failed to count sessions: %w", err)
+		}
+		if int(existing)+len(inputs) > MaxEventSessions {
+			return fmt.Errorf("%w: an event may have up to %d sessions", ErrInvalidSession, MaxEventSessions)
+		}
+
+		for i, input := range inputs {
+			sessions[i] = models.EventSession{
+				EventID:   event.ID,
+				Title:     input.Title,
+				StartDate: input.StartDate,
+				EndDate:   input.EndDate,
+				Capacity:  input.Capacity,
+			}
+		}
+		if err := tx.Create(&sessions).Error; err != nil {
+			return fmt.Errorf("failed to create sessions: %w", err)
+		}
+
+		return syncSeriesDatesTx(tx, event)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].StartDate.Before(sessions[j].StartDate) })
+	return sessions, nil
+}
+
+// UpdateSession changes a session's title, dates or capacity. Capacity cannot drop
+// below the tickets already sold or held for it.
+func (s *SessionService) UpdateSession(sessionID uuid.UUID, input SessionInput) (*models.EventSession, error) {
+	if err := validateSessionInput(input); err != nil {
+		return nil, err
+	}
+
+	var session models.EventSession
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&session, "id = ?", sessionID).Error; err != nil {
+			return err
+		}
+		event, err := lockSeriesTx(tx, session.EventID)
+		if err != nil {
+			return err
+		}
+
+		if input.Capacity > 0 {
+			headcount, err := sessionHeadcountTx(tx, &session)
+			if err != nil {
+				return err
+			}
+			if headcount > input.Capacity {
+				return fmt.Errorf("%w: %d tickets are already sold or held for the session", ErrInvalidSession, headcount)
+			}
+		}
+
+		session.Title = input.Title
+		session.StartDate = input.StartDate
+		session.EndDate = input.EndDate
+		session.Capacity = input.Capacity
+		if err := tx.Model(&session).Select("title", "start_date", "end_date", "capacity").Updates(&session).Error; err != nil {
+			return fmt.Errorf("failed to update session: %w", err)
+		}
+
+		return syncSeriesDatesTx(tx, event)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// DeleteSession removes a session and its ticket types. Sessions with tickets sold
+// or held, or where series passes were already admitted, are kept.
+func (s *SessionService) DeleteSession(sessionID uuid.UUID) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var session models.EventSession
+		if err := tx.First(&session, "id = ?", sessionID).Error; err != nil {
+			return err
+		}
+		event, err := lockSeriesTx(tx, session.EventID)
+		if err != nil {
+			return err
+		}
+
+		var sold int64
+		if err := tx.Model(&models.TicketType{}).Where("session_id = ? AND sold + reserved > 0", session.ID).
+			Count(&sold).Error; err != nil {
+			return fmt.Errorf("failed to check session sales: %w", err)
+		}
+		var admitted int64
+		if err := tx.Model(&models.SessionCheckIn{}).Where("session_id = ?", session.ID).Count(&admitted).Error; err != nil {
+			return fmt.Errorf("failed to check session check-ins: %w", err)
+		}
+		if sold > 0 || admitted > 0 {
+			return fmt.Errorf("%w: tickets have been sold or used for it", ErrSessionLocked)
+		}
+
+		if err := tx.Where("session_id = ?", session.ID).Delete(&models.TicketType{}).Error; err != nil {
+			return fmt.Errorf("failed to remove session ticket types: %w", err)
+		}
+		if err := tx.Delete(&session).Error; err != nil {
+			return fmt.Errorf("failed to remove session: %w", err)
+		}
+
+		return syncSeriesDatesTx(tx, event)
+	})
+}
+
+func validateSessionInput(input SessionInput) error {
+	if !input.EndDate.After(input.StartDate) {
+		return fmt.Errorf("%w: session end date must be after its start date", ErrInvalidSession)
+	}
+	if input.Capacity < 0 {
+		return fmt.Errorf("%w: capacity cannot be negative", ErrInvalidSession)
+	}
+	return nil
+}
+
+// lockSeriesTx locks an event whose sessions are about to change
+func lockSeriesTx(tx *gorm.DB, eventID uuid.UUID) (*models.Event, error) {
+	var event models.Event
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&event, "id = ?", eventID).Error; err != nil {
+		return nil, err
+	}
+	if event.Status == models.EventStatusCancelled || event.Status == models.EventStatusCompleted {
+		return nil, fmt.Errorf("%w: event is %s", ErrSessionLocked, event.Status)
+	}
+	return &event, nil
+}
+
+// syncSeriesDatesTx sets the event's dates to span its sessions, so listings,
+// refund and transfer deadlines follow the series
+func syncSeriesDatesTx(tx *gorm.DB, event *models.Event) error {
+	var span struct {
+		StartDate *time.Time
+		EndDate   *time.Time
+	}
+	if err := tx.Model(&models.EventSession{}).Select("MIN(start_date) AS start_date, MAX(end_date) AS end_date").
+		Where("event_id = ?", event.ID).Scan(&span).Error; err != nil {
+		return fmt.Errorf("failed to read series dates: %w", err)
+	}
+	if span.StartDate == nil || span.EndDate == nil {
+		return nil
+	}
+
+	if err := tx.Model(event).Updates(map[string]interface{}{
+		"start_date": *span.StartDate,
+		"end_date":   *span.EndDate,
+	}).Error; err != nil {
+		return fmt.Errorf("failed to update series dates: %w", err)
+	}
+	return nil
+}
+
+// sessionHeadcountTx counts the tickets sold or held that admit to a session:
+// its own ticket types plus the event's series passes
+func sessionHeadcountTx(tx *gorm.DB, session *models.EventSession) (int, error) {
+	var headcount int
+	if err := tx.Model(&models.TicketType{}).Select("COALESCE(SUM(sold + reserved), 0)").
+		Where("event_id = ? AND (session_id = ? OR session_id IS NULL)", session.EventID, session.ID).
+		Scan(&headcount).Error; err != nil {
+		return 0, fmt.Errorf("failed to count session tickets: %w", err)
+	}
+	return headcount, nil
+}
+
+// checkSessionCapacityTx fails with ErrSessionFull when stock just taken for the
+// ticket types pushed one of their sessions past its capacity. It runs after the
+// ticket type counters were updated, and locks the sessions so concurrent orders
+// for different ticket types of one session are counted one after the other.
+func checkSessionCapacityTx(tx *gorm.DB, ticketTypeIDs []uuid.UUID) error {
+	var ticketTypes []models.TicketType
+	if err := tx.Select("id", "event_id", "session_id").Where("id IN ?", ticketTypeIDs).Find(&ticketTypes).Error; err != nil {
+		return fmt.Errorf("failed to load ticket types: %w", err)
+	}
+
+	var sessionIDs []uuid.UUID
+	var passEventIDs []uuid.UUID
+	for _, ticketType := range ticketTypes {
+		if ticketType.SessionID != nil {
+			sessionIDs = append(sessionIDs, *ticketType.SessionID)
+		} else {
+			passEventIDs = append(passEventIDs, ticketType.EventID)
+		}
+	}
+	if len(sessionIDs) == 0 && len(passEventIDs) == 0 {
+		return nil
+	}
+
+	query := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("capacity > 0")
+	switch {
+	case len(passEventIDs) == 0:
+		query = query.Where("id IN ?", sessionIDs)
+	case len(sessionIDs) == 0:
+		query = query.Where("event_id IN ?", passEventIDs)
+	default:
+		query = query.Where("id IN ? OR event_id IN ?", sessionIDs, passEventIDs)
+	}
+
+	var sessions []models.EventSession
+	if err := query.Order("id").Find(&sessions).Error; err != nil {
+		return fmt.Errorf("failed to lock sessions: %w", err)
+	}
+
+	for i := range sessions {
+		headcount, err := sessionHeadcountTx(tx, &sessions[i])
+		if err != nil {
+			return err
+		}
+		if headcount > sessions[i].Capacity {
+			return fmt.Errorf("%w: session starting %s", ErrSessionFull, sessions[i].StartDate.Format(time.RFC3339))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/warui/event-ticketing-api/internal/models"
+	"gorm.io/gorm"
+)
+
+func TestRecurrenceRuleSessions(t *testing.T) {
+	first := SessionInput{
+		Title:     "Yoga",
+		StartDate: time.Date(2025, 1, 31, 18, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2025, 1, 31, 19, 30, 0, 0, time.UTC),
+		Capacity:  20,
+	}
+
+	weekly, err := RecurrenceRule{Frequency: RecurrenceWeekly, Count: 3, First: first}.Sessions()
+	if err != nil {
+		t.Fatalf("Sessions failed: %v", err)
+	}
+	if len(weekly) != 3 || !weekly[2].StartDate.Equal(first.StartDate.AddDate(0, 0, 14)) {
+		t.Fatalf("Expected three weekly sessions, got %+v", weekly)
+	}
+	if weekly[2].EndDate.Sub(weekly[2].StartDate) != 90*time.Minute || weekly[2].Capacity != 20 {
+		t.Error("Expected every session to keep the first session's length and capacity")
+	}
+
+	until := time.Date(2025, 4, 30, 0, 0, 0, 0, time.UTC)
+	monthly, err := RecurrenceRule{Frequency: RecurrenceMonthly, Until: &until, First: first}.Sessions()
+	if err != nil {
+		t.Fatalf("Sessions failed: %v", err)
+	}
+	if len(monthly) != 3 || monthly[1].StartDate.Day() != 28 || monthly[2].StartDate.Day() != 31 {
+		t.Errorf("Expected month ends without overflow, got %v, %v", monthly[1].StartDate, monthly[2].StartDate)
+	}
+
+	tests := []struct {
+		name string
+		rule RecurrenceRule
+	}{
+		{"Neither count nor until", RecurrenceRule{Frequency: RecurrenceDaily, First: first}},
+		{"Both count and until", RecurrenceRule{Frequency: RecurrenceDaily, Count: 2, Until: &until, First: first}},
+		{"Too many sessions", RecurrenceRule{Frequency: RecurrenceDaily, Count: MaxEventSessions + 1, First: first}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.rule.Sessions(); !errors.Is(err, ErrInvalidSession) {
+				t.Errorf("Expected ErrInvalidSession, got %v", err)
+			}
+		})
+	}
+}
+
+func TestSessionCapacityCountsSeriesPasses(t *testing.T) {
+	db := setupInventoryDB(t)
+	inventory := NewInventoryService(db)
+	pass := createTestTicketType(t, db, 10)
+	now := time.Now().Truncate(time.Second)
+
+	sessions, err := NewSessionService(db).AddSessions(pass.EventID, []SessionInput{
+		{Title: "Day 1", StartDate: now.Add(24 * time.Hour), EndDate: now.Add(30 * time.Hour), Capacity: 3},
+		{Title: "Day 2", StartDate: now.Add(48 * time.Hour), EndDate: now.Add(54 * time.Hour)},
+	})
+	if err != nil {
+		t.Fatalf("AddSessions failed: %v", err)
+	}
+
+	dayOne := &models.TicketType{
+		EventID:     pass.EventID,
+		SessionID:   &sessions[0].ID,
+		Name:        "Day 1",
+		Price:       50000,
+		Quantity:    10,
+		MaxPerOrder: 10,
+		SaleStart:   time.Now().Add(-time.Hour),
+		SaleEnd:     time.Now().Add(time.Hour),
+		IsActive:    true,
+	}
+	if err := db.Create(dayOne).Error; err != nil {
+		t.Fatalf("Failed to create ticket type: %v", err)
+	}
+
+	reserve := func(ticketTypeID uuid.UUID, quantity int) error {
+		return db.Transaction(func(tx *gorm.DB) error {
+			return inventory.ReserveTx(tx, uuid.New(), []StockRequest{{TicketTypeID: ticketTypeID, Quantity: quantity}}, time.Now().Add(time.Minute))
+		})
+	}
+
+	// Two passes and one day ticket fill day 1
+	if err := reserve(pass.ID, 2); err != nil {
+		t.Fatalf("Reserving passes failed: %v", err)
+	}
+	if err := reserve(dayOne.ID, 1); err != nil {
+		t.Fatalf("Reserving a day ticket failed: %v", err)
+	}
+	if err := reserve(dayOne.ID, 1); !errors.Is(err, ErrSessionFull) || !errors.Is(err, ErrInsufficientStock) {
+		t.Errorf("Expected day 1 to be full, got %v", err)
+	}
+	if err := reserve(pass.ID, 1); !errors.Is(err, ErrSessionFull) {
+		t.Errorf("Expected a pass to need a place on day 1, got %v", err)
+	}
+
+	var event models.Event
+	db.First(&event, "id = ?", pass.EventID)
+	if !event.StartDate.Equal(sessions[0].StartDate) || !event.EndDate.Equal(sessions[1].EndDate) {
+		t.Errorf("Expected the event to span its sessions, got %v to %v", event.StartDate, event.EndDate)
+	}
+
+	if err := NewSessionService(db).DeleteSession(sessions[0].ID); !errors.Is(err, ErrSessionLocked) {
+		t.Errorf("Expected a session with sales to be kept, got %v", err)
+	}
+}
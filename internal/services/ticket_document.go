@@ -0,0 +1,67 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/warui/event-ticketing-api/internal/models"
+)
+
+// TicketDocumentService produces the signed QR code and PDF for a ticket
+type TicketDocumentService struct {
+	storageService *StorageService
+	qrcodeService  *QRCodeService
+	pdfService     *PDFService
+	signer         *TicketSigner
+}
+
+func NewTicketDocumentService(storageService *StorageService, qrcodeService *QRCodeService, pdfService *PDFService, signer *TicketSigner) *TicketDocumentService {
+	return &TicketDocumentService{
+		storageService: storageService,
+		qrcodeService:  qrcodeService,
+		pdfService:     pdfService,
+		signer:         signer,
+	}
+}
+
+// GenerateTicketDocuments signs the ticket, uploads its QR code and PDF and sets
+// QRCodeURL, PDFURL and QRKeyID on the ticket. The caller is responsible for saving
+// the ticket. The PDF bytes are returned so they can be attached to emails.
+func (s *TicketDocumentService) GenerateTicketDocuments(ticket *models.Ticket, event *models.Event, attendee *models.User) ([]byte, error) {
+	payload, err := s.signer.SignTicket(ticket)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign ticket: %w", err)
+	}
+
+	qrData, err := s.qrcodeService.GenerateTicketQRCode(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate QR code: %w", err)
+	}
+
+	qrFilename := GenerateUniqueFilename(fmt.Sprintf("qr-%s", ticket.TicketNumber), "png")
+	qrURL, err := s.storageService.UploadFile(qrData, "tickets/qrcodes", qrFilename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload QR code: %w", err)
+	}
+
+	pdfData, err := s.pdfService.GenerateTicketPDF(ticket, event, attendee, qrData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate PDF: %w", err)
+	}
+
+	pdfFilename := GenerateUniqueFilename(fmt.Sprintf("ticket-%s", ticket.TicketNumber), "pdf")
+	pdfURL, err := s.storageService.UploadFile(pdfData, "tickets/pdfs", pdfFilename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload PDF: %w", err)
+	}
+
+	ticket.QRCodeURL = qrURL
+	ticket.PDFURL = pdfURL
+	ticket.QRKeyID = s.signer.ActiveKeyID()
+
+	return pdfData, nil
+}
+
+// ActiveKeyID returns the signing key ID new ticket documents are signed with
+func (s *TicketDocumentService) ActiveKeyID() string {
+	return s.signer.ActiveKeyID()
+}
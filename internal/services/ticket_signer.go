@@ -0,0 +1,184 @@
+package services
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/warui/event-ticketing-api/internal/config"
+	"github.com/warui/event-ticketing-api/internal/models"
+)
+
+// defaultTicketKeyID is used when no signing keys are configured
+const defaultTicketKeyID = "default"
+
+// TicketSigner signs ticket QR payloads with Ed25519 so scanners can verify
+// them offline using only the published public keys
+type TicketSigner struct {
+	activeKeyID string
+	keys        map[string]ed25519.PrivateKey
+}
+
+// TicketClaims holds the verified contents of a signed ticket QR payload
+type TicketClaims struct {
+	TicketNumber string
+	TicketID     string
+	EventID      string
+	KeyID        string
+}
+
+// TicketSigningKey describes a public key scanners can use to verify tickets
+type TicketSigningKey struct {
+	KeyID     string `json:"key_id"`
+	Algorithm string `json:"algorithm"`
+	PublicKey string `json:"public_key"`
+	Active    bool   `json:"active"`
+}
+
+// NewTicketSigner loads signing keys from TICKET_SIGNING_KEYS ("kid:base64seed,...").
+// Without configured keys a key is derived from the JWT secret so development
+// setups keep working across restarts; production refuses to start without keys.
+func NewTicketSigner(cfg *config.Config) (*TicketSigner, error) {
+	signer := &TicketSigner{
+		activeKeyID: cfg.TicketSigningKeyID,
+		keys:        make(map[string]ed25519.PrivateKey),
+	}
+
+	if strings.TrimSpace(cfg.TicketSigningKeys) == "" {
+		if cfg.Environment == "production" {
+			return nil, fmt.Errorf("TICKET_SIGNING_KEYS must be set in production")
+		}
+		log.Println("Warning: TICKET_SIGNING_KEYS not set, deriving ticket signing key from JWT secret")
+		seed := sha256.Sum256([]byte("ticket-signing:" + cfg.JWTSecret))
+		signer.keys[defaultTicketKeyID] = ed25519.NewKeyFromSeed(seed[:])
+		signer.activeKeyID = defaultTicketKeyID
+		return signer, nil
+	}
+
+	for _, entry := range strings.Split(cfg.TicketSigningKeys, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		keyID, encodedSeed, ok := strings.Cut(entry, ":")
+		if !ok || keyID == "" {
+			return nil, fmt.Errorf("invalid ticket signing key entry %q", entry)
+		}
+
+		seed, err := base64.StdEncoding.DecodeString(encodedSeed)
+		if err != nil {
+			return nil, fmt.Errorf("invalid seed for ticket signing key %s: %w", keyID, err)
+		}
+		if len(seed) != ed25519.SeedSize {
+			return nil, fmt.Errorf("ticket signing key %s must be a %d byte seed", keyID, ed25519.SeedSize)
+		}
+
+		signer.keys[keyID] = ed25519.NewKeyFromSeed(seed)
+	}
+
+	if signer.activeKeyID == "" {
+		return nil, fmt.Errorf("TICKET_SIGNING_KEY_ID must name the active signing key")
+	}
+	if _, ok := signer.keys[signer.activeKeyID]; !ok {
+		return nil, fmt.Errorf("active ticket signing key %s is not configured", signer.activeKeyID)
+	}
+
+	return signer, nil
+}
+
+// ActiveKeyID returns the ID of the key used to sign new tickets
+func (s *TicketSigner) ActiveKeyID() string {
+	return s.activeKeyID
+}
+
+// SignTicket builds the signed QR payload for a ticket
+func (s *TicketSigner) SignTicket(ticket *models.Ticket) (string, error) {
+	key, ok := s.keys[s.activeKeyID]
+	if !ok {
+		return "", fmt.Errorf("active ticket signing key not configured")
+	}
+
+	message := fmt.Sprintf("%s:%s:ID:%s:EVT:%s:KID:%s",
+		ticketQRPrefix, ticket.TicketNumber, ticket.ID.String(), ticket.EventID.String(), s.activeKeyID)
+	signature := ed25519.Sign(key, []byte(message))
+
+	return message + ":SIG:" + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// VerifyTicketPayload checks the signature on a scanned QR payload and returns its claims.
+// Unsigned payloads and payloads signed with an unknown key are rejected.
+func (s *TicketSigner) VerifyTicketPayload(data string) (*TicketClaims, error) {
+	data = strings.TrimSpace(data)
+
+	message, encodedSignature, ok := strings.Cut(data, ":SIG:")
+	if !ok {
+		return nil, fmt.Errorf("ticket code is not signed")
+	}
+
+	fields, err := parseTicketPayloadFields(message)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := &TicketClaims{
+		TicketNumber: fields[ticketQRPrefix],
+		TicketID:     fields["ID"],
+		EventID:      fields["EVT"],
+		KeyID:        fields["KID"],
+	}
+	if claims.TicketNumber == "" || claims.TicketID == "" || claims.EventID == "" || claims.KeyID == "" {
+		return nil, fmt.Errorf("ticket code is missing required fields")
+	}
+
+	key, ok := s.keys[claims.KeyID]
+	if !ok {
+		return nil, fmt.Errorf("ticket code signed with unknown key %s", claims.KeyID)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(encodedSignature)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ticket signature encoding")
+	}
+
+	if !ed25519.Verify(key.Public().(ed25519.PublicKey), []byte(message), signature) {
+		return nil, fmt.Errorf("invalid ticket signature")
+	}
+
+	return claims, nil
+}
+
+// PublicKeys lists the public half of every configured signing key
+func (s *TicketSigner) PublicKeys() []TicketSigningKey {
+	keys := make([]TicketSigningKey, 0, len(s.keys))
+	for keyID, key := range s.keys {
+		keys = append(keys, TicketSigningKey{
+			KeyID:     keyID,
+			Algorithm: "Ed25519",
+			PublicKey: base64.StdEncoding.EncodeToString(key.Public().(ed25519.PublicKey)),
+			Active:    keyID == s.activeKeyID,
+		})
+	}
+
+	sort.Slice(keys, func(i, j int) bool { return keys[i].KeyID < keys[j].KeyID })
+	return keys
+}
+
+// parseTicketPayloadFields splits "TICKET:<number>:ID:<id>:..." into key/value pairs
+func parseTicketPayloadFields(message string) (map[string]string, error) {
+	parts := strings.Split(message, ":")
+	if len(parts)%2 != 0 || parts[0] != ticketQRPrefix {
+		return nil, fmt.Errorf("invalid ticket QR payload")
+	}
+
+	fields := make(map[string]string, len(parts)/2)
+	for i := 0; i < len(parts); i += 2 {
+		fields[parts[i]] = parts[i+1]
+	}
+
+	return fields, nil
+}
@@ -0,0 +1,139 @@
+package services
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/warui/event-ticketing-api/internal/config"
+	"github.com/warui/event-ticketing-api/internal/models"
+)
+
+func testSeed(b byte) string {
+	return base64.StdEncoding.EncodeToString([]byte(strings.Repeat(string(rune(b)), ed25519.SeedSize)))
+}
+
+func testTicket() *models.Ticket {
+	return &models.Ticket{
+		ID:           uuid.New(),
+		TicketNumber: "TKT-12345678",
+		EventID:      uuid.New(),
+	}
+}
+
+func TestTicketSignerRoundTrip(t *testing.T) {
+	signer, err := NewTicketSigner(&config.Config{JWTSecret: "test-secret"})
+	if err != nil {
+		t.Fatalf("NewTicketSigner failed: %v", err)
+	}
+
+	ticket := testTicket()
+	payload, err := signer.SignTicket(ticket)
+	if err != nil {
+		t.Fatalf("SignTicket failed: %v", err)
+	}
+
+	claims, err := signer.VerifyTicketPayload(payload)
+	if err != nil {
+		t.Fatalf("VerifyTicketPayload failed: %v", err)
+	}
+
+	if claims.TicketNumber != ticket.TicketNumber || claims.TicketID != ticket.ID.String() || claims.EventID != ticket.EventID.String() {
+		t.Errorf("Claims do not match ticket: %+v", claims)
+	}
+
+	if claims.KeyID != signer.ActiveKeyID() {
+		t.Errorf("Expected key ID %s, got %s", signer.ActiveKeyID(), claims.KeyID)
+	}
+}
+
+func TestTicketSignerRejectsTamperedPayloads(t *testing.T) {
+	signer, _ := NewTicketSigner(&config.Config{JWTSecret: "test-secret"})
+	ticket := testTicket()
+	payload, _ := signer.SignTicket(ticket)
+
+	tests := []struct {
+		name    string
+		payload string
+	}{
+		{"Unsigned legacy payload", "TICKET:" + ticket.TicketNumber + ":ID:" + ticket.ID.String()},
+		{"Changed ticket ID", strings.Replace(payload, ticket.ID.String(), uuid.New().String(), 1)},
+		{"Changed event ID", strings.Replace(payload, ticket.EventID.String(), uuid.New().String(), 1)},
+		{"Truncated signature", payload[:len(payload)-4]},
+		{"Unknown key ID", strings.Replace(payload, ":KID:default:", ":KID:other:", 1)},
+		{"Garbage", "not a ticket"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := signer.VerifyTicketPayload(tt.payload); err == nil {
+				t.Error("Expected verification error but got none")
+			}
+		})
+	}
+}
+
+func TestTicketSignerKeyRotation(t *testing.T) {
+	oldSigner, err := NewTicketSigner(&config.Config{
+		TicketSigningKeys:  "2024-01:" + testSeed('a'),
+		TicketSigningKeyID: "2024-01",
+	})
+	if err != nil {
+		t.Fatalf("NewTicketSigner failed: %v", err)
+	}
+
+	oldPayload, _ := oldSigner.SignTicket(testTicket())
+
+	rotated, err := NewTicketSigner(&config.Config{
+		TicketSigningKeys:  "2024-01:" + testSeed('a') + ",2024-06:" + testSeed('b'),
+		TicketSigningKeyID: "2024-06",
+	})
+	if err != nil {
+		t.Fatalf("NewTicketSigner failed: %v", err)
+	}
+
+	if _, err := rotated.VerifyTicketPayload(oldPayload); err != nil {
+		t.Errorf("Tickets signed with a retained key should still verify: %v", err)
+	}
+
+	newPayload, _ := rotated.SignTicket(testTicket())
+	if !strings.Contains(newPayload, ":KID:2024-06:") {
+		t.Errorf("New tickets should be signed with the active key, got %s", newPayload)
+	}
+
+	retired, _ := NewTicketSigner(&config.Config{
+		TicketSigningKeys:  "2024-06:" + testSeed('b'),
+		TicketSigningKeyID: "2024-06",
+	})
+	if _, err := retired.VerifyTicketPayload(oldPayload); err == nil {
+		t.Error("Tickets signed with a removed key should be rejected")
+	}
+
+	keys := rotated.PublicKeys()
+	if len(keys) != 2 || keys[0].KeyID != "2024-01" || keys[0].Active || !keys[1].Active {
+		t.Errorf("Unexpected public keys: %+v", keys)
+	}
+}
+
+func TestNewTicketSignerInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.Config
+	}{
+		{"No keys in production", config.Config{Environment: "production", JWTSecret: "test-secret"}},
+		{"Missing active key ID", config.Config{TicketSigningKeys: "k1:" + testSeed('a')}},
+		{"Active key not configured", config.Config{TicketSigningKeys: "k1:" + testSeed('a'), TicketSigningKeyID: "k2"}},
+		{"Malformed entry", config.Config{TicketSigningKeys: "k1", TicketSigningKeyID: "k1"}},
+		{"Short seed", config.Config{TicketSigningKeys: "k1:c2hvcnQ=", TicketSigningKeyID: "k1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewTicketSigner(&tt.cfg); err == nil {
+				t.Error("Expected configuration error but got none")
+			}
+		})
+	}
+}
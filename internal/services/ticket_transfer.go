@@ -0,0 +1,242 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/warui/event-ticketing-api/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrTransferNotAllowed is returned when a ticket cannot be offered, or an offer
+// cannot be accepted, under the ticket's state or the event's transfer policy
+var ErrTransferNotAllowed = errors.New("transfer not allowed")
+
+// TicketTransferService moves tickets between attendees. A ticket is offered to an
+// email address and only changes hands when the recipient accepts; it is then given
+// a new ticket number so the old QR code no longer admits anyone.
+type TicketTransferService struct {
+	db           *gorm.DB
+	orderService *OrderService
+	emailService *EmailService
+}
+
+func NewTicketTransferService(db *gorm.DB, orderService *OrderService, emailService *EmailService) *TicketTransferService {
+	return &TicketTransferService{
+		db:           db,
+		orderService: orderService,
+		emailService: emailService,
+	}
+}
+
+// RequestTransfer offers one of the sender's tickets to the given email address
+func (s *TicketTransferService) RequestTransfer(ticketID, fromUserID uuid.UUID, toEmail, message string) (*models.TicketTransfer, error) {
+	address, err := mail.ParseAddress(toEmail)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid recipient email", ErrTransferNotAllowed)
+	}
+	toEmail = strings.ToLower(address.Address)
+
+	var sender models.User
+	var event models.Event
+	var transfer *models.TicketTransfer
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&sender, "id = ?", fromUserID).Error; err != nil {
+			return fmt.Errorf("failed to load sender: %w", err)
+		}
+		if strings.EqualFold(sender.Email, toEmail) {
+			return fmt.Errorf("%w: you already hold this ticket", ErrTransferNotAllowed)
+		}
+
+		// Locking the ticket keeps two offers for it from being created at once
+		var ticket models.Ticket
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&ticket, "id = ? AND attendee_id = ?", ticketID, fromUserID).Error; err != nil {
+			return fmt.Errorf("failed to load ticket: %w", err)
+		}
+		if ticket.Status != models.TicketStatusConfirmed {
+			return fmt.Errorf("%w: ticket is %s", ErrTransferNotAllowed, ticket.Status)
+		}
+
+		if err := tx.First(&event, "id = ?", ticket.EventID).Error; err != nil {
+			return fmt.Errorf("failed to load event: %w", err)
+		}
+		if !event.TransfersOpenAt(time.Now()) {
+			return fmt.Errorf("%w: transfers are closed for this event", ErrTransferNotAllowed)
+		}
+
+		var pending int64
+		if err := tx.Model(&models.TicketTransfer{}).
+			Where("ticket_id = ? AND status = ?", ticket.ID, models.TransferStatusPending).
+			Count(&pending).Error; err != nil {
+			return fmt.Errorf("failed to check pending transfers: %w", err)
+		}
+		if pending > 0 {
+			return fmt.Errorf("%w: ticket already has a pending transfer", ErrTransferNotAllowed)
+		}
+
+		transfer = &models.TicketTransfer{
+			TicketID:   ticket.ID,
+			EventID:    ticket.EventID,
+			FromUserID: fromUserID,
+			ToEmail:    toEmail,
+			Message:    message,
+			Status:     models.TransferStatusPending,
+		}
+		if err := tx.Create(transfer).Error; err != nil {
+			return fmt.Errorf("failed to create transfer: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		if err := s.emailService.SendTicketTransferOfferEmail(transfer, &event, &sender); err != nil {
+			log.Printf("Failed to send transfer offer %s to %s: %v", transfer.ID, transfer.ToEmail, err)
+		}
+	}()
+
+	return transfer, nil
+}
+
+// AcceptTransfer moves the ticket to the recipient, who must be signed in with the
+// email address it was offered to. The ticket gets a new number, so the sender's QR
+// code is revoked, and fresh documents are delivered to the recipient.
+func (s *TicketTransferService) AcceptTransfer(transferID, userID uuid.UUID) (*models.TicketTransfer, error) {
+	var transfer models.TicketTransfer
+	var recipient models.User
+	var transaction models.Transaction
+	var ticket models.Ticket
+	var event models.Event
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&recipient, "id = ?", userID).Error; err != nil {
+			return fmt.Errorf("failed to load recipient: %w", err)
+		}
+
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&transfer, "id = ? AND to_email = ?", transferID, strings.ToLower(recipient.Email)).Error; err != nil {
+			return fmt.Errorf("failed to load transfer: %w", err)
+		}
+		if transfer.Status != models.TransferStatusPending {
+			return fmt.Errorf("%w: transfer is %s", ErrTransferNotAllowed, transfer.Status)
+		}
+
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&ticket, "id = ?", transfer.TicketID).Error; err != nil {
+			return fmt.Errorf("failed to load ticket: %w", err)
+		}
+		if ticket.AttendeeID != transfer.FromUserID || ticket.Status != models.TicketStatusConfirmed {
+			return fmt.Errorf("%w: ticket is no longer available", ErrTransferNotAllowed)
+		}
+
+		if err := tx.First(&event, "id = ?", ticket.EventID).Error; err != nil {
+			return fmt.Errorf("failed to load event: %w", err)
+		}
+		now := time.Now()
+		if !event.TransfersOpenAt(now) {
+			return fmt.Errorf("%w: transfers are closed for this event", ErrTransferNotAllowed)
+		}
+
+		previousNumber := ticket.TicketNumber
+		ticket.AttendeeID = recipient.ID
+		ticket.TicketNumber = models.GenerateTicketNumber()
+		if err := tx.Model(&ticket).Updates(map[string]interface{}{
+			"attendee_id":   ticket.AttendeeID,
+			"ticket_number": ticket.TicketNumber,
+			"qr_code_url":   "",
+			"pdf_url":       "",
+			"qr_key_id":     "",
+			"holder_name":   "",
+			"holder_email":  "",
+		}).Error; err != nil {
+			return fmt.Errorf("failed to transfer ticket: %w", err)
+		}
+
+		// The recipient answers the registration questions for themselves
+		if err := saveTicketAnswersTx(tx, ticket.ID, nil); err != nil {
+			return err
+		}
+
+		transfer.Status = models.TransferStatusAccepted
+		transfer.ToUserID = &recipient.ID
+		transfer.PreviousTicketNumber = previousNumber
+		transfer.RespondedAt = &now
+		if err := tx.Model(&transfer).Select("status", "to_user_id", "previous_ticket_number", "responded_at").
+			Updates(&transfer).Error; err != nil {
+			return fmt.Errorf("failed to accept transfer: %w", err)
+		}
+
+		// Delivery picks the ticket up again; the retry job covers a failed attempt
+		if err := tx.Model(&models.Transaction{}).Where("id = ?", ticket.TransactionID).
+			Update("delivered_at", nil).Error; err != nil {
+			return fmt.Errorf("failed to queue ticket delivery: %w", err)
+		}
+		return tx.First(&transaction, "id = ?", ticket.TransactionID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.orderService.DeliverTickets(&transaction); err != nil {
+		log.Printf("Ticket delivery after transfer %s failed, will retry: %v", transfer.ID, err)
+	}
+
+	go func() {
+		var sender models.User
+		if err := s.db.First(&sender, "id = ?", transfer.FromUserID).Error; err != nil {
+			log.Printf("Failed to load sender of transfer %s: %v", transfer.ID, err)
+			return
+		}
+		if err := s.emailService.SendTicketTransferredEmail(&transfer, &event, &sender, &recipient); err != nil {
+			log.Printf("Failed to send transfer confirmation for %s to %s: %v", transfer.ID, sender.Email, err)
+		}
+	}()
+
+	return &transfer, nil
+}
+
+// DeclineTransfer lets the recipient turn an offer down; the sender keeps the ticket
+func (s *TicketTransferService) DeclineTransfer(transferID, userID uuid.UUID) (*models.TicketTransfer, error) {
+	var recipient models.User
+	if err := s.db.First(&recipient, "id = ?", userID).Error; err != nil {
+		return nil, fmt.Errorf("failed to load recipient: %w", err)
+	}
+
+	return s.closeTransfer(s.db.Where("id = ? AND to_email = ?", transferID, strings.ToLower(recipient.Email)), models.TransferStatusDeclined, &recipient.ID)
+}
+
+// CancelTransfer lets the sender withdraw an offer that has not been accepted yet
+func (s *TicketTransferService) CancelTransfer(transferID, fromUserID uuid.UUID) (*models.TicketTransfer, error) {
+	return s.closeTransfer(s.db.Where("id = ? AND from_user_id = ?", transferID, fromUserID), models.TransferStatusCancelled, nil)
+}
+
+// closeTransfer ends a pending transfer without moving the ticket
+func (s *TicketTransferService) closeTransfer(query *gorm.DB, status models.TransferStatus, toUserID *uuid.UUID) (*models.TicketTransfer, error) {
+	var transfer models.TicketTransfer
+	if err := query.First(&transfer).Error; err != nil {
+		return nil, fmt.Errorf("failed to load transfer: %w", err)
+	}
+
+	now := time.Now()
+	result := s.db.Model(&models.TicketTransfer{}).
+		Where("id = ? AND status = ?", transfer.ID, models.TransferStatusPending).
+		Updates(map[string]interface{}{"status": status, "to_user_id": toUserID, "responded_at": now})
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to update transfer: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("%w: transfer is no longer pending", ErrTransferNotAllowed)
+	}
+
+	transfer.Status = status
+	transfer.ToUserID = toUserID
+	transfer.RespondedAt = &now
+	return &transfer, nil
+}
@@ -0,0 +1,147 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/warui/event-ticketing-api/internal/models"
+)
+
+func TestTicketTransferMovesTicketAndRevokesOldCode(t *testing.T) {
+	db := setupInventoryDB(t)
+	orderService := newTestOrderService(t, db)
+	service := NewTicketTransferService(db, orderService, orderService.emailService)
+	ticketType := createTestTicketType(t, db, 10)
+
+	var event models.Event
+	db.First(&event, "id = ?", ticketType.EventID)
+
+	purchase := &models.Transaction{
+		UserID:           event.OrganizerID,
+		EventID:          &event.ID,
+		Type:             models.TransactionTypeTicketPurchase,
+		Status:           models.TransactionStatusPending,
+		Amount:           100000,
+		Currency:         "NGN",
+		NetAmount:        95000,
+		PaymentReference: "TXN-TRF-" + ticketType.ID.String(),
+	}
+	if err := db.Create(purchase).Error; err != nil {
+		t.Fatalf("Failed to create transaction: %v", err)
+	}
+
+	tickets, err := orderService.FulfillPayment(purchase, &PaymentResult{
+		Successful: true,
+		Amount:     100000,
+		Currency:   "NGN",
+		Metadata: map[string]interface{}{
+			"event_id": event.ID.String(),
+			"items": []interface{}{
+				map[string]interface{}{"ticket_type_id": ticketType.ID.String(), "quantity": float64(1)},
+			},
+		},
+	})
+	if err != nil || len(tickets) != 1 {
+		t.Fatalf("Failed to fulfil purchase: %v", err)
+	}
+	original := tickets[0]
+
+	recipient := &models.User{
+		Email:     fmt.Sprintf("friend-%s@example.com", uuid.New().String()[:8]),
+		Password:  "hashed",
+		FirstName: "Friend",
+		LastName:  "Attendee",
+		Role:      models.RoleAttendee,
+	}
+	if err := db.Create(recipient).Error; err != nil {
+		t.Fatalf("Failed to create recipient: %v", err)
+	}
+
+	transfer, err := service.RequestTransfer(original.ID, event.OrganizerID, recipient.Email, "Enjoy the show")
+	if err != nil {
+		t.Fatalf("RequestTransfer failed: %v", err)
+	}
+	if _, err := service.RequestTransfer(original.ID, event.OrganizerID, recipient.Email, ""); !errors.Is(err, ErrTransferNotAllowed) {
+		t.Errorf("Expected a second offer for the same ticket to be refused, got %v", err)
+	}
+
+	// Only the recipient can accept
+	if _, err := service.AcceptTransfer(transfer.ID, event.OrganizerID); err == nil {
+		t.Error("Expected the sender to be unable to accept their own transfer")
+	}
+
+	accepted, err := service.AcceptTransfer(transfer.ID, recipient.ID)
+	if err != nil {
+		t.Fatalf("AcceptTransfer failed: %v", err)
+	}
+	if accepted.Status != models.TransferStatusAccepted || accepted.PreviousTicketNumber != original.TicketNumber {
+		t.Errorf("Expected an accepted transfer recording the old ticket number, got %+v", accepted)
+	}
+
+	var ticket models.Ticket
+	db.First(&ticket, "id = ?", original.ID)
+	if ticket.AttendeeID != recipient.ID {
+		t.Errorf("Expected the ticket to belong to the recipient, got %s", ticket.AttendeeID)
+	}
+	if ticket.TicketNumber == original.TicketNumber {
+		t.Error("Expected the ticket number to change so the old QR code is revoked")
+	}
+	if ticket.PDFURL == "" || ticket.PDFURL == original.PDFURL {
+		t.Errorf("Expected a fresh ticket PDF, got %q", ticket.PDFURL)
+	}
+
+	if _, err := service.AcceptTransfer(transfer.ID, recipient.ID); !errors.Is(err, ErrTransferNotAllowed) {
+		t.Errorf("Expected accepting twice to be refused, got %v", err)
+	}
+
+	// The buyer can no longer refund a ticket they gave away
+	refundService := NewRefundService(db, orderService.cfg, newTestGateways(t, orderService.cfg), orderService.ledgerService)
+	_, err = refundService.RefundTickets(RefundRequest{
+		TransactionID: purchase.ID,
+		Percentage:    100,
+		HolderID:      event.OrganizerID,
+	})
+	if !errors.Is(err, ErrNothingToRefund) {
+		t.Errorf("Expected ErrNothingToRefund for a transferred ticket, got %v", err)
+	}
+}
+
+func TestTicketTransferRefusedWhenTransfersDisabled(t *testing.T) {
+	db := setupInventoryDB(t)
+	orderService := newTestOrderService(t, db)
+	service := NewTicketTransferService(db, orderService, orderService.emailService)
+	ticketType := createTestTicketType(t, db, 10)
+
+	var event models.Event
+	db.First(&event, "id = ?", ticketType.EventID)
+	db.Model(&event).Update("allow_transfers", false)
+
+	purchase := &models.Transaction{
+		UserID:           event.OrganizerID,
+		EventID:          &event.ID,
+		Type:             models.TransactionTypeTicketPurchase,
+		Status:           models.TransactionStatusCompleted,
+		Currency:         "NGN",
+		PaymentReference: "TXN-TRF-OFF-" + ticketType.ID.String(),
+	}
+	if err := db.Create(purchase).Error; err != nil {
+		t.Fatalf("Failed to create transaction: %v", err)
+	}
+	ticket := &models.Ticket{
+		EventID:       event.ID,
+		TicketTypeID:  ticketType.ID,
+		AttendeeID:    event.OrganizerID,
+		TransactionID: purchase.ID,
+		Status:        models.TicketStatusConfirmed,
+	}
+	if err := db.Create(ticket).Error; err != nil {
+		t.Fatalf("Failed to create ticket: %v", err)
+	}
+
+	_, err := service.RequestTransfer(ticket.ID, event.OrganizerID, "someone@example.com", "")
+	if !errors.Is(err, ErrTransferNotAllowed) {
+		t.Errorf("Expected ErrTransferNotAllowed, got %v", err)
+	}
+}
@@ -0,0 +1,279 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/warui/event-ticketing-api/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrInvalidTicketType is returned when a ticket type change breaks its rules
+	ErrInvalidTicketType = errors.New("invalid ticket type")
+	// ErrTicketTypeLocked is returned when a ticket type has sales or its event is over
+	ErrTicketTypeLocked = errors.New("ticket type can no longer be changed")
+)
+
+// TicketTypeOrder is the order ticket types are listed in
+const TicketTypeOrder = "display_order ASC, created_at ASC"
+
+// TicketTypeUpdate is a partial change to a ticket type; fields left out are kept
+type TicketTypeUpdate struct {
+	Name         *string    `json:"name"`
+	Description  *string    `json:"description"`
+	Price        *int64     `json:"price" binding:"omitempty,min=0"` // Minor units
+	Quantity     *int       `json:"quantity" binding:"omitempty,min=1"`
+	MaxPerOrder  *int       `json:"max_per_order" binding:"omitempty,min=1"`
+	SaleStart    *time.Time `json:"sale_start"`
+	SaleEnd      *time.Time `json:"sale_end"`
+	IsHidden     *bool      `json:"is_hidden"`
+	DisplayOrder *int       `json:"display_order"`
+
+	// Required to change the price once the event is published
+	PriceChangeReason string `json:"price_change_reason"`
+}
+
+// Apply checks the change against the ticket type's sales and applies it in place
+func (u *TicketTypeUpdate) Apply(ticketType *models.TicketType) error {
+	if u.Name != nil {
+		name := strings.TrimSpace(*u.Name)
+		if name == "" {
+			return fmt.Errorf("%w: name is required", ErrInvalidTicketType)
+		}
+		ticketType.Name = name
+	}
+	if u.Description != nil {
+		ticketType.Description = *u.Description
+	}
+	if u.Price != nil {
+		if *u.Price < 0 {
+			return fmt.Errorf("%w: price cannot be negative", ErrInvalidTicketType)
+		}
+		ticketType.Price = *u.Price
+	}
+	if u.Quantity != nil && *u.Quantity != ticketType.Quantity {
+		if ticketType.ReservedSeating {
+			return fmt.Errorf("%w: the quantity of a reserved seating ticket type follows its seats on the seat map", ErrInvalidTicketType)
+		}
+		if taken := ticketType.Sold + ticketType.Reserved; *u.Quantity < taken {
+			return fmt.Errorf("%w: quantity cannot be lower than the %d tickets already sold or held", ErrInvalidTicketType, taken)
+		}
+		ticketType.Quantity = *u.Quantity
+	}
+	if u.MaxPerOrder != nil {
+		if *u.MaxPerOrder < 1 {
+			return fmt.Errorf("%w: max per order must be at least 1", ErrInvalidTicketType)
+		}
+		ticketType.MaxPerOrder = *u.MaxPerOrder
+	}
+	if u.SaleStart != nil {
+		ticketType.SaleStart = *u.SaleStart
+	}
+	if u.SaleEnd != nil {
+		ticketType.SaleEnd = *u.SaleEnd
+	}
+	if ticketType.SaleEnd.Before(ticketType.SaleStart) {
+		return fmt.Errorf("%w: sale end date must be after sale start date", ErrInvalidTicketType)
+	}
+	if u.IsHidden != nil {
+		ticketType.IsHidden = *u.IsHidden
+	}
+	if u.DisplayOrder != nil {
+		ticketType.DisplayOrder = *u.DisplayOrder
+	}
+	return nil
+}
+
+// TicketTypeService changes and removes ticket types while checkouts may be buying them
+type TicketTypeService struct {
+	db *gorm.DB
+}
+
+func NewTicketTypeService(db *gorm.DB) *TicketTypeService {
+	return &TicketTypeService{db: db}
+}
+
+// UpdateTicketType applies a change to a ticket type. The ticket type row is locked
+// so its Sold and Reserved counts cannot move while the new quantity is checked.
+// A price change on a published event is recorded with its reason.
+func (s *TicketTypeService) UpdateTicketType(ticketTypeID, changedBy uuid.UUID, update TicketTypeUpdate) (*models.TicketType, error) {
+	var ticketType models.TicketType
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		event, err := s.lockTicketTypeTx(tx, ticketTypeID, &ticketType)
+		if err != nil {
+			return err
+		}
+
+		oldPrice := ticketType.Price
+		if err := update.Apply(&ticketType); err != nil {
+			return err
+		}
+
+		if ticketType.Price != oldPrice && event.Status == models.EventStatusPublished {
+			reason := strings.TrimSpace(update.PriceChangeReason)
+			if reason == "" {
+				return fmt.Errorf("%w: a reason is required to change the price of a published event's ticket", ErrInvalidTicketType)
+			}
+			change := &models.TicketPriceChange{
+				TicketTypeID: ticketType.ID,
+				EventID:      event.ID,
+				OldPrice:     oldPrice,
+				NewPrice:     ticketType.Price,
+				Reason:       reason,
+				ChangedBy:    changedBy,
+			}
+			if err := tx.Create(change).Error; err != nil {
+				return fmt.Errorf("failed to record price change: %w", err)
+			}
+		}
+
+		if err := tx.Model(&ticketType).Select("Name", "Description", "Price", "Quantity", "MaxPerOrder",
+			"SaleStart", "SaleEnd", "IsHidden", "DisplayOrder").Updates(&ticketType).Error; err != nil {
+			return fmt.Errorf("failed to update ticket type: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ticketType, nil
+}
+
+// SetActive starts or stops sales of a ticket type. Tickets already sold stay valid
+// and checkouts already holding stock can still be paid.
+func (s *TicketTypeService) SetActive(ticketTypeID uuid.UUID, active bool) (*models.TicketType, error) {
+	var ticketType models.TicketType
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if _, err := s.lockTicketTypeTx(tx, ticketTypeID, &ticketType); err != nil {
+			return err
+		}
+
+		if err := tx.Model(&ticketType).Update("is_active", active).Error; err != nil {
+			return fmt.Errorf("failed to update ticket type: %w", err)
+		}
+		ticketType.IsActive = active
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ticketType, nil
+}
+
+// DeleteTicketType removes a ticket type that has never had tickets issued or held.
+// Its seats and codes go with it; a promo code left without ticket types is
+// deactivated rather than let it discount the whole event.
+func (s *TicketTypeService) DeleteTicketType(ticketTypeID uuid.UUID) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var ticketType models.TicketType
+		if _, err := s.lockTicketTypeTx(tx, ticketTypeID, &ticketType); err != nil {
+			return err
+		}
+
+		var issued int64
+		if err := tx.Model(&models.Ticket{}).Where("ticket_type_id = ?", ticketType.ID).Count(&issued).Error; err != nil {
+			return fmt.Errorf("failed to check tickets: %w", err)
+		}
+		if ticketType.Sold+ticketType.Reserved > 0 || issued > 0 {
+			return fmt.Errorf("%w: %s has sales; deactivate it instead", ErrTicketTypeLocked, ticketType.Name)
+		}
+
+		var promoCodeIDs []uuid.UUID
+		if err := tx.Table("promo_code_ticket_types").Where("ticket_type_id = ?", ticketType.ID).
+			Pluck("promo_code_id", &promoCodeIDs).Error; err != nil {
+			return fmt.Errorf("failed to load promo codes: %w", err)
+		}
+
+		for _, cleanup := range []struct {
+			query string
+			what  string
+		}{
+			{"DELETE FROM promo_code_ticket_types WHERE ticket_type_id = ?", "promo codes"},
+			{"DELETE FROM access_code_ticket_types WHERE ticket_type_id = ?", "access codes"},
+			{"DELETE FROM seats WHERE ticket_type_id = ?", "seats"},
+			{"DELETE FROM waitlist_entries WHERE ticket_type_id = ?", "waitlist entries"},
+			{"DELETE FROM inventory_holds WHERE ticket_type_id = ?", "inventory holds"},
+		} {
+			if err := tx.Exec(cleanup.query, ticketType.ID).Error; err != nil {
+				return fmt.Errorf("failed to remove %s: %w", cleanup.what, err)
+			}
+		}
+
+		if len(promoCodeIDs) > 0 {
+			if err := tx.Model(&models.PromoCode{}).
+				Where("id IN ? AND NOT EXISTS (SELECT 1 FROM promo_code_ticket_types WHERE promo_code_id = promo_codes.id)", promoCodeIDs).
+				Update("is_active", false).Error; err != nil {
+				return fmt.Errorf("failed to deactivate promo codes: %w", err)
+			}
+		}
+
+		if err := tx.Delete(&ticketType).Error; err != nil {
+			return fmt.Errorf("failed to delete ticket type: %w", err)
+		}
+		return nil
+	})
+}
+
+// ReorderTicketTypes sets the display order of an event's ticket types to the order
+// given. Every ticket type of the event must be listed once.
+func (s *TicketTypeService) ReorderTicketTypes(eventID uuid.UUID, ticketTypeIDs []uuid.UUID) ([]models.TicketType, error) {
+	var ticketTypes []models.TicketType
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var existing []uuid.UUID
+		if err := tx.Model(&models.TicketType{}).Where("event_id = ?", eventID).Pluck("id", &existing).Error; err != nil {
+			return fmt.Errorf("failed to load ticket types: %w", err)
+		}
+
+		known := make(map[uuid.UUID]bool, len(existing))
+		for _, id := range existing {
+			known[id] = true
+		}
+		if len(ticketTypeIDs) != len(existing) {
+			return fmt.Errorf("%w: list each of the event's %d ticket types once", ErrInvalidTicketType, len(existing))
+		}
+		for _, id := range ticketTypeIDs {
+			if !known[id] {
+				return fmt.Errorf("%w: ticket type %s is listed twice or does not belong to the event", ErrInvalidTicketType, id)
+			}
+			delete(known, id)
+		}
+
+		for i, id := range ticketTypeIDs {
+			if err := tx.Model(&models.TicketType{}).Where("id = ?", id).Update("display_order", i+1).Error; err != nil {
+				return fmt.Errorf("failed to reorder ticket types: %w", err)
+			}
+		}
+
+		return tx.Where("event_id = ?", eventID).Order(TicketTypeOrder).Find(&ticketTypes).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ticketTypes, nil
+}
+
+// lockTicketTypeTx loads and locks a ticket type and returns its event, failing with
+// ErrTicketTypeLocked once the event is cancelled or over
+func (s *TicketTypeService) lockTicketTypeTx(tx *gorm.DB, ticketTypeID uuid.UUID, ticketType *models.TicketType) (*models.Event, error) {
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(ticketType, "id = ?", ticketTypeID).Error; err != nil {
+		return nil, err
+	}
+
+	var event models.Event
+	if err := tx.First(&event, "id = ?", ticketType.EventID).Error; err != nil {
+		return nil, err
+	}
+	if event.Status == models.EventStatusCancelled || event.Status == models.EventStatusCompleted {
+		return nil, fmt.Errorf("%w: event is %s", ErrTicketTypeLocked, event.Status)
+	}
+
+	return &event, nil
+}
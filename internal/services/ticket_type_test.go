@@ -0,0 +1,105 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/warui/event-ticketing-api/internal/models"
+)
+
+func TestTicketTypeUpdateApply(t *testing.T) {
+	now := time.Now()
+	intPtr := func(v int) *int { return &v }
+	timePtr := func(v time.Time) *time.Time { return &v }
+	name := "  VIP  "
+	blank := " "
+
+	tests := []struct {
+		name       string
+		ticketType models.TicketType
+		update     TicketTypeUpdate
+		wantErr    bool
+	}{
+		{"Raise quantity", models.TicketType{Quantity: 100, Sold: 100}, TicketTypeUpdate{Quantity: intPtr(150)}, false},
+		{"Lower quantity to sold and held", models.TicketType{Quantity: 100, Sold: 40, Reserved: 10}, TicketTypeUpdate{Quantity: intPtr(50)}, false},
+		{"Lower quantity below sold", models.TicketType{Quantity: 100, Sold: 40}, TicketTypeUpdate{Quantity: intPtr(39)}, true},
+		{"Lower quantity below held", models.TicketType{Quantity: 100, Sold: 40, Reserved: 10}, TicketTypeUpdate{Quantity: intPtr(45)}, true},
+		{"Quantity of reserved seating", models.TicketType{Quantity: 100, ReservedSeating: true}, TicketTypeUpdate{Quantity: intPtr(120)}, true},
+		{"Unchanged quantity of reserved seating", models.TicketType{Quantity: 100, ReservedSeating: true}, TicketTypeUpdate{Quantity: intPtr(100)}, false},
+		{"Rename", models.TicketType{Name: "General"}, TicketTypeUpdate{Name: &name}, false},
+		{"Blank name", models.TicketType{Name: "General"}, TicketTypeUpdate{Name: &blank}, true},
+		{"Sale end before start", models.TicketType{SaleStart: now, SaleEnd: now.Add(time.Hour)}, TicketTypeUpdate{SaleEnd: timePtr(now.Add(-time.Hour))}, true},
+		{"Zero max per order", models.TicketType{MaxPerOrder: 10}, TicketTypeUpdate{MaxPerOrder: intPtr(0)}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ticketType := tt.ticketType
+			err := tt.update.Apply(&ticketType)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidTicketType) {
+					t.Errorf("Expected ErrInvalidTicketType, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if tt.update.Quantity != nil && ticketType.Quantity != *tt.update.Quantity {
+				t.Errorf("Expected quantity %d, got %d", *tt.update.Quantity, ticketType.Quantity)
+			}
+			if tt.update.Name != nil && ticketType.Name != "VIP" {
+				t.Errorf("Expected trimmed name, got %q", ticketType.Name)
+			}
+		})
+	}
+}
+
+func TestTicketTypeServicePriceChangesAndDeletion(t *testing.T) {
+	db := setupInventoryDB(t)
+	service := NewTicketTypeService(db)
+
+	ticketType := createTestTicketType(t, db, 10)
+	if err := db.Model(&models.Event{}).Where("id = ?", ticketType.EventID).
+		Update("status", models.EventStatusPublished).Error; err != nil {
+		t.Fatalf("Failed to publish event: %v", err)
+	}
+
+	price := int64(120000)
+	if _, err := service.UpdateTicketType(ticketType.ID, uuid.New(), TicketTypeUpdate{Price: &price}); !errors.Is(err, ErrInvalidTicketType) {
+		t.Fatalf("Expected a price change without a reason to fail, got %v", err)
+	}
+
+	updated, err := service.UpdateTicketType(ticketType.ID, uuid.New(), TicketTypeUpdate{Price: &price, PriceChangeReason: "Typo"})
+	if err != nil {
+		t.Fatalf("Failed to change price: %v", err)
+	}
+	if updated.Price != price {
+		t.Errorf("Expected price %d, got %d", price, updated.Price)
+	}
+
+	var changes []models.TicketPriceChange
+	db.Where("ticket_type_id = ?", ticketType.ID).Find(&changes)
+	if len(changes) != 1 || changes[0].OldPrice != 100000 || changes[0].NewPrice != price {
+		t.Errorf("Expected one price change from 100000 to %d, got %+v", price, changes)
+	}
+
+	if err := db.Model(&models.TicketType{}).Where("id = ?", ticketType.ID).Update("sold", 1).Error; err != nil {
+		t.Fatalf("Failed to record a sale: %v", err)
+	}
+	if err := service.DeleteTicketType(ticketType.ID); !errors.Is(err, ErrTicketTypeLocked) {
+		t.Fatalf("Expected deleting a ticket type with sales to fail, got %v", err)
+	}
+
+	unsold := createTestTicketType(t, db, 10)
+	if err := service.DeleteTicketType(unsold.ID); err != nil {
+		t.Fatalf("Failed to delete unsold ticket type: %v", err)
+	}
+	var count int64
+	db.Model(&models.TicketType{}).Where("id = ?", unsold.ID).Count(&count)
+	if count != 0 {
+		t.Errorf("Expected the unsold ticket type to be deleted")
+	}
+}
@@ -0,0 +1,375 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/warui/event-ticketing-api/internal/config"
+	"github.com/warui/event-ticketing-api/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrWaitlistUnavailable is returned when a buyer cannot join or leave a waitlist
+	ErrWaitlistUnavailable = errors.New("waitlist not available")
+	// ErrWaitlistOfferInvalid is returned when a waitlist purchase link is unknown, expired or used
+	ErrWaitlistOfferInvalid = errors.New("waitlist offer is not valid")
+)
+
+// WaitlistService queues buyers for sold-out ticket types. Stock that comes back is
+// offered to the queue in order before it returns to general sale: each offer
+// reserves its tickets for the buyer until it expires.
+type WaitlistService struct {
+	db           *gorm.DB
+	cfg          *config.Config
+	emailService *EmailService
+}
+
+func NewWaitlistService(db *gorm.DB, cfg *config.Config, emailService *EmailService) *WaitlistService {
+	return &WaitlistService{
+		db:           db,
+		cfg:          cfg,
+		emailService: emailService,
+	}
+}
+
+// Join adds the buyer to the waitlist of a ticket type that cannot cover the quantity
+func (s *WaitlistService) Join(ticketTypeID, userID uuid.UUID, quantity int) (*models.WaitlistEntry, error) {
+	var ticketType models.TicketType
+	if err := s.db.Preload("Event").First(&ticketType, "id = ?", ticketTypeID).Error; err != nil {
+		return nil, fmt.Errorf("failed to load ticket type: %w", err)
+	}
+	if ticketType.IsHidden || ticketType.Event.Status != models.EventStatusPublished {
+		return nil, fmt.Errorf("failed to load ticket type: %w", gorm.ErrRecordNotFound)
+	}
+	if !ticketType.IsActive || !time.Now().Before(ticketType.SaleEnd) {
+		return nil, fmt.Errorf("%w: ticket sales have ended", ErrWaitlistUnavailable)
+	}
+	if quantity < 1 || quantity > ticketType.MaxPerOrder {
+		return nil, fmt.Errorf("%w: quantity must be between 1 and %d", ErrWaitlistUnavailable, ticketType.MaxPerOrder)
+	}
+
+	var entry *models.WaitlistEntry
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var active int64
+		if err := tx.Model(&models.WaitlistEntry{}).
+			Where("ticket_type_id = ? AND user_id = ? AND status IN ?", ticketType.ID, userID,
+				[]models.WaitlistStatus{models.WaitlistStatusWaiting, models.WaitlistStatusOffered}).
+			Count(&active).Error; err != nil {
+			return fmt.Errorf("failed to check waitlist: %w", err)
+		}
+		if active > 0 {
+			return fmt.Errorf("%w: you are already on the waitlist", ErrWaitlistUnavailable)
+		}
+
+		stock, err := s.GeneralStockTx(tx, &ticketType)
+		if err != nil {
+			return err
+		}
+		if stock >= quantity {
+			return fmt.Errorf("%w: tickets are still on sale", ErrWaitlistUnavailable)
+		}
+
+		entry = &models.WaitlistEntry{
+			EventID:      ticketType.EventID,
+			TicketTypeID: ticketType.ID,
+			UserID:       userID,
+			Quantity:     quantity,
+			Status:       models.WaitlistStatusWaiting,
+		}
+		if err := tx.Create(entry).Error; err != nil {
+			return fmt.Errorf("failed to join waitlist: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// Leave takes the buyer off a waitlist. An unused offer is given to the next in line.
+func (s *WaitlistService) Leave(entryID, userID uuid.UUID) error {
+	var entry models.WaitlistEntry
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&entry, "id = ? AND user_id = ?", entryID, userID).Error; err != nil {
+			return fmt.Errorf("failed to load waitlist entry: %w", err)
+		}
+		if !entry.IsActive() {
+			return fmt.Errorf("%w: entry is %s", ErrWaitlistUnavailable, entry.Status)
+		}
+
+		return s.closeEntryTx(tx, &entry, models.WaitlistStatusCancelled)
+	})
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.OfferAvailable(entry.TicketTypeID); err != nil {
+		log.Printf("Failed to offer tickets of type %s to the waitlist: %v", entry.TicketTypeID, err)
+	}
+	return nil
+}
+
+// GeneralStockTx returns how many tickets of the type anyone may buy: the unsold and
+// unheld tickets less those owed to buyers still waiting in the queue
+func (s *WaitlistService) GeneralStockTx(tx *gorm.DB, ticketType *models.TicketType) (int, error) {
+	var waiting int64
+	if err := tx.Model(&models.WaitlistEntry{}).
+		Select("COALESCE(SUM(quantity), 0)").
+		Where("ticket_type_id = ? AND status = ?", ticketType.ID, models.WaitlistStatusWaiting).
+		Scan(&waiting).Error; err != nil {
+		return 0, fmt.Errorf("failed to count waitlist demand: %w", err)
+	}
+
+	stock := ticketType.RemainingTickets() - int(waiting)
+	if stock < 0 {
+		stock = 0
+	}
+	return stock, nil
+}
+
+// CheckGeneralStockTx locks the requested ticket types and fails with ErrInsufficientStock
+// if any request needs tickets owed to buyers still waiting in the queue. It must run
+// in the transaction that reserves the stock, after any waitlist offer was redeemed,
+// so concurrent checkouts cannot both take the waitlist's tickets.
+func (s *WaitlistService) CheckGeneralStockTx(tx *gorm.DB, requests []StockRequest) error {
+	ticketTypeIDs := make([]uuid.UUID, len(requests))
+	for i, request := range requests {
+		ticketTypeIDs[i] = request.TicketTypeID
+	}
+
+	var ticketTypes []models.TicketType
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id IN ?", ticketTypeIDs).Order("id").Find(&ticketTypes).Error; err != nil {
+		return fmt.Errorf("failed to lock ticket types: %w", err)
+	}
+	byID := make(map[uuid.UUID]*models.TicketType, len(ticketTypes))
+	for i := range ticketTypes {
+		byID[ticketTypes[i].ID] = &ticketTypes[i]
+	}
+
+	for _, request := range requests {
+		ticketType, ok := byID[request.TicketTypeID]
+		if !ok {
+			return fmt.Errorf("%w for ticket type %s", ErrInsufficientStock, request.TicketTypeID)
+		}
+		stock, err := s.GeneralStockTx(tx, ticketType)
+		if err != nil {
+			return err
+		}
+		if request.Quantity > stock {
+			return fmt.Errorf("%w for ticket type %s", ErrInsufficientStock, request.TicketTypeID)
+		}
+	}
+	return nil
+}
+
+// FindOffer returns the buyer's waitlist offer for a purchase link, if it can still be used
+func (s *WaitlistService) FindOffer(token string, userID uuid.UUID, now time.Time) (*models.WaitlistEntry, error) {
+	return s.findOfferTx(s.db, false, token, userID, now)
+}
+
+// RedeemOfferTx uses the buyer's offer for a checkout. The tickets it reserved are
+// handed back so the checkout can hold them itself; it must run in the transaction
+// that creates the purchase, before the stock is reserved.
+func (s *WaitlistService) RedeemOfferTx(tx *gorm.DB, token string, userID, transactionID uuid.UUID, now time.Time) (*models.WaitlistEntry, error) {
+	entry, err := s.findOfferTx(tx, true, token, userID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Model(&models.TicketType{}).Where("id = ?", entry.TicketTypeID).
+		Update("reserved", gorm.Expr("reserved - ?", entry.Quantity)).Error; err != nil {
+		return nil, fmt.Errorf("failed to release waitlist offer: %w", err)
+	}
+	if err := tx.Model(entry).Updates(map[string]interface{}{
+		"status":         models.WaitlistStatusPurchased,
+		"transaction_id": transactionID,
+	}).Error; err != nil {
+		return nil, fmt.Errorf("failed to redeem waitlist offer: %w", err)
+	}
+
+	entry.Status = models.WaitlistStatusPurchased
+	entry.TransactionID = &transactionID
+	return entry, nil
+}
+
+func (s *WaitlistService) findOfferTx(tx *gorm.DB, lock bool, token string, userID uuid.UUID, now time.Time) (*models.WaitlistEntry, error) {
+	query := tx
+	if lock {
+		query = tx.Clauses(clause.Locking{Strength: "UPDATE"})
+	}
+
+	var entry models.WaitlistEntry
+	if err := query.First(&entry, "offer_token = ? AND user_id = ?", token, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWaitlistOfferInvalid
+		}
+		return nil, fmt.Errorf("failed to load waitlist offer: %w", err)
+	}
+	if !entry.OfferValidAt(now) {
+		return nil, ErrWaitlistOfferInvalid
+	}
+	return &entry, nil
+}
+
+// OfferAvailable offers the free tickets of a ticket type to the waitlist in order.
+// An entry is only offered when its whole quantity is free, and later entries wait
+// behind it. It returns how many offers were made.
+func (s *WaitlistService) OfferAvailable(ticketTypeID uuid.UUID) (int, error) {
+	now := time.Now()
+	var offered []models.WaitlistEntry
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var ticketType models.TicketType
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&ticketType, "id = ?", ticketTypeID).Error; err != nil {
+			return fmt.Errorf("failed to load ticket type: %w", err)
+		}
+		if !ticketType.IsActive || !now.Before(ticketType.SaleEnd) {
+			return nil
+		}
+
+		var event models.Event
+		if err := tx.Select("status").First(&event, "id = ?", ticketType.EventID).Error; err != nil {
+			return fmt.Errorf("failed to load event: %w", err)
+		}
+		if event.Status != models.EventStatusPublished {
+			return nil
+		}
+
+		free := ticketType.RemainingTickets()
+		if free <= 0 {
+			return nil
+		}
+
+		var waiting []models.WaitlistEntry
+		if err := tx.Where("ticket_type_id = ? AND status = ?", ticketType.ID, models.WaitlistStatusWaiting).
+			Order("created_at ASC, id ASC").Find(&waiting).Error; err != nil {
+			return fmt.Errorf("failed to load waitlist: %w", err)
+		}
+
+		expiresAt := now.Add(s.cfg.WaitlistOfferDuration)
+		reserved := 0
+		for _, entry := range waiting {
+			if entry.Quantity > free-reserved {
+				break
+			}
+
+			token, err := s.emailService.GenerateVerificationToken()
+			if err != nil {
+				return err
+			}
+			if err := tx.Model(&entry).Updates(map[string]interface{}{
+				"status":           models.WaitlistStatusOffered,
+				"offer_token":      token,
+				"offered_at":       now,
+				"offer_expires_at": expiresAt,
+			}).Error; err != nil {
+				return fmt.Errorf("failed to offer tickets: %w", err)
+			}
+
+			entry.Status = models.WaitlistStatusOffered
+			entry.OfferToken = &token
+			entry.OfferedAt = &now
+			entry.OfferExpiresAt = &expiresAt
+			offered = append(offered, entry)
+			reserved += entry.Quantity
+		}
+
+		if reserved == 0 {
+			return nil
+		}
+		return tx.Model(&models.TicketType{}).Where("id = ?", ticketType.ID).
+			Update("reserved", gorm.Expr("reserved + ?", reserved)).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range offered {
+		go s.sendOffer(&offered[i])
+	}
+	return len(offered), nil
+}
+
+// ProcessOffers expires offers that ran out and offers free stock to every waitlist.
+// It returns how many new offers were made.
+func (s *WaitlistService) ProcessOffers(limit int) (int, error) {
+	var expired []models.WaitlistEntry
+	if err := s.db.Where("status = ? AND offer_expires_at < ?", models.WaitlistStatusOffered, time.Now()).
+		Order("offer_expires_at ASC").Limit(limit).Find(&expired).Error; err != nil {
+		return 0, fmt.Errorf("failed to load expired waitlist offers: %w", err)
+	}
+	for i := range expired {
+		if err := s.db.Transaction(func(tx *gorm.DB) error {
+			return s.closeEntryTx(tx, &expired[i], models.WaitlistStatusExpired)
+		}); err != nil {
+			return 0, err
+		}
+	}
+
+	var ticketTypeIDs []uuid.UUID
+	if err := s.db.Model(&models.WaitlistEntry{}).
+		Where("status = ?", models.WaitlistStatusWaiting).
+		Distinct("ticket_type_id").Limit(limit).Pluck("ticket_type_id", &ticketTypeIDs).Error; err != nil {
+		return 0, fmt.Errorf("failed to load waitlists: %w", err)
+	}
+
+	offers := 0
+	for _, ticketTypeID := range ticketTypeIDs {
+		made, err := s.OfferAvailable(ticketTypeID)
+		if err != nil {
+			return offers, err
+		}
+		offers += made
+	}
+	return offers, nil
+}
+
+// closeEntryTx ends an active entry, returning the tickets its offer reserved.
+// The conditional status update makes a concurrent close or purchase a no-op.
+func (s *WaitlistService) closeEntryTx(tx *gorm.DB, entry *models.WaitlistEntry, status models.WaitlistStatus) error {
+	result := tx.Model(&models.WaitlistEntry{}).
+		Where("id = ? AND status = ?", entry.ID, entry.Status).
+		Update("status", status)
+	if result.Error != nil {
+		return fmt.Errorf("failed to close waitlist entry: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil
+	}
+
+	if entry.Status == models.WaitlistStatusOffered {
+		if err := tx.Model(&models.TicketType{}).Where("id = ?", entry.TicketTypeID).
+			Update("reserved", gorm.Expr("reserved - ?", entry.Quantity)).Error; err != nil {
+			return fmt.Errorf("failed to release waitlist offer: %w", err)
+		}
+	}
+
+	entry.Status = status
+	return nil
+}
+
+// sendOffer emails the buyer their purchase link
+func (s *WaitlistService) sendOffer(entry *models.WaitlistEntry) {
+	var user models.User
+	var ticketType models.TicketType
+	if err := s.db.First(&user, "id = ?", entry.UserID).Error; err != nil {
+		log.Printf("Failed to load user for waitlist offer %s: %v", entry.ID, err)
+		return
+	}
+	if err := s.db.Preload("Event").First(&ticketType, "id = ?", entry.TicketTypeID).Error; err != nil {
+		log.Printf("Failed to load ticket type for waitlist offer %s: %v", entry.ID, err)
+		return
+	}
+
+	if err := s.emailService.SendWaitlistOfferEmail(entry, &ticketType.Event, &ticketType, &user); err != nil {
+		log.Printf("Failed to send waitlist offer %s to %s: %v", entry.ID, user.Email, err)
+	}
+}
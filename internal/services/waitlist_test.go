@@ -0,0 +1,149 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/warui/event-ticketing-api/internal/config"
+	"github.com/warui/event-ticketing-api/internal/models"
+	"gorm.io/gorm"
+)
+
+func createWaitlistBuyer(t *testing.T, db *gorm.DB) *models.User {
+	user := &models.User{
+		Email:     fmt.Sprintf("waitlist-%s@example.com", uuid.New().String()[:8]),
+		Password:  "hashed",
+		FirstName: "Waiting",
+		LastName:  "Attendee",
+		Role:      models.RoleAttendee,
+	}
+	if err := db.Create(user).Error; err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+	return user
+}
+
+func TestWaitlistOffersReturnedStockInOrder(t *testing.T) {
+	db := setupInventoryDB(t)
+	cfg := &config.Config{WaitlistOfferDuration: time.Hour}
+	service := NewWaitlistService(db, cfg, NewEmailService(cfg))
+	ticketType := createTestTicketType(t, db, 2)
+	db.Model(&models.Event{}).Where("id = ?", ticketType.EventID).Update("status", models.EventStatusPublished)
+
+	first := createWaitlistBuyer(t, db)
+	second := createWaitlistBuyer(t, db)
+
+	if _, err := service.Join(ticketType.ID, first.ID, 1); !errors.Is(err, ErrWaitlistUnavailable) {
+		t.Fatalf("Expected joining to be refused while tickets are on sale, got %v", err)
+	}
+
+	db.Model(ticketType).Update("sold", 2)
+	firstEntry, err := service.Join(ticketType.ID, first.ID, 2)
+	if err != nil {
+		t.Fatalf("Join failed: %v", err)
+	}
+	if _, err := service.Join(ticketType.ID, first.ID, 1); !errors.Is(err, ErrWaitlistUnavailable) {
+		t.Errorf("Expected a second entry for the same buyer to be refused, got %v", err)
+	}
+	secondEntry, err := service.Join(ticketType.ID, second.ID, 1)
+	if err != nil {
+		t.Fatalf("Join failed: %v", err)
+	}
+
+	// One ticket comes back: the first buyer wants two, so nobody is offered yet
+	// and the ticket is kept from general sale
+	db.Model(ticketType).Update("sold", 1)
+	if offered, err := service.OfferAvailable(ticketType.ID); err != nil || offered != 0 {
+		t.Fatalf("Expected no offers, got %d (%v)", offered, err)
+	}
+	db.First(ticketType, "id = ?", ticketType.ID)
+	if stock, _ := service.GeneralStockTx(db, ticketType); stock != 0 {
+		t.Errorf("Expected returned stock to be kept for the waitlist, got %d", stock)
+	}
+	if err := service.CheckGeneralStockTx(db, []StockRequest{{TicketTypeID: ticketType.ID, Quantity: 1}}); !errors.Is(err, ErrInsufficientStock) {
+		t.Errorf("Expected a checkout to be refused the waitlist's ticket, got %v", err)
+	}
+
+	db.Model(ticketType).Update("sold", 0)
+	if offered, err := service.OfferAvailable(ticketType.ID); err != nil || offered != 1 {
+		t.Fatalf("Expected one offer, got %d (%v)", offered, err)
+	}
+
+	db.First(firstEntry, "id = ?", firstEntry.ID)
+	db.First(secondEntry, "id = ?", secondEntry.ID)
+	if firstEntry.Status != models.WaitlistStatusOffered || secondEntry.Status != models.WaitlistStatusWaiting {
+		t.Fatalf("Expected only the first entry to be offered, got %s and %s", firstEntry.Status, secondEntry.Status)
+	}
+	db.First(ticketType, "id = ?", ticketType.ID)
+	if ticketType.Reserved != 2 {
+		t.Errorf("Expected the offer to reserve 2 tickets, got %d", ticketType.Reserved)
+	}
+
+	// The link only works for the buyer it was sent to
+	if _, err := service.FindOffer(*firstEntry.OfferToken, second.ID, time.Now()); !errors.Is(err, ErrWaitlistOfferInvalid) {
+		t.Errorf("Expected the offer to be refused for another buyer, got %v", err)
+	}
+
+	transactionID := uuid.New()
+	redeemed, err := service.RedeemOfferTx(db, *firstEntry.OfferToken, first.ID, transactionID, time.Now())
+	if err != nil {
+		t.Fatalf("RedeemOfferTx failed: %v", err)
+	}
+	if redeemed.Status != models.WaitlistStatusPurchased || *redeemed.TransactionID != transactionID {
+		t.Errorf("Expected a purchased entry, got %+v", redeemed)
+	}
+	db.First(ticketType, "id = ?", ticketType.ID)
+	if ticketType.Reserved != 0 {
+		t.Errorf("Expected the offer's reservation to be handed back, got %d", ticketType.Reserved)
+	}
+	if _, err := service.RedeemOfferTx(db, *firstEntry.OfferToken, first.ID, uuid.New(), time.Now()); !errors.Is(err, ErrWaitlistOfferInvalid) {
+		t.Errorf("Expected a used offer to be refused, got %v", err)
+	}
+}
+
+func TestWaitlistExpiredOfferPassesToNextInLine(t *testing.T) {
+	db := setupInventoryDB(t)
+	cfg := &config.Config{WaitlistOfferDuration: time.Hour}
+	service := NewWaitlistService(db, cfg, NewEmailService(cfg))
+	ticketType := createTestTicketType(t, db, 1)
+	db.Model(&models.Event{}).Where("id = ?", ticketType.EventID).Update("status", models.EventStatusPublished)
+	db.Model(ticketType).Update("sold", 1)
+
+	first := createWaitlistBuyer(t, db)
+	second := createWaitlistBuyer(t, db)
+	firstEntry, err := service.Join(ticketType.ID, first.ID, 1)
+	if err != nil {
+		t.Fatalf("Join failed: %v", err)
+	}
+	secondEntry, err := service.Join(ticketType.ID, second.ID, 1)
+	if err != nil {
+		t.Fatalf("Join failed: %v", err)
+	}
+
+	db.Model(ticketType).Update("sold", 0)
+	if offered, err := service.OfferAvailable(ticketType.ID); err != nil || offered != 1 {
+		t.Fatalf("Expected one offer, got %d (%v)", offered, err)
+	}
+
+	// Let the first offer run out
+	db.Model(firstEntry).Update("offer_expires_at", time.Now().Add(-time.Minute))
+	if _, err := service.ProcessOffers(100); err != nil {
+		t.Fatalf("ProcessOffers failed: %v", err)
+	}
+
+	db.First(firstEntry, "id = ?", firstEntry.ID)
+	db.First(secondEntry, "id = ?", secondEntry.ID)
+	if firstEntry.Status != models.WaitlistStatusExpired {
+		t.Errorf("Expected the first offer to expire, got %s", firstEntry.Status)
+	}
+	if secondEntry.Status != models.WaitlistStatusOffered {
+		t.Errorf("Expected the ticket to pass to the next in line, got %s", secondEntry.Status)
+	}
+	db.First(ticketType, "id = ?", ticketType.ID)
+	if ticketType.Reserved != 1 {
+		t.Errorf("Expected one ticket reserved for the new offer, got %d", ticketType.Reserved)
+	}
+}